@@ -43,7 +43,10 @@ import (
 	"github.com/trustbloc/edv/pkg/client"
 
 	"github.com/trustbloc/edge-service/cmd/common"
+	"github.com/trustbloc/edge-service/pkg/did"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
 	"github.com/trustbloc/edge-service/pkg/jsonld"
+	"github.com/trustbloc/edge-service/pkg/lock"
 	restgovernance "github.com/trustbloc/edge-service/pkg/restapi/governance"
 	governanceops "github.com/trustbloc/edge-service/pkg/restapi/governance/operation"
 	restholder "github.com/trustbloc/edge-service/pkg/restapi/holder"
@@ -93,7 +96,9 @@ const (
 	databaseTypeEnvKey        = "DATABASE_TYPE"
 	databaseTypeFlagShorthand = "t"
 	databaseTypeFlagUsage     = "The type of database to use for everything except key storage. " +
-		"Supported options: mem, couchdb, mysql. " + commonEnvVarUsageText + databaseTypeEnvKey
+		"Supported options: mem, couchdb, mysql. Instances sharing a couchdb or mysql database lock status list, " +
+		"profile and challenge updates on a best-effort basis only, the locks are leases kept in the database " +
+		"without atomic compare-and-swap. " + commonEnvVarUsageText + databaseTypeEnvKey
 
 	databaseURLFlagName      = "database-url"
 	databaseURLEnvKey        = "DATABASE_URL"
@@ -175,6 +180,44 @@ const (
 	didAnchorOriginEnvKey    = "VC_REST_DID_ANCHOR_ORIGIN"
 	didAnchorOriginFlagUsage = "DID anchor origin" + commonEnvVarUsageText + didAnchorOriginEnvKey
 
+	statusListMaxAgeFlagName  = "status-list-max-age"
+	statusListMaxAgeEnvKey    = "VC_REST_STATUS_LIST_MAX_AGE"
+	statusListMaxAgeFlagUsage = "Time (in seconds) verifiers may cache a credential status list for " +
+		"before revalidating it with the issuer. Defaults to 0, which requires revalidation on every use. " +
+		commonEnvVarUsageText + statusListMaxAgeEnvKey
+
+	statusCacheTTLFlagName  = "status-cache-ttl"
+	statusCacheTTLEnvKey    = "VC_REST_STATUS_CACHE_TTL"
+	statusCacheTTLFlagUsage = "Maximum time (in seconds) the verifier caches a credential status list for " +
+		"before revalidating it with the issuer. The max age set by the issuer is used if shorter. " +
+		"Defaults to 0, which disables caching. " + commonEnvVarUsageText + statusCacheTTLEnvKey
+
+	clockSkewFlagName  = "clock-skew"
+	clockSkewEnvKey    = "VC_REST_CLOCK_SKEW"
+	clockSkewFlagUsage = "Time (in seconds) the clocks of issuers and the verifier may differ by when checking " +
+		"the validity period of credentials. Defaults to 0. " + commonEnvVarUsageText + clockSkewEnvKey
+
+	challengeTTLFlagName  = "challenge-ttl"
+	challengeTTLEnvKey    = "VC_REST_CHALLENGE_TTL"
+	challengeTTLFlagUsage = "Time (in seconds) the challenges issued by the verifier and for credential refreshes " +
+		"can be used for. Defaults to 300. " + commonEnvVarUsageText + challengeTTLEnvKey
+
+	schemaDirFlagName  = "schema-dir"
+	schemaDirEnvKey    = "VC_REST_SCHEMA_DIR"
+	schemaDirFlagUsage = "Directory of the JSON schema files credentials are validated against, keyed by their $id. " +
+		"Other credential schemas are fetched from their URL. " + commonEnvVarUsageText + schemaDirEnvKey
+
+	schemaCacheTTLFlagName  = "schema-cache-ttl"
+	schemaCacheTTLEnvKey    = "VC_REST_SCHEMA_CACHE_TTL"
+	schemaCacheTTLFlagUsage = "Time (in seconds) fetched credential schemas are cached for. " +
+		"Defaults to 0, which disables caching. " + commonEnvVarUsageText + schemaCacheTTLEnvKey
+
+	schemaAllowedHostsFlagName  = "schema-allowed-hosts"
+	schemaAllowedHostsEnvKey    = "VC_REST_SCHEMA_ALLOWED_HOSTS"
+	schemaAllowedHostsFlagUsage = "Hosts credential schemas may be fetched from. " +
+		"Defaults to any host, the schema URLs come from the credentials issued and verified so this should be set. " +
+		commonEnvVarUsageText + schemaAllowedHostsEnvKey
+
 	databaseTypeMemOption     = "mem"
 	databaseTypeCouchDBOption = "couchdb"
 	databaseTypeMYSQLDBOption = "mysql"
@@ -227,6 +270,13 @@ type vcRestParameters struct {
 	logLevel             string
 	governanceClaimsFile string
 	didAnchorOrigin      string
+	statusListMaxAge     time.Duration
+	statusCacheTTL       time.Duration
+	clockSkew            time.Duration
+	challengeTTL         time.Duration
+	schemaDir            string
+	schemaCacheTTL       time.Duration
+	schemaAllowedHosts   []string
 }
 
 type dbParameters struct {
@@ -353,6 +403,39 @@ func getVCRestParameters(cmd *cobra.Command) (*vcRestParameters, error) {
 
 	didAnchorOrigin := cmdutils.GetUserSetOptionalVarFromString(cmd, didAnchorOriginFlagName, didAnchorOriginEnvKey)
 
+	statusListMaxAge, err := getDurationInSeconds(cmd, statusListMaxAgeFlagName, statusListMaxAgeEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCacheTTL, err := getDurationInSeconds(cmd, statusCacheTTLFlagName, statusCacheTTLEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clockSkew, err := getDurationInSeconds(cmd, clockSkewFlagName, clockSkewEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeTTL, err := getDurationInSeconds(cmd, challengeTTLFlagName, challengeTTLEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDir := cmdutils.GetUserSetOptionalVarFromString(cmd, schemaDirFlagName, schemaDirEnvKey)
+
+	schemaCacheTTL, err := getDurationInSeconds(cmd, schemaCacheTTLFlagName, schemaCacheTTLEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaAllowedHosts, err := cmdutils.GetUserSetVarFromArrayString(cmd, schemaAllowedHostsFlagName,
+		schemaAllowedHostsEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
 	return &vcRestParameters{
 		hostURL:              hostURL,
 		edvURL:               edvURL,
@@ -369,9 +452,31 @@ func getVCRestParameters(cmd *cobra.Command) (*vcRestParameters, error) {
 		logLevel:             loggingLevel,
 		governanceClaimsFile: governanceClaimsFile,
 		didAnchorOrigin:      didAnchorOrigin,
+		statusListMaxAge:     statusListMaxAge,
+		statusCacheTTL:       statusCacheTTL,
+		clockSkew:            clockSkew,
+		challengeTTL:         challengeTTL,
+		schemaDir:            schemaDir,
+		schemaCacheTTL:       schemaCacheTTL,
+		schemaAllowedHosts:   schemaAllowedHosts,
 	}, nil
 }
 
+func getDurationInSeconds(cmd *cobra.Command, flagName, envKey string) (time.Duration, error) {
+	secondsString := cmdutils.GetUserSetOptionalVarFromString(cmd, flagName, envKey)
+	if secondsString == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.ParseUint(secondsString, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(`the given %s value "%s" is not a valid non-negative integer: %w`,
+			flagName, secondsString, err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
 func getRequestTokens(cmd *cobra.Command) (map[string]string, error) {
 	requestTokens, err := cmdutils.GetUserSetVarFromArrayString(cmd, requestTokensFlagName,
 		requestTokensEnvKey, true)
@@ -611,6 +716,13 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(common.LogLevelFlagName, common.LogLevelFlagShorthand, "", common.LogLevelPrefixFlagUsage)
 	startCmd.Flags().StringP(governanceClaimsFlagName, "", "", governanceClaimsFlagUsage)
 	startCmd.Flags().StringP(didAnchorOriginFlagName, "", "", didAnchorOriginFlagUsage)
+	startCmd.Flags().StringP(statusListMaxAgeFlagName, "", "", statusListMaxAgeFlagUsage)
+	startCmd.Flags().StringP(statusCacheTTLFlagName, "", "", statusCacheTTLFlagUsage)
+	startCmd.Flags().StringP(clockSkewFlagName, "", "", clockSkewFlagUsage)
+	startCmd.Flags().StringP(challengeTTLFlagName, "", "", challengeTTLFlagUsage)
+	startCmd.Flags().StringP(schemaDirFlagName, "", "", schemaDirFlagUsage)
+	startCmd.Flags().StringP(schemaCacheTTLFlagName, "", "", schemaCacheTTLFlagUsage)
+	startCmd.Flags().StringArrayP(schemaAllowedHostsFlagName, "", []string{}, schemaAllowedHostsFlagUsage)
 }
 
 // nolint: gocyclo,funlen,gocognit
@@ -629,7 +741,17 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 		return err
 	}
 
-	localKMS, err := createKMS(edgeServiceProvs.kmsSecretsProvider)
+	secretLock, err := createSecretLock(edgeServiceProvs.kmsSecretsProvider)
+	if err != nil {
+		return err
+	}
+
+	localKMS, err := createKMS(edgeServiceProvs.kmsSecretsProvider, secretLock)
+	if err != nil {
+		return err
+	}
+
+	didOperationKeys, err := did.NewOperationKeyStore(edgeServiceProvs.kmsSecretsProvider, secretLock, masterKeyURI)
 	if err != nil {
 		return err
 	}
@@ -637,7 +759,7 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 	// Create VDRI
 	vdr, err := createVDRI(parameters.universalResolverURL,
 		&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}, parameters.blocDomain,
-		parameters.requestTokens["sidetreeToken"])
+		parameters.requestTokens["sidetreeToken"], didOperationKeys)
 	if err != nil {
 		return err
 	}
@@ -663,6 +785,18 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 		return err
 	}
 
+	schemaLoader, err := createSchemaLoader(parameters, &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		return err
+	}
+
+	// the issuer and governance services of this and other instances update the same status lists,
+	// the issuers and verifiers consume the same challenges, all the services update the same profiles
+	storeLocker, err := lock.NewStoreLocker(edgeServiceProvs.provider)
+	if err != nil {
+		return err
+	}
+
 	issuerService, err := restissuer.New(&issuerops.Config{
 		StoreProvider:      edgeServiceProvs.provider,
 		KMSSecretsProvider: edgeServiceProvs.kmsSecretsProvider,
@@ -670,15 +804,22 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 			RootCAs:    rootCAs,
 			MinVersion: tls.VersionTLS12,
 		})),
-		KeyManager:      localKMS,
-		Crypto:          crypto,
-		VDRI:            vdr,
-		HostURL:         externalHostURL,
-		Domain:          parameters.blocDomain,
-		TLSConfig:       &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
-		RetryParameters: parameters.retryParameters,
-		DIDAnchorOrigin: parameters.didAnchorOrigin,
-		DocumentLoader:  loader,
+		KeyManager:       localKMS,
+		Crypto:           crypto,
+		VDRI:             vdr,
+		HostURL:          externalHostURL,
+		Domain:           parameters.blocDomain,
+		TLSConfig:        &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+		RetryParameters:  parameters.retryParameters,
+		DIDAnchorOrigin:  parameters.didAnchorOrigin,
+		DocumentLoader:   loader,
+		StatusListMaxAge: parameters.statusListMaxAge,
+		SchemaLoader:     schemaLoader,
+		DIDOperationKeys: didOperationKeys,
+		StatusListLocker: storeLocker,
+		ProfileLocker:    storeLocker,
+		ChallengeTTL:     parameters.challengeTTL,
+		ChallengeLocker:  storeLocker,
 	})
 	if err != nil {
 		return err
@@ -693,6 +834,7 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 		VDRI: vdr, Domain: parameters.blocDomain,
 		DIDAnchorOrigin: parameters.didAnchorOrigin,
 		DocumentLoader:  loader,
+		ProfileLocker:   storeLocker,
 	})
 	if err != nil {
 		return err
@@ -701,8 +843,14 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 	verifierService, err := restverifier.New(&verifierops.Config{
 		StoreProvider: edgeServiceProvs.provider,
 		TLSConfig:     &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}, VDRI: vdr,
-		RequestTokens:  parameters.requestTokens,
-		DocumentLoader: loader,
+		RequestTokens:   parameters.requestTokens,
+		DocumentLoader:  loader,
+		StatusCacheTTL:  parameters.statusCacheTTL,
+		ClockSkew:       parameters.clockSkew,
+		ChallengeTTL:    parameters.challengeTTL,
+		SchemaLoader:    schemaLoader,
+		ChallengeLocker: storeLocker,
+		ProfileLocker:   storeLocker,
 	})
 	if err != nil {
 		return err
@@ -714,7 +862,8 @@ func startEdgeService(parameters *vcRestParameters, srv server) error {
 			MinVersion: tls.VersionTLS12,
 		}, StoreProvider: edgeServiceProvs.provider, KeyManager: localKMS, Crypto: crypto,
 		VDRI: vdr, Domain: parameters.blocDomain, HostURL: externalHostURL, ClaimsFile: parameters.governanceClaimsFile,
-		DIDAnchorOrigin: parameters.didAnchorOrigin, DocumentLoader: loader,
+		DIDAnchorOrigin: parameters.didAnchorOrigin, DocumentLoader: loader, StatusListLocker: storeLocker,
+		ProfileLocker: storeLocker,
 	})
 	if err != nil {
 		return err
@@ -769,8 +918,32 @@ func (k kmsProvider) SecretLock() secretlock.Service {
 	return k.secretLockService
 }
 
+// createSchemaLoader loads credential schemas from the schema dir, falling back to fetching them
+// from their URL on the allowed hosts, fetched schemas are cached if a cache TTL is set.
+func createSchemaLoader(parameters *vcRestParameters, tlsConfig *tls.Config) (schema.Loader, error) {
+	var loader schema.Loader = schema.NewHTTPLoader(
+		&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		schema.WithAllowedHosts(parameters.schemaAllowedHosts...),
+	)
+
+	if parameters.schemaCacheTTL > 0 {
+		loader = schema.NewCachingLoader(loader, parameters.schemaCacheTTL)
+	}
+
+	if parameters.schemaDir == "" {
+		return loader, nil
+	}
+
+	schemas, err := schema.ReadDir(parameters.schemaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.NewLocalLoader(schemas, loader), nil
+}
+
 func createVDRI(universalResolver string, tlsConfig *tls.Config, blocDomain,
-	sidetreeAuthToken string) (vdrapi.Registry, error) {
+	sidetreeAuthToken string, keyRetriever orb.KeyRetriever) (vdrapi.Registry, error) {
 	var opts []vdrpkg.Option
 
 	if universalResolver != "" {
@@ -784,7 +957,7 @@ func createVDRI(universalResolver string, tlsConfig *tls.Config, blocDomain,
 		opts = append(opts, vdrpkg.WithVDR(universalResolverVDRI))
 	}
 
-	vdr, err := orb.New(nil, orb.WithDomain(blocDomain), orb.WithTLSConfig(tlsConfig),
+	vdr, err := orb.New(keyRetriever, orb.WithDomain(blocDomain), orb.WithTLSConfig(tlsConfig),
 		orb.WithAuthToken(sidetreeAuthToken))
 	if err != nil {
 		return nil, err
@@ -875,32 +1048,23 @@ func createStoreProviders(parameters *vcRestParameters) (*edgeServiceProviders,
 	return &edgeServiceProvs, nil
 }
 
-func createKMS(kmsSecretsProvider ariesstorage.Provider) (*localkms.LocalKMS, error) {
-	localKMS, err := createLocalKMS(kmsSecretsProvider)
-	if err != nil {
-		return nil, err
+func createKMS(kmsSecretsProvider ariesstorage.Provider, secretLock secretlock.Service) (*localkms.LocalKMS, error) {
+	kmsProv := kmsProvider{
+		storageProvider:   kmsSecretsProvider,
+		secretLockService: secretLock,
 	}
 
-	return localKMS, nil
+	return localkms.New(masterKeyURI, kmsProv)
 }
 
-func createLocalKMS(kmsSecretsStoreProvider ariesstorage.Provider) (*localkms.LocalKMS, error) {
+// createSecretLock creates the secret lock of the master key, it encrypts the KMS keys and the DID operation keys.
+func createSecretLock(kmsSecretsStoreProvider ariesstorage.Provider) (secretlock.Service, error) {
 	masterKeyReader, err := prepareMasterKeyReader(kmsSecretsStoreProvider)
 	if err != nil {
 		return nil, err
 	}
 
-	secretLockService, err := local.NewService(masterKeyReader, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	kmsProv := kmsProvider{
-		storageProvider:   kmsSecretsStoreProvider,
-		secretLockService: secretLockService,
-	}
-
-	return localkms.New(masterKeyURI, kmsProv)
+	return local.NewService(masterKeyReader, nil)
 }
 
 // prepareMasterKeyReader prepares a master key reader for secret lock usage
@@ -933,8 +1097,14 @@ func prepareMasterKeyReader(kmsSecretsStoreProvider ariesstorage.Provider) (*byt
 func constructCORSHandler(handler http.Handler) http.Handler {
 	return cors.New(
 		cors.Options{
-			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodHead},
-			AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization"},
+			AllowedMethods: []string{
+				http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+			},
+			AllowedHeaders: []string{
+				"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization",
+				"X-Caller-ID", "If-None-Match", "If-Modified-Since",
+			},
+			ExposedHeaders: []string{"ETag", "Last-Modified"},
 		},
 	).Handler(handler)
 }
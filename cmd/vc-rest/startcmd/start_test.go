@@ -8,9 +8,11 @@ package startcmd
 import (
 	"crypto/tls"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
@@ -216,6 +218,70 @@ func TestStartCmdWithNegativeInitialBackoff(t *testing.T) {
 		`strconv.ParseUint: parsing "-500": invalid syntax`)
 }
 
+func TestStartCmdWithInvalidStatusCacheTTL(t *testing.T) {
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080", "--" + edvURLFlagName,
+		"localhost:8081", "--" + blocDomainFlagName, "domain", "--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + statusCacheTTLFlagName, "-60",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.EqualError(t, err, `the given status-cache-ttl value "-60" is not a valid non-negative integer: `+
+		`strconv.ParseUint: parsing "-60": invalid syntax`)
+}
+
+func TestStartCmdWithInvalidClockSkew(t *testing.T) {
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080", "--" + edvURLFlagName,
+		"localhost:8081", "--" + blocDomainFlagName, "domain", "--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + clockSkewFlagName, "1m",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.EqualError(t, err, `the given clock-skew value "1m" is not a valid non-negative integer: `+
+		`strconv.ParseUint: parsing "1m": invalid syntax`)
+}
+
+func TestStartCmdWithInvalidChallengeTTL(t *testing.T) {
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080", "--" + edvURLFlagName,
+		"localhost:8081", "--" + blocDomainFlagName, "domain", "--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + challengeTTLFlagName, "5m",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.EqualError(t, err, `the given challenge-ttl value "5m" is not a valid non-negative integer: `+
+		`strconv.ParseUint: parsing "5m": invalid syntax`)
+}
+
+func TestStartCmdWithInvalidSchemaDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.json"), []byte(`{"type":"object"}`), 0600))
+
+	startCmd := GetStartCmd(&mockServer{})
+
+	args := []string{
+		"--" + hostURLFlagName, "localhost:8080", "--" + edvURLFlagName,
+		"localhost:8081", "--" + blocDomainFlagName, "domain", "--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption, "--" + schemaDirFlagName, dir,
+		"--" + schemaCacheTTLFlagName, "60", "--" + schemaAllowedHostsFlagName, "example.com",
+	}
+	startCmd.SetArgs(args)
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has no $id")
+}
+
 func TestStartCmdWithInvalidFloatingPointBackoffFactor(t *testing.T) {
 	startCmd := GetStartCmd(&mockServer{})
 
@@ -330,10 +396,20 @@ func TestCreateProviders(t *testing.T) {
 }
 
 func TestCreateKMS(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := ariesmockstorage.NewMockStoreProvider()
+
+		secretLock, err := createSecretLock(provider)
+		require.NoError(t, err)
+
+		localKMS, err := createKMS(provider, secretLock)
+		require.NoError(t, err)
+		require.NotNil(t, localKMS)
+	})
 	t.Run("fail to open master key store", func(t *testing.T) {
-		localKMS, err := createKMS(&ariesmockstorage.MockStoreProvider{FailNamespace: "masterkey"})
+		secretLock, err := createSecretLock(&ariesmockstorage.MockStoreProvider{FailNamespace: "masterkey"})
 
-		require.Nil(t, localKMS)
+		require.Nil(t, secretLock)
 		require.EqualError(t, err, "failed to open store for name space masterkey")
 	})
 	t.Run("fail to create master key service", func(t *testing.T) {
@@ -344,15 +420,15 @@ func TestCreateKMS(t *testing.T) {
 		err := masterKeyStore.Put("masterkey", []byte(""))
 		require.NoError(t, err)
 
-		localKMS, err := createKMS(&ariesmockstorage.MockStoreProvider{Store: &masterKeyStore})
+		secretLock, err := createSecretLock(&ariesmockstorage.MockStoreProvider{Store: &masterKeyStore})
 		require.EqualError(t, err, "masterKeyReader is empty")
-		require.Nil(t, localKMS)
+		require.Nil(t, secretLock)
 	})
 }
 
 func TestCreateVDRI(t *testing.T) {
 	t.Run("test error from create new universal resolver vdr", func(t *testing.T) {
-		v, err := createVDRI("wrong", &tls.Config{MinVersion: tls.VersionTLS12}, "", "")
+		v, err := createVDRI("wrong", &tls.Config{MinVersion: tls.VersionTLS12}, "", "", nil)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to create new universal resolver vdr")
 		require.Nil(t, v)
@@ -368,7 +444,7 @@ func TestCreateVDRI(t *testing.T) {
 	})
 
 	t.Run("test success", func(t *testing.T) {
-		v, err := createVDRI("localhost:8083", &tls.Config{MinVersion: tls.VersionTLS12}, "", "")
+		v, err := createVDRI("localhost:8083", &tls.Config{MinVersion: tls.VersionTLS12}, "", "", nil)
 		require.NoError(t, err)
 		require.NotNil(t, v)
 	})
@@ -522,3 +598,36 @@ func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName, flag
 	flagAnnotations := flag.Annotations
 	require.Nil(t, flagAnnotations)
 }
+
+func TestCORSHandler(t *testing.T) {
+	handler := constructCORSHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		req := httptest.NewRequest(http.MethodOptions, "/profile/test", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", method)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, method, rr.Header().Get("Access-Control-Allow-Methods"))
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/status/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "If-None-Match, If-Modified-Since")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, "If-None-Match, If-Modified-Since", rr.Header().Get("Access-Control-Allow-Headers"))
+
+	req = httptest.NewRequest(http.MethodGet, "/status/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, "Etag, Last-Modified", rr.Header().Get("Access-Control-Expose-Headers"))
+}
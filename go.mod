@@ -35,4 +35,6 @@ require (
 	github.com/trustbloc/edv v0.1.7-0.20210527173439-3b17690a0345
 	github.com/trustbloc/kms v0.1.7-0.20210527174658-019e1bcabd9c
 	github.com/trustbloc/trustbloc-did-method v0.1.7-0.20210514185319-4d40ab112344
+	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415
+	github.com/xeipuuv/gojsonschema v1.2.0
 )
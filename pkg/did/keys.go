@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/vdr/orb"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+const operationKeysStoreName = "didoperationkeys"
+
+// OperationKeys are the sidetree keys of a DID, used to sign updates and recovery of its document.
+type OperationKeys struct {
+	UpdateKey     ed25519.PrivateKey `json:"updateKey"`
+	NextUpdateKey ed25519.PrivateKey `json:"nextUpdateKey,omitempty"`
+	RecoveryKey   ed25519.PrivateKey `json:"recoveryKey"`
+}
+
+// OperationKeyStore keeps the sidetree operation keys of the DIDs created by the service, so that their documents
+// can be updated later. It is the orb.KeyRetriever of the orb VDR, which signs with the private keys themselves,
+// so they can't be kept in the KMS. They are encrypted with the secret lock of the KMS instead.
+type OperationKeyStore struct {
+	store      ariesstorage.Store
+	secretLock secretlock.Service
+	keyURI     string
+}
+
+// NewOperationKeyStore returns new operation key store, the keys are encrypted with the master key of the
+// given secret lock.
+func NewOperationKeyStore(provider ariesstorage.Provider, secretLock secretlock.Service,
+	keyURI string) (*OperationKeyStore, error) {
+	store, err := provider.OpenStore(operationKeysStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open did operation keys store: %w", err)
+	}
+
+	return &OperationKeyStore{store: store, secretLock: secretLock, keyURI: keyURI}, nil
+}
+
+// GenerateKeys generates new update and recovery keys, to be saved once the DID is created.
+func GenerateKeys() (*OperationKeys, error) {
+	_, updateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate update key: %w", err)
+	}
+
+	_, recoveryKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery key: %w", err)
+	}
+
+	return &OperationKeys{UpdateKey: updateKey, RecoveryKey: recoveryKey}, nil
+}
+
+// Save saves the operation keys of the DID.
+func (s *OperationKeyStore) Save(didID string, keys *OperationKeys) error {
+	keysBytes, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal did operation keys: %w", err)
+	}
+
+	// the DID is authenticated with the keys so that they can't be swapped between DIDs
+	encrypted, err := s.secretLock.Encrypt(s.keyURI, &secretlock.EncryptRequest{
+		Plaintext:                   string(keysBytes),
+		AdditionalAuthenticatedData: didID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt did operation keys: %w", err)
+	}
+
+	return s.store.Put(didID, []byte(encrypted.Ciphertext))
+}
+
+// Get returns the operation keys of the DID, the error wraps ariesstorage.ErrDataNotFound if the service
+// has no keys for it.
+func (s *OperationKeyStore) Get(didID string) (*OperationKeys, error) {
+	encrypted, err := s.store.Get(didID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation keys of did %s: %w", didID, err)
+	}
+
+	decrypted, err := s.secretLock.Decrypt(s.keyURI, &secretlock.DecryptRequest{
+		Ciphertext:                  string(encrypted),
+		AdditionalAuthenticatedData: didID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt operation keys of did %s: %w", didID, err)
+	}
+
+	keys := &OperationKeys{}
+
+	if err = json.Unmarshal([]byte(decrypted.Plaintext), keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal did operation keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// CommitUpdate makes the next update key the update key of the DID, once an update using it was accepted.
+func (s *OperationKeyStore) CommitUpdate(didID string) error {
+	keys, err := s.Get(didID)
+	if err != nil {
+		return err
+	}
+
+	if keys.NextUpdateKey == nil {
+		return fmt.Errorf("did %s has no pending update", didID)
+	}
+
+	keys.UpdateKey, keys.NextUpdateKey = keys.NextUpdateKey, nil
+
+	return s.Save(didID, keys)
+}
+
+// GetNextUpdatePublicKey returns the public key the next update of the DID has to be signed with,
+// generating it on first use.
+func (s *OperationKeyStore) GetNextUpdatePublicKey(didID string) (crypto.PublicKey, error) {
+	keys, err := s.Get(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	if keys.NextUpdateKey == nil {
+		_, keys.NextUpdateKey, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate next update key: %w", err)
+		}
+
+		if err = s.Save(didID, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys.NextUpdateKey.Public(), nil
+}
+
+// GetNextRecoveryPublicKey isn't supported, DIDs of the service aren't recovered.
+func (s *OperationKeyStore) GetNextRecoveryPublicKey(didID string) (crypto.PublicKey, error) {
+	return nil, fmt.Errorf("recovery of did %s isn't supported", didID)
+}
+
+// GetSigningKey returns the key the given operation on the DID is signed with.
+func (s *OperationKeyStore) GetSigningKey(didID string, ot orb.OperationType) (crypto.PrivateKey, error) {
+	keys, err := s.Get(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ot == orb.Recover {
+		return keys.RecoveryKey, nil
+	}
+
+	return keys.UpdateKey, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/vdr/orb"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	did2 "github.com/trustbloc/edge-service/pkg/did"
+)
+
+const keyURI = "local-lock://test/master/key/"
+
+func TestOperationKeyStore(t *testing.T) {
+	const didID = "did:orb:abc"
+
+	t.Run("update keys", func(t *testing.T) {
+		s, err := did2.NewOperationKeyStore(mem.NewProvider(), newSecretLock(t), keyURI)
+		require.NoError(t, err)
+
+		keys, err := did2.GenerateKeys()
+		require.NoError(t, err)
+		require.NoError(t, s.Save(didID, keys))
+
+		signingKey, err := s.GetSigningKey(didID, orb.Update)
+		require.NoError(t, err)
+		require.Equal(t, keys.UpdateKey, signingKey)
+
+		signingKey, err = s.GetSigningKey(didID, orb.Recover)
+		require.NoError(t, err)
+		require.Equal(t, keys.RecoveryKey, signingKey)
+
+		nextKey, err := s.GetNextUpdatePublicKey(didID)
+		require.NoError(t, err)
+
+		// the next key stays the same until the update is committed
+		again, err := s.GetNextUpdatePublicKey(didID)
+		require.NoError(t, err)
+		require.Equal(t, nextKey, again)
+
+		require.NoError(t, s.CommitUpdate(didID))
+
+		updated, err := s.Get(didID)
+		require.NoError(t, err)
+		require.Nil(t, updated.NextUpdateKey)
+		require.Equal(t, nextKey, updated.UpdateKey.Public())
+
+		err = s.CommitUpdate(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "has no pending update")
+
+		_, err = s.GetNextRecoveryPublicKey(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "recovery of did did:orb:abc isn't supported")
+	})
+
+	t.Run("unknown DID", func(t *testing.T) {
+		s, err := did2.NewOperationKeyStore(mem.NewProvider(), newSecretLock(t), keyURI)
+		require.NoError(t, err)
+
+		_, err = s.GetSigningKey(didID, orb.Update)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get operation keys of did did:orb:abc")
+
+		_, err = s.GetNextUpdatePublicKey(didID)
+		require.Error(t, err)
+
+		require.Error(t, s.CommitUpdate(didID))
+	})
+
+	t.Run("keys are encrypted", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := did2.NewOperationKeyStore(provider, newSecretLock(t), keyURI)
+		require.NoError(t, err)
+
+		keys, err := did2.GenerateKeys()
+		require.NoError(t, err)
+		require.NoError(t, s.Save(didID, keys))
+
+		store, err := provider.OpenStore("didoperationkeys")
+		require.NoError(t, err)
+
+		stored, err := store.Get(didID)
+		require.NoError(t, err)
+		require.NotContains(t, string(stored), "updateKey")
+
+		// the keys of a DID can't be used for another one
+		require.NoError(t, store.Put("did:orb:other", stored))
+
+		_, err = s.Get("did:orb:other")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt operation keys of did did:orb:other")
+
+		// nor read without the master key
+		other, err := did2.NewOperationKeyStore(provider, newSecretLock(t), keyURI)
+		require.NoError(t, err)
+
+		_, err = other.Get(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt operation keys")
+	})
+
+	t.Run("unknown DID error is not found", func(t *testing.T) {
+		s, err := did2.NewOperationKeyStore(mem.NewProvider(), newSecretLock(t), keyURI)
+		require.NoError(t, err)
+
+		_, err = s.Get(didID)
+		require.True(t, errors.Is(err, ariesstorage.ErrDataNotFound))
+	})
+
+	t.Run("encrypt error", func(t *testing.T) {
+		s, err := did2.NewOperationKeyStore(mem.NewProvider(),
+			&mockSecretLock{err: errors.New("encrypt error")}, keyURI)
+		require.NoError(t, err)
+
+		keys, err := did2.GenerateKeys()
+		require.NoError(t, err)
+
+		err = s.Save(didID, keys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "encrypt error")
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		_, err := did2.NewOperationKeyStore(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+			newSecretLock(t), keyURI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open error")
+	})
+}
+
+func newSecretLock(t *testing.T) secretlock.Service {
+	t.Helper()
+
+	masterKey := make([]byte, 32)
+
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+
+	secretLock, err := local.NewService(bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(masterKey))), nil)
+	require.NoError(t, err)
+
+	return secretLock
+}
+
+type mockSecretLock struct {
+	err error
+}
+
+func (m *mockSecretLock) Encrypt(string, *secretlock.EncryptRequest) (*secretlock.EncryptResponse, error) {
+	return nil, m.err
+}
+
+func (m *mockSecretLock) Decrypt(string, *secretlock.DecryptRequest) (*secretlock.DecryptResponse, error) {
+	return nil, m.err
+}
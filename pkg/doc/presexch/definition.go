@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// RuleAll requires all the inputs of a submission requirement
+	RuleAll = "all"
+	// RulePick requires the count, min or max inputs of a submission requirement
+	RulePick = "pick"
+)
+
+// PresentationDefinition states the credentials a verifier requires,
+// see https://identity.foundation/presentation-exchange/spec/v2.0.0/#presentation-definition.
+type PresentationDefinition struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+	// Format lists the claim formats the verifier supports, it is passed on to the holder as is
+	Format json.RawMessage `json:"format,omitempty"`
+	// SubmissionRequirements select the input descriptors to match, all of them are required when empty
+	SubmissionRequirements []*SubmissionRequirement `json:"submission_requirements,omitempty"`
+	InputDescriptors       []*InputDescriptor       `json:"input_descriptors"`
+}
+
+// InputDescriptor describes a credential the verifier requires.
+type InputDescriptor struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	Purpose     string          `json:"purpose,omitempty"`
+	Group       []string        `json:"group,omitempty"`
+	Format      json.RawMessage `json:"format,omitempty"`
+	Constraints *Constraints    `json:"constraints,omitempty"`
+}
+
+// Constraints are the fields a credential has to hold to match its input descriptor.
+type Constraints struct {
+	LimitDisclosure string   `json:"limit_disclosure,omitempty"`
+	Fields          []*Field `json:"fields,omitempty"`
+}
+
+// Field is selected from the credential by the first of its JSONPath expressions finding a value,
+// which has to conform to the JSON Schema filter when there is one.
+type Field struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Purpose  string          `json:"purpose,omitempty"`
+	Path     []string        `json:"path"`
+	Filter   json.RawMessage `json:"filter,omitempty"`
+	Optional bool            `json:"optional,omitempty"`
+}
+
+// SubmissionRequirement requires all or some of the input descriptors of a group, or of the nested requirements.
+type SubmissionRequirement struct {
+	Name       string                   `json:"name,omitempty"`
+	Purpose    string                   `json:"purpose,omitempty"`
+	Rule       string                   `json:"rule"`
+	Count      int                      `json:"count,omitempty"`
+	Min        int                      `json:"min,omitempty"`
+	Max        int                      `json:"max,omitempty"`
+	From       string                   `json:"from,omitempty"`
+	FromNested []*SubmissionRequirement `json:"from_nested,omitempty"`
+}
+
+// Validate checks that the definition is well formed: its identifiers, JSONPath expressions, filters and
+// submission requirements.
+func (pd *PresentationDefinition) Validate() error {
+	if pd.ID == "" {
+		return errors.New("missing presentation definition id")
+	}
+
+	if len(pd.InputDescriptors) == 0 {
+		return fmt.Errorf("presentation definition %s has no input descriptors", pd.ID)
+	}
+
+	builder := gval.Full(jsonpath.PlaceholderExtension())
+	ids := make(map[string]bool)
+	groups := make(map[string]bool)
+
+	for _, d := range pd.InputDescriptors {
+		if d.ID == "" {
+			return fmt.Errorf("presentation definition %s has an input descriptor without id", pd.ID)
+		}
+
+		if ids[d.ID] {
+			return fmt.Errorf("presentation definition %s has input descriptor %s twice", pd.ID, d.ID)
+		}
+
+		ids[d.ID] = true
+
+		for _, g := range d.Group {
+			groups[g] = true
+		}
+
+		if err := validateConstraints(builder, d); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range pd.SubmissionRequirements {
+		if err := validateRequirement(r, groups); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateConstraints(builder gval.Language, d *InputDescriptor) error {
+	if d.Constraints == nil {
+		return nil
+	}
+
+	for _, f := range d.Constraints.Fields {
+		if len(f.Path) == 0 {
+			return fmt.Errorf("input descriptor %s has a field without path", d.ID)
+		}
+
+		for _, p := range f.Path {
+			if _, err := builder.NewEvaluable(p); err != nil {
+				return fmt.Errorf("input descriptor %s has invalid path %s: %w", d.ID, p, err)
+			}
+		}
+
+		if len(f.Filter) != 0 {
+			if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(f.Filter)); err != nil {
+				return fmt.Errorf("input descriptor %s has invalid filter for field %s: %w", d.ID, f.name(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateRequirement(r *SubmissionRequirement, groups map[string]bool) error {
+	if r.Rule != RuleAll && r.Rule != RulePick {
+		return fmt.Errorf("invalid submission requirement rule : %s", r.Rule)
+	}
+
+	if r.Count < 0 || r.Min < 0 || r.Max < 0 || (r.Max > 0 && r.Min > r.Max) {
+		return fmt.Errorf("invalid count, min or max of submission requirement %s", r.name())
+	}
+
+	switch {
+	case r.From != "" && len(r.FromNested) != 0:
+		return fmt.Errorf("submission requirement %s has both from and from_nested", r.name())
+	case r.From != "":
+		if !groups[r.From] {
+			return fmt.Errorf("submission requirement %s is from unknown group %s", r.name(), r.From)
+		}
+	case len(r.FromNested) != 0:
+		for _, nested := range r.FromNested {
+			if err := validateRequirement(nested, groups); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("submission requirement %s has neither from nor from_nested", r.name())
+	}
+
+	return nil
+}
+
+func (f *Field) name() string {
+	if f.ID != "" {
+		return f.ID
+	}
+
+	if len(f.Path) != 0 {
+		return f.Path[0]
+	}
+
+	return ""
+}
+
+func (r *SubmissionRequirement) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	if r.From != "" {
+		return "from group " + r.From
+	}
+
+	return "from nested requirements"
+}
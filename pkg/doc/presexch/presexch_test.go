@@ -0,0 +1,269 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+)
+
+const definitionJSON = `{
+  "id": "pd-1",
+  "submission_requirements": [
+    {"name": "card", "rule": "all", "from": "A"},
+    {"name": "degree", "rule": "pick", "min": 1, "from": "B"}
+  ],
+  "input_descriptors": [
+    {
+      "id": "prc",
+      "group": ["A"],
+      "constraints": {
+        "fields": [
+          {
+            "path": ["$.credentialSubject.type", "$.vc.credentialSubject.type"],
+            "filter": {"const": "PermanentResident"}
+          },
+          {"id": "nickname", "path": ["$.credentialSubject.nickname"], "optional": true}
+        ]
+      }
+    },
+    {
+      "id": "bachelor",
+      "group": ["B"],
+      "constraints": {"fields": [{"path": ["$.credentialSubject.degree.type"], "filter": {"const": "BachelorDegree"}}]}
+    },
+    {
+      "id": "master",
+      "group": ["B"],
+      "constraints": {"fields": [{"path": ["$.credentialSubject.degree.type"], "filter": {"const": "MasterDegree"}}]}
+    }
+  ]
+}`
+
+func TestPresentationDefinition_Validate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, parseDefinition(t, definitionJSON).Validate())
+	})
+
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{"missing id", `{"input_descriptors": [{"id": "a"}]}`, "missing presentation definition id"},
+		{"no input descriptors", `{"id": "pd"}`, "presentation definition pd has no input descriptors"},
+		{"descriptor without id", `{"id": "pd", "input_descriptors": [{}]}`, "has an input descriptor without id"},
+		{"duplicate descriptor", `{"id": "pd", "input_descriptors": [{"id": "a"}, {"id": "a"}]}`,
+			"has input descriptor a twice"},
+		{"field without path", `{"id": "pd", "input_descriptors": [{"id": "a", "constraints": {"fields": [{}]}}]}`,
+			"input descriptor a has a field without path"},
+		{"invalid path", `{"id": "pd", "input_descriptors": [{"id": "a", "constraints": {"fields": [{"path": ["$[?"]}]}}]}`,
+			"input descriptor a has invalid path $[?"},
+		{"invalid filter", `{"id": "pd", "input_descriptors": [{"id": "a", "constraints": {"fields": [
+			{"path": ["$.a"], "filter": {"type": 5}}]}}]}`, "input descriptor a has invalid filter for field $.a"},
+		{"invalid rule", `{"id": "pd", "submission_requirements": [{"rule": "any", "from": "A"}],
+			"input_descriptors": [{"id": "a", "group": ["A"]}]}`, "invalid submission requirement rule : any"},
+		{"invalid count", `{"id": "pd", "submission_requirements": [{"rule": "pick", "min": 2, "max": 1, "from": "A"}],
+			"input_descriptors": [{"id": "a", "group": ["A"]}]}`, "invalid count, min or max"},
+		{"unknown group", `{"id": "pd", "submission_requirements": [{"rule": "all", "from": "B"}],
+			"input_descriptors": [{"id": "a", "group": ["A"]}]}`, "is from unknown group B"},
+		{"no from", `{"id": "pd", "submission_requirements": [{"rule": "all"}],
+			"input_descriptors": [{"id": "a", "group": ["A"]}]}`, "has neither from nor from_nested"},
+		{"both from", `{"id": "pd", "submission_requirements": [{"rule": "all", "from": "A",
+			"from_nested": [{"rule": "all", "from": "A"}]}], "input_descriptors": [{"id": "a", "group": ["A"]}]}`,
+			"has both from and from_nested"},
+		{"invalid nested", `{"id": "pd", "submission_requirements": [{"rule": "all",
+			"from_nested": [{"rule": "all", "from": "B"}]}], "input_descriptors": [{"id": "a", "group": ["A"]}]}`,
+			"is from unknown group B"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := parseDefinition(t, tc.json).Validate()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestGetSubmission(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		submission, err := presexch.GetSubmission(map[string]interface{}{
+			"presentation_submission": map[string]interface{}{
+				"id":             "s-1",
+				"definition_id":  "pd-1",
+				"descriptor_map": []interface{}{map[string]interface{}{"id": "prc", "path": "$.verifiableCredential[0]"}},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "pd-1", submission.DefinitionID)
+		require.Len(t, submission.DescriptorMap, 1)
+		require.Equal(t, "$.verifiableCredential[0]", submission.DescriptorMap[0].Path)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := presexch.GetSubmission(map[string]interface{}{})
+		require.ErrorIs(t, err, presexch.ErrSubmissionNotFound)
+	})
+
+	t.Run("invalid submission", func(t *testing.T) {
+		_, err := presexch.GetSubmission(map[string]interface{}{"presentation_submission": "submission"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid presentation submission")
+	})
+}
+
+func TestPresentationDefinition_Evaluate(t *testing.T) {
+	pd := parseDefinition(t, definitionJSON)
+
+	prc := map[string]interface{}{"credentialSubject": map[string]interface{}{"type": "PermanentResident"}}
+	bachelor := map[string]interface{}{
+		"credentialSubject": map[string]interface{}{"degree": map[string]interface{}{"type": "BachelorDegree"}},
+	}
+
+	t.Run("matched", func(t *testing.T) {
+		vp := presentation(prc, bachelor)
+
+		evaluation := pd.Evaluate(vp, submission("pd-1",
+			mapping("prc", "$.verifiableCredential[0]"),
+			mapping("bachelor", "$.verifiableCredential[1]"),
+		))
+		require.True(t, evaluation.Matched, evaluation.Errors)
+		require.Equal(t, "pd-1", evaluation.DefinitionID)
+		require.Len(t, evaluation.InputDescriptors, 3)
+		require.True(t, evaluation.InputDescriptors[0].Matched)
+		require.Equal(t, "$.verifiableCredential[0]", evaluation.InputDescriptors[0].Path)
+		require.True(t, evaluation.InputDescriptors[1].Matched)
+		require.False(t, evaluation.InputDescriptors[2].Matched)
+		require.Equal(t, []string{"no credential submitted"}, evaluation.InputDescriptors[2].Errors)
+	})
+
+	t.Run("matched - JWT credential in nested path", func(t *testing.T) {
+		payload, err := json.Marshal(map[string]interface{}{"vc": prc})
+		require.NoError(t, err)
+
+		jwt := "eyJhbGciOiJFUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".c2ln"
+		vp := presentation(jwt, bachelor)
+
+		evaluation := pd.Evaluate(map[string]interface{}{"vp": vp}, submission("pd-1",
+			&presexch.InputDescriptorMapping{
+				ID: "prc", Path: "$.vp", PathNested: &presexch.InputDescriptorMapping{
+					ID: "prc", Path: "$.verifiableCredential[0]",
+				},
+			},
+			&presexch.InputDescriptorMapping{
+				ID: "bachelor", Path: "$.vp", PathNested: &presexch.InputDescriptorMapping{
+					ID: "bachelor", Path: "$.verifiableCredential[1]",
+				},
+			},
+		))
+		require.True(t, evaluation.Matched, evaluation.Errors)
+	})
+
+	t.Run("not matched - filter", func(t *testing.T) {
+		other := map[string]interface{}{"credentialSubject": map[string]interface{}{"type": "Visitor"}}
+
+		evaluation := pd.Evaluate(presentation(other, bachelor), submission("pd-1",
+			mapping("prc", "$.verifiableCredential[0]"),
+			mapping("bachelor", "$.verifiableCredential[1]"),
+		))
+		require.False(t, evaluation.Matched)
+		require.False(t, evaluation.InputDescriptors[0].Matched)
+		require.Len(t, evaluation.InputDescriptors[0].Errors, 1)
+		require.Contains(t, evaluation.InputDescriptors[0].Errors[0],
+			"credential at $.verifiableCredential[0]: field $.credentialSubject.type doesn't match the filter")
+		require.Contains(t, evaluation.Errors[0], "submission requirement card needs all of its 1 inputs, 0 matched")
+	})
+
+	t.Run("not matched - pick", func(t *testing.T) {
+		evaluation := pd.Evaluate(presentation(prc), submission("pd-1",
+			mapping("prc", "$.verifiableCredential[0]"),
+			mapping("bachelor", "$.verifiableCredential[1]"),
+		))
+		require.False(t, evaluation.Matched)
+		require.Contains(t, evaluation.InputDescriptors[1].Errors[0], "nothing found at $.verifiableCredential[1]")
+		require.Equal(t, []string{"submission requirement degree needs at least 1 inputs, 0 matched"},
+			evaluation.Errors)
+	})
+
+	t.Run("not matched - field not found", func(t *testing.T) {
+		evaluation := pd.Evaluate(presentation(map[string]interface{}{}), submission("pd-1",
+			mapping("prc", "$.verifiableCredential[0]"),
+		))
+		require.False(t, evaluation.Matched)
+		require.Contains(t, evaluation.InputDescriptors[0].Errors[0], "field $.credentialSubject.type not found")
+	})
+
+	t.Run("not matched - submission errors", func(t *testing.T) {
+		evaluation := pd.Evaluate(presentation(prc, bachelor), submission("pd-2",
+			mapping("prc", "$.verifiableCredential[0]"),
+			mapping("bachelor", "$.verifiableCredential[1]"),
+			mapping("other", "$.verifiableCredential[1]"),
+		))
+		require.False(t, evaluation.Matched)
+		require.Equal(t, []string{
+			"presentation submission is for definition pd-2",
+			"descriptor map entry other matches no input descriptor",
+		}, evaluation.Errors)
+	})
+
+	t.Run("without submission requirements all descriptors are required", func(t *testing.T) {
+		pd := parseDefinition(t, `{"id": "pd", "input_descriptors": [{"id": "a"}, {"id": "b"}]}`)
+
+		evaluation := pd.Evaluate(presentation(prc), submission("pd", mapping("a", "$.verifiableCredential[0]")))
+		require.False(t, evaluation.Matched)
+		require.Equal(t, []string{"input descriptor b didn't match"}, evaluation.Errors)
+	})
+
+	t.Run("nested requirements", func(t *testing.T) {
+		pd := parseDefinition(t, `{"id": "pd", "submission_requirements": [{"rule": "pick", "count": 1,
+			"from_nested": [{"rule": "all", "from": "A"}, {"rule": "all", "from": "B"}]}],
+			"input_descriptors": [{"id": "a", "group": ["A"]}, {"id": "b", "group": ["B"]}]}`)
+		require.NoError(t, pd.Validate())
+
+		evaluation := pd.Evaluate(presentation(prc), submission("pd", mapping("a", "$.verifiableCredential[0]")))
+		require.True(t, evaluation.Matched, evaluation.Errors)
+
+		evaluation = pd.Evaluate(presentation(prc, bachelor), submission("pd",
+			mapping("a", "$.verifiableCredential[0]"),
+			mapping("b", "$.verifiableCredential[1]"),
+		))
+		require.False(t, evaluation.Matched)
+		require.Contains(t, evaluation.Errors[0], "needs 1 inputs, 2 matched")
+	})
+}
+
+func parseDefinition(t *testing.T, definition string) *presexch.PresentationDefinition {
+	t.Helper()
+
+	pd := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(definition), pd))
+
+	return pd
+}
+
+func presentation(credentials ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 []interface{}{"VerifiablePresentation"},
+		"verifiableCredential": credentials,
+	}
+}
+
+func submission(definitionID string, mappings ...*presexch.InputDescriptorMapping) *presexch.PresentationSubmission {
+	return &presexch.PresentationSubmission{ID: "s-1", DefinitionID: definitionID, DescriptorMap: mappings}
+}
+
+func mapping(id, path string) *presexch.InputDescriptorMapping {
+	return &presexch.InputDescriptorMapping{ID: id, Path: path}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	submissionProperty = "presentation_submission"
+
+	jwtParts = 3
+)
+
+// ErrSubmissionNotFound is returned when the presentation holds no presentation submission
+var ErrSubmissionNotFound = errors.New("presentation has no presentation_submission")
+
+// PresentationSubmission maps the input descriptors of a definition to the credentials of a presentation,
+// see https://identity.foundation/presentation-exchange/spec/v2.0.0/#presentation-submission.
+type PresentationSubmission struct {
+	ID            string                    `json:"id"`
+	DefinitionID  string                    `json:"definition_id"`
+	DescriptorMap []*InputDescriptorMapping `json:"descriptor_map"`
+}
+
+// InputDescriptorMapping selects the credential submitted for an input descriptor with a JSONPath expression,
+// the nested path is evaluated against the document selected by the path.
+type InputDescriptorMapping struct {
+	ID         string                  `json:"id"`
+	Format     string                  `json:"format,omitempty"`
+	Path       string                  `json:"path"`
+	PathNested *InputDescriptorMapping `json:"path_nested,omitempty"`
+}
+
+// Evaluation is the result of matching a presentation submission against its presentation definition.
+type Evaluation struct {
+	DefinitionID     string                       `json:"definitionID"`
+	Matched          bool                         `json:"matched"`
+	InputDescriptors []*InputDescriptorEvaluation `json:"inputDescriptors"`
+	// Errors are the reasons the submission doesn't match, besides the input descriptors not matched
+	Errors []string `json:"errors,omitempty"`
+}
+
+// InputDescriptorEvaluation tells whether a credential of the submission matched the input descriptor, and why not.
+type InputDescriptorEvaluation struct {
+	ID      string `json:"id"`
+	Matched bool   `json:"matched"`
+	// Path selects the credential matching the input descriptor
+	Path   string   `json:"path,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// GetSubmission returns the presentation submission of the presentation document,
+// ErrSubmissionNotFound if it has none.
+func GetSubmission(vp map[string]interface{}) (*PresentationSubmission, error) {
+	raw, ok := vp[submissionProperty]
+	if !ok {
+		return nil, ErrSubmissionNotFound
+	}
+
+	rawBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal presentation submission: %w", err)
+	}
+
+	submission := &PresentationSubmission{}
+
+	if err = json.Unmarshal(rawBytes, submission); err != nil {
+		return nil, fmt.Errorf("invalid presentation submission: %w", err)
+	}
+
+	return submission, nil
+}
+
+// Evaluate matches the credentials the submission selects from the presentation document against the input
+// descriptors, then checks the submission requirements.
+func (pd *PresentationDefinition) Evaluate(vp map[string]interface{}, submission *PresentationSubmission) *Evaluation {
+	builder := gval.Full(jsonpath.PlaceholderExtension())
+	evaluation := &Evaluation{DefinitionID: pd.ID}
+
+	if submission.DefinitionID != pd.ID {
+		evaluation.Errors = append(evaluation.Errors,
+			fmt.Sprintf("presentation submission is for definition %s", submission.DefinitionID))
+	}
+
+	matched := make(map[string]bool)
+
+	for _, d := range pd.InputDescriptors {
+		result := evaluateDescriptor(builder, d, vp, submission.DescriptorMap)
+
+		matched[d.ID] = result.Matched
+		evaluation.InputDescriptors = append(evaluation.InputDescriptors, result)
+	}
+
+	for _, m := range submission.DescriptorMap {
+		if _, ok := matched[m.ID]; !ok {
+			evaluation.Errors = append(evaluation.Errors,
+				fmt.Sprintf("descriptor map entry %s matches no input descriptor", m.ID))
+		}
+	}
+
+	if len(pd.SubmissionRequirements) == 0 {
+		for _, d := range pd.InputDescriptors {
+			if !matched[d.ID] {
+				evaluation.Errors = append(evaluation.Errors, fmt.Sprintf("input descriptor %s didn't match", d.ID))
+			}
+		}
+	}
+
+	for _, r := range pd.SubmissionRequirements {
+		if err := pd.evaluateRequirement(r, matched); err != nil {
+			evaluation.Errors = append(evaluation.Errors, err.Error())
+		}
+	}
+
+	evaluation.Matched = len(evaluation.Errors) == 0
+
+	return evaluation
+}
+
+// evaluateDescriptor matches the credentials mapped to the input descriptor, the first one matching is selected
+func evaluateDescriptor(builder gval.Language, d *InputDescriptor, vp map[string]interface{},
+	descriptorMap []*InputDescriptorMapping) *InputDescriptorEvaluation {
+	result := &InputDescriptorEvaluation{ID: d.ID}
+
+	for _, m := range descriptorMap {
+		if m.ID != d.ID {
+			continue
+		}
+
+		credential, err := selectCredential(builder, vp, m)
+		if err == nil {
+			err = evaluateConstraints(builder, d.Constraints, credential)
+		}
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("credential at %s: %s", m.Path, err.Error()))
+
+			continue
+		}
+
+		result.Matched = true
+		result.Path = m.Path
+		result.Errors = nil
+
+		return result
+	}
+
+	if len(result.Errors) == 0 {
+		result.Errors = []string{"no credential submitted"}
+	}
+
+	return result
+}
+
+func selectCredential(builder gval.Language, vp interface{}, m *InputDescriptorMapping) (interface{}, error) {
+	value, err := selectPath(builder, vp, m.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	for nested := m.PathNested; nested != nil; nested = nested.PathNested {
+		doc, err := decodeDocument(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, err = selectPath(builder, doc, nested.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	return decodeDocument(value)
+}
+
+func evaluateConstraints(builder gval.Language, constraints *Constraints, credential interface{}) error {
+	if constraints == nil {
+		return nil
+	}
+
+	var errs []string
+
+	for _, f := range constraints.Fields {
+		if err := evaluateField(builder, f, credential); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// evaluateField checks the value selected by the first path finding one against the filter
+func evaluateField(builder gval.Language, f *Field, credential interface{}) error {
+	for _, p := range f.Path {
+		value, err := selectPath(builder, credential, p)
+		if err != nil {
+			continue
+		}
+
+		if len(f.Filter) == 0 {
+			return nil
+		}
+
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(f.Filter), gojsonschema.NewGoLoader(value))
+		if err != nil {
+			return fmt.Errorf("field %s: invalid filter: %w", f.name(), err)
+		}
+
+		if result.Valid() {
+			return nil
+		}
+
+		errs := make([]string, len(result.Errors()))
+		for i, e := range result.Errors() {
+			errs[i] = e.String()
+		}
+
+		return fmt.Errorf("field %s doesn't match the filter: %s", f.name(), strings.Join(errs, ", "))
+	}
+
+	if f.Optional {
+		return nil
+	}
+
+	return fmt.Errorf("field %s not found", f.name())
+}
+
+func (pd *PresentationDefinition) evaluateRequirement(r *SubmissionRequirement, matched map[string]bool) error {
+	var total, satisfied int
+
+	if r.From != "" {
+		for _, d := range pd.InputDescriptors {
+			if contains(d.Group, r.From) {
+				total++
+
+				if matched[d.ID] {
+					satisfied++
+				}
+			}
+		}
+	} else {
+		for _, nested := range r.FromNested {
+			total++
+
+			if pd.evaluateRequirement(nested, matched) == nil {
+				satisfied++
+			}
+		}
+	}
+
+	switch {
+	case r.Rule == RuleAll && satisfied != total:
+		return fmt.Errorf("submission requirement %s needs all of its %d inputs, %d matched", r.name(), total, satisfied)
+	case r.Rule == RulePick && r.Count > 0 && satisfied != r.Count:
+		return fmt.Errorf("submission requirement %s needs %d inputs, %d matched", r.name(), r.Count, satisfied)
+	case r.Rule == RulePick && satisfied < r.Min:
+		return fmt.Errorf("submission requirement %s needs at least %d inputs, %d matched", r.name(), r.Min, satisfied)
+	case r.Rule == RulePick && r.Max > 0 && satisfied > r.Max:
+		return fmt.Errorf("submission requirement %s needs at most %d inputs, %d matched", r.name(), r.Max, satisfied)
+	}
+
+	return nil
+}
+
+func selectPath(builder gval.Language, doc interface{}, path string) (interface{}, error) {
+	evaluable, err := builder.NewEvaluable(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %s: %w", path, err)
+	}
+
+	value, err := evaluable(context.Background(), doc)
+	if err != nil {
+		return nil, fmt.Errorf("nothing found at %s", path)
+	}
+
+	// wildcards and filters select a list, empty when nothing matches
+	if values, ok := value.([]interface{}); ok && len(values) == 0 && strings.ContainsAny(path, "*?") {
+		return nil, fmt.Errorf("nothing found at %s", path)
+	}
+
+	return value, nil
+}
+
+// decodeDocument returns the claims of a JWT, credentials in the JWT format being selected as their serialized
+// string. Their proof was checked with the presentation.
+func decodeDocument(value interface{}) (interface{}, error) {
+	jwt, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != jwtParts {
+		return nil, errors.New("selected value is neither a document nor a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	var claims map[string]interface{}
+
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+func contains(l []string, e string) bool {
+	for _, s := range l {
+		if s == e {
+			return true
+		}
+	}
+
+	return false
+}
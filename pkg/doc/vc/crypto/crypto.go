@@ -9,6 +9,7 @@ package crypto
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	ariescrypto "github.com/hyperledger/aries-framework-go/pkg/crypto"
@@ -318,6 +319,40 @@ func (c *Crypto) getAndResolveDID(verificationMethod string) (*did.Doc, error) {
 	return docResolution.DIDDocument, nil
 }
 
+// WithDIDCache returns a copy of the crypto resolving every DID only once, meant for signing a batch of
+// credentials with the same keys.
+func (c *Crypto) WithDIDCache() *Crypto {
+	cached := *c
+	cached.vdr = &cachingVDR{Registry: c.vdr, docs: make(map[string]*did.DocResolution)}
+
+	return &cached
+}
+
+// cachingVDR keeps the DID documents it resolved, resolution options are ignored
+type cachingVDR struct {
+	vdrapi.Registry
+	mu   sync.Mutex
+	docs map[string]*did.DocResolution
+}
+
+func (v *cachingVDR) Resolve(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if doc, ok := v.docs[didID]; ok {
+		return doc, nil
+	}
+
+	doc, err := v.Registry.Resolve(didID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	v.docs[didID] = doc
+
+	return doc, nil
+}
+
 // getSigner returns signer and verification method based on profile and signing opts
 // verificationMethod from opts takes priority to create signer and verification method
 func (c *Crypto) getSigner(creator string, opts *signingOpts, signatureType string) (*kmsSigner, string, error) {
@@ -7,20 +7,32 @@ SPDX-License-Identifier: Apache-2.0
 package crypto
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	cryptomock "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
 	"github.com/stretchr/testify/require"
 
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
 )
 
@@ -264,6 +276,43 @@ func TestCrypto_SignCredential(t *testing.T) {
 	})
 }
 
+func TestCrypto_WithDIDCache(t *testing.T) {
+	calls := 0
+	resolveErr := error(nil)
+
+	c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+		&vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				calls++
+
+				return &did.DocResolution{DIDDocument: createDIDDoc(didID)}, resolveErr
+			},
+		},
+		testutil.DocumentLoader(t),
+	).WithDIDCache()
+
+	for i := 0; i < 3; i++ {
+		signedVC, err := c.SignCredential(
+			getTestIssuerProfile().DataProfile, &verifiable.Credential{ID: "http://example.edu/credentials/1872"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(signedVC.Proofs))
+	}
+
+	require.Equal(t, 1, calls)
+
+	// failed resolutions aren't cached
+	resolveErr = errors.New("resolve error")
+
+	for i := 0; i < 2; i++ {
+		_, err := c.SignCredential(getTestIssuerProfile().DataProfile, &verifiable.Credential{ID: "http://example.edu/1"},
+			WithVerificationMethod("did:trustbloc:other#key1"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve error")
+	}
+
+	require.Equal(t, 3, calls)
+}
+
 func TestCrypto_SignCredentialBBS(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
@@ -327,6 +376,324 @@ func TestSignPresentation(t *testing.T) {
 	})
 }
 
+func TestCrypto_SignCredentialJWT(t *testing.T) {
+	vc := &verifiable.Credential{
+		ID:      "http://example.edu/credentials/1872",
+		Context: []string{verifiable.ContextURI},
+		Types:   []string{verifiable.VCType},
+		Issuer:  verifiable.Issuer{ID: "did:trustbloc:abc"},
+		Issued:  util.NewTime(time.Now()),
+		Subject: "did:example:ebfeb1f712ebc6f1c276e12ec21",
+	}
+
+	t.Run("test success - EdDSA", func(t *testing.T) {
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignValue: []byte("signature")},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:trustbloc:abc")},
+			testutil.DocumentLoader(t),
+		)
+
+		vcJWT, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc)
+		require.NoError(t, err)
+		require.Equal(t, EdDSA, jwtHeaders(t, vcJWT)[jose.HeaderAlgorithm])
+		require.Equal(t, "did:trustbloc:abc#key1", jwtHeaders(t, vcJWT)[jose.HeaderKeyID])
+
+		parsedVC, err := verifiable.ParseCredential([]byte(vcJWT), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(testutil.DocumentLoader(t)))
+		require.NoError(t, err)
+		require.Equal(t, vc.ID, parsedVC.ID)
+	})
+
+	t.Run("test success - ES256", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignValue: []byte("signature")},
+			&vdrmock.MockVDRegistry{ResolveValue: createJWKDIDDoc(t, "did:trustbloc:abc", &privKey.PublicKey)},
+			testutil.DocumentLoader(t),
+		)
+
+		vcJWT, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc,
+			WithVerificationMethod("did:trustbloc:abc#key2"))
+		require.NoError(t, err)
+		require.Equal(t, ES256, jwtHeaders(t, vcJWT)[jose.HeaderAlgorithm])
+		require.Equal(t, "did:trustbloc:abc#key2", jwtHeaders(t, vcJWT)[jose.HeaderKeyID])
+	})
+
+	t.Run("test success - ed25519 JsonWebKey2020", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignValue: []byte("signature")},
+			&vdrmock.MockVDRegistry{ResolveValue: createJWKDIDDoc(t, "did:trustbloc:abc", pubKey)},
+			testutil.DocumentLoader(t),
+		)
+
+		vcJWT, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc,
+			WithVerificationMethod("did:trustbloc:abc#key2"))
+		require.NoError(t, err)
+		require.Equal(t, EdDSA, jwtHeaders(t, vcJWT)[jose.HeaderAlgorithm])
+	})
+
+	t.Run("test error - unsupported key type", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createJWKDIDDoc(t, "did:trustbloc:abc", &privKey.PublicKey)},
+			testutil.DocumentLoader(t),
+		)
+
+		_, err = c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc,
+			WithVerificationMethod("did:trustbloc:abc#key2"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no jws algorithm for key type")
+	})
+
+	t.Run("test error - invalid proof purpose", func(t *testing.T) {
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:trustbloc:abc")},
+			testutil.DocumentLoader(t),
+		)
+
+		_, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc, WithPurpose("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "proof purpose invalid not supported")
+	})
+
+	t.Run("test error - resolve did", func(t *testing.T) {
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")},
+			testutil.DocumentLoader(t),
+		)
+
+		_, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve error")
+	})
+
+	t.Run("test error - sign", func(t *testing.T) {
+		c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignErr: fmt.Errorf("sign error")},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:trustbloc:abc")},
+			testutil.DocumentLoader(t),
+		)
+
+		_, err := c.SignCredentialJWT(getTestIssuerProfile().DataProfile, vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sign error")
+	})
+}
+
+func TestCrypto_SignCredentialSDJWT(t *testing.T) {
+	vc := &verifiable.Credential{
+		ID:      "http://example.edu/credentials/1872",
+		Context: []string{verifiable.ContextURI},
+		Types:   []string{verifiable.VCType},
+		Issuer:  verifiable.Issuer{ID: "did:trustbloc:abc"},
+		Issued:  util.NewTime(time.Now()),
+		Subject: []verifiable.Subject{{
+			ID:           "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			CustomFields: verifiable.CustomFields{"name": "Jayden Doe", "birthDate": "1958-07-17"},
+		}},
+	}
+
+	c := New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignValue: []byte("signature")},
+		&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:trustbloc:abc")},
+		testutil.DocumentLoader(t),
+	)
+
+	t.Run("test success", func(t *testing.T) {
+		vcSDJWT, err := c.SignCredentialSDJWT(getTestIssuerProfile().DataProfile, vc, []string{"birthDate"},
+			"did:example:holder#key1")
+		require.NoError(t, err)
+
+		sdJWT, err := sdjwt.Parse(vcSDJWT)
+		require.NoError(t, err)
+		require.Len(t, sdJWT.Disclosures, 1)
+		require.Empty(t, sdJWT.KeyBinding)
+
+		holderKeyID, err := sdJWT.HolderKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "did:example:holder#key1", holderKeyID)
+
+		claims, err := sdJWT.Claims()
+		require.NoError(t, err)
+		require.Equal(t, sdjwt.SHA256, claims[sdjwt.SDAlgKey])
+
+		subject := claims["vc"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+		require.Equal(t, "Jayden Doe", subject["name"])
+		require.NotContains(t, subject, "birthDate")
+		require.Len(t, subject[sdjwt.SDKey], 1)
+
+		revealed, err := sdjwt.Reveal(claims, sdJWT.Disclosures)
+		require.NoError(t, err)
+
+		subject = revealed["vc"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+		require.Equal(t, "1958-07-17", subject["birthDate"])
+	})
+
+	t.Run("test error - claim not found", func(t *testing.T) {
+		_, err := c.SignCredentialSDJWT(getTestIssuerProfile().DataProfile, vc, []string{"address"}, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "claim address not found")
+	})
+
+	t.Run("test error - multiple subjects", func(t *testing.T) {
+		_, err := c.SignCredentialSDJWT(getTestIssuerProfile().DataProfile, &verifiable.Credential{
+			ID:      "http://example.edu/credentials/1872",
+			Context: []string{verifiable.ContextURI},
+			Types:   []string{verifiable.VCType},
+			Issuer:  verifiable.Issuer{ID: "did:trustbloc:abc"},
+			Issued:  util.NewTime(time.Now()),
+			Subject: []verifiable.Subject{{ID: "did:example:1"}, {ID: "did:example:2"}},
+		}, nil, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "more than one subject is defined")
+	})
+}
+
+func TestJWTVerifier(t *testing.T) {
+	edPubKey, edPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	didDoc := createJWKDIDDoc(t, "did:trustbloc:abc", &ecPrivKey.PublicKey)
+	didDoc.VerificationMethod[0].Value = edPubKey
+
+	for _, verifications := range [][]did.Verification{didDoc.AssertionMethod, didDoc.Authentication,
+		didDoc.CapabilityInvocation, didDoc.CapabilityDelegation} {
+		verifications[0].VerificationMethod.Value = edPubKey
+	}
+
+	edSigner := &testJWSSigner{alg: EdDSA, sign: func(data []byte) ([]byte, error) {
+		return ed25519.Sign(edPrivKey, data), nil
+	}}
+
+	ecSigner := &testJWSSigner{alg: ES256, sign: func(data []byte) ([]byte, error) {
+		digest := sha256.Sum256(data)
+
+		r, s, err := ecdsa.Sign(rand.Reader, ecPrivKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...), nil
+	}}
+
+	verifier := NewJWTVerifier(&vdrmock.MockVDRegistry{ResolveValue: didDoc}, AssertionMethod)
+
+	sign := func(kid string, signer *testJWSSigner) string {
+		token, err := jwt.NewSigned(map[string]interface{}{"iss": "did:trustbloc:abc"},
+			jose.Headers{jose.HeaderKeyID: kid}, signer)
+		require.NoError(t, err)
+
+		s, err := token.Serialize(false)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("test success", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc#key1", edSigner), verifier)
+		require.NoError(t, err)
+
+		_, err = jose.ParseJWS(sign("did:trustbloc:abc#key2", ecSigner), verifier)
+		require.NoError(t, err)
+	})
+
+	t.Run("test error - signature doesn't match", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc#key1", &testJWSSigner{alg: EdDSA,
+			sign: ecSigner.sign}), verifier)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jwt signature doesn't match")
+
+		_, err = jose.ParseJWS(sign("did:trustbloc:abc#key2", &testJWSSigner{alg: ES256,
+			sign: edSigner.sign}), verifier)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jwt signature doesn't match")
+	})
+
+	t.Run("test error - alg doesn't match key", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc#key1", &testJWSSigner{alg: ES256,
+			sign: ecSigner.sign}), verifier)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jwt alg ES256 doesn't match the EdDSA key of did:trustbloc:abc#key1")
+	})
+
+	t.Run("test error - key not authorized for proof purpose", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc#key2", ecSigner),
+			NewJWTVerifier(&vdrmock.MockVDRegistry{ResolveValue: didDoc}, Authentication))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to find matching authentication key IDs")
+	})
+
+	t.Run("test error - resolve did", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc#key1", edSigner),
+			NewJWTVerifier(&vdrmock.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")}, AssertionMethod))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve error")
+	})
+
+	t.Run("test error - invalid kid", func(t *testing.T) {
+		_, err := jose.ParseJWS(sign("did:trustbloc:abc", edSigner), verifier)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "should be in did#keyID format")
+
+		token, err := jwt.NewSigned(map[string]interface{}{}, nil, edSigner)
+		require.NoError(t, err)
+
+		s, err := token.Serialize(false)
+		require.NoError(t, err)
+
+		_, err = jose.ParseJWS(s, verifier)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jwt kid header not found")
+	})
+}
+
+type testJWSSigner struct {
+	alg  string
+	sign func(data []byte) ([]byte, error)
+}
+
+func (s *testJWSSigner) Sign(data []byte) ([]byte, error) {
+	return s.sign(data)
+}
+
+func (s *testJWSSigner) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: s.alg}
+}
+
+func jwtHeaders(t *testing.T, vcJWT string) map[string]interface{} {
+	t.Helper()
+
+	headersBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(vcJWT, ".")[0])
+	require.NoError(t, err)
+
+	headers := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(headersBytes, &headers))
+
+	return headers
+}
+
+func createJWKDIDDoc(t *testing.T, didID string, pubKey interface{}) *did.Doc {
+	t.Helper()
+
+	didDoc := createDIDDoc(didID)
+
+	jwk, err := jose.JWKFromKey(pubKey)
+	require.NoError(t, err)
+
+	vm, err := did.NewVerificationMethodFromJWK(didID+"#key2", JSONWebKey2020, didID, jwk)
+	require.NoError(t, err)
+
+	didDoc.VerificationMethod = append(didDoc.VerificationMethod, *vm)
+	didDoc.AssertionMethod = append(didDoc.AssertionMethod, did.Verification{VerificationMethod: *vm})
+
+	return didDoc
+}
+
 func getTestIssuerProfile() *vcprofile.IssuerProfile {
 	return &vcprofile.IssuerProfile{
 		DataProfile: &vcprofile.DataProfile{
@@ -0,0 +1,298 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
+)
+
+const (
+	// EdDSA JWS algorithm for ed25519 keys
+	EdDSA = "EdDSA"
+	// ES256 JWS algorithm for EC P-256 keys
+	ES256 = "ES256"
+)
+
+// jwsSigner signs JWTs with a kms key, the JWS algorithm follows the type of the key.
+type jwsSigner struct {
+	*kmsSigner
+	headers jose.Headers
+}
+
+func (s *jwsSigner) Headers() jose.Headers {
+	return s.headers
+}
+
+// SignCredentialJWT signs the vc in the VC-JWT format, returning the compact JWS with the vc claim
+func (c *Crypto) SignCredentialJWT(dataProfile *vcprofile.DataProfile, vc *verifiable.Credential,
+	opts ...SigningOpts) (string, error) {
+	claims, err := vc.JWTClaims(false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get jwt claims of vc: %w", err)
+	}
+
+	return c.SignJWT(dataProfile, claims, jwt.TypeJWT, opts...)
+}
+
+// SignCredentialSDJWT signs the vc as SD-JWT, the given credential subject claims are made selectively disclosable.
+// Presentations are bound to the holder key of the given verification method, if any.
+func (c *Crypto) SignCredentialSDJWT(dataProfile *vcprofile.DataProfile, vc *verifiable.Credential,
+	disclosable []string, holderVerificationMethod string, opts ...SigningOpts) (string, error) {
+	vcClaims, err := vc.JWTClaims(false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get jwt claims of vc: %w", err)
+	}
+
+	claimsBytes, err := json.Marshal(vcClaims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal jwt claims of vc: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	if err = json.Unmarshal(claimsBytes, &claims); err != nil {
+		return "", fmt.Errorf("failed to unmarshal jwt claims of vc: %w", err)
+	}
+
+	subject, err := credentialSubject(claims)
+	if err != nil {
+		return "", err
+	}
+
+	disclosures, err := sdjwt.MakeDisclosable(subject, disclosable)
+	if err != nil {
+		return "", fmt.Errorf("failed to make claims disclosable: %w", err)
+	}
+
+	claims[sdjwt.SDAlgKey] = sdjwt.SHA256
+
+	if holderVerificationMethod != "" {
+		claims[sdjwt.CNFKey] = &sdjwt.Confirmation{KeyID: holderVerificationMethod}
+	}
+
+	signed, err := c.SignJWT(dataProfile, claims, jwt.TypeJWT, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return (&sdjwt.SDJWT{JWT: signed, Disclosures: disclosures}).Serialize(), nil
+}
+
+// SignJWT signs the claims as compact JWS of the given type with the profile key.
+// The key has to be authorized for the proof purpose, assertionMethod unless passed in the options.
+func (c *Crypto) SignJWT(dataProfile *vcprofile.DataProfile, claims interface{}, typ string,
+	opts ...SigningOpts) (string, error) {
+	signOpts := &signingOpts{}
+	// apply opts
+	for _, opt := range opts {
+		opt(signOpts)
+	}
+
+	s, method, err := c.getSigner(dataProfile.Creator, signOpts, "")
+	if err != nil {
+		return "", err
+	}
+
+	proofPurpose := AssertionMethod
+	if signOpts.Purpose != "" {
+		proofPurpose = signOpts.Purpose
+	}
+
+	didDoc, err := c.getAndResolveDID(method)
+	if err != nil {
+		return "", err
+	}
+
+	err = ValidateProofPurpose(proofPurpose, method, didDoc)
+	if err != nil {
+		return "", err
+	}
+
+	alg, err := getJWSAlgorithm(method, didDoc)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := jwt.NewSigned(claims, jose.Headers{jose.HeaderKeyID: method}, &jwsSigner{
+		kmsSigner: s,
+		headers:   jose.Headers{jose.HeaderAlgorithm: alg, jose.HeaderType: typ},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign jwt: %w", err)
+	}
+
+	return token.Serialize(false)
+}
+
+// credentialSubject returns the single credential subject object of the vc claim
+func credentialSubject(claims map[string]interface{}) (map[string]interface{}, error) {
+	vcClaim, ok := claims["vc"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("vc claim not found")
+	}
+
+	subject := vcClaim["credentialSubject"]
+
+	if subjects, ok := subject.([]interface{}); ok && len(subjects) == 1 {
+		subject = subjects[0]
+	}
+
+	// a subject with only an id is serialized as the id itself
+	if id, ok := subject.(string); ok {
+		subject = map[string]interface{}{"id": id}
+	}
+
+	vcClaim["credentialSubject"] = subject
+
+	subjectObj, ok := subject.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("selective disclosure needs a single credential subject object")
+	}
+
+	return subjectObj, nil
+}
+
+// JWTVerifier verifies EdDSA and ES256 signed JWTs with the key of the verification method in the kid header.
+// The verification method has to be authorized for the proof purpose in its DID document.
+type JWTVerifier struct {
+	vdr     vdrapi.Registry
+	purpose string
+}
+
+// NewJWTVerifier returns JWT verifier resolving keys through the given vdr
+func NewJWTVerifier(vdr vdrapi.Registry, purpose string) *JWTVerifier {
+	return &JWTVerifier{vdr: vdr, purpose: purpose}
+}
+
+// Verify verifies the JWS signature
+func (v *JWTVerifier) Verify(joseHeaders jose.Headers, _, signingInput, signature []byte) error {
+	method, ok := joseHeaders.KeyID()
+	if !ok {
+		return errors.New("jwt kid header not found")
+	}
+
+	didID, err := diddoc.GetDIDFromVerificationMethod(method)
+	if err != nil {
+		return err
+	}
+
+	docResolution, err := v.vdr.Resolve(didID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", didID, err)
+	}
+
+	if err = ValidateProofPurpose(v.purpose, method, docResolution.DIDDocument); err != nil {
+		return err
+	}
+
+	vm, err := getVerificationMethod(method, docResolution.DIDDocument)
+	if err != nil {
+		return err
+	}
+
+	alg, err := jwsAlgorithm(vm)
+	if err != nil {
+		return err
+	}
+
+	if headerAlg, _ := joseHeaders.Algorithm(); headerAlg != alg { // nolint: errcheck
+		return fmt.Errorf("jwt alg %s doesn't match the %s key of %s", headerAlg, alg, method)
+	}
+
+	return verifyJWS(vm, alg, signingInput, signature)
+}
+
+func verifyJWS(vm *did.VerificationMethod, alg string, signingInput, signature []byte) error {
+	var key interface{} = ed25519.PublicKey(vm.Value)
+	if vm.JSONWebKey() != nil {
+		key = vm.JSONWebKey().Key
+	}
+
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if alg == EdDSA && len(k) == ed25519.PublicKeySize && ed25519.Verify(k, signingInput, signature) {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		const es256SignatureSize = 64
+
+		if alg == ES256 && len(signature) == es256SignatureSize {
+			digest := sha256.Sum256(signingInput)
+			r := new(big.Int).SetBytes(signature[:es256SignatureSize/2])
+			s := new(big.Int).SetBytes(signature[es256SignatureSize/2:])
+
+			if ecdsa.Verify(k, digest[:], r, s) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("jwt signature doesn't match")
+}
+
+// getJWSAlgorithm returns the JWS algorithm for the key of the given verification method
+func getJWSAlgorithm(method string, didDoc *did.Doc) (string, error) {
+	vm, err := getVerificationMethod(method, didDoc)
+	if err != nil {
+		return "", err
+	}
+
+	return jwsAlgorithm(vm)
+}
+
+func getVerificationMethod(method string, didDoc *did.Doc) (*did.VerificationMethod, error) {
+	for _, verifications := range didDoc.VerificationMethods() {
+		for i := range verifications {
+			vm := verifications[i].VerificationMethod
+			if vm.ID == method || didDoc.ID+vm.ID == method {
+				return &vm, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("verification method %s not found in did document", method)
+}
+
+func jwsAlgorithm(vm *did.VerificationMethod) (string, error) {
+	if vm.Type == Ed25519VerificationKey2018 {
+		return EdDSA, nil
+	}
+
+	if vm.JSONWebKey() == nil {
+		return "", fmt.Errorf("no jws algorithm for verification method type %s", vm.Type)
+	}
+
+	keyType, err := vm.JSONWebKey().KeyType()
+	if err != nil {
+		return "", fmt.Errorf("failed to get key type of verification method %s: %w", vm.ID, err)
+	}
+
+	switch keyType { //nolint:exhaustive
+	case kms.ED25519Type:
+		return EdDSA, nil
+	case kms.ECDSAP256TypeIEEEP1363:
+		return ES256, nil
+	default:
+		return "", fmt.Errorf("no jws algorithm for key type %s", keyType)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tagging tags the profiles saved before profiles were tagged as they are read, the storage SPI only
+// queries tagged records so they can't be found to be tagged all at once.
+package tagging
+
+import (
+	"fmt"
+	"sync"
+
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+var logger = log.New("edge-service-profile-tagging")
+
+// Index keeps the keys of the records of the store having the tag, queried with the tag when first needed.
+type Index struct {
+	store ariesstorage.Store
+	tag   string
+	mu    sync.Mutex
+	keys  map[string]bool
+}
+
+// New returns new index of the records of the store having the tag
+func New(store ariesstorage.Store, tag string) *Index {
+	return &Index{store: store, tag: tag}
+}
+
+// Added records that the record was saved with the tag.
+func (i *Index) Added(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.keys != nil {
+		i.keys[key] = true
+	}
+}
+
+// Removed records that the record was deleted.
+func (i *Index) Removed(key string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.keys, key)
+}
+
+// Ensure tags the record read with the given value unless it's tagged already. The keys are queried again
+// before tagging, the record may have been saved by another instance since they were.
+func (i *Index) Ensure(key string, value []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.keys[key] {
+		return nil
+	}
+
+	keys, err := i.query()
+	if err != nil {
+		return err
+	}
+
+	i.keys = keys
+
+	if i.keys[key] {
+		return nil
+	}
+
+	if err := i.store.Put(key, value, ariesstorage.Tag{Name: i.tag}); err != nil {
+		return fmt.Errorf("failed to tag %s: %w", key, err)
+	}
+
+	i.keys[key] = true
+
+	return nil
+}
+
+func (i *Index) query() (map[string]bool, error) {
+	iter, err := i.store.Query(i.tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", i.tag, err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close iterator: %s", errClose)
+		}
+	}()
+
+	keys := make(map[string]bool)
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", i.tag, err)
+		}
+
+		if !ok {
+			return keys, nil
+		}
+
+		key, err := iter.Key()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", i.tag, err)
+		}
+
+		keys[key] = true
+	}
+}
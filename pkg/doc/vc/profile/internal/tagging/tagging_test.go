@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tagging
+
+import (
+	"errors"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const testTag = "testProfile"
+
+func TestIndex_Ensure(t *testing.T) {
+	t.Run("test untagged record is tagged", func(t *testing.T) {
+		store, err := ariesmemstorage.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("tagged", []byte("tagged"), ariesstorage.Tag{Name: testTag}))
+		require.NoError(t, store.Put("untagged", []byte("untagged")))
+
+		index := New(store, testTag)
+
+		require.NoError(t, index.Ensure("tagged", []byte("tagged")))
+		require.NoError(t, index.Ensure("untagged", []byte("untagged")))
+
+		tags, err := store.GetTags("untagged")
+		require.NoError(t, err)
+		require.Equal(t, []ariesstorage.Tag{{Name: testTag}}, tags)
+
+		// known keys aren't queried again
+		require.NoError(t, store.Put("untagged", []byte("untagged")))
+		require.NoError(t, index.Ensure("untagged", []byte("untagged")))
+
+		tags, err = store.GetTags("untagged")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		index.Removed("untagged")
+		require.NoError(t, index.Ensure("untagged", []byte("untagged")))
+
+		tags, err = store.GetTags("untagged")
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+	})
+
+	t.Run("test tagged records aren't tagged again", func(t *testing.T) {
+		store := &ariesmockstorage.MockStore{Store: make(map[string]ariesmockstorage.DBEntry)}
+		index := New(store, testTag)
+
+		require.NoError(t, store.Put("other", []byte("other"), ariesstorage.Tag{Name: testTag}))
+
+		store.ErrPut = errors.New("put error")
+
+		// saved by another instance
+		require.NoError(t, index.Ensure("other", []byte("other")))
+
+		index.Added("saved")
+		require.NoError(t, index.Ensure("saved", []byte("saved")))
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		store := &ariesmockstorage.MockStore{Store: make(map[string]ariesmockstorage.DBEntry),
+			ErrPut: errors.New("put error")}
+
+		err := New(store, testTag).Ensure("key", []byte("value"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to tag key: put error")
+
+		store.ErrQuery = errors.New("query error")
+
+		err = New(store, testTag).Ensure("key", []byte("value"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query testProfile: query error")
+	})
+}
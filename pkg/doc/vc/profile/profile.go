@@ -8,10 +8,14 @@ package profile
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/internal/tagging"
 )
 
 const (
@@ -23,6 +27,20 @@ const (
 	issuerMode     = "issuer"
 	holderMode     = "holder"
 	governanceMode = "governance"
+
+	// profiles are tagged with their mode, to be listed
+	profileTagSuffix = "Profile"
+)
+
+var logger = log.New("edge-service-profile")
+
+const (
+	// LDPCredentialFormat credentials secured with embedded linked data proofs
+	LDPCredentialFormat = "ldp"
+	// JWTCredentialFormat credentials secured as VC-JWT
+	JWTCredentialFormat = "jwt"
+	// SDJWTCredentialFormat credentials secured as SD-JWT, with selectively disclosable claims
+	SDJWTCredentialFormat = "sd-jwt"
 )
 
 // New returns new credential recorder instance
@@ -32,12 +50,19 @@ func New(provider ariesstorage.Provider) (*Profile, error) {
 		return nil, err
 	}
 
-	return &Profile{store: store}, nil
+	tags := make(map[string]*tagging.Index)
+	for _, mode := range []string{issuerMode, holderMode, governanceMode} {
+		tags[mode] = tagging.New(store, profileTag(mode).Name)
+	}
+
+	return &Profile{store: store, tags: tags}, nil
 }
 
 // Profile takes care of features to be persisted for credentials
 type Profile struct {
 	store ariesstorage.Store
+	// tags indexes the tagged profiles by mode, profiles saved before they were tagged are tagged when read
+	tags map[string]*tagging.Index
 }
 
 // DataProfile base profile
@@ -52,15 +77,63 @@ type DataProfile struct {
 
 // IssuerProfile struct for issuer profile
 type IssuerProfile struct {
-	URI             string          `json:"uri"`
-	EDVVaultID      string          `json:"edvVaultID"`
-	DisableVCStatus bool            `json:"disableVCStatus"`
-	OverwriteIssuer bool            `json:"overwriteIssuer"`
-	EDVCapability   json.RawMessage `json:"edvCapability,omitempty"`
-	EDVController   string          `json:"edvController"`
+	URI              string `json:"uri"`
+	EDVVaultID       string `json:"edvVaultID"`
+	DisableVCStatus  bool   `json:"disableVCStatus"`
+	VCStatusType     string `json:"vcStatusType,omitempty"`
+	VCStatusListSize int    `json:"vcStatusListSize,omitempty"`
+	// VCStatusListBitLength is the bit string length of new status lists, 128000 or list size if larger when 0
+	VCStatusListBitLength int  `json:"vcStatusListBitLength,omitempty"`
+	OverwriteIssuer       bool `json:"overwriteIssuer"`
+	// CredentialFormat is the format of issued credentials, linked data proofs when empty
+	CredentialFormat string `json:"credentialFormat,omitempty"`
+	// CredentialRegistry records issued credentials, so they can be listed and revoked without storing them
+	CredentialRegistry bool `json:"credentialRegistry,omitempty"`
+	// CredentialTemplates are the templates credentials can be composed from
+	CredentialTemplates []*CredentialTemplate `json:"credentialTemplates,omitempty"`
+	// ValidityPeriod in seconds sets the expiration date of credentials issued without one, templates setting
+	// their own validity period take precedence
+	ValidityPeriod int64 `json:"validityPeriod,omitempty"`
+	// RefreshService is embedded in issued credentials so that holders can refresh them, none when nil
+	RefreshService *RefreshService `json:"refreshService,omitempty"`
+	EDVCapability  json.RawMessage `json:"edvCapability,omitempty"`
+	EDVController  string          `json:"edvController"`
 	*DataProfile
 }
 
+// RefreshService is the refresh service of the credentials issued by a profile.
+type RefreshService struct {
+	// Type is the refresh service type, ManualRefreshService2018 when empty. Other types need a context
+	// defining them in the issued credentials.
+	Type string `json:"type,omitempty"`
+	// URL is the endpoint of the refresh service, the refresh endpoint of the issuer when empty
+	URL string `json:"url,omitempty"`
+}
+
+// CredentialTemplate fixes the contexts, types and evidence of the credentials composed from it,
+// their claims have to match its JSON Schema.
+type CredentialTemplate struct {
+	ID       string   `json:"id"`
+	Contexts []string `json:"contexts,omitempty"`
+	Types    []string `json:"types,omitempty"`
+	// Schema is the JSON Schema of the credential subject claims
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// ValidityPeriod in seconds sets the expiration date of credentials composed without one
+	ValidityPeriod int64           `json:"validityPeriod,omitempty"`
+	Evidence       json.RawMessage `json:"evidence,omitempty"`
+}
+
+// CredentialTemplate returns the credential template with the given ID, nil if there is none
+func (p *IssuerProfile) CredentialTemplate(id string) *CredentialTemplate {
+	for _, t := range p.CredentialTemplates {
+		if t.ID == id {
+			return t
+		}
+	}
+
+	return nil
+}
+
 // HolderProfile struct for holder profile
 type HolderProfile struct {
 	OverwriteHolder bool `json:"overwriteHolder,omitempty"`
@@ -69,6 +142,8 @@ type HolderProfile struct {
 
 // GovernanceProfile struct for governance profile
 type GovernanceProfile struct {
+	VCStatusListSize      int `json:"vcStatusListSize,omitempty"`
+	VCStatusListBitLength int `json:"vcStatusListBitLength,omitempty"`
 	*DataProfile
 }
 
@@ -79,12 +154,12 @@ func (c *Profile) SaveProfile(data *IssuerProfile) error {
 		return fmt.Errorf("save profile marshalling error: %w", err)
 	}
 
-	return c.store.Put(getDBKey(issuerMode, data.Name), bytes)
+	return c.put(issuerMode, data.Name, bytes)
 }
 
 // GetProfile returns profile information for given profile name from underlying store
 func (c *Profile) GetProfile(name string) (*IssuerProfile, error) {
-	bytes, err := c.store.Get(getDBKey(issuerMode, name))
+	bytes, err := c.get(issuerMode, name)
 	if err != nil {
 		return nil, err
 	}
@@ -99,9 +174,30 @@ func (c *Profile) GetProfile(name string) (*IssuerProfile, error) {
 	return response, nil
 }
 
+// ListProfiles returns the given page of the issuer profiles sorted by name, along with the total number of
+// profiles. Pages are numbered from 0, all profiles are returned when the page size is 0.
+func (c *Profile) ListProfiles(page, pageSize int) ([]*IssuerProfile, int, error) {
+	values, total, err := c.list(issuerMode, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profiles := make([]*IssuerProfile, len(values))
+
+	for i, v := range values {
+		profiles[i] = &IssuerProfile{}
+
+		if err := json.Unmarshal(v, profiles[i]); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal issuer profile: %w", err)
+		}
+	}
+
+	return profiles, total, nil
+}
+
 // DeleteProfile deletes the profile from the underlying store.
 func (c *Profile) DeleteProfile(name string) error {
-	return c.store.Delete(getDBKey(issuerMode, name))
+	return c.delete(issuerMode, name)
 }
 
 // SaveHolderProfile saves holder profile to the underlying store.
@@ -111,12 +207,12 @@ func (c *Profile) SaveHolderProfile(data *HolderProfile) error {
 		return fmt.Errorf("save holder profile : %w", err)
 	}
 
-	return c.store.Put(getDBKey(holderMode, data.Name), bytes)
+	return c.put(holderMode, data.Name, bytes)
 }
 
 // GetHolderProfile retrieves the holder profile based on name.
 func (c *Profile) GetHolderProfile(name string) (*HolderProfile, error) {
-	bytes, err := c.store.Get(getDBKey(holderMode, name))
+	bytes, err := c.get(holderMode, name)
 	if err != nil {
 		return nil, err
 	}
@@ -131,9 +227,30 @@ func (c *Profile) GetHolderProfile(name string) (*HolderProfile, error) {
 	return response, nil
 }
 
+// ListHolderProfiles returns the given page of the holder profiles sorted by name, along with the total number of
+// profiles. Pages are numbered from 0, all profiles are returned when the page size is 0.
+func (c *Profile) ListHolderProfiles(page, pageSize int) ([]*HolderProfile, int, error) {
+	values, total, err := c.list(holderMode, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profiles := make([]*HolderProfile, len(values))
+
+	for i, v := range values {
+		profiles[i] = &HolderProfile{}
+
+		if err := json.Unmarshal(v, profiles[i]); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal holder profile: %w", err)
+		}
+	}
+
+	return profiles, total, nil
+}
+
 // DeleteHolderProfile deletes the holder profile from the underlying store.
 func (c *Profile) DeleteHolderProfile(name string) error {
-	return c.store.Delete(getDBKey(holderMode, name))
+	return c.delete(holderMode, name)
 }
 
 // SaveGovernanceProfile saves governance profile to the underlying store.
@@ -143,12 +260,12 @@ func (c *Profile) SaveGovernanceProfile(data *GovernanceProfile) error {
 		return fmt.Errorf("save governance profile : %w", err)
 	}
 
-	return c.store.Put(getDBKey(governanceMode, data.Name), bytes)
+	return c.put(governanceMode, data.Name, bytes)
 }
 
 // GetGovernanceProfile retrieves the governance profile based on name.
 func (c *Profile) GetGovernanceProfile(name string) (*GovernanceProfile, error) {
-	bytes, err := c.store.Get(getDBKey(governanceMode, name))
+	bytes, err := c.get(governanceMode, name)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +280,138 @@ func (c *Profile) GetGovernanceProfile(name string) (*GovernanceProfile, error)
 	return response, nil
 }
 
+// ListGovernanceProfiles returns the given page of the governance profiles sorted by name, along with the total
+// number of profiles. Pages are numbered from 0, all profiles are returned when the page size is 0.
+func (c *Profile) ListGovernanceProfiles(page, pageSize int) ([]*GovernanceProfile, int, error) {
+	values, total, err := c.list(governanceMode, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	profiles := make([]*GovernanceProfile, len(values))
+
+	for i, v := range values {
+		profiles[i] = &GovernanceProfile{}
+
+		if err := json.Unmarshal(v, profiles[i]); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal governance profile: %w", err)
+		}
+	}
+
+	return profiles, total, nil
+}
+
+func (c *Profile) put(mode, name string, value []byte) error {
+	key := getDBKey(mode, name)
+
+	if err := c.store.Put(key, value, profileTag(mode)); err != nil {
+		return err
+	}
+
+	c.tags[mode].Added(key)
+
+	return nil
+}
+
+// get returns the profile, tagging it if it was saved before profiles were tagged so that it's listed.
+func (c *Profile) get(mode, name string) ([]byte, error) {
+	key := getDBKey(mode, name)
+
+	value, err := c.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.tags[mode].Ensure(key, value); err != nil {
+		logger.Warnf("failed to tag %s profile %s: %s", mode, name, err)
+	}
+
+	return value, nil
+}
+
+func (c *Profile) delete(mode, name string) error {
+	key := getDBKey(mode, name)
+
+	if err := c.store.Delete(key); err != nil {
+		return err
+	}
+
+	c.tags[mode].Removed(key)
+
+	return nil
+}
+
+// list returns the given page of the profiles of the mode sorted by name and the total number of profiles.
+// Profiles saved before profiles were tagged are listed once they're read or saved again.
+func (c *Profile) list(mode string, page, pageSize int) ([][]byte, int, error) {
+	iter, err := c.store.Query(profileTag(mode).Name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query %s profiles: %w", mode, err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close profile iterator: %s", errClose)
+		}
+	}()
+
+	var keys []string
+
+	values := make(map[string][]byte)
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query %s profiles: %w", mode, err)
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := iter.Key()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query %s profiles: %w", mode, err)
+		}
+
+		values[key], err = iter.Value()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query %s profiles: %w", mode, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	total := len(keys)
+
+	if pageSize > 0 {
+		start := page * pageSize
+		if start > total {
+			start = total
+		}
+
+		end := start + pageSize
+		if end > total {
+			end = total
+		}
+
+		keys = keys[start:end]
+	}
+
+	result := make([][]byte, len(keys))
+	for i, k := range keys {
+		result[i] = values[k]
+	}
+
+	return result, total, nil
+}
+
+func profileTag(mode string) ariesstorage.Tag {
+	return ariesstorage.Tag{Name: mode + profileTagSuffix}
+}
+
 func getDBKey(mode, name string) string {
 	return fmt.Sprintf(keyPattern, profileKeyPrefix, mode, name)
 }
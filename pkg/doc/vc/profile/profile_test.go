@@ -11,8 +11,10 @@ import (
 	"testing"
 	"time"
 
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -349,3 +351,120 @@ func TestGovernanceHolder(t *testing.T) {
 		require.Nil(t, resp)
 	})
 }
+
+func TestListProfiles(t *testing.T) {
+	t.Run("test list profiles success", func(t *testing.T) {
+		record, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		for _, name := range []string{"issuer3", "issuer1", "issuer2"} {
+			require.NoError(t, record.SaveProfile(&IssuerProfile{DataProfile: &DataProfile{Name: name}}))
+			require.NoError(t, record.SaveHolderProfile(&HolderProfile{DataProfile: &DataProfile{Name: "h" + name}}))
+		}
+
+		require.NoError(t, record.SaveGovernanceProfile(&GovernanceProfile{DataProfile: &DataProfile{Name: "gov"}}))
+
+		profiles, total, err := record.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 3)
+		require.Equal(t, "issuer1", profiles[0].Name)
+		require.Equal(t, "issuer3", profiles[2].Name)
+
+		profiles, total, err = record.ListProfiles(1, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 1)
+		require.Equal(t, "issuer3", profiles[0].Name)
+
+		profiles, _, err = record.ListProfiles(2, 2)
+		require.NoError(t, err)
+		require.Empty(t, profiles)
+
+		holderProfiles, total, err := record.ListHolderProfiles(0, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, holderProfiles, 2)
+		require.Equal(t, "hissuer1", holderProfiles[0].Name)
+
+		governanceProfiles, total, err := record.ListGovernanceProfiles(0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, "gov", governanceProfiles[0].Name)
+	})
+
+	t.Run("test list profiles error", func(t *testing.T) {
+		record, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: fmt.Errorf("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, _, err = record.ListProfiles(0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query issuer profiles: query error")
+
+		_, _, err = record.ListHolderProfiles(0, 0)
+		require.Error(t, err)
+
+		_, _, err = record.ListGovernanceProfiles(0, 0)
+		require.Error(t, err)
+	})
+}
+
+func TestProfilesSavedBeforeTagging(t *testing.T) {
+	provider := ariesmemstorage.NewProvider()
+
+	record, err := New(provider)
+	require.NoError(t, err)
+
+	store, err := provider.OpenStore(credentialStoreName)
+	require.NoError(t, err)
+
+	require.NoError(t, record.SaveProfile(&IssuerProfile{DataProfile: &DataProfile{Name: "issuer1"}}))
+
+	for mode, name := range map[string]string{issuerMode: "issuer2", holderMode: "holder1",
+		governanceMode: "governance1"} {
+		profileBytes, errMarshal := json.Marshal(&DataProfile{Name: name})
+		require.NoError(t, errMarshal)
+		require.NoError(t, store.Put(getDBKey(mode, name), profileBytes))
+	}
+
+	profiles, total, err := record.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, profiles, 1)
+
+	// profiles are tagged when read
+	_, err = record.GetProfile("issuer2")
+	require.NoError(t, err)
+	_, err = record.GetHolderProfile("holder1")
+	require.NoError(t, err)
+	_, err = record.GetGovernanceProfile("governance1")
+	require.NoError(t, err)
+
+	profiles, total, err = record.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, "issuer2", profiles[1].Name)
+
+	holderProfiles, total, err := record.ListHolderProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "holder1", holderProfiles[0].Name)
+
+	_, total, err = record.ListGovernanceProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	tags, err := store.GetTags(getDBKey(issuerMode, "issuer2"))
+	require.NoError(t, err)
+	require.Equal(t, []ariesstorage.Tag{profileTag(issuerMode)}, tags)
+
+	// deleted profiles aren't listed
+	require.NoError(t, record.DeleteProfile("issuer2"))
+
+	_, total, err = record.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
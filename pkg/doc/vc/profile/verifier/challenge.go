@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+const (
+	challengeStoreName = "verifierchallenge"
+	challengeKeyPrefix = "challenge"
+	challengeTag       = "challenge"
+
+	// purgeInterval is the least time between purges of the expired challenges
+	purgeInterval = 10 * time.Minute
+)
+
+// ErrChallengeNotFound is returned when consuming a challenge that wasn't issued, or was already consumed
+var ErrChallengeNotFound = errors.New("challenge not issued or already used")
+
+// Challenge is a single-use challenge issued for presentations verified with a profile.
+type Challenge struct {
+	Value     string    `json:"challenge"`
+	ProfileID string    `json:"profileID"`
+	Domain    string    `json:"domain,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Locker locks a challenge while it is consumed, for all the instances of the service sharing the store.
+type Locker interface {
+	// Lock blocks until the lock for the given key is acquired and returns the function releasing it.
+	Lock(key string) (func(), error)
+}
+
+// ChallengeStore keeps the challenges issued by the verifier until they are consumed, in the storage shared by
+// the instances of the service.
+type ChallengeStore struct {
+	name       string
+	store      ariesstorage.Store
+	locker     Locker
+	mutex      sync.Mutex
+	purgeMutex sync.Mutex
+	lastPurge  time.Time
+}
+
+// ChallengeStoreOpt configures the challenge store.
+type ChallengeStoreOpt func(s *ChallengeStore)
+
+// WithChallengeLocker is an option to pass the locker serializing consumption of challenges across the instances
+// sharing the store, consumption is only serialized within the service if not set.
+func WithChallengeLocker(locker Locker) ChallengeStoreOpt {
+	return func(s *ChallengeStore) {
+		s.locker = locker
+	}
+}
+
+// WithChallengeStoreName is an option to keep the challenges in another store than the one of the verifier, for
+// the services binding presentations to challenges of their own.
+func WithChallengeStoreName(name string) ChallengeStoreOpt {
+	return func(s *ChallengeStore) {
+		s.name = name
+	}
+}
+
+// NewChallengeStore returns new challenge store.
+func NewChallengeStore(provider ariesstorage.Provider, opts ...ChallengeStoreOpt) (*ChallengeStore, error) {
+	s := &ChallengeStore{name: challengeStoreName}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	store, err := provider.OpenStore(s.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open challenge store: %w", err)
+	}
+
+	s.store = store
+
+	return s, nil
+}
+
+// Issue saves a new challenge for the profile and domain, valid for the given duration.
+// Challenges expired without being consumed are purged along the way.
+func (s *ChallengeStore) Issue(profileID, domain string, ttl time.Duration) (*Challenge, error) {
+	challenge := &Challenge{
+		Value:     uuid.New().String(),
+		ProfileID: profileID,
+		Domain:    domain,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+
+	challengeBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal challenge: %w", err)
+	}
+
+	if err = s.store.Put(getChallengeDBKey(challenge.Value), challengeBytes,
+		ariesstorage.Tag{Name: challengeTag}); err != nil {
+		return nil, fmt.Errorf("failed to save challenge: %w", err)
+	}
+
+	if s.purgeDue() {
+		if err := s.PurgeExpired(); err != nil {
+			logger.Warnf("failed to purge expired challenges: %s", err)
+		}
+	}
+
+	return challenge, nil
+}
+
+// Check checks the challenge was issued for the profile and domain and hasn't expired, without consuming it.
+func (s *ChallengeStore) Check(value, profileID, domain string) error {
+	challenge, err := s.get(getChallengeDBKey(value))
+	if err != nil {
+		return err
+	}
+
+	return challenge.check(profileID, domain)
+}
+
+// Consume deletes the challenge, then checks it was issued for the profile and domain and hasn't expired.
+// The challenge is deleted whether the checks pass or not so that it is accepted once at most, callers consume
+// the challenge only once the presentation bound to it is verified. The challenge is locked while it is read and
+// deleted, so only one of the requests consuming it at the same time succeeds.
+func (s *ChallengeStore) Consume(value, profileID, domain string) error {
+	key := getChallengeDBKey(value)
+
+	unlock, err := s.lock(key)
+	if err != nil {
+		return fmt.Errorf("failed to lock challenge: %w", err)
+	}
+
+	defer unlock()
+
+	challenge, err := s.get(key)
+	if err != nil {
+		return err
+	}
+
+	if err = s.store.Delete(key); err != nil {
+		return fmt.Errorf("failed to delete challenge: %w", err)
+	}
+
+	return challenge.check(profileID, domain)
+}
+
+// PurgeExpired deletes the challenges which expired without being consumed.
+func (s *ChallengeStore) PurgeExpired() error {
+	iter, err := s.store.Query(challengeTag)
+	if err != nil {
+		return fmt.Errorf("failed to query challenges: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close challenge iterator: %s", errClose)
+		}
+	}()
+
+	var expired []string
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to query challenges: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		challengeBytes, err := iter.Value()
+		if err != nil {
+			return fmt.Errorf("failed to query challenges: %w", err)
+		}
+
+		challenge := &Challenge{}
+		if err := json.Unmarshal(challengeBytes, challenge); err != nil {
+			return fmt.Errorf("failed to unmarshal challenge: %w", err)
+		}
+
+		if time.Now().After(challenge.ExpiresAt) {
+			expired = append(expired, getChallengeDBKey(challenge.Value))
+		}
+	}
+
+	for _, key := range expired {
+		if err := s.store.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete challenge: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// purgeDue tells whether the expired challenges are to be purged, at most once per purge interval.
+func (s *ChallengeStore) purgeDue() bool {
+	s.purgeMutex.Lock()
+	defer s.purgeMutex.Unlock()
+
+	if time.Since(s.lastPurge) < purgeInterval {
+		return false
+	}
+
+	s.lastPurge = time.Now()
+
+	return true
+}
+
+func (s *ChallengeStore) lock(key string) (func(), error) {
+	if s.locker != nil {
+		return s.locker.Lock(key)
+	}
+
+	s.mutex.Lock()
+
+	return s.mutex.Unlock, nil
+}
+
+func (s *ChallengeStore) get(key string) (*Challenge, error) {
+	challengeBytes, err := s.store.Get(key)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, ErrChallengeNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	challenge := &Challenge{}
+
+	if err = json.Unmarshal(challengeBytes, challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+func (c *Challenge) check(profileID, domain string) error {
+	switch {
+	case c.ProfileID != profileID:
+		return fmt.Errorf("challenge was issued for profile %s", c.ProfileID)
+	case c.Domain != domain:
+		return fmt.Errorf("challenge was issued for domain %s", c.Domain)
+	case time.Now().After(c.ExpiresAt):
+		return fmt.Errorf("challenge expired on %s", c.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func getChallengeDBKey(value string) string {
+	return fmt.Sprintf(keyPattern, challengeKeyPrefix, value)
+}
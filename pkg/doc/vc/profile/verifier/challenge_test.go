@@ -0,0 +1,229 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeStore(t *testing.T) {
+	t.Run("consume - success", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "example.com", time.Minute)
+		require.NoError(t, err)
+		require.NotEmpty(t, challenge.Value)
+		require.Equal(t, testProfileID, challenge.ProfileID)
+		require.Equal(t, "example.com", challenge.Domain)
+		require.True(t, challenge.ExpiresAt.After(time.Now()))
+
+		require.NoError(t, s.Consume(challenge.Value, testProfileID, "example.com"))
+
+		// challenges are single-use
+		require.ErrorIs(t, s.Consume(challenge.Value, testProfileID, "example.com"), ErrChallengeNotFound)
+	})
+
+	t.Run("consume - concurrent", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "", time.Minute)
+		require.NoError(t, err)
+
+		var (
+			wg       sync.WaitGroup
+			mutex    sync.Mutex
+			consumed int
+		)
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if s.Consume(challenge.Value, testProfileID, "") == nil {
+					mutex.Lock()
+					consumed++
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+		require.Equal(t, 1, consumed)
+	})
+
+	t.Run("consume - not issued", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		require.ErrorIs(t, s.Consume("challenge", testProfileID, ""), ErrChallengeNotFound)
+	})
+
+	t.Run("consume - other profile, domain or expired", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "example.com", time.Minute)
+		require.NoError(t, err)
+
+		err = s.Consume(challenge.Value, "other", "example.com")
+		require.EqualError(t, err, "challenge was issued for profile "+testProfileID)
+
+		// a challenge failing the checks is consumed all the same
+		require.ErrorIs(t, s.Consume(challenge.Value, testProfileID, "example.com"), ErrChallengeNotFound)
+
+		challenge, err = s.Issue(testProfileID, "example.com", time.Minute)
+		require.NoError(t, err)
+
+		err = s.Consume(challenge.Value, testProfileID, "other.com")
+		require.EqualError(t, err, "challenge was issued for domain example.com")
+
+		challenge, err = s.Issue(testProfileID, "", -time.Minute)
+		require.NoError(t, err)
+
+		err = s.Consume(challenge.Value, testProfileID, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "challenge expired on")
+	})
+
+	t.Run("check - challenge isn't consumed", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "example.com", time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Check(challenge.Value, testProfileID, "example.com"))
+		require.EqualError(t, s.Check(challenge.Value, testProfileID, "other.com"),
+			"challenge was issued for domain example.com")
+		require.ErrorIs(t, s.Check("other", testProfileID, "example.com"), ErrChallengeNotFound)
+
+		require.NoError(t, s.Consume(challenge.Value, testProfileID, "example.com"))
+		require.ErrorIs(t, s.Check(challenge.Value, testProfileID, "example.com"), ErrChallengeNotFound)
+	})
+
+	t.Run("consume - locker", func(t *testing.T) {
+		locker := &mockLocker{}
+
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider(), WithChallengeLocker(locker))
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "", time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Consume(challenge.Value, testProfileID, ""))
+		require.Equal(t, []string{getChallengeDBKey(challenge.Value)}, locker.locked)
+		require.Equal(t, 1, locker.unlocked)
+
+		locker.err = errors.New("lock error")
+
+		err = s.Consume(challenge.Value, testProfileID, "")
+		require.EqualError(t, err, "failed to lock challenge: lock error")
+	})
+
+	t.Run("purge expired", func(t *testing.T) {
+		s, err := NewChallengeStore(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		expired, err := s.Issue(testProfileID, "", -time.Minute)
+		require.NoError(t, err)
+
+		// the challenges are purged on the first issue, then once per purge interval
+		require.ErrorIs(t, s.Check(expired.Value, testProfileID, ""), ErrChallengeNotFound)
+
+		expired, err = s.Issue(testProfileID, "", -time.Minute)
+		require.NoError(t, err)
+
+		_, err = s.store.Get(getChallengeDBKey(expired.Value))
+		require.NoError(t, err)
+
+		valid, err := s.Issue(testProfileID, "", time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, s.PurgeExpired())
+
+		_, err = s.store.Get(getChallengeDBKey(expired.Value))
+		require.ErrorIs(t, err, ariesstorage.ErrDataNotFound)
+		require.NoError(t, s.Check(valid.Value, testProfileID, ""))
+	})
+
+	t.Run("store name", func(t *testing.T) {
+		provider := ariesmemstorage.NewProvider()
+
+		s, err := NewChallengeStore(provider, WithChallengeStoreName("issuerchallenge"))
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, s.Check(challenge.Value, testProfileID, ""))
+
+		verifierStore, err := NewChallengeStore(provider)
+		require.NoError(t, err)
+
+		// challenges aren't shared between the stores
+		require.ErrorIs(t, verifierStore.Check(challenge.Value, testProfileID, ""), ErrChallengeNotFound)
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		_, err := NewChallengeStore(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+		require.EqualError(t, err, "failed to open challenge store: open error")
+
+		store := &ariesmockstorage.MockStore{Store: make(map[string]ariesmockstorage.DBEntry)}
+
+		s, err := NewChallengeStore(&ariesmockstorage.MockStoreProvider{Store: store})
+		require.NoError(t, err)
+
+		challenge, err := s.Issue(testProfileID, "", time.Minute)
+		require.NoError(t, err)
+
+		store.ErrDelete = errors.New("delete error")
+
+		err = s.Consume(challenge.Value, testProfileID, "")
+		require.EqualError(t, err, "failed to delete challenge: delete error")
+
+		store.ErrGet = errors.New("get error")
+
+		err = s.Consume(challenge.Value, testProfileID, "")
+		require.EqualError(t, err, "failed to get challenge: get error")
+
+		store.ErrQuery = errors.New("query error")
+
+		require.EqualError(t, s.PurgeExpired(), "failed to query challenges: query error")
+
+		store.ErrPut = errors.New("put error")
+
+		_, err = s.Issue(testProfileID, "", time.Minute)
+		require.EqualError(t, err, "failed to save challenge: put error")
+	})
+}
+
+type mockLocker struct {
+	locked   []string
+	unlocked int
+	err      error
+}
+
+func (l *mockLocker) Lock(key string) (func(), error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	l.locked = append(l.locked, key)
+
+	return func() { l.unlocked++ }, nil
+}
@@ -9,8 +9,13 @@ package verifier
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/internal/tagging"
 )
 
 const (
@@ -18,11 +23,18 @@ const (
 	profileKeyPrefix = "profile"
 
 	storeName = "verifier"
+
+	// profiles are tagged to be listed
+	profileTag = "verifierProfile"
 )
 
+var logger = log.New("edge-service-verifier-profile")
+
 // Profile db operation
 type Profile struct {
 	store ariesstorage.Store
+	// tags indexes the tagged profiles, profiles saved before they were tagged are tagged when read
+	tags *tagging.Index
 }
 
 // ProfileData struct for profile
@@ -31,6 +43,40 @@ type ProfileData struct {
 	Name               string   `json:"name"`
 	CredentialChecks   []string `json:"credentialChecks,omitempty"`
 	PresentationChecks []string `json:"presentationChecks,omitempty"`
+	// PresentationDefinitions state the credentials presentations verified with the profile have to submit
+	PresentationDefinitions []*presexch.PresentationDefinition `json:"presentationDefinitions,omitempty"`
+	// RequireIssuedChallenge only accepts presentations proving a challenge issued for the profile, once
+	RequireIssuedChallenge bool `json:"requireIssuedChallenge,omitempty"`
+	// IssuerTrust states the issuers trusted by the issuerTrust check
+	IssuerTrust *IssuerTrustPolicy `json:"issuerTrust,omitempty"`
+}
+
+// IssuerTrustPolicy states the issuers whose credentials are trusted, an issuer being trusted if any of the rules
+// trusts it.
+type IssuerTrustPolicy struct {
+	// Issuers are trusted by DID, for some credential types only if they are set
+	Issuers []*TrustedIssuer `json:"issuers,omitempty"`
+	// DIDMethods trust all the issuers of the given DID methods, for any credential type
+	DIDMethods []string `json:"didMethods,omitempty"`
+	// GovernanceFramework is a governance credential, the DIDs it defines are trusted for any credential type
+	GovernanceFramework json.RawMessage `json:"governanceFramework,omitempty"`
+}
+
+// TrustedIssuer is an issuer trusted for the credentials of the given types, or of any type if none is set.
+type TrustedIssuer struct {
+	DID             string   `json:"did"`
+	CredentialTypes []string `json:"credentialTypes,omitempty"`
+}
+
+// PresentationDefinition returns the presentation definition of the profile with the given id, nil if not found.
+func (p *ProfileData) PresentationDefinition(id string) *presexch.PresentationDefinition {
+	for _, pd := range p.PresentationDefinitions {
+		if pd.ID == id {
+			return pd
+		}
+	}
+
+	return nil
 }
 
 // New returns new credential recorder instance
@@ -40,7 +86,7 @@ func New(provider ariesstorage.Provider) (*Profile, error) {
 		return nil, err
 	}
 
-	return &Profile{store: store}, nil
+	return &Profile{store: store, tags: tagging.New(store, profileTag)}, nil
 }
 
 // SaveProfile saves the profile data.
@@ -50,7 +96,13 @@ func (c *Profile) SaveProfile(data *ProfileData) error {
 		return fmt.Errorf("verifier profile save - marshalling error: %w", err)
 	}
 
-	return c.store.Put(getDBKey(data.ID), bytes)
+	if err := c.store.Put(getDBKey(data.ID), bytes, ariesstorage.Tag{Name: profileTag}); err != nil {
+		return err
+	}
+
+	c.tags.Added(getDBKey(data.ID))
+
+	return nil
 }
 
 // GetProfile retrieves the profile data based on id.
@@ -60,6 +112,11 @@ func (c *Profile) GetProfile(id string) (*ProfileData, error) {
 		return nil, err
 	}
 
+	// profiles saved before profiles were tagged are tagged so that they're listed
+	if err := c.tags.Ensure(getDBKey(id), bytes); err != nil {
+		logger.Warnf("failed to tag verifier profile %s: %s", id, err)
+	}
+
 	response := &ProfileData{}
 
 	err = json.Unmarshal(bytes, response)
@@ -70,9 +127,76 @@ func (c *Profile) GetProfile(id string) (*ProfileData, error) {
 	return response, nil
 }
 
+// ListProfiles returns the given page of the profiles sorted by id, along with the total number of profiles.
+// Pages are numbered from 0, all profiles are returned when the page size is 0. Profiles saved before profiles
+// were tagged are listed once they're read or saved again.
+func (c *Profile) ListProfiles(page, pageSize int) ([]*ProfileData, int, error) {
+	iter, err := c.store.Query(profileTag)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query verifier profiles: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close profile iterator: %s", errClose)
+		}
+	}()
+
+	var profiles []*ProfileData
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query verifier profiles: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		bytes, err := iter.Value()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to query verifier profiles: %w", err)
+		}
+
+		profile := &ProfileData{}
+		if err := json.Unmarshal(bytes, profile); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal verifier profile: %w", err)
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+
+	total := len(profiles)
+
+	if pageSize == 0 {
+		return profiles, total, nil
+	}
+
+	start := page * pageSize
+	if start >= total {
+		return []*ProfileData{}, total, nil
+	}
+
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return profiles[start:end], total, nil
+}
+
 // DeleteProfile deletes the verifier profile from underlying store
 func (c *Profile) DeleteProfile(name string) error {
-	return c.store.Delete(getDBKey(name))
+	if err := c.store.Delete(getDBKey(name)); err != nil {
+		return err
+	}
+
+	c.tags.Removed(getDBKey(name))
+
+	return nil
 }
 
 func getDBKey(id string) string {
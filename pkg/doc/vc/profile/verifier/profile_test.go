@@ -11,6 +11,7 @@ import (
 	"errors"
 	"testing"
 
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/stretchr/testify/require"
 )
@@ -128,3 +129,74 @@ func TestDeleteProfile(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestListProfiles(t *testing.T) {
+	t.Run("test list profiles - success", func(t *testing.T) {
+		profileStore, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		for _, id := range []string{"verifier-3", "verifier-1", "verifier-2"} {
+			require.NoError(t, profileStore.SaveProfile(&ProfileData{ID: id}))
+		}
+
+		profiles, total, err := profileStore.ListProfiles(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 3)
+		require.Equal(t, "verifier-1", profiles[0].ID)
+
+		profiles, total, err = profileStore.ListProfiles(1, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Len(t, profiles, 1)
+		require.Equal(t, "verifier-3", profiles[0].ID)
+
+		profiles, _, err = profileStore.ListProfiles(5, 2)
+		require.NoError(t, err)
+		require.Empty(t, profiles)
+	})
+
+	t.Run("test list profiles - error", func(t *testing.T) {
+		profileStore, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: errors.New("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, _, err = profileStore.ListProfiles(0, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query verifier profiles: query error")
+	})
+}
+
+func TestProfilesSavedBeforeTagging(t *testing.T) {
+	provider := ariesmemstorage.NewProvider()
+
+	profileStore, err := New(provider)
+	require.NoError(t, err)
+
+	store, err := provider.OpenStore(storeName)
+	require.NoError(t, err)
+
+	require.NoError(t, profileStore.SaveProfile(&ProfileData{ID: "verifier-1"}))
+	require.NoError(t, store.Put(getDBKey("verifier-2"), []byte(`{"id":"verifier-2"}`)))
+
+	_, total, err := profileStore.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	// profiles are tagged when read
+	_, err = profileStore.GetProfile("verifier-2")
+	require.NoError(t, err)
+
+	profiles, total, err := profileStore.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, "verifier-2", profiles[1].ID)
+
+	require.NoError(t, profileStore.DeleteProfile("verifier-2"))
+
+	_, total, err = profileStore.ListProfiles(0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
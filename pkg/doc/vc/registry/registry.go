@@ -0,0 +1,365 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/edge-service/pkg/lock"
+)
+
+const (
+	storeName  = "issuedcredentials"
+	keyPrefix  = "issuedvc"
+	profileTag = "profile"
+	subjectTag = "subject"
+	typeTag    = "type"
+	issuedTag  = "issued"
+)
+
+var logger = log.New("edge-service-credential-registry")
+
+// ErrNotFound is returned when the credential isn't in the registry
+var ErrNotFound = errors.New("credential not found in the registry")
+
+// ErrAlreadyRegistered is returned when the profile already registered a credential with the same ID
+var ErrAlreadyRegistered = errors.New("credential already in the registry")
+
+// Record is the registry entry of an issued credential
+type Record struct {
+	ID       string   `json:"id"`
+	Subjects []string `json:"subjects,omitempty"`
+	Types    []string `json:"types"`
+	// Status is the status list entry of the credential, holding its status list index
+	Status         *verifiable.TypedID `json:"credentialStatus,omitempty"`
+	IssuanceDate   *time.Time          `json:"issuanceDate,omitempty"`
+	ExpirationDate *time.Time          `json:"expirationDate,omitempty"`
+}
+
+// Query filters the records of a profile, empty fields match any record
+type Query struct {
+	Subject string
+	Type    string
+}
+
+// Store keeps the records of the credentials issued by a profile
+type Store struct {
+	store  ariesstorage.Store
+	locker Locker
+}
+
+// Locker serializes the registration of a credential ID.
+type Locker interface {
+	// Lock blocks until the lock for the given key is acquired and returns the function releasing it.
+	Lock(key string) (func(), error)
+}
+
+// Opt configures the registry store
+type Opt func(s *Store)
+
+// WithLocker is an option to pass the locker serializing the registration of a credential ID, in-memory locker
+// is used if not set
+func WithLocker(locker Locker) Opt {
+	return func(s *Store) {
+		s.locker = locker
+	}
+}
+
+// New returns new registry store
+func New(provider ariesstorage.Provider, opts ...Opt) (*Store, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open registry store: %w", err)
+	}
+
+	s := &Store{store: store}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.locker == nil {
+		s.locker = lock.NewMemLocker()
+	}
+
+	return s, nil
+}
+
+// NewRecord returns the registry record of the given credential
+func NewRecord(vc *verifiable.Credential) *Record {
+	record := &Record{
+		ID:     vc.ID,
+		Types:  vc.Types,
+		Status: vc.Status,
+	}
+
+	if vc.Issued != nil {
+		record.IssuanceDate = &vc.Issued.Time
+	}
+
+	if vc.Expired != nil {
+		record.ExpirationDate = &vc.Expired.Time
+	}
+
+	if subjectID, err := verifiable.SubjectID(vc.Subject); err == nil {
+		record.Subjects = []string{subjectID}
+	} else if subjects, ok := vc.Subject.([]verifiable.Subject); ok {
+		for _, s := range subjects {
+			record.Subjects = append(record.Subjects, s.ID)
+		}
+	}
+
+	return record
+}
+
+// Credential returns the credential fields kept in the record, enough to update the credential status
+func (r *Record) Credential() *verifiable.Credential {
+	return &verifiable.Credential{ID: r.ID, Types: r.Types, Status: r.Status}
+}
+
+// Add records the credential issued by the given profile, ErrAlreadyRegistered if the profile already
+// issued a credential with the same ID. The record is tagged with its subjects, types and issuance date
+// so that it can be found without loading the other records of the profile.
+// The record is checked and stored under the lock of its key, so only one of the credentials issued concurrently
+// with the same ID is registered.
+func (s *Store) Add(profileID string, record *Record) error {
+	if record.ID == "" {
+		return errors.New("credential ID is required")
+	}
+
+	unlock, err := s.locker.Lock(key(profileID, record.ID))
+	if err != nil {
+		return fmt.Errorf("failed to lock registry record: %w", err)
+	}
+
+	defer unlock()
+
+	_, err = s.store.Get(key(profileID, record.ID))
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, record.ID)
+	}
+
+	if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return fmt.Errorf("failed to get registry record: %w", err)
+	}
+
+	return s.Update(profileID, record)
+}
+
+// Update replaces the record of the credential issued again by the given profile, registering it if it isn't yet.
+func (s *Store) Update(profileID string, record *Record) error {
+	if record.ID == "" {
+		return errors.New("credential ID is required")
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry record: %w", err)
+	}
+
+	if err = s.store.Put(key(profileID, record.ID), recordBytes, recordTags(profileID, record)...); err != nil {
+		return fmt.Errorf("failed to store registry record: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the record of the given credential, ErrNotFound if it isn't registered
+func (s *Store) Get(profileID, credentialID string) (*Record, error) {
+	recordBytes, err := s.store.Get(key(profileID, credentialID))
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get registry record: %w", err)
+	}
+
+	record := &Record{}
+	if err := json.Unmarshal(recordBytes, record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal registry record: %w", err)
+	}
+
+	return record, nil
+}
+
+// Find returns the given page of the profile records matching the query, latest issued first,
+// along with the total number of matching records. Pages are numbered from 0, all matching records are
+// returned when the page size is 0.
+// Matching records are found and sorted through their tags, only the records of the page are loaded.
+func (s *Store) Find(profileID string, q *Query, page, pageSize int) ([]*Record, int, error) {
+	entries, err := s.find(profileID, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].issued != entries[j].issued {
+			return entries[i].issued > entries[j].issued
+		}
+
+		return entries[i].key < entries[j].key
+	})
+
+	total := len(entries)
+
+	if pageSize != 0 {
+		start := page * pageSize
+		if start >= total {
+			return []*Record{}, total, nil
+		}
+
+		end := start + pageSize
+		if end > total {
+			end = total
+		}
+
+		entries = entries[start:end]
+	}
+
+	records := make([]*Record, len(entries))
+
+	for i, e := range entries {
+		recordBytes, err := s.store.Get(e.key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get registry record: %w", err)
+		}
+
+		records[i] = &Record{}
+		if err := json.Unmarshal(recordBytes, records[i]); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal registry record: %w", err)
+		}
+	}
+
+	return records, total, nil
+}
+
+type entry struct {
+	key    string
+	issued string
+}
+
+// find returns the keys and issuance dates of the profile records matching the query, the store is queried
+// by the most selective tag of the query.
+func (s *Store) find(profileID string, q *Query) ([]*entry, error) {
+	expression := profileTag + ":" + encode(profileID)
+
+	var filter *ariesstorage.Tag
+
+	switch {
+	case q != nil && q.Subject != "":
+		expression = subjectTag + ":" + tagValue(profileID, q.Subject)
+
+		if q.Type != "" {
+			filter = &ariesstorage.Tag{Name: typeTag, Value: tagValue(profileID, q.Type)}
+		}
+	case q != nil && q.Type != "":
+		expression = typeTag + ":" + tagValue(profileID, q.Type)
+	}
+
+	iter, err := s.store.Query(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query registry: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close registry iterator: %s", errClose)
+		}
+	}()
+
+	var entries []*entry
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query registry: %w", err)
+		}
+
+		if !ok {
+			return entries, nil
+		}
+
+		k, err := iter.Key()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query registry: %w", err)
+		}
+
+		tags, err := iter.Tags()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query registry: %w", err)
+		}
+
+		if filter != nil && !hasTag(tags, *filter) {
+			continue
+		}
+
+		e := &entry{key: k}
+
+		for _, tag := range tags {
+			if tag.Name == issuedTag {
+				e.issued = tag.Value
+			}
+		}
+
+		entries = append(entries, e)
+	}
+}
+
+// recordTags returns the tags the record is found by, values are encoded since tag values can't hold colons.
+// The issuance date is in nanoseconds padded to sort as a string, records without one sort last.
+func recordTags(profileID string, record *Record) []ariesstorage.Tag {
+	tags := []ariesstorage.Tag{{Name: profileTag, Value: encode(profileID)}}
+
+	if record.IssuanceDate != nil {
+		tags = append(tags, ariesstorage.Tag{
+			Name:  issuedTag,
+			Value: fmt.Sprintf("%020d", record.IssuanceDate.UnixNano()),
+		})
+	}
+
+	for _, subject := range record.Subjects {
+		tags = append(tags, ariesstorage.Tag{Name: subjectTag, Value: tagValue(profileID, subject)})
+	}
+
+	for _, t := range record.Types {
+		tags = append(tags, ariesstorage.Tag{Name: typeTag, Value: tagValue(profileID, t)})
+	}
+
+	return tags
+}
+
+func tagValue(profileID, value string) string {
+	return encode(profileID) + "." + encode(value)
+}
+
+func encode(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func hasTag(tags []ariesstorage.Tag, tag ariesstorage.Tag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func key(profileID, credentialID string) string {
+	return fmt.Sprintf("%s_%s_%s", keyPrefix, profileID, credentialID)
+}
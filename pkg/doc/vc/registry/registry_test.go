@@ -0,0 +1,332 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	})
+
+	t.Run("test error from open store", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")})
+		require.Error(t, err)
+		require.Nil(t, s)
+		require.Contains(t, err.Error(), "failed to open registry store")
+	})
+}
+
+func TestNewRecord(t *testing.T) {
+	issued := time.Now().UTC()
+	expired := issued.Add(time.Hour)
+
+	vc := &verifiable.Credential{
+		ID:      "http://example.edu/credentials/1",
+		Types:   []string{"VerifiableCredential", "UniversityDegreeCredential"},
+		Subject: "did:example:subject1",
+		Issued:  util.NewTime(issued),
+		Expired: util.NewTime(expired),
+		Status:  &verifiable.TypedID{ID: "https://example.com/status/1#1", Type: "StatusList2021Entry"},
+	}
+
+	record := NewRecord(vc)
+	require.Equal(t, vc.ID, record.ID)
+	require.Equal(t, vc.Types, record.Types)
+	require.Equal(t, []string{"did:example:subject1"}, record.Subjects)
+	require.Equal(t, issued, *record.IssuanceDate)
+	require.Equal(t, expired, *record.ExpirationDate)
+	require.Equal(t, vc.Status, record.Status)
+
+	vc.Subject = []verifiable.Subject{{ID: "did:example:subject1"}, {ID: "did:example:subject2"}}
+	vc.Issued, vc.Expired = nil, nil
+
+	record = NewRecord(vc)
+	require.Equal(t, []string{"did:example:subject1", "did:example:subject2"}, record.Subjects)
+	require.Nil(t, record.IssuanceDate)
+	require.Nil(t, record.ExpirationDate)
+
+	credential := record.Credential()
+	require.Equal(t, vc.ID, credential.ID)
+	require.Equal(t, vc.Status, credential.Status)
+}
+
+func TestStore_AddGet(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		_, err = s.Get("profile1", "cred1")
+		require.ErrorIs(t, err, ErrNotFound)
+
+		require.NoError(t, s.Add("profile1", &Record{ID: "cred1", Subjects: []string{"did:example:1"}}))
+
+		record, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"did:example:1"}, record.Subjects)
+
+		_, err = s.Get("profile2", "cred1")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("test error - missing credential ID", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		err = s.Add("profile1", &Record{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "credential ID is required")
+	})
+
+	t.Run("test error - already registered", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Add("profile1", &Record{ID: "cred1", Subjects: []string{"did:example:1"}}))
+
+		err = s.Add("profile1", &Record{ID: "cred1", Subjects: []string{"did:example:2"}})
+		require.ErrorIs(t, err, ErrAlreadyRegistered)
+
+		record, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"did:example:1"}, record.Subjects)
+
+		// IDs are unique per profile
+		require.NoError(t, s.Add("profile2", &Record{ID: "cred1"}))
+	})
+
+	t.Run("test concurrent adds of the same ID", func(t *testing.T) {
+		s, err := New(ariesmemstorage.NewProvider())
+		require.NoError(t, err)
+
+		const adds = 10
+
+		errs := make([]error, adds)
+
+		var wg sync.WaitGroup
+
+		wg.Add(adds)
+
+		for i := 0; i < adds; i++ {
+			go func(i int) {
+				defer wg.Done()
+
+				errs[i] = s.Add("profile1", &Record{ID: "cred1", Subjects: []string{fmt.Sprintf("did:example:%d", i)}})
+			}(i)
+		}
+
+		wg.Wait()
+
+		added := 0
+
+		for _, err := range errs {
+			if err == nil {
+				added++
+
+				continue
+			}
+
+			require.ErrorIs(t, err, ErrAlreadyRegistered)
+		}
+
+		require.Equal(t, 1, added)
+	})
+
+	t.Run("test error from lock", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), WithLocker(&mockLocker{err: errors.New("lock error")}))
+		require.NoError(t, err)
+
+		err = s.Add("profile1", &Record{ID: "cred1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to lock registry record: lock error")
+	})
+
+	t.Run("test update", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Update("profile1", &Record{ID: "cred1", Subjects: []string{"did:example:1"}}))
+		require.NoError(t, s.Update("profile1", &Record{ID: "cred1", Subjects: []string{"did:example:2"}}))
+
+		record, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"did:example:2"}, record.Subjects)
+
+		records, total, err := s.Find("profile1", &Query{Subject: "did:example:1"}, 0, 10)
+		require.NoError(t, err)
+		require.Zero(t, total)
+		require.Empty(t, records)
+
+		require.EqualError(t, s.Update("profile1", &Record{}), "credential ID is required")
+	})
+
+	t.Run("test error from put", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrPut: fmt.Errorf("put error"),
+		}})
+		require.NoError(t, err)
+
+		err = s.Add("profile1", &Record{ID: "cred1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to store registry record: put error")
+	})
+
+	t.Run("test error from get", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrGet: fmt.Errorf("get error"),
+		}})
+		require.NoError(t, err)
+
+		err = s.Add("profile1", &Record{ID: "cred1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get registry record: get error")
+
+		_, err = s.Get("profile1", "cred1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get registry record: get error")
+	})
+}
+
+func TestStore_Find(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		now := time.Now().UTC()
+
+		for i := 0; i < 5; i++ {
+			issued := now.Add(time.Duration(i) * time.Minute)
+
+			require.NoError(t, s.Add("profile1", &Record{
+				ID:           fmt.Sprintf("cred%d", i),
+				Subjects:     []string{fmt.Sprintf("did:example:%d", i%2)},
+				Types:        []string{"VerifiableCredential", fmt.Sprintf("Type%d", i%3)},
+				IssuanceDate: &issued,
+			}))
+		}
+
+		require.NoError(t, s.Add("profile2", &Record{ID: "cred5", Subjects: []string{"did:example:0"}}))
+
+		records, total, err := s.Find("profile1", nil, 0, 2)
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+		require.Len(t, records, 2)
+		require.Equal(t, "cred4", records[0].ID)
+		require.Equal(t, "cred3", records[1].ID)
+
+		records, total, err = s.Find("profile1", &Query{}, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+		require.Len(t, records, 1)
+		require.Equal(t, "cred0", records[0].ID)
+
+		records, total, err = s.Find("profile1", nil, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+		require.Len(t, records, 5)
+
+		records, total, err = s.Find("profile1", nil, 3, 2)
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+		require.Empty(t, records)
+
+		records, total, err = s.Find("profile1", &Query{Subject: "did:example:0"}, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Equal(t, "cred4", records[0].ID)
+		require.Equal(t, "cred2", records[1].ID)
+		require.Equal(t, "cred0", records[2].ID)
+
+		records, total, err = s.Find("profile1", &Query{Subject: "did:example:0", Type: "Type0"}, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, "cred0", records[0].ID)
+
+		records, total, err = s.Find("profile1", &Query{Type: "Type1"}, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		require.Equal(t, "cred4", records[0].ID)
+		require.Equal(t, "cred1", records[1].ID)
+
+		records, total, err = s.Find("profile2", &Query{Subject: "did:example:0"}, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, "cred5", records[0].ID)
+
+		records, total, err = s.Find("profile3", nil, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, total)
+		require.Empty(t, records)
+	})
+
+	t.Run("test records without issuance date come last", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		issued := time.Now()
+
+		require.NoError(t, s.Add("profile1", &Record{ID: "cred0"}))
+		require.NoError(t, s.Add("profile1", &Record{ID: "cred1", IssuanceDate: &issued}))
+
+		records, total, err := s.Find("profile1", nil, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		require.Equal(t, "cred1", records[0].ID)
+		require.Equal(t, "cred0", records[1].ID)
+	})
+
+	t.Run("test error from get", func(t *testing.T) {
+		store := &ariesmockstorage.MockStore{Store: make(map[string]ariesmockstorage.DBEntry)}
+
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: store})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Add("profile1", &Record{ID: "cred1"}))
+
+		store.ErrGet = fmt.Errorf("get error")
+
+		_, _, err = s.Find("profile1", nil, 0, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get registry record: get error")
+	})
+
+	t.Run("test error from store", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: fmt.Errorf("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, _, err = s.Find("profile1", nil, 0, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query registry: query error")
+	})
+}
+
+type mockLocker struct {
+	err error
+}
+
+func (m *mockLocker) Lock(string) (func(), error) {
+	return func() {}, m.err
+}
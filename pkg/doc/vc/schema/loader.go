@@ -0,0 +1,292 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFetchTimeout = 10 * time.Second
+	maxSchemaSize       = 1 << 20
+	defaultCacheSize    = 1000
+)
+
+// Loader loads the JSON schemas referenced by credentials
+type Loader interface {
+	Load(url string) ([]byte, error)
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTPLoader fetches schemas from their URL. The URLs come from the credentials, so the hosts
+// schemas may be fetched from should be restricted with WithAllowedHosts.
+type HTTPLoader struct {
+	client       httpClient
+	timeout      time.Duration
+	allowedHosts map[string]bool
+}
+
+// HTTPLoaderOpt configures the HTTP schema loader
+type HTTPLoaderOpt func(*HTTPLoader)
+
+// WithFetchTimeout sets the time fetching a schema may take, defaults to 10 seconds
+func WithFetchTimeout(timeout time.Duration) HTTPLoaderOpt {
+	return func(l *HTTPLoader) {
+		l.timeout = timeout
+	}
+}
+
+// WithAllowedHosts restricts the hosts schemas are fetched from, any host is allowed if none is given
+func WithAllowedHosts(hosts ...string) HTTPLoaderOpt {
+	return func(l *HTTPLoader) {
+		for _, host := range hosts {
+			l.allowedHosts[host] = true
+		}
+	}
+}
+
+// NewHTTPLoader returns new HTTP schema loader
+func NewHTTPLoader(client httpClient, opts ...HTTPLoaderOpt) *HTTPLoader {
+	l := &HTTPLoader{client: client, timeout: defaultFetchTimeout, allowedHosts: make(map[string]bool)}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Load fetches the schema
+func (l *HTTPLoader) Load(schemaURL string) ([]byte, error) {
+	u, err := url.Parse(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema request: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("schema %s isn't an http(s) URL", schemaURL)
+	}
+
+	if len(l.allowedHosts) > 0 && !l.allowedHosts[u.Hostname()] {
+		return nil, fmt.Errorf("schema host %s isn't allowed", u.Hostname())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, schemaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema request: %w", err)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema %s: %w", schemaURL, err)
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body: %s", errClose)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch schema %s: status %d", schemaURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSchemaSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", schemaURL, err)
+	}
+
+	if len(body) > maxSchemaSize {
+		return nil, fmt.Errorf("schema %s exceeds %d bytes", schemaURL, maxSchemaSize)
+	}
+
+	return body, nil
+}
+
+// LocalLoader serves schemas kept in memory, other schemas are loaded by the next loader if any.
+type LocalLoader struct {
+	schemas map[string][]byte
+	next    Loader
+}
+
+// NewLocalLoader returns new loader of the given schemas keyed by URL
+func NewLocalLoader(schemas map[string][]byte, next Loader) *LocalLoader {
+	return &LocalLoader{schemas: schemas, next: next}
+}
+
+// Load returns the local schema, or the one of the next loader
+func (l *LocalLoader) Load(url string) ([]byte, error) {
+	if s, ok := l.schemas[url]; ok {
+		return s, nil
+	}
+
+	if l.next == nil {
+		return nil, fmt.Errorf("schema %s not found", url)
+	}
+
+	return l.next.Load(url)
+}
+
+// ReadDir reads the JSON schema files of the directory, keyed by their $id
+func ReadDir(dir string) (map[string][]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema files: %w", err)
+	}
+
+	schemas := make(map[string][]byte, len(files))
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema file %s: %w", file, err)
+		}
+
+		s := struct {
+			ID string `json:"$id"`
+		}{}
+
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("invalid schema file %s: %w", file, err)
+		}
+
+		if s.ID == "" {
+			return nil, fmt.Errorf("schema file %s has no $id", file)
+		}
+
+		schemas[s.ID] = data
+	}
+
+	return schemas, nil
+}
+
+// CachingLoader keeps the schemas loaded by the next loader for the given TTL, the least recently used
+// schemas are evicted once the cache is full.
+type CachingLoader struct {
+	next       Loader
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	recent     *list.List
+}
+
+type cacheEntry struct {
+	url     string
+	schema  []byte
+	expires time.Time
+}
+
+// CachingLoaderOpt configures the caching schema loader
+type CachingLoaderOpt func(*CachingLoader)
+
+// WithMaxEntries sets the number of schemas cached, defaults to 1000
+func WithMaxEntries(maxEntries int) CachingLoaderOpt {
+	return func(l *CachingLoader) {
+		l.maxEntries = maxEntries
+	}
+}
+
+// NewCachingLoader returns new caching loader
+func NewCachingLoader(next Loader, ttl time.Duration, opts ...CachingLoaderOpt) *CachingLoader {
+	l := &CachingLoader{
+		next:       next,
+		ttl:        ttl,
+		maxEntries: defaultCacheSize,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.maxEntries <= 0 {
+		l.maxEntries = defaultCacheSize
+	}
+
+	return l
+}
+
+// Load returns the cached schema, loading it with the next loader if it isn't cached or has expired
+func (l *CachingLoader) Load(url string) ([]byte, error) {
+	if s, ok := l.get(url); ok {
+		return s, nil
+	}
+
+	s, err := l.next.Load(url)
+	if err != nil {
+		return nil, err
+	}
+
+	l.put(url, s)
+
+	return s, nil
+}
+
+func (l *CachingLoader) get(url string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[url]
+	if !ok {
+		return nil, false
+	}
+
+	e := element.Value.(*cacheEntry) // nolint: errcheck,forcetypeassert
+
+	if time.Now().After(e.expires) {
+		l.remove(element)
+
+		return nil, false
+	}
+
+	l.recent.MoveToFront(element)
+
+	return e.schema, true
+}
+
+func (l *CachingLoader) put(url string, s []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &cacheEntry{url: url, schema: s, expires: time.Now().Add(l.ttl)}
+
+	if element, ok := l.entries[url]; ok {
+		element.Value = e
+		l.recent.MoveToFront(element)
+
+		return
+	}
+
+	l.entries[url] = l.recent.PushFront(e)
+
+	if l.recent.Len() > l.maxEntries {
+		l.remove(l.recent.Back())
+	}
+}
+
+func (l *CachingLoader) remove(element *list.Element) {
+	l.recent.Remove(element)
+	delete(l.entries, element.Value.(*cacheEntry).url) // nolint: errcheck,forcetypeassert
+}
@@ -0,0 +1,185 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPLoader(t *testing.T) {
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schema.json":
+			_, err := w.Write([]byte(testSchema))
+			require.NoError(t, err)
+		case "/large.json":
+			_, err := w.Write(make([]byte, maxSchemaSize+1))
+			require.NoError(t, err)
+		case "/slow.json":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer serv.Close()
+
+	l := NewHTTPLoader(&http.Client{})
+
+	s, err := l.Load(serv.URL + "/schema.json")
+	require.NoError(t, err)
+	require.Equal(t, testSchema, string(s))
+
+	_, err = l.Load(serv.URL + "/other.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "status 404")
+
+	_, err = l.Load("http://[::1]:namedport")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create schema request")
+
+	_, err = l.Load("file:///etc/passwd")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "isn't an http(s) URL")
+
+	_, err = l.Load(serv.URL + "/large.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds")
+
+	_, err = NewHTTPLoader(&http.Client{}, WithFetchTimeout(10*time.Millisecond)).Load(serv.URL + "/slow.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "context deadline exceeded")
+
+	_, err = NewHTTPLoader(&http.Client{}, WithAllowedHosts("example.com")).Load(serv.URL + "/schema.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "schema host 127.0.0.1 isn't allowed")
+
+	s, err = NewHTTPLoader(&http.Client{}, WithAllowedHosts("127.0.0.1")).Load(serv.URL + "/schema.json")
+	require.NoError(t, err)
+	require.Equal(t, testSchema, string(s))
+
+	_, err = NewHTTPLoader(&mockHTTPClient{err: fmt.Errorf("http error")}).Load(serv.URL)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "http error")
+}
+
+func TestLocalLoader(t *testing.T) {
+	next := NewLocalLoader(map[string][]byte{"https://example.com/next.json": []byte("next")}, nil)
+	l := NewLocalLoader(map[string][]byte{"https://example.com/local.json": []byte("local")}, next)
+
+	s, err := l.Load("https://example.com/local.json")
+	require.NoError(t, err)
+	require.Equal(t, "local", string(s))
+
+	s, err = l.Load("https://example.com/next.json")
+	require.NoError(t, err)
+	require.Equal(t, "next", string(s))
+
+	_, err = l.Load("https://example.com/other.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "schema https://example.com/other.json not found")
+}
+
+func TestReadDir(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.json"), []byte(testSchema), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "readme.txt"), []byte("skipped"), 0600))
+
+		schemas, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, schemas, 1)
+		require.Equal(t, testSchema, string(schemas[testSchemaID]))
+	})
+
+	t.Run("test error - invalid schema file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.json"), []byte("{"), 0600))
+
+		_, err := ReadDir(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid schema file")
+	})
+
+	t.Run("test error - missing $id", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.json"), []byte(`{"type":"object"}`), 0600))
+
+		_, err := ReadDir(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "has no $id")
+	})
+}
+
+func TestCachingLoader(t *testing.T) {
+	next := &countingLoader{schema: []byte(testSchema)}
+	l := NewCachingLoader(next, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		s, err := l.Load(testSchemaID)
+		require.NoError(t, err)
+		require.Equal(t, testSchema, string(s))
+	}
+
+	require.Equal(t, 1, next.calls)
+
+	// expired entries are loaded again
+	l = NewCachingLoader(next, 0)
+
+	_, err := l.Load(testSchemaID)
+	require.NoError(t, err)
+	_, err = l.Load(testSchemaID)
+	require.NoError(t, err)
+	require.Equal(t, 3, next.calls)
+
+	// errors aren't cached
+	next.err = fmt.Errorf("load error")
+
+	_, err = l.Load("https://example.com/other.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "load error")
+
+	// the least recently used entries are evicted once the cache is full
+	next = &countingLoader{schema: []byte(testSchema)}
+	l = NewCachingLoader(next, time.Hour, WithMaxEntries(2))
+
+	for _, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/1",
+		"https://example.com/3", "https://example.com/1", "https://example.com/2"} {
+		_, err = l.Load(url)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 4, next.calls)
+	require.Len(t, l.entries, 2)
+}
+
+type countingLoader struct {
+	schema []byte
+	err    error
+	calls  int
+}
+
+func (l *countingLoader) Load(string) ([]byte, error) {
+	l.calls++
+
+	return l.schema, l.err
+}
+
+type mockHTTPClient struct {
+	err error
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	return nil, m.err
+}
@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/xeipuuv/gojsonreference"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// JSONSchemaValidator2018 is the credential schema type validated with JSON schema
+const JSONSchemaValidator2018 = "JsonSchemaValidator2018"
+
+var logger = log.New("edge-service-credential-schema")
+
+// ValidationError lists the errors of a credential not conforming to its schema
+type ValidationError struct {
+	Schema string
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("credential doesn't conform to schema %s: %s", e.Schema, strings.Join(e.Errors, "; "))
+}
+
+// Validator validates credentials against their credential schemas
+type Validator struct {
+	loader Loader
+}
+
+// NewValidator returns new validator loading schemas with the given loader
+func NewValidator(loader Loader) *Validator {
+	return &Validator{loader: loader}
+}
+
+// Validate validates the credential document against each of its JsonSchemaValidator2018 schemas,
+// returning ValidationError if it doesn't conform. Schemas of other types are skipped.
+func (v *Validator) Validate(vc *verifiable.Credential) error {
+	if len(vc.Schemas) == 0 {
+		return nil
+	}
+
+	vcBytes, err := vc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential: %w", err)
+	}
+
+	for _, s := range vc.Schemas {
+		if s.Type != JSONSchemaValidator2018 {
+			logger.Debugf("skipping credential schema %s of unsupported type %s", s.ID, s.Type)
+
+			continue
+		}
+
+		if err := v.validate(s.ID, vcBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) validate(schemaURL string, vcBytes []byte) error {
+	schemaBytes, err := v.loader.Load(schemaURL)
+	if err != nil {
+		return fmt.Errorf("failed to load credential schema: %w", err)
+	}
+
+	// the schemas referenced with $ref are loaded with the same loader as the credential schema
+	schemaLoader := gojsonschema.NewSchemaLoader()
+
+	if err := schemaLoader.AddSchema(schemaURL, gojsonschema.NewBytesLoader(schemaBytes)); err != nil {
+		return fmt.Errorf("invalid credential schema %s: %w", schemaURL, err)
+	}
+
+	s, err := schemaLoader.Compile(&refLoader{source: schemaURL, loader: v.loader})
+	if err != nil {
+		return fmt.Errorf("invalid credential schema %s: %w", schemaURL, err)
+	}
+
+	result, err := s.Validate(gojsonschema.NewBytesLoader(vcBytes))
+	if err != nil {
+		return fmt.Errorf("invalid credential schema %s: %w", schemaURL, err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	errs := make([]string, len(result.Errors()))
+	for i, e := range result.Errors() {
+		errs[i] = e.String()
+	}
+
+	return &ValidationError{Schema: schemaURL, Errors: errs}
+}
+
+// refLoader loads the schema referenced by its URL with the schema loader, in place of the gojsonschema
+// loaders fetching the remote references themselves.
+type refLoader struct {
+	source string
+	loader Loader
+}
+
+func (l *refLoader) JsonSource() interface{} { // nolint: golint,stylecheck // implements gojsonschema.JSONLoader
+	return l.source
+}
+
+func (l *refLoader) LoadJSON() (interface{}, error) {
+	ref, err := gojsonreference.NewJsonReference(l.source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema reference %s: %w", l.source, err)
+	}
+
+	url := *ref.GetUrl()
+	url.Fragment = ""
+
+	schemaBytes, err := l.loader.Load(url.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load referenced schema: %w", err)
+	}
+
+	var doc interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(schemaBytes))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid referenced schema %s: %w", url.String(), err)
+	}
+
+	return doc, nil
+}
+
+func (l *refLoader) JsonReference() (gojsonreference.JsonReference, error) { // nolint: golint,stylecheck
+	return gojsonreference.NewJsonReference(l.source)
+}
+
+func (l *refLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return &refLoaderFactory{loader: l.loader}
+}
+
+type refLoaderFactory struct {
+	loader Loader
+}
+
+func (f *refLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return &refLoader{source: source, loader: f.loader}
+}
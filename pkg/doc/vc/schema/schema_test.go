@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSchemaID = "https://example.com/schemas/degree.json"
+	testSchema   = `{
+  "$id": "https://example.com/schemas/degree.json",
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "properties": {
+        "degree": {"type": "string", "enum": ["BachelorDegree", "MasterDegree"]}
+      },
+      "required": ["degree"]
+    }
+  },
+  "required": ["credentialSubject"]
+}`
+)
+
+func getTestCredential(degree interface{}) *verifiable.Credential {
+	return &verifiable.Credential{
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Types:   []string{"VerifiableCredential"},
+		Issuer:  verifiable.Issuer{ID: "did:example:issuer"},
+		Subject: verifiable.Subject{
+			ID:           "did:example:subject",
+			CustomFields: verifiable.CustomFields{"degree": degree},
+		},
+		Schemas: []verifiable.TypedID{{ID: testSchemaID, Type: JSONSchemaValidator2018}},
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	v := NewValidator(NewLocalLoader(map[string][]byte{testSchemaID: []byte(testSchema)}, nil))
+
+	t.Run("test success", func(t *testing.T) {
+		require.NoError(t, v.Validate(getTestCredential("MasterDegree")))
+
+		vc := getTestCredential("PhD")
+		vc.Schemas = nil
+		require.NoError(t, v.Validate(vc))
+
+		// schemas of other types are skipped
+		vc.Schemas = []verifiable.TypedID{{ID: "https://example.com/other", Type: "ZkpExampleSchema2018"}}
+		require.NoError(t, v.Validate(vc))
+	})
+
+	t.Run("test error - credential doesn't conform to the schema", func(t *testing.T) {
+		err := v.Validate(getTestCredential("PhD"))
+		require.Error(t, err)
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Equal(t, testSchemaID, validationErr.Schema)
+		require.Len(t, validationErr.Errors, 1)
+		require.Contains(t, validationErr.Errors[0], "credentialSubject.degree must be one of the following")
+		require.Contains(t, err.Error(), "credential doesn't conform to schema "+testSchemaID)
+	})
+
+	t.Run("test error - schema not found", func(t *testing.T) {
+		vc := getTestCredential("MasterDegree")
+		vc.Schemas[0].ID = "https://example.com/other.json"
+
+		err := v.Validate(vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load credential schema")
+	})
+
+	t.Run("test success - references are loaded with the loader", func(t *testing.T) {
+		next := &countingLoader{schema: []byte(`{"type": "string", "enum": ["MasterDegree"]}`)}
+		refSchema := `{
+  "$id": "https://example.com/schemas/ref.json",
+  "type": "object",
+  "properties": {
+    "credentialSubject": {
+      "type": "object",
+      "properties": {"degree": {"$ref": "degrees.json#"}}
+    }
+  }
+}`
+
+		rv := NewValidator(NewLocalLoader(map[string][]byte{testSchemaID: []byte(refSchema)}, next))
+
+		require.NoError(t, rv.Validate(getTestCredential("MasterDegree")))
+		require.Equal(t, 1, next.calls)
+
+		err := rv.Validate(getTestCredential("BachelorDegree"))
+		require.Error(t, err)
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+
+		next.err = errors.New("load error")
+
+		err = rv.Validate(getTestCredential("MasterDegree"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "load error")
+	})
+
+	t.Run("test error - invalid schema", func(t *testing.T) {
+		err := NewValidator(NewLocalLoader(map[string][]byte{testSchemaID: []byte(`{"type":1}`)}, nil)).
+			Validate(getTestCredential("MasterDegree"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential schema "+testSchemaID)
+	})
+}
@@ -0,0 +1,381 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sdjwt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// SHA256 is the hash algorithm of the disclosure digests
+	SHA256 = "sha-256"
+	// KeyBindingJWTType is the typ header of key binding JWTs
+	KeyBindingJWTType = "kb+jwt"
+
+	// SDKey is the claim listing the digests of the disclosable claims of an object
+	SDKey = "_sd"
+	// SDAlgKey is the claim with the hash algorithm of the digests
+	SDAlgKey = "_sd_alg"
+	// CNFKey is the confirmation claim holding the key the holder binds presentations with
+	CNFKey = "cnf"
+
+	separator       = "~"
+	saltSize        = 16
+	disclosureParts = 3
+)
+
+// SDJWT is an issuer signed JWT with the disclosures of its selectively disclosable claims,
+// followed by a key binding JWT when presented by the holder.
+type SDJWT struct {
+	JWT         string
+	Disclosures []string
+	KeyBinding  string
+}
+
+// Disclosure discloses the value of a selectively disclosable claim
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value interface{}
+}
+
+// KeyBindingClaims are the claims of a key binding JWT
+type KeyBindingClaims struct {
+	Nonce    string `json:"nonce"`
+	Audience string `json:"aud"`
+	IssuedAt int64  `json:"iat"`
+	SDHash   string `json:"sd_hash"`
+}
+
+// Confirmation is the cnf claim, referencing the holder key by its verification method
+type Confirmation struct {
+	KeyID string `json:"kid"`
+}
+
+// IsSDJWT checks whether the given string is a serialized SD-JWT
+func IsSDJWT(s string) bool {
+	return strings.Contains(s, separator) && strings.Count(strings.SplitN(s, separator, 2)[0], ".") == 2
+}
+
+// Parse parses a serialized SD-JWT, signatures are not checked
+func Parse(s string) (*SDJWT, error) {
+	if !IsSDJWT(s) {
+		return nil, errors.New("not a serialized SD-JWT")
+	}
+
+	parts := strings.Split(s, separator)
+
+	sdJWT := &SDJWT{
+		JWT:         parts[0],
+		Disclosures: parts[1 : len(parts)-1],
+		KeyBinding:  parts[len(parts)-1],
+	}
+
+	for _, d := range sdJWT.Disclosures {
+		if _, err := DecodeDisclosure(d); err != nil {
+			return nil, err
+		}
+	}
+
+	return sdJWT, nil
+}
+
+// Serialize returns the SD-JWT in its combined serialization
+func (s *SDJWT) Serialize() string {
+	return s.presentation() + s.KeyBinding
+}
+
+// SDHash returns the digest of the SD-JWT without key binding, the key binding JWT is tied to it
+func (s *SDJWT) SDHash() string {
+	return Digest(s.presentation())
+}
+
+func (s *SDJWT) presentation() string {
+	parts := append([]string{s.JWT}, s.Disclosures...)
+
+	return strings.Join(parts, separator) + separator
+}
+
+// Claims decodes the claims of the issuer signed JWT, the signature is not checked
+func (s *SDJWT) Claims() (map[string]interface{}, error) {
+	parts := strings.Split(s.JWT, ".")
+	if len(parts) != 3 { // nolint: gomnd
+		return nil, errors.New("invalid JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT payload: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JWT claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+// HolderKeyID returns the verification method of the holder key in the cnf claim, empty when not bound to a holder
+func (s *SDJWT) HolderKeyID() (string, error) {
+	claims, err := s.Claims()
+	if err != nil {
+		return "", err
+	}
+
+	return holderKeyID(claims)
+}
+
+// Select returns the SD-JWT keeping only the disclosures of the claims with the given names
+func (s *SDJWT) Select(names []string) (*SDJWT, error) {
+	selected := &SDJWT{JWT: s.JWT, Disclosures: []string{}}
+
+	found := make(map[string]bool)
+
+	for _, d := range s.Disclosures {
+		disclosure, err := DecodeDisclosure(d)
+		if err != nil {
+			return nil, err
+		}
+
+		if contains(names, disclosure.Name) {
+			selected.Disclosures = append(selected.Disclosures, d)
+			found[disclosure.Name] = true
+		}
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("no disclosure for claim %s", name)
+		}
+	}
+
+	return selected, nil
+}
+
+// MakeDisclosable replaces the named claims of the object by the digests of their disclosures,
+// returning the disclosures
+func MakeDisclosable(obj map[string]interface{}, names []string) ([]string, error) {
+	disclosures := make([]string, 0, len(names))
+	digests := make([]string, 0, len(names))
+
+	for _, name := range names {
+		value, ok := obj[name]
+		if !ok {
+			return nil, fmt.Errorf("claim %s not found", name)
+		}
+
+		if name == SDKey || name == SDAlgKey {
+			return nil, fmt.Errorf("claim %s can't be disclosable", name)
+		}
+
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		disclosureBytes, err := json.Marshal([]interface{}{base64.RawURLEncoding.EncodeToString(salt), name, value})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal disclosure: %w", err)
+		}
+
+		disclosure := base64.RawURLEncoding.EncodeToString(disclosureBytes)
+
+		disclosures = append(disclosures, disclosure)
+		digests = append(digests, Digest(disclosure))
+
+		delete(obj, name)
+	}
+
+	// digests are sorted so their order doesn't reveal the original order of the claims
+	sort.Strings(digests)
+
+	obj[SDKey] = digests
+
+	return disclosures, nil
+}
+
+// Reveal returns the claims with the given disclosures in place of their digests, digests without disclosure
+// are removed. Disclosures with no matching digest or disclosed twice are rejected.
+func Reveal(claims map[string]interface{}, disclosures []string) (map[string]interface{}, error) {
+	if alg, ok := claims[SDAlgKey]; ok && alg != SHA256 {
+		return nil, fmt.Errorf("digest algorithm %v not supported", alg)
+	}
+
+	byDigest := make(map[string]*Disclosure, len(disclosures))
+
+	for _, d := range disclosures {
+		digest := Digest(d)
+		if _, ok := byDigest[digest]; ok {
+			return nil, errors.New("disclosure included more than once")
+		}
+
+		disclosure, err := DecodeDisclosure(d)
+		if err != nil {
+			return nil, err
+		}
+
+		byDigest[digest] = disclosure
+	}
+
+	revealed, err := reveal(claims, byDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(byDigest) != 0 {
+		return nil, errors.New("disclosure without matching digest")
+	}
+
+	revealedClaims, ok := revealed.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("unexpected claims")
+	}
+
+	delete(revealedClaims, SDAlgKey)
+
+	return revealedClaims, nil
+}
+
+func reveal(value interface{}, byDigest map[string]*Disclosure) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+
+		for name, claim := range v {
+			if name == SDKey {
+				continue
+			}
+
+			revealed, err := reveal(claim, byDigest)
+			if err != nil {
+				return nil, err
+			}
+
+			obj[name] = revealed
+		}
+
+		digests, ok := v[SDKey].([]interface{})
+		if !ok && v[SDKey] != nil {
+			return nil, fmt.Errorf("invalid %s claim", SDKey)
+		}
+
+		for _, d := range digests {
+			digest, ok := d.(string)
+			if !ok {
+				return nil, errors.New("invalid digest")
+			}
+
+			disclosure, ok := byDigest[digest]
+			if !ok {
+				continue
+			}
+
+			if _, exists := obj[disclosure.Name]; exists {
+				return nil, fmt.Errorf("disclosed claim %s already exists", disclosure.Name)
+			}
+
+			revealed, err := reveal(disclosure.Value, byDigest)
+			if err != nil {
+				return nil, err
+			}
+
+			obj[disclosure.Name] = revealed
+
+			delete(byDigest, digest)
+		}
+
+		return obj, nil
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+
+		for i, item := range v {
+			revealed, err := reveal(item, byDigest)
+			if err != nil {
+				return nil, err
+			}
+
+			arr[i] = revealed
+		}
+
+		return arr, nil
+	default:
+		return value, nil
+	}
+}
+
+func holderKeyID(claims map[string]interface{}) (string, error) {
+	cnf, ok := claims[CNFKey]
+	if !ok {
+		return "", nil
+	}
+
+	cnfObj, ok := cnf.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid %s claim", CNFKey)
+	}
+
+	kid, ok := cnfObj["kid"].(string)
+	if !ok || kid == "" {
+		return "", fmt.Errorf("%s claim without kid", CNFKey)
+	}
+
+	return kid, nil
+}
+
+// DecodeDisclosure decodes a disclosure, the base64url encoded array of salt, claim name and value
+func DecodeDisclosure(disclosure string) (*Disclosure, error) {
+	disclosureBytes, err := base64.RawURLEncoding.DecodeString(disclosure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode disclosure: %w", err)
+	}
+
+	var parts []interface{}
+	if err := json.Unmarshal(disclosureBytes, &parts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal disclosure: %w", err)
+	}
+
+	if len(parts) != disclosureParts {
+		return nil, errors.New("disclosure must have salt, claim name and value")
+	}
+
+	salt, ok := parts[0].(string)
+	if !ok {
+		return nil, errors.New("disclosure salt must be a string")
+	}
+
+	name, ok := parts[1].(string)
+	if !ok || name == SDKey || name == SDAlgKey {
+		return nil, errors.New("invalid disclosure claim name")
+	}
+
+	return &Disclosure{Salt: salt, Name: name, Value: parts[2]}, nil
+}
+
+// Digest returns the base64url encoded sha-256 digest of the given disclosure
+func Digest(disclosure string) string {
+	digest := sha256.Sum256([]byte(disclosure))
+
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func contains(l []string, e string) bool {
+	for _, s := range l {
+		if s == e {
+			return true
+		}
+	}
+
+	return false
+}
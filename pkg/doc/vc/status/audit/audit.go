@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+const (
+	storeName     = "credentialstatusaudit"
+	keyPrefix     = "statusaudit"
+	credentialTag = "credential"
+)
+
+var logger = log.New("edge-service-status-audit")
+
+// Reason codes of a status change, modelled on the CRL reason codes of RFC 5280.
+const (
+	ReasonUnspecified          = "unspecified"
+	ReasonKeyCompromise        = "keyCompromise"
+	ReasonAffiliationChanged   = "affiliationChanged"
+	ReasonSuperseded           = "superseded"
+	ReasonCessationOfOperation = "cessationOfOperation"
+	ReasonCredentialHold       = "credentialHold"
+	ReasonPrivilegeWithdrawn   = "privilegeWithdrawn"
+)
+
+// Record is an audit record of a credential status change
+type Record struct {
+	CredentialID  string `json:"credentialId"`
+	StatusPurpose string `json:"statusPurpose"`
+	Status        bool   `json:"status"`
+	ReasonCode    string `json:"reasonCode"`
+	Comment       string `json:"comment,omitempty"`
+	// ClaimedCaller is the identity the caller claimed, it isn't authenticated by the service
+	ClaimedCaller string    `json:"claimedCaller,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// Store keeps the status change records of credentials, per issuer profile.
+// Every record is stored under its own key, tagged with the profile and credential it belongs to.
+type Store struct {
+	store ariesstorage.Store
+}
+
+// New returns new audit store
+func New(provider ariesstorage.Provider) (*Store, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit store: %w", err)
+	}
+
+	return &Store{store: store}, nil
+}
+
+// IsSupportedReasonCode checks whether the given reason code is known
+func IsSupportedReasonCode(reasonCode string) bool {
+	switch reasonCode {
+	case ReasonUnspecified, ReasonKeyCompromise, ReasonAffiliationChanged, ReasonSuperseded,
+		ReasonCessationOfOperation, ReasonCredentialHold, ReasonPrivilegeWithdrawn:
+		return true
+	default:
+		return false
+	}
+}
+
+// Add appends the given records to the status history of their credentials
+func (s *Store) Add(profileID string, records ...*Record) error {
+	recorded := time.Now().UnixNano()
+
+	for i, r := range records {
+		recordBytes, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal status record: %w", err)
+		}
+
+		tag := ariesstorage.Tag{Name: credentialTag, Value: tagValue(profileID, r.CredentialID)}
+
+		// keys keep the order the records were added in when their timestamps are equal
+		key := fmt.Sprintf("%s_%020d_%04d_%s", keyPrefix, recorded, i, uuid.New().String())
+
+		if err := s.store.Put(key, recordBytes, tag); err != nil {
+			return fmt.Errorf("failed to store status record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get returns the status history of the given credential, oldest record first
+func (s *Store) Get(profileID, credentialID string) ([]*Record, error) {
+	iter, err := s.store.Query(credentialTag + ":" + tagValue(profileID, credentialID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close status history iterator: %s", errClose)
+		}
+	}()
+
+	history := []*Record{}
+
+	var keys []string
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status history: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := iter.Key()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status history: %w", err)
+		}
+
+		recordBytes, err := iter.Value()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status history: %w", err)
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(recordBytes, record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status record: %w", err)
+		}
+
+		history = append(history, record)
+		keys = append(keys, key)
+	}
+
+	sort.Sort(&byTimestamp{history: history, keys: keys})
+
+	return history, nil
+}
+
+// byTimestamp sorts the records by timestamp, then by the order they were added in.
+type byTimestamp struct {
+	history []*Record
+	keys    []string
+}
+
+func (b *byTimestamp) Len() int {
+	return len(b.history)
+}
+
+func (b *byTimestamp) Less(i, j int) bool {
+	if !b.history[i].Timestamp.Equal(b.history[j].Timestamp) {
+		return b.history[i].Timestamp.Before(b.history[j].Timestamp)
+	}
+
+	return b.keys[i] < b.keys[j]
+}
+
+func (b *byTimestamp) Swap(i, j int) {
+	b.history[i], b.history[j] = b.history[j], b.history[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+// tagValue identifies the credential of the profile, the IDs are encoded since tag values can't hold colons.
+func tagValue(profileID, credentialID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(profileID)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(credentialID))
+}
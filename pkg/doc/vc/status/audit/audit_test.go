@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	})
+
+	t.Run("test error from open store", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")})
+		require.Error(t, err)
+		require.Nil(t, s)
+		require.Contains(t, err.Error(), "failed to open audit store")
+	})
+}
+
+func TestStore_AddGet(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		history, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		revoked := &Record{
+			CredentialID: "cred1", StatusPurpose: "revocation", Status: true,
+			ReasonCode: ReasonKeyCompromise, Comment: "key lost", ClaimedCaller: "admin", Timestamp: time.Now().UTC(),
+		}
+
+		unrevoked := &Record{
+			CredentialID: "cred1", StatusPurpose: "revocation", Status: false,
+			ReasonCode: ReasonUnspecified, ClaimedCaller: "admin", Timestamp: time.Now().UTC(),
+		}
+
+		require.NoError(t, s.Add("profile1", revoked, &Record{CredentialID: "cred2", Status: true}))
+		require.NoError(t, s.Add("profile1", unrevoked))
+
+		history, err = s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, "key lost", history[0].Comment)
+		require.True(t, history[0].Status)
+		require.False(t, history[1].Status)
+		require.True(t, revoked.Timestamp.Equal(history[0].Timestamp))
+
+		history, err = s.Get("profile1", "cred2")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+
+		// history is kept per profile
+		history, err = s.Get("profile2", "cred1")
+		require.NoError(t, err)
+		require.Empty(t, history)
+	})
+
+	t.Run("test records with the same timestamp keep the order they were added in", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		now := time.Now().UTC()
+
+		records := make([]*Record, 10)
+		for i := range records {
+			records[i] = &Record{CredentialID: "cred1", Comment: fmt.Sprint(i), Timestamp: now}
+		}
+
+		require.NoError(t, s.Add("profile1", records...))
+
+		history, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Len(t, history, len(records))
+
+		for i, r := range history {
+			require.Equal(t, fmt.Sprint(i), r.Comment)
+		}
+	})
+
+	t.Run("test records of concurrent changes are all kept", func(t *testing.T) {
+		s, err := New(ariesmockstorage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		errs := make([]error, 10)
+
+		for i := range errs {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs[i] = s.Add("profile1", &Record{CredentialID: "cred1", Timestamp: time.Now()})
+			}(i)
+		}
+
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		history, err := s.Get("profile1", "cred1")
+		require.NoError(t, err)
+		require.Len(t, history, len(errs))
+	})
+
+	t.Run("test error from query", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:    make(map[string]ariesmockstorage.DBEntry),
+			ErrQuery: fmt.Errorf("query error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = s.Get("profile1", "cred1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get status history: query error")
+	})
+
+	t.Run("test error from iterator", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:   make(map[string]ariesmockstorage.DBEntry),
+			ErrNext: fmt.Errorf("next error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = s.Get("profile1", "cred1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "next error")
+	})
+
+	t.Run("test error from iterator key", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrKey: fmt.Errorf("key error"),
+		}})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Add("profile1", &Record{CredentialID: "cred1"}))
+
+		_, err = s.Get("profile1", "cred1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get status history: key error")
+	})
+
+	t.Run("test error from put", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store:  make(map[string]ariesmockstorage.DBEntry),
+			ErrPut: fmt.Errorf("put error"),
+		}})
+		require.NoError(t, err)
+
+		err = s.Add("profile1", &Record{CredentialID: "cred1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to store status record")
+	})
+
+	t.Run("test invalid stored record", func(t *testing.T) {
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: map[string]ariesmockstorage.DBEntry{"statusaudit_1": {
+				Value: []byte("{"),
+				Tags:  []storage.Tag{{Name: credentialTag, Value: tagValue("profile1", "cred1")}},
+			}},
+		}})
+		require.NoError(t, err)
+
+		_, err = s.Get("profile1", "cred1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal status record")
+	})
+}
+
+func TestIsSupportedReasonCode(t *testing.T) {
+	require.True(t, IsSupportedReasonCode(ReasonKeyCompromise))
+	require.True(t, IsSupportedReasonCode(ReasonCredentialHold))
+	require.False(t, IsSupportedReasonCode(""))
+	require.False(t, IsSupportedReasonCode("certificateHold"))
+}
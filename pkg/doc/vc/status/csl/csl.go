@@ -7,20 +7,25 @@ SPDX-License-Identifier: Apache-2.0
 package csl
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/edge-core/pkg/log"
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+	"github.com/trustbloc/edge-service/pkg/lock"
 )
 
 const (
@@ -44,15 +49,52 @@ const (
 	// RevocationListCredential for RevocationList2020 credential
 	RevocationListCredential = "revocationListCredential"
 
+	// StatusList2021Context context for Status List 2021
+	StatusList2021Context = "https://w3id.org/vc/status-list/2021/v1"
+	statusList2021VCType  = "StatusList2021Credential"
+	statusList2021Type    = "StatusList2021"
+	// StatusList2021Entry for StatusList2021 Status
+	StatusList2021Entry = "StatusList2021Entry"
+	// StatusListIndex for StatusList2021 index
+	StatusListIndex = "statusListIndex"
+	// StatusListCredential for StatusList2021 credential
+	StatusListCredential = "statusListCredential"
+	// StatusPurpose for StatusList2021 purpose
+	StatusPurpose = "statusPurpose"
+	// StatusPurposeRevocation revocation status purpose
+	StatusPurposeRevocation = "revocation"
+	// StatusPurposeSuspension suspension status purpose
+	StatusPurposeSuspension = "suspension"
+	// SuspensionStatus is the property of the suspension entry of credentials with StatusList2021 status
+	SuspensionStatus    = "suspensionStatus"
+	suspensionStatusIRI = "https://trustbloc.github.io/context/vc/status#suspensionStatus"
+	// ValidAtHeader confirms the time the status list served for a validAt query was valid at, status lists not
+	// kept by this service ignore the query and don't set it
+	ValidAtHeader = "X-Status-List-Valid-At"
+
 	// proof json keys
 	jsonKeyProofValue         = "proofValue"
 	jsonKeyProofPurpose       = "proofPurpose"
 	jsonKeyVerificationMethod = "verificationMethod"
 	jsonKeySignatureOfType    = "type"
 
-	bitStringSize = 128000
+	// defaultBitStringLength is the bit string length of lists, unless more entries are put in a list
+	defaultBitStringLength = 128000
+	// MaxBitStringLength is the length of the largest status list bit string, 128 KiB, well below the length of
+	// bit strings the verifiers decode so that the lists stay small enough to fetch and sign again on every update
+	MaxBitStringLength = 1024 * 1024
+
+	// random indexes are drawn until 3/4 of the bit string is used, free indexes are listed after that
+	maxUsedRatioNumerator   = 3
+	maxUsedRatioDenominator = 4
+	maxIndexDraws           = 32
 )
 
+var logger = log.New("edge-service-credential-status")
+
+// ErrConcurrentUpdate is returned when the status list was changed by another update since it was read
+var ErrConcurrentUpdate = errors.New("status list was updated concurrently")
+
 type crypto interface {
 	SignCredential(dataProfile *vcprofile.DataProfile, vc *verifiable.Credential,
 		opts ...vccrypto.SigningOpts) (*verifiable.Credential, error)
@@ -64,52 +106,232 @@ type CredentialStatusManager struct {
 	listSize       int
 	crypto         crypto
 	documentLoader ld.DocumentLoader
+	locker         Locker
+}
+
+// Locker guards read-modify-write of the status lists kept in the store.
+type Locker interface {
+	// Lock blocks until the lock for the given key is acquired and returns the function releasing it.
+	Lock(key string) (func(), error)
+}
+
+// Opt configures the credential status manager
+type Opt func(c *CredentialStatusManager)
+
+// WithLocker is an option to pass the locker guarding status list updates, in-memory locker is used if not set
+func WithLocker(locker Locker) Opt {
+	return func(c *CredentialStatusManager) {
+		c.locker = locker
+	}
 }
 
 // cslWrapper contain csl and metadata
 type cslWrapper struct {
-	VCByte              json.RawMessage        `json:"vc"`
-	Size                int                    `json:"size"`
-	RevocationListIndex int                    `json:"revocationListIndex"`
-	ListID              string                 `json:"listID"`
-	VC                  *verifiable.Credential `json:"-"`
+	VCByte json.RawMessage `json:"vc"`
+	Size   int             `json:"size"`
+	// RevocationListIndex is the next index of lists whose indexes were handed out sequentially,
+	// it is only read to find out the used indexes of such lists.
+	RevocationListIndex int        `json:"revocationListIndex"`
+	ListID              string     `json:"listID"`
+	Capacity            int        `json:"capacity,omitempty"`
+	Length              int        `json:"length,omitempty"`
+	UsedIndexes         string     `json:"usedIndexes,omitempty"`
+	Updated             *time.Time `json:"updated,omitempty"`
+	// Version is incremented whenever the list is stored, updates based on a version replaced meanwhile fail
+	Version int                    `json:"version,omitempty"`
+	VC      *verifiable.Credential `json:"-"`
+}
+
+// StatusListVC is the signed status list vc along with the time it was last changed
+type StatusListVC struct {
+	VC      []byte
+	Updated *time.Time
 }
 
 type credentialSubject struct {
-	ID          string `json:"id"`
-	Type        string `json:"type"`
-	EncodedList string `json:"encodedList"`
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	StatusPurpose string `json:"statusPurpose,omitempty"`
+	EncodedList   string `json:"encodedList"`
+}
+
+// statusOpts holds options for the credential status
+type statusOpts struct {
+	StatusType      string
+	StatusPurpose   string
+	ListSize        int
+	BitStringLength int
+}
+
+// StatusOpts is credential status option
+type StatusOpts func(opts *statusOpts)
+
+// WithStatusType is an option to pass credential status type, RevocationList2020Status is used if not set
+func WithStatusType(statusType string) StatusOpts {
+	return func(opts *statusOpts) {
+		opts.StatusType = statusType
+	}
+}
+
+// WithStatusPurpose is an option to pass credential status purpose, StatusPurposeRevocation is used if not set
+func WithStatusPurpose(purpose string) StatusOpts {
+	return func(opts *statusOpts) {
+		opts.StatusPurpose = purpose
+	}
+}
+
+// WithListSize is an option to pass the number of credentials per status list,
+// the list size the manager was created with is used if not set
+func WithListSize(size int) StatusOpts {
+	return func(opts *statusOpts) {
+		opts.ListSize = size
+	}
+}
+
+// WithBitStringLength is an option to pass the bit string length of new status lists, indexes are spread
+// over the whole bit string. The larger of list size and 128000 is used if not set, MaxBitStringLength at most.
+func WithBitStringLength(length int) StatusOpts {
+	return func(opts *statusOpts) {
+		opts.BitStringLength = length
+	}
 }
 
 // New returns new Credential Status List
 func New(provider ariesstorage.Provider, listSize int, c crypto,
-	loader ld.DocumentLoader) (*CredentialStatusManager, error) {
+	loader ld.DocumentLoader, opts ...Opt) (*CredentialStatusManager, error) {
 	store, err := provider.OpenStore(credentialStatusStore)
 	if err != nil {
 		return nil, err
 	}
 
-	return &CredentialStatusManager{store: store, listSize: listSize, crypto: c, documentLoader: loader}, nil
+	m := &CredentialStatusManager{store: store, listSize: listSize, crypto: c, documentLoader: loader}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.locker == nil {
+		m.locker = lock.NewMemLocker()
+	}
+
+	return m, nil
 }
 
 // CreateStatusID create status id
 func (c *CredentialStatusManager) CreateStatusID(profile *vcprofile.DataProfile,
-	url string) (*verifiable.TypedID, error) {
-	cslWrapper, err := c.getLatestCSL(profile, url)
+	url string, opts ...StatusOpts) (*verifiable.TypedID, error) {
+	statusIDs, err := c.CreateStatusIDs(profile, url, 1, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return statusIDs[0], nil
+}
+
+// CreateStatusIDs creates status ids of the given number of credentials in one pass, every list affected
+// is stored once. New lists are opened as the latest one of the profile fills up.
+func (c *CredentialStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile,
+	url string, count int, opts ...StatusOpts) ([]*verifiable.TypedID, error) {
+	sOpts := &statusOpts{}
+
+	for _, opt := range opts {
+		opt(sOpts)
+	}
+
+	if sOpts.StatusType == "" {
+		sOpts.StatusType = RevocationList2020Status
+	}
+
+	if sOpts.ListSize <= 0 {
+		sOpts.ListSize = c.listSize
+	}
+
+	if sOpts.BitStringLength <= 0 {
+		sOpts.BitStringLength = bitStringLength(sOpts.ListSize)
+	}
+
+	if !isSupportedStatusType(sOpts.StatusType) {
+		return nil, fmt.Errorf("vc status %s not supported", sOpts.StatusType)
+	}
+
+	if sOpts.BitStringLength < sOpts.ListSize || sOpts.BitStringLength > MaxBitStringLength {
+		return nil, fmt.Errorf("status list bit string length %d out of range [%d, %d]",
+			sOpts.BitStringLength, sOpts.ListSize, MaxBitStringLength)
+	}
+
+	if count < 1 {
+		return nil, fmt.Errorf("invalid number of status ids %d", count)
+	}
+
+	// lock the profile first so that no other list is opened for it meanwhile, then the list itself
+	// which is also updated on revocation
+	unlock, err := c.locker.Lock(latestListIDKey(profile.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock status list: %w", err)
+	}
+
+	defer unlock()
+
+	statusIDs := make([]*verifiable.TypedID, 0, count)
+
+	for len(statusIDs) < count {
+		listStatusIDs, err := c.allocateStatusIDs(profile, url, count-len(statusIDs), sOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		statusIDs = append(statusIDs, listStatusIDs...)
+	}
+
+	return statusIDs, nil
+}
+
+// allocateStatusIDs hands out up to the given number of indexes of the latest list of the profile,
+// moving on to the next list once it is full. The caller holds the lock of the profile.
+func (c *CredentialStatusManager) allocateStatusIDs(profile *vcprofile.DataProfile, url string, count int,
+	sOpts *statusOpts) ([]*verifiable.TypedID, error) {
+	listID, err := c.getLatestListID(profile.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	vcID := url + "/" + listID
+
+	unlockList, err := c.locker.Lock(vcID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock status list: %w", err)
+	}
+
+	defer unlockList()
+
+	cslWrapper, err := c.getLatestCSL(profile, vcID, listID, sOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	newList := cslWrapper.Size == 0
+
+	if free := cslWrapper.Capacity - cslWrapper.Size; count > free && free > 0 {
+		count = free
+	}
+
+	indexes, err := cslWrapper.allocateIndexes(count)
 	if err != nil {
 		return nil, err
 	}
 
-	revocationListIndex := strconv.FormatInt(int64(cslWrapper.RevocationListIndex), 10)
+	cslWrapper.Size += len(indexes)
 
-	cslWrapper.Size++
-	cslWrapper.RevocationListIndex++
+	store := c.storeCSL
+	if newList {
+		store = c.publishCSL
+	}
 
-	if err := c.storeCSL(cslWrapper); err != nil {
+	if err := store(cslWrapper); err != nil {
 		return nil, err
 	}
 
-	if cslWrapper.Size == c.listSize {
+	if cslWrapper.Size >= cslWrapper.Capacity {
 		id, err := strconv.Atoi(cslWrapper.ListID)
 		if err != nil {
 			return nil, err
@@ -117,39 +339,277 @@ func (c *CredentialStatusManager) CreateStatusID(profile *vcprofile.DataProfile,
 
 		id++
 
-		if err := c.store.Put(latestListID, []byte(strconv.FormatInt(int64(id), 10))); err != nil {
+		if err := c.store.Put(latestListIDKey(profile.Name), []byte(strconv.FormatInt(int64(id), 10))); err != nil {
 			return nil, fmt.Errorf("failed to store latest list ID in store: %w", err)
 		}
 	}
 
+	// suspension list is kept in parallel to the revocation list, so it has to be there
+	// before the first credential pointing to this list is handed out
+	if isStatusList2021(cslWrapper.VC) && newList {
+		suspensionCSL, err := c.getSuspensionCSL(profile, cslWrapper)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.publishCSL(suspensionCSL); err != nil {
+			return nil, err
+		}
+	}
+
+	statusIDs := make([]*verifiable.TypedID, len(indexes))
+
+	for i, index := range indexes {
+		statusIDs[i] = statusID(cslWrapper.VC, strconv.Itoa(index))
+	}
+
+	return statusIDs, nil
+}
+
+// ReleaseStatusIDs hands back the indexes of the given status ids created for credentials that weren't issued
+// after all, they can be handed out again as long as their list takes new credentials.
+func (c *CredentialStatusManager) ReleaseStatusIDs(statusIDs []*verifiable.TypedID) error {
+	var lists []string
+
+	indexes := make(map[string][]int)
+
+	for _, s := range statusIDs {
+		listCredential, index, err := statusListEntry(s, StatusPurposeRevocation)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := indexes[listCredential]; !ok {
+			lists = append(lists, listCredential)
+		}
+
+		indexes[listCredential] = append(indexes[listCredential], index)
+	}
+
+	for _, listCredential := range lists {
+		if err := c.releaseIndexes(listCredential, indexes[listCredential]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *CredentialStatusManager) releaseIndexes(listCredential string, indexes []int) error {
+	unlock, err := c.locker.Lock(listCredential)
+	if err != nil {
+		return fmt.Errorf("failed to lock status list: %w", err)
+	}
+
+	defer unlock()
+
+	cslWrapper, err := c.getCSLWrapper(listCredential)
+	if err != nil {
+		return err
+	}
+
+	used, err := cslWrapper.usedIndexes()
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		bitSet, err := used.Get(index)
+		if err != nil {
+			return err
+		}
+
+		if !bitSet {
+			continue
+		}
+
+		if err := used.Set(index, false); err != nil {
+			return err
+		}
+
+		cslWrapper.Size--
+	}
+
+	cslWrapper.UsedIndexes, err = used.EncodeBits()
+	if err != nil {
+		return err
+	}
+
+	return c.storeCSL(cslWrapper)
+}
+
+// statusID returns the status of the credential with the given index of the status list vc
+func statusID(listVC *verifiable.Credential, index string) *verifiable.TypedID {
+	if isStatusList2021(listVC) {
+		return &verifiable.TypedID{
+			ID:   listVC.ID + "#" + index,
+			Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+				StatusPurpose:        StatusPurposeRevocation,
+				StatusListIndex:      index,
+				StatusListCredential: listVC.ID,
+			},
+		}
+	}
+
 	return &verifiable.TypedID{
-		ID:   cslWrapper.VC.ID + "#" + revocationListIndex,
+		ID:   listVC.ID + "#" + index,
 		Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
-			RevocationListIndex:      revocationListIndex,
-			RevocationListCredential: cslWrapper.VC.ID,
+			RevocationListIndex:      index,
+			RevocationListCredential: listVC.ID,
 		},
-	}, nil
+	}
+}
+
+// SetStatus sets the given status of the credential along with the context of the status type. Credentials with
+// StatusList2021 revocation entry get a second StatusList2021Entry with suspension purpose, pointing to the same
+// index of the suspension list kept in parallel. The credential model has room for one credentialStatus entry only,
+// so the suspension entry is put in the SuspensionStatus property defined by an inline context, which keeps it
+// covered by the proof of the credential.
+func SetStatus(credential *verifiable.Credential, status *verifiable.TypedID) {
+	credential.Status = status
+	credential.Context = append(credential.Context, StatusContext(status.Type))
+
+	if status.Type != StatusList2021Entry || status.CustomFields[StatusPurpose] != StatusPurposeRevocation {
+		return
+	}
+
+	listCredential, ok := status.CustomFields[StatusListCredential].(string)
+	if !ok {
+		return
+	}
+
+	index := fmt.Sprint(status.CustomFields[StatusListIndex])
+
+	if credential.CustomFields == nil {
+		credential.CustomFields = verifiable.CustomFields{}
+	}
+
+	credential.CustomFields[SuspensionStatus] = &verifiable.TypedID{
+		ID:   SuspensionListID(listCredential) + "#" + index,
+		Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+			StatusPurpose:        StatusPurposeSuspension,
+			StatusListIndex:      index,
+			StatusListCredential: SuspensionListID(listCredential),
+		},
+	}
+
+	credential.CustomContext = append(credential.CustomContext, map[string]interface{}{
+		SuspensionStatus: map[string]interface{}{"@id": suspensionStatusIRI, "@type": "@id"},
+	})
+}
+
+// GetSuspensionStatus returns the suspension entry of the credential set by SetStatus, nil if it has none.
+func GetSuspensionStatus(credential *verifiable.Credential) (*verifiable.TypedID, error) {
+	raw, ok := credential.CustomFields[SuspensionStatus]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	rawBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", SuspensionStatus, err)
+	}
+
+	status := &verifiable.TypedID{}
+	if err := json.Unmarshal(rawBytes, status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", SuspensionStatus, err)
+	}
+
+	return status, nil
 }
 
 // UpdateVC update vc
-//nolint: gocyclo, funlen
 func (c *CredentialStatusManager) UpdateVC(v *verifiable.Credential,
-	profile *vcprofile.DataProfile, status bool) error {
-	// validate vc status
-	if err := c.validateVCStatus(v.Status); err != nil {
-		return err
+	profile *vcprofile.DataProfile, status bool, opts ...StatusOpts) error {
+	return c.UpdateVCs([]*verifiable.Credential{v}, profile, status, opts...)[0]
+}
+
+// UpdateVCs updates status of the given vcs, every status list affected is signed only once.
+// Returned errors are aligned with the given vcs, nil error means the status of the vc was updated.
+func (c *CredentialStatusManager) UpdateVCs(vcs []*verifiable.Credential,
+	profile *vcprofile.DataProfile, status bool, opts ...StatusOpts) []error {
+	sOpts := &statusOpts{}
+
+	for _, opt := range opts {
+		opt(sOpts)
 	}
 
-	revocationListCredential, ok := v.Status.CustomFields[RevocationListCredential].(string)
-	if !ok {
-		return fmt.Errorf("failed to cast status revocationListCredential")
+	if sOpts.StatusPurpose == "" {
+		sOpts.StatusPurpose = StatusPurposeRevocation
+	}
+
+	errs := make([]error, len(vcs))
+
+	// group vcs by the revocation list they point to, keeping the order lists are first seen in
+	var lists []string
+
+	entries := make(map[string][]listEntry)
+
+	for i, v := range vcs {
+		listCredential, index, err := statusListEntry(v.Status, sOpts.StatusPurpose)
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		if _, ok := entries[listCredential]; !ok {
+			lists = append(lists, listCredential)
+		}
+
+		entries[listCredential] = append(entries[listCredential], listEntry{pos: i, index: index})
+	}
+
+	for _, listCredential := range lists {
+		err := c.updateList(profile, listCredential, sOpts.StatusPurpose, entries[listCredential], status, errs)
+		if err == nil {
+			continue
+		}
+
+		for _, e := range entries[listCredential] {
+			if errs[e.pos] == nil {
+				errs[e.pos] = err
+			}
+		}
 	}
 
+	return errs
+}
+
+// listEntry is position of the vc in the batch and its index in the status list.
+type listEntry struct {
+	pos   int
+	index int
+}
+
+// updateList sets the status of the given entries of the list and signs the list again,
+// errors of single entries are reported in errs.
+func (c *CredentialStatusManager) updateList(profile *vcprofile.DataProfile, revocationListCredential,
+	purpose string, entries []listEntry, status bool, errs []error) error {
+	listID := revocationListCredential
+	if purpose == StatusPurposeSuspension {
+		listID = SuspensionListID(revocationListCredential)
+	}
+
+	unlock, err := c.locker.Lock(listID)
+	if err != nil {
+		return fmt.Errorf("failed to lock status list: %w", err)
+	}
+
+	defer unlock()
+
 	cslWrapper, err := c.getCSLWrapper(revocationListCredential)
 	if err != nil {
 		return err
 	}
 
+	if purpose == StatusPurposeSuspension {
+		cslWrapper, err = c.getSuspensionCSL(profile, cslWrapper)
+		if err != nil {
+			return err
+		}
+	}
+
 	signOpts, err := prepareSigningOpts(profile, cslWrapper.VC.Proofs)
 	if err != nil {
 		return err
@@ -165,13 +625,20 @@ func (c *CredentialStatusManager) UpdateVC(v *verifiable.Credential,
 		return err
 	}
 
-	revocationListIndex, err := strconv.Atoi(v.Status.CustomFields[RevocationListIndex].(string))
-	if err != nil {
-		return err
+	updated := 0
+
+	for _, e := range entries {
+		if errSet := bitString.Set(e.index, status); errSet != nil {
+			errs[e.pos] = errSet
+
+			continue
+		}
+
+		updated++
 	}
 
-	if errSet := bitString.Set(revocationListIndex, status); errSet != nil {
-		return errSet
+	if updated == 0 {
+		return nil
 	}
 
 	cs[0].CustomFields["encodedList"], err = bitString.EncodeBits()
@@ -192,26 +659,74 @@ func (c *CredentialStatusManager) UpdateVC(v *verifiable.Credential,
 		return err
 	}
 
+	now := time.Now().UTC()
+
 	cslWrapper.VCByte = signedCredentialBytes
+	cslWrapper.Updated = &now
 
-	return c.storeCSL(cslWrapper)
+	return c.publishCSL(cslWrapper)
+}
+
+// statusListEntry returns the revocation list and the index the given vc status points to.
+func statusListEntry(vcStatus *verifiable.TypedID, purpose string) (string, int, error) {
+	// validate vc status
+	if err := validateStatus(vcStatus); err != nil {
+		return "", 0, err
+	}
+
+	listCredentialKey, listIndexKey := statusListKeys(vcStatus.Type)
+
+	revocationListCredential, ok := vcStatus.CustomFields[listCredentialKey].(string)
+	if !ok {
+		return "", 0, fmt.Errorf("failed to cast status %s", listCredentialKey)
+	}
+
+	switch purpose {
+	case StatusPurposeRevocation:
+	case StatusPurposeSuspension:
+		if vcStatus.Type != StatusList2021Entry {
+			return "", 0, fmt.Errorf("vc status %s does not support %s purpose", vcStatus.Type, purpose)
+		}
+	default:
+		return "", 0, fmt.Errorf("vc status purpose %s not supported", purpose)
+	}
+
+	revocationListIndex, err := strconv.Atoi(fmt.Sprint(vcStatus.CustomFields[listIndexKey]))
+	if err != nil {
+		return "", 0, err
+	}
+
+	return revocationListCredential, revocationListIndex, nil
 }
 
-func (c *CredentialStatusManager) validateVCStatus(vcStatus *verifiable.TypedID) error {
+func validateStatus(vcStatus *verifiable.TypedID) error {
 	if vcStatus == nil {
 		return fmt.Errorf("vc status not exist")
 	}
 
-	if vcStatus.Type != RevocationList2020Status {
+	if !isSupportedStatusType(vcStatus.Type) {
 		return fmt.Errorf("vc status %s not supported", vcStatus.Type)
 	}
 
-	if vcStatus.CustomFields[RevocationListIndex] == nil {
-		return fmt.Errorf("revocationListIndex field not exist in vc status")
+	listCredentialKey, listIndexKey := statusListKeys(vcStatus.Type)
+
+	if vcStatus.CustomFields[listIndexKey] == nil {
+		return fmt.Errorf("%s field not exist in vc status", listIndexKey)
 	}
 
-	if vcStatus.CustomFields[RevocationListCredential] == nil {
-		return fmt.Errorf("revocationListCredential field not exist in vc status")
+	if vcStatus.CustomFields[listCredentialKey] == nil {
+		return fmt.Errorf("%s field not exist in vc status", listCredentialKey)
+	}
+
+	if vcStatus.Type == StatusList2021Entry {
+		purpose, ok := vcStatus.CustomFields[StatusPurpose].(string)
+		if !ok {
+			return fmt.Errorf("statusPurpose field not exist in vc status")
+		}
+
+		if purpose != StatusPurposeRevocation {
+			return fmt.Errorf("vc status purpose %s not supported", purpose)
+		}
 	}
 
 	return nil
@@ -227,6 +742,56 @@ func (c *CredentialStatusManager) GetRevocationListVC(id string) ([]byte, error)
 	return cslWrapper.VCByte, nil
 }
 
+// GetStatusListVC returns the status list vc along with the time it was last changed,
+// the time is unknown for lists not changed since it started being kept.
+func (c *CredentialStatusManager) GetStatusListVC(id string) (*StatusListVC, error) {
+	cslWrapper, err := c.getCSLWrapper(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revocationListVC from store: %w", err)
+	}
+
+	return &StatusListVC{VC: cslWrapper.VCByte, Updated: cslWrapper.Updated}, nil
+}
+
+// GetStatus tells whether the status of the given purpose is set for the credential status entry. Credentials
+// aren't suspended as long as the suspension list of their revocation list wasn't created.
+func (c *CredentialStatusManager) GetStatus(vcStatus *verifiable.TypedID, purpose string) (bool, error) {
+	listCredential, index, err := statusListEntry(vcStatus, purpose)
+	if err != nil {
+		return false, err
+	}
+
+	if purpose == StatusPurposeSuspension {
+		listCredential = SuspensionListID(listCredential)
+	}
+
+	cslWrapper, err := c.getCSLWrapper(listCredential)
+	if err != nil {
+		if purpose == StatusPurposeSuspension && errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	cs, ok := cslWrapper.VC.Subject.([]verifiable.Subject)
+	if !ok || len(cs) == 0 {
+		return false, fmt.Errorf("failed to cast vc subject")
+	}
+
+	encodedList, ok := cs[0].CustomFields["encodedList"].(string)
+	if !ok {
+		return false, fmt.Errorf("failed to cast encodedList")
+	}
+
+	bitString, err := utils.DecodeBits(encodedList)
+	if err != nil {
+		return false, err
+	}
+
+	return bitString.Get(index)
+}
+
 func (c *CredentialStatusManager) getCSLWrapper(id string) (*cslWrapper, error) {
 	cslWrapperBytes, err := c.store.Get(id)
 	if err != nil {
@@ -244,42 +809,58 @@ func (c *CredentialStatusManager) getCSLWrapper(id string) (*cslWrapper, error)
 		return nil, err
 	}
 
+	// lists stored before the capacity was kept with the list are sized by the manager
+	if w.Capacity == 0 {
+		w.Capacity = c.listSize
+	}
+
+	// lists stored before the length was kept with the list have the default length
+	if w.Length == 0 {
+		w.Length = bitStringLength(w.Capacity)
+	}
+
 	return &w, nil
 }
 
-func (c *CredentialStatusManager) getLatestCSL(profile *vcprofile.DataProfile, url string) (*cslWrapper, error) {
-	// get latest id
-	id, err := c.store.Get(latestListID)
-	if err != nil { //nolint: nestif
-		if errors.Is(err, ariesstorage.ErrDataNotFound) {
-			if errPut := c.store.Put(latestListID, []byte("1")); errPut != nil {
-				return nil, fmt.Errorf("failed to store latest list ID in store: %w", errPut)
-			}
+// getSuspensionCSL returns the suspension list kept in parallel to the given revocation list,
+// the list is created if it doesn't exist yet.
+func (c *CredentialStatusManager) getSuspensionCSL(profile *vcprofile.DataProfile,
+	revocationCSL *cslWrapper) (*cslWrapper, error) {
+	vcID := SuspensionListID(revocationCSL.VC.ID)
 
-			// create verifiable credential that encapsulates the revocation list
-			vc, errCreateVC := c.createVC(url+"/1", profile)
-			if errCreateVC != nil {
-				return nil, errCreateVC
-			}
+	w, err := c.getCSLWrapper(vcID)
+	if err == nil {
+		return w, nil
+	}
 
-			vcBytes, errMarshal := vc.MarshalJSON()
-			if errMarshal != nil {
-				return nil, errMarshal
-			}
+	if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return nil, err
+	}
 
-			return &cslWrapper{vcBytes, 0, 0, "1", vc}, nil
-		}
+	vc, err := c.createVC(vcID, profile, StatusList2021Entry, StatusPurposeSuspension, revocationCSL.Length)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil, fmt.Errorf("failed to get latestListID from store: %w", err)
+	vcBytes, err := vc.MarshalJSON()
+	if err != nil {
+		return nil, err
 	}
 
-	vcID := url + "/" + string(id)
+	return &cslWrapper{
+		VCByte: vcBytes, ListID: revocationCSL.ListID, Capacity: revocationCSL.Capacity,
+		Length: revocationCSL.Length, Updated: &vc.Issued.Time, VC: vc,
+	}, nil
+}
 
+func (c *CredentialStatusManager) getLatestCSL(profile *vcprofile.DataProfile, vcID, id string,
+	sOpts *statusOpts) (*cslWrapper, error) {
 	w, err := c.getCSLWrapper(vcID)
 	if err != nil { //nolint: nestif
 		if errors.Is(err, ariesstorage.ErrDataNotFound) {
 			// create verifiable credential that encapsulates the revocation list
-			vc, errCreateVC := c.createVC(vcID, profile)
+			vc, errCreateVC := c.createVC(vcID, profile, sOpts.StatusType, StatusPurposeRevocation,
+				sOpts.BitStringLength)
 			if errCreateVC != nil {
 				return nil, errCreateVC
 			}
@@ -289,7 +870,10 @@ func (c *CredentialStatusManager) getLatestCSL(profile *vcprofile.DataProfile, u
 				return nil, errMarshal
 			}
 
-			return &cslWrapper{vcBytes, 0, 0, string(id), vc}, nil
+			return &cslWrapper{
+				VCByte: vcBytes, ListID: id, Capacity: sOpts.ListSize, Length: sOpts.BitStringLength,
+				Updated: &vc.Issued.Time, VC: vc,
+			}, nil
 		}
 
 		return nil, fmt.Errorf("failed to get csl from store: %w", err)
@@ -298,10 +882,41 @@ func (c *CredentialStatusManager) getLatestCSL(profile *vcprofile.DataProfile, u
 	return w, nil
 }
 
-func (c *CredentialStatusManager) createVC(vcID string,
-	profile *vcprofile.DataProfile) (*verifiable.Credential, error) {
+// getLatestListID returns ID of the list currently being filled for the given profile.
+func (c *CredentialStatusManager) getLatestListID(profileName string) (string, error) {
+	key := latestListIDKey(profileName)
+
+	id, err := c.store.Get(key)
+	if err == nil {
+		return string(id), nil
+	}
+
+	if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return "", fmt.Errorf("failed to get latestListID from store: %w", err)
+	}
+
+	// status data stored before list IDs were scoped per profile uses one global counter,
+	// the profile continues from it so that none of the lists it may already own is reused
+	id, err = c.store.Get(latestListID)
+	if err != nil {
+		if !errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return "", fmt.Errorf("failed to get latestListID from store: %w", err)
+		}
+
+		id = []byte("1")
+	}
+
+	if err := c.store.Put(key, id); err != nil {
+		return "", fmt.Errorf("failed to store latest list ID in store: %w", err)
+	}
+
+	return string(id), nil
+}
+
+func (c *CredentialStatusManager) createVC(vcID string, profile *vcprofile.DataProfile,
+	statusType, statusPurpose string, length int) (*verifiable.Credential, error) {
 	credential := &verifiable.Credential{}
-	credential.Context = []string{vcContext, Context}
+	credential.Context = []string{vcContext, StatusContext(statusType)}
 
 	if profile.SignatureType == vccrypto.JSONWebSignature2020 {
 		credential.Context = append(credential.Context, jsonWebSignature2020Ctx)
@@ -313,25 +928,30 @@ func (c *CredentialStatusManager) createVC(vcID string,
 
 	credential.ID = vcID
 	credential.Types = []string{vcType, revocationList2020VCType}
-	credential.Issuer = verifiable.Issuer{ID: profile.DID}
-	credential.Issued = util.NewTime(time.Now().UTC())
 
-	size := c.listSize
-
-	if size < bitStringSize {
-		size = bitStringSize
+	if statusType == StatusList2021Entry {
+		credential.Types = []string{vcType, statusList2021VCType}
 	}
+	credential.Issuer = verifiable.Issuer{ID: profile.DID}
+	credential.Issued = util.NewTime(time.Now().UTC())
 
-	encodeBits, err := utils.NewBitString(size).EncodeBits()
+	encodeBits, err := utils.NewBitString(length).EncodeBits()
 	if err != nil {
 		return nil, err
 	}
 
-	credential.Subject = &credentialSubject{
+	subject := &credentialSubject{
 		ID: credential.ID + "#list", Type: revocationList2020Type,
 		EncodedList: encodeBits,
 	}
 
+	if statusType == StatusList2021Entry {
+		subject.Type = statusList2021Type
+		subject.StatusPurpose = statusPurpose
+	}
+
+	credential.Subject = subject
+
 	signOpts, err := prepareSigningOpts(profile, credential.Proofs)
 	if err != nil {
 		return nil, err
@@ -345,7 +965,177 @@ func (c *CredentialStatusManager) createVC(vcID string,
 	return signedCredential, nil
 }
 
+// allocateIndexes picks the given number of random indexes of the bit string among the ones not handed out yet,
+// so that the indexes don't tell anything about the order credentials were issued in. Random indexes are drawn
+// until a free one is found, the free indexes are only listed once the list is nearly full.
+func (w *cslWrapper) allocateIndexes(count int) ([]int, error) {
+	if w.Size+count > w.Capacity {
+		return nil, fmt.Errorf("status list %s is full", w.VC.ID)
+	}
+
+	used, err := w.usedIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, count)
+
+	var free []int
+
+	for len(indexes) < count {
+		var index int
+
+		if free == nil {
+			found := false
+
+			index, found, err = drawFreeIndex(used, w.Length, w.Size+len(indexes))
+			if err != nil {
+				return nil, err
+			}
+
+			if !found {
+				if free, err = freeIndexes(used, w.Length); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+		} else {
+			if len(free) == 0 {
+				return nil, fmt.Errorf("status list %s is full", w.VC.ID)
+			}
+
+			n, err := randomInt(len(free))
+			if err != nil {
+				return nil, err
+			}
+
+			index = free[n]
+			free[n] = free[len(free)-1]
+			free = free[:len(free)-1]
+		}
+
+		if err := used.Set(index, true); err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	w.UsedIndexes, err = used.EncodeBits()
+	if err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
+
+// drawFreeIndex draws random indexes of the bit string until it finds one not used yet. It gives up once the
+// list is nearly full, or after a number of draws, which is unlikely before that.
+func drawFreeIndex(used *utils.BitString, length, usedCount int) (int, bool, error) {
+	if usedCount*maxUsedRatioDenominator > length*maxUsedRatioNumerator {
+		return 0, false, nil
+	}
+
+	for i := 0; i < maxIndexDraws; i++ {
+		index, err := randomInt(length)
+		if err != nil {
+			return 0, false, err
+		}
+
+		bitSet, err := used.Get(index)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if !bitSet {
+			return index, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// freeIndexes lists the indexes of the bit string not used yet.
+func freeIndexes(used *utils.BitString, length int) ([]int, error) {
+	free := []int{}
+
+	for i := 0; i < length; i++ {
+		bitSet, err := used.Get(i)
+		if err != nil {
+			return nil, err
+		}
+
+		if !bitSet {
+			free = append(free, i)
+		}
+	}
+
+	return free, nil
+}
+
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate status list index: %w", err)
+	}
+
+	return int(n.Int64()), nil
+}
+
+// usedIndexes returns indexes of the list already handed out.
+func (w *cslWrapper) usedIndexes() (*utils.BitString, error) {
+	used := utils.NewBitString(w.Length)
+
+	if w.UsedIndexes != "" {
+		stored, err := utils.DecodeBits(w.UsedIndexes)
+		if err != nil {
+			return nil, err
+		}
+
+		if stored.Len() >= w.Length {
+			return stored, nil
+		}
+
+		// lists stored before indexes were spread over the whole bit string only track the first entries
+		for i := 0; i < stored.Len() && i < w.Length; i++ {
+			bitSet, err := stored.Get(i)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := used.Set(i, bitSet); err != nil {
+				return nil, err
+			}
+		}
+
+		return used, nil
+	}
+
+	// lists stored before random allocation handed out indexes sequentially
+	for i := 0; i < w.RevocationListIndex && i < w.Capacity; i++ {
+		if err := used.Set(i, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return used, nil
+}
+
+// storeCSL stores the list unless the stored version was replaced since the list was read, which only happens
+// when the locker didn't keep out the update of another instance.
 func (c *CredentialStatusManager) storeCSL(cslWrapper *cslWrapper) error {
+	stored, err := c.storedVersion(cslWrapper.VC.ID)
+	if err != nil {
+		return err
+	}
+
+	if stored != cslWrapper.Version {
+		return ErrConcurrentUpdate
+	}
+
+	cslWrapper.Version++
+
 	cslWrapperBytes, err := json.Marshal(cslWrapper)
 	if err != nil {
 		return fmt.Errorf("failed to marshal csl struct: %w", err)
@@ -358,6 +1148,80 @@ func (c *CredentialStatusManager) storeCSL(cslWrapper *cslWrapper) error {
 	return nil
 }
 
+// storedVersion returns the version of the stored list, 0 for lists not stored yet.
+func (c *CredentialStatusManager) storedVersion(id string) (int, error) {
+	cslWrapperBytes, err := c.store.Get(id)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to get csl from store: %w", err)
+	}
+
+	stored := struct {
+		Version int `json:"version"`
+	}{}
+
+	if err := json.Unmarshal(cslWrapperBytes, &stored); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal csl bytes: %w", err)
+	}
+
+	return stored.Version, nil
+}
+
+// StatusContext returns the JSON-LD context of the given credential status type.
+func StatusContext(statusType string) string {
+	if statusType == StatusList2021Entry {
+		return StatusList2021Context
+	}
+
+	return Context
+}
+
+// latestListIDKey returns the store key of the latest list ID of the given profile.
+func latestListIDKey(profileName string) string {
+	return latestListID + "_" + profileName
+}
+
+// SuspensionListID returns the ID of the suspension list kept in parallel to the given revocation list,
+// the suspension list shares list ID and index with the revocation list it belongs to.
+func SuspensionListID(revocationListCredential string) string {
+	return revocationListCredential + "/" + StatusPurposeSuspension
+}
+
+// bitStringLength returns the default bit string length of lists with the given number of entries.
+func bitStringLength(listSize int) int {
+	if listSize > defaultBitStringLength {
+		return listSize
+	}
+
+	return defaultBitStringLength
+}
+
+func isSupportedStatusType(statusType string) bool {
+	return statusType == RevocationList2020Status || statusType == StatusList2021Entry
+}
+
+// statusListKeys returns status list credential and index field names for the given credential status type.
+func statusListKeys(statusType string) (string, string) {
+	if statusType == StatusList2021Entry {
+		return StatusListCredential, StatusListIndex
+	}
+
+	return RevocationListCredential, RevocationListIndex
+}
+
+func isStatusList2021(vc *verifiable.Credential) bool {
+	for _, t := range vc.Types {
+		if t == statusList2021VCType {
+			return true
+		}
+	}
+
+	return false
+}
+
 // prepareSigningOpts prepares signing opts from recently issued proof of given credential
 func prepareSigningOpts(profile *vcprofile.DataProfile, proofs []verifiable.Proof) ([]vccrypto.SigningOpts, error) {
 	var signingOpts []vccrypto.SigningOpts
@@ -388,8 +1252,9 @@ func prepareSigningOpts(profile *vcprofile.DataProfile, proofs []verifiable.Proo
 		return nil, err
 	}
 
-	// add verification method option only when it is not matching profile creator
-	if vm != profile.Creator {
+	// add verification method option only when it is not a key of the profile DID, so that lists are signed
+	// with the profile creator once its key was rotated
+	if vm != profile.Creator && !strings.HasPrefix(vm, profile.DID+"#") {
 		signingOpts = append(signingOpts, vccrypto.WithVerificationMethod(vm))
 	}
 
@@ -11,11 +11,14 @@ import (
 	"crypto/rand"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	cryptomock "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
@@ -23,12 +26,14 @@ import (
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	"github.com/trustbloc/edge-service/pkg/lock"
 )
 
 const (
@@ -72,17 +77,16 @@ func TestCredentialStatusList_New(t *testing.T) {
 	})
 }
 
-func validateVCStatus(t *testing.T, s *CredentialStatusManager, id string, index int) {
+func validateVCStatus(t *testing.T, s *CredentialStatusManager, id string) int {
 	t.Helper()
 
 	status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
 	require.NoError(t, err)
 	require.Equal(t, RevocationList2020Status, status.Type)
-	require.Equal(t, id+"#"+strconv.Itoa(index), status.ID)
 
 	revocationListIndex, err := strconv.Atoi(status.CustomFields[RevocationListIndex].(string))
 	require.NoError(t, err)
-	require.Equal(t, index, revocationListIndex)
+	require.Equal(t, id+"#"+strconv.Itoa(revocationListIndex), status.ID)
 	require.Equal(t, id, status.CustomFields[RevocationListCredential].(string))
 
 	revocationListVCBytes, err := s.GetRevocationListVC(id)
@@ -106,6 +110,8 @@ func validateVCStatus(t *testing.T, s *CredentialStatusManager, id string, index
 	bitSet, err := bitString.Get(revocationListIndex)
 	require.NoError(t, err)
 	require.False(t, bitSet)
+
+	return revocationListIndex
 }
 
 func TestCredentialStatusList_CreateStatusID(t *testing.T) {
@@ -116,9 +122,244 @@ func TestCredentialStatusList_CreateStatusID(t *testing.T) {
 				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
 		require.NoError(t, err)
 
-		validateVCStatus(t, s, "localhost:8080/status/1", 0)
-		validateVCStatus(t, s, "localhost:8080/status/1", 1)
-		validateVCStatus(t, s, "localhost:8080/status/2", 0)
+		first := validateVCStatus(t, s, "localhost:8080/status/1")
+		second := validateVCStatus(t, s, "localhost:8080/status/1")
+		require.NotEqual(t, first, second)
+		validateVCStatus(t, s, "localhost:8080/status/2")
+	})
+
+	t.Run("test success random indexes are not reused", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		const listSize = 100
+
+		indexes := make(map[string]bool)
+
+		for i := 0; i < listSize; i++ {
+			status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithListSize(listSize),
+				WithBitStringLength(listSize))
+			require.NoError(t, err)
+			require.Equal(t, "localhost:8080/status/1", status.CustomFields[RevocationListCredential])
+
+			index := status.CustomFields[RevocationListIndex].(string)
+			require.False(t, indexes[index], "index %s handed out twice", index)
+
+			indexes[index] = true
+		}
+
+		for i := 0; i < listSize; i++ {
+			require.True(t, indexes[strconv.Itoa(i)])
+		}
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithListSize(listSize))
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080/status/2", status.CustomFields[RevocationListCredential])
+	})
+
+	t.Run("test success list with sequential indexes", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		provider := ariesmockstorage.NewMockStoreProvider()
+		s, err := New(provider, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		validateVCStatus(t, s, "localhost:8080/status/1")
+
+		// turn the stored list into one allocated before random indexes
+		wrapperBytes, err := provider.Store.Get("localhost:8080/status/1")
+		require.NoError(t, err)
+
+		var w cslWrapper
+		require.NoError(t, json.Unmarshal(wrapperBytes, &w))
+
+		w.RevocationListIndex = 1
+		w.Capacity = 0
+		w.Length = 0
+		w.UsedIndexes = ""
+
+		wrapperBytes, err = json.Marshal(w)
+		require.NoError(t, err)
+		require.NoError(t, provider.Store.Put("localhost:8080/status/1", wrapperBytes))
+
+		require.NotEqual(t, 0, validateVCStatus(t, s, "localhost:8080/status/1"))
+		validateVCStatus(t, s, "localhost:8080/status/2")
+	})
+
+	t.Run("test success list with indexes tracked within capacity", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		provider := ariesmockstorage.NewMockStoreProvider()
+		s, err := New(provider, 3,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		validateVCStatus(t, s, "localhost:8080/status/1")
+
+		// turn the stored list into one allocating indexes within its capacity only
+		wrapperBytes, err := provider.Store.Get("localhost:8080/status/1")
+		require.NoError(t, err)
+
+		var w cslWrapper
+		require.NoError(t, json.Unmarshal(wrapperBytes, &w))
+
+		used := utils.NewBitString(3)
+		require.NoError(t, used.Set(2, true))
+
+		w.Length = 0
+		w.UsedIndexes, err = used.EncodeBits()
+		require.NoError(t, err)
+
+		wrapperBytes, err = json.Marshal(w)
+		require.NoError(t, err)
+		require.NoError(t, provider.Store.Put("localhost:8080/status/1", wrapperBytes))
+
+		require.NotEqual(t, 2, validateVCStatus(t, s, "localhost:8080/status/1"))
+		require.NotEqual(t, 2, validateVCStatus(t, s, "localhost:8080/status/1"))
+		validateVCStatus(t, s, "localhost:8080/status/2")
+	})
+
+	t.Run("test success with bit string length", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry),
+			WithListSize(10), WithBitStringLength(16))
+		require.NoError(t, err)
+
+		index, err := strconv.Atoi(status.CustomFields[StatusListIndex].(string))
+		require.NoError(t, err)
+		require.Less(t, index, 16)
+
+		for _, id := range []string{"localhost:8080/status/1", SuspensionListID("localhost:8080/status/1")} {
+			statusListVCBytes, errGet := s.GetRevocationListVC(id)
+			require.NoError(t, errGet)
+
+			statusListVC, errParse := verifiable.ParseCredential(statusListVCBytes, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(loader))
+			require.NoError(t, errParse)
+
+			credSubject, ok := statusListVC.Subject.([]verifiable.Subject)
+			require.True(t, ok)
+
+			bitString, errDecode := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+			require.NoError(t, errDecode)
+			require.Equal(t, 16, bitString.Len())
+		}
+	})
+
+	t.Run("test error bit string length out of range", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithListSize(10),
+			WithBitStringLength(8))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status list bit string length 8 out of range")
+
+		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status",
+			WithBitStringLength(MaxBitStringLength+1))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "out of range")
+	})
+
+	t.Run("test success list ID per profile", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		validateVCStatus(t, s, "localhost:8080/status/1")
+		validateVCStatus(t, s, "localhost:8080/status/1")
+
+		profile := getTestProfile()
+		profile.Name = "other"
+
+		status, err := s.CreateStatusID(profile, "localhost:8080/other/status", WithListSize(1),
+			WithBitStringLength(1))
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080/other/status/1", status.CustomFields[RevocationListCredential])
+		require.Equal(t, "0", status.CustomFields[RevocationListIndex])
+
+		status, err = s.CreateStatusID(profile, "localhost:8080/other/status", WithListSize(1),
+			WithBitStringLength(1))
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080/other/status/2", status.CustomFields[RevocationListCredential])
+		require.Equal(t, "0", status.CustomFields[RevocationListIndex])
+
+		validateVCStatus(t, s, "localhost:8080/status/2")
+	})
+
+	t.Run("test success migrate global list ID", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		provider := ariesmockstorage.NewMockStoreProvider()
+		s, err := New(provider, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		require.NoError(t, provider.Store.Put(latestListID, []byte("3")))
+
+		validateVCStatus(t, s, "localhost:8080/status/3")
+		validateVCStatus(t, s, "localhost:8080/status/3")
+		validateVCStatus(t, s, "localhost:8080/status/4")
+
+		id, err := provider.Store.Get(latestListIDKey(getTestProfile().Name))
+		require.NoError(t, err)
+		require.Equal(t, "4", string(id))
+	})
+
+	t.Run("test success with StatusList2021", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+		require.Equal(t, StatusList2021Entry, status.Type)
+		require.Equal(t, "localhost:8080/status/1#"+status.CustomFields[StatusListIndex].(string), status.ID)
+		require.Equal(t, StatusPurposeRevocation, status.CustomFields[StatusPurpose])
+		require.Equal(t, "localhost:8080/status/1", status.CustomFields[StatusListCredential])
+
+		statusListVCBytes, err := s.GetRevocationListVC("localhost:8080/status/1")
+		require.NoError(t, err)
+
+		statusListVC, err := verifiable.ParseCredential(statusListVCBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.Equal(t, StatusList2021Context, statusListVC.Context[1])
+		require.Equal(t, []string{vcType, statusList2021VCType}, statusListVC.Types)
+
+		credSubject, ok := statusListVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+		require.Equal(t, statusList2021Type, credSubject[0].CustomFields["type"].(string))
+		require.Equal(t, StatusPurposeRevocation, credSubject[0].CustomFields[StatusPurpose].(string))
+	})
+
+	t.Run("test error status type not supported", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType("noMatch"))
+		require.Error(t, err)
+		require.Nil(t, status)
+		require.Contains(t, err.Error(), "vc status noMatch not supported")
 	})
 
 	t.Run("test error from get latest id from store", func(t *testing.T) {
@@ -136,254 +377,1006 @@ func TestCredentialStatusList_CreateStatusID(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to get latestListID from store")
 	})
 
-	t.Run("test error from put latest id to store", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
-			ErrGet: storage.ErrDataNotFound,
-			ErrPut: fmt.Errorf("put error"),
-		}}, 1,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{},
-				loader), loader)
-		require.NoError(t, err)
+	t.Run("test error from put latest id to store", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			ErrGet: storage.ErrDataNotFound,
+			ErrPut: fmt.Errorf("put error"),
+		}}, 1,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{},
+				loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.Error(t, err)
+		require.Nil(t, status)
+		require.Contains(t, err.Error(), "failed to store latest list ID in store")
+	})
+
+	t.Run("test error from store csl list in store", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{
+			getFunc: func(k string) (bytes []byte, err error) {
+				return nil, storage.ErrDataNotFound
+			},
+			putFunc: func(k string, v []byte) error {
+				if k == "localhost:8080/status/1" {
+					return fmt.Errorf("put error")
+				}
+				return nil
+			},
+		}}, 1,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.Error(t, err)
+		require.Nil(t, status)
+		require.Contains(t, err.Error(), "failed to store csl in store")
+	})
+
+	t.Run("test error from put latest id to store after store new list", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{
+			getFunc: func(k string) (bytes []byte, err error) {
+				return nil, storage.ErrDataNotFound
+			},
+			putFunc: func(k string, v []byte) error {
+				if k == latestListIDKey(getTestProfile().Name) && string(v) == "2" {
+					return fmt.Errorf("put error")
+				}
+				return nil
+			},
+		}}, 1,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.Error(t, err)
+		require.Nil(t, status)
+		require.Contains(t, err.Error(), "failed to store latest list ID in store")
+	})
+}
+
+func TestCredentialStatusList_CreateStatusIDs(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		// the first list already has a credential
+		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+
+		statusIDs, err := s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 4,
+			WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+		require.Len(t, statusIDs, 4)
+
+		indexes := make(map[string]bool)
+
+		for i, statusID := range statusIDs {
+			require.Equal(t, StatusList2021Entry, statusID.Type)
+
+			listID := "localhost:8080/status/1"
+			if i > 0 {
+				listID = fmt.Sprintf("localhost:8080/status/%d", (i+1)/2+1)
+			}
+
+			require.Equal(t, listID, statusID.CustomFields[StatusListCredential])
+			require.Equal(t, listID+"#"+statusID.CustomFields[StatusListIndex].(string), statusID.ID)
+			require.False(t, indexes[statusID.ID], "status %s handed out twice", statusID.ID)
+
+			indexes[statusID.ID] = true
+		}
+
+		// suspension lists are published along with the new lists
+		_, err = s.GetStatusListVC(SuspensionListID("localhost:8080/status/3"))
+		require.NoError(t, err)
+
+		// the last list still has room
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080/status/3", status.CustomFields[StatusListCredential])
+	})
+
+	t.Run("test error - invalid number of status ids", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		_, err = s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid number of status ids 0")
+	})
+}
+
+func TestCredentialStatusList_ReleaseStatusIDs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	newManager := func(t *testing.T) *CredentialStatusManager {
+		t.Helper()
+
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("test released index is handed out again", func(t *testing.T) {
+		s := newManager(t)
+
+		released, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, err)
+
+		require.NoError(t, s.ReleaseStatusIDs([]*verifiable.TypedID{released}))
+
+		// releasing twice doesn't free room taken by another credential
+		require.NoError(t, s.ReleaseStatusIDs([]*verifiable.TypedID{released}))
+
+		statusIDs, err := s.CreateStatusIDs(getTestProfile(), "localhost:8080/status", 2)
+		require.NoError(t, err)
+
+		for _, statusID := range statusIDs {
+			require.Equal(t, "localhost:8080/status/1", statusID.CustomFields[RevocationListCredential])
+		}
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080/status/2", status.CustomFields[RevocationListCredential])
+	})
+
+	t.Run("test error - invalid status", func(t *testing.T) {
+		err := newManager(t).ReleaseStatusIDs([]*verifiable.TypedID{{Type: "Unknown"}})
+		require.Error(t, err)
+	})
+
+	t.Run("test error - unknown list", func(t *testing.T) {
+		err := newManager(t).ReleaseStatusIDs([]*verifiable.TypedID{{
+			ID: "localhost:8080/status/1#1", Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
+				RevocationListIndex: "1", RevocationListCredential: "localhost:8080/status/1",
+			},
+		}})
+		require.Error(t, err)
+	})
+}
+
+func TestCredentialStatusList_GetRevocationListVC(t *testing.T) {
+	t.Run("test error getting csl from store", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
+			return nil, fmt.Errorf("get error")
+		}}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{},
+				loader), loader)
+		require.NoError(t, err)
+		csl, err := s.GetRevocationListVC("1")
+		require.Error(t, err)
+		require.Nil(t, csl)
+		require.Contains(t, err.Error(), "failed to get revocationListVC from store")
+	})
+}
+
+func TestCredentialStatusList_RevokeVC(t *testing.T) {
+	t.Run("test vc status not exists", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vc status not exist")
+	})
+
+	t.Run("test vc status type not supported", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = &verifiable.TypedID{Type: "noMatch"}
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vc status noMatch not supported")
+	})
+
+	t.Run("test vc status revocationListIndex not exists", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = &verifiable.TypedID{Type: RevocationList2020Status}
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "revocationListIndex field not exist in vc status")
+	})
+
+	t.Run("test vc status revocationListCredential not exists", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = &verifiable.TypedID{
+			Type: RevocationList2020Status, CustomFields: map[string]interface{}{RevocationListIndex: "1"},
+		}
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "revocationListCredential field not exist in vc status")
+	})
+
+	t.Run("test vc status revocationListCredential wrong value type", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = &verifiable.TypedID{Type: RevocationList2020Status, CustomFields: map[string]interface{}{
+			RevocationListIndex: "1", RevocationListCredential: 1,
+		}}
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to cast status revocationListCredential")
+	})
+
+	t.Run("test success", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = status
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true))
+
+		revocationListVCBytes, err := s.GetRevocationListVC(status.CustomFields["revocationListCredential"].(string))
+		require.NoError(t, err)
+		revocationListIndex, err := strconv.Atoi(status.CustomFields[RevocationListIndex].(string))
+		require.NoError(t, err)
+
+		revocationListVC, err := verifiable.ParseCredential(revocationListVCBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		credSubject, ok := revocationListVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+		require.NotEmpty(t, credSubject[0].CustomFields["encodedList"].(string))
+		bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+		bitSet, err := bitString.Get(revocationListIndex)
+		require.NoError(t, err)
+		require.True(t, bitSet)
+	})
+
+	t.Run("test success with StatusList2021", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = status
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true))
+
+		statusListVCBytes, err := s.GetRevocationListVC(status.CustomFields[StatusListCredential].(string))
+		require.NoError(t, err)
+		statusListIndex, err := strconv.Atoi(status.CustomFields[StatusListIndex].(string))
+		require.NoError(t, err)
+
+		statusListVC, err := verifiable.ParseCredential(statusListVCBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		credSubject, ok := statusListVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+		bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+		bitSet, err := bitString.Get(statusListIndex)
+		require.NoError(t, err)
+		require.True(t, bitSet)
+	})
+
+	t.Run("test success suspend with StatusList2021", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = status
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true, WithStatusPurpose(StatusPurposeSuspension)))
+
+		statusListIndex, err := strconv.Atoi(status.CustomFields[StatusListIndex].(string))
+		require.NoError(t, err)
+
+		getBit := func(listID, purpose string) bool {
+			listVCBytes, errGet := s.GetRevocationListVC(listID)
+			require.NoError(t, errGet)
+
+			listVC, errParse := verifiable.ParseCredential(listVCBytes, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(loader))
+			require.NoError(t, errParse)
+			credSubject, ok := listVC.Subject.([]verifiable.Subject)
+			require.True(t, ok)
+			require.Equal(t, purpose, credSubject[0].CustomFields[StatusPurpose])
+			bitString, errDecode := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+			require.NoError(t, errDecode)
+			bitSet, errGet := bitString.Get(statusListIndex)
+			require.NoError(t, errGet)
+
+			return bitSet
+		}
+
+		listID := status.CustomFields[StatusListCredential].(string)
+		require.False(t, getBit(listID, StatusPurposeRevocation))
+		require.True(t, getBit(SuspensionListID(listID), StatusPurposeSuspension))
+
+		// lift the suspension
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), false, WithStatusPurpose(StatusPurposeSuspension)))
+		require.False(t, getBit(SuspensionListID(listID), StatusPurposeSuspension))
+	})
+
+	t.Run("test error suspend with RevocationList2020", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = status
+		err = s.UpdateVC(cred, getTestProfile(), true, WithStatusPurpose(StatusPurposeSuspension))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vc status RevocationList2020Status does not support suspension purpose")
+
+		err = s.UpdateVC(cred, getTestProfile(), true, WithStatusPurpose("noMatch"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vc status purpose noMatch not supported")
+	})
+
+	t.Run("test vc status statusPurpose not supported", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		cred.ID = credID
+		cred.Status = &verifiable.TypedID{Type: StatusList2021Entry, CustomFields: map[string]interface{}{
+			StatusListIndex: "1", StatusListCredential: "test",
+		}}
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "statusPurpose field not exist in vc status")
+
+		cred.Status.CustomFields[StatusPurpose] = "noMatch"
+		err = s.UpdateVC(cred, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vc status purpose noMatch not supported")
+	})
+
+	t.Run("test error get csl from store", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
+			return nil, fmt.Errorf("get error")
+		}}}, 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		err = s.UpdateVC(&verifiable.Credential{
+			ID: credID,
+			Status: &verifiable.TypedID{
+				ID: "test", Type: RevocationList2020Status,
+				CustomFields: map[string]interface{}{
+					RevocationListCredential: "test",
+					RevocationListIndex:      "1",
+				},
+			},
+		}, getTestProfile(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get csl from store")
+	})
+
+	t.Run("test error from sign status credential", func(t *testing.T) {
+		loader := testutil.DocumentLoader(t)
+		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignErr: fmt.Errorf("failed to sign")},
+				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		require.NoError(t, err)
+
+		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to sign vc")
+	})
+}
+
+func TestCredentialStatusList_UpdateVCs(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+	c := &countingCrypto{crypto: vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+		&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader)}
+
+	s, err := New(ariesmockstorage.NewMockStoreProvider(), 2, c, loader)
+	require.NoError(t, err)
+
+	var vcs []*verifiable.Credential
+
+	for i := 0; i < 3; i++ {
+		status, errCreate := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		require.NoError(t, errCreate)
+
+		cred, errParse := verifiable.ParseCredential([]byte(universityDegreeCred),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+
+		cred.Status = status
+		vcs = append(vcs, cred)
+	}
+
+	invalidIndex := *vcs[0]
+	invalidIndex.Status = &verifiable.TypedID{Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
+		RevocationListIndex: "128000", RevocationListCredential: "localhost:8080/status/1",
+	}}
+
+	vcs = append(vcs, &verifiable.Credential{ID: "noStatus"}, &invalidIndex)
+
+	statusList, err := s.GetStatusListVC("localhost:8080/status/1")
+	require.NoError(t, err)
+	require.NotNil(t, statusList.Updated)
+
+	created := *statusList.Updated
+
+	c.calls = 0
+
+	errs := s.UpdateVCs(vcs, getTestProfile(), true)
+	require.Len(t, errs, 5)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NoError(t, errs[2])
+	require.EqualError(t, errs[3], "vc status not exist")
+	require.EqualError(t, errs[4], "position is invalid")
+
+	// two lists signed once each
+	require.Equal(t, 2, c.calls)
+
+	statusList, err = s.GetStatusListVC("localhost:8080/status/1")
+	require.NoError(t, err)
+	require.False(t, statusList.Updated.Before(created))
+
+	for _, v := range vcs[:3] {
+		listVCBytes, err := s.GetRevocationListVC(v.Status.CustomFields[RevocationListCredential].(string))
+		require.NoError(t, err)
+
+		listVC, err := verifiable.ParseCredential(listVCBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+
+		credSubject, ok := listVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+
+		bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+
+		index, err := strconv.Atoi(v.Status.CustomFields[RevocationListIndex].(string))
+		require.NoError(t, err)
+
+		bitSet, err := bitString.Get(index)
+		require.NoError(t, err)
+		require.True(t, bitSet)
+	}
+
+	t.Run("test error get csl from store", func(t *testing.T) {
+		errs := s.UpdateVCs([]*verifiable.Credential{{Status: &verifiable.TypedID{
+			Type: RevocationList2020Status, CustomFields: verifiable.CustomFields{
+				RevocationListIndex: "1", RevocationListCredential: "localhost:8080/status/10",
+			},
+		}}}, getTestProfile(), true)
+		require.Len(t, errs, 1)
+		require.Error(t, errs[0])
+		require.Contains(t, errs[0].Error(), "failed to get csl from store")
+	})
+}
+
+func TestCredentialStatusList_GetStatus(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+		vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	require.NoError(t, err)
+
+	status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
+	require.NoError(t, err)
+
+	cred := &verifiable.Credential{ID: credID, Status: status}
+
+	t.Run("status not set", func(t *testing.T) {
+		revoked, err := s.GetStatus(status, StatusPurposeRevocation)
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		// the suspension list isn't created before a credential of the list is suspended
+		suspended, err := s.GetStatus(status, StatusPurposeSuspension)
+		require.NoError(t, err)
+		require.False(t, suspended)
+	})
+
+	t.Run("status set", func(t *testing.T) {
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true, WithStatusPurpose(StatusPurposeSuspension)))
+
+		suspended, err := s.GetStatus(status, StatusPurposeSuspension)
+		require.NoError(t, err)
+		require.True(t, suspended)
+
+		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true))
+
+		revoked, err := s.GetStatus(status, StatusPurposeRevocation)
+		require.NoError(t, err)
+		require.True(t, revoked)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := s.GetStatus(nil, StatusPurposeRevocation)
+		require.EqualError(t, err, "vc status not exist")
+
+		_, err = s.GetStatus(&verifiable.TypedID{Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+			StatusPurpose: StatusPurposeRevocation, StatusListIndex: "1",
+			StatusListCredential: "localhost:8080/status/10",
+		}}, StatusPurposeRevocation)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get csl from store")
+	})
+}
+
+func TestCredentialStatusList_ConcurrentUpdate(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+		vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	require.NoError(t, err)
+
+	status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+	require.NoError(t, err)
+
+	listID := status.CustomFields[RevocationListCredential].(string)
+
+	stale, err := s.getCSLWrapper(listID)
+	require.NoError(t, err)
+
+	// another instance updates the list the locker didn't keep it out of
+	require.NoError(t, s.UpdateVC(&verifiable.Credential{ID: credID, Status: status}, getTestProfile(), true))
+
+	require.ErrorIs(t, s.storeCSL(stale), ErrConcurrentUpdate)
+
+	current, err := s.getCSLWrapper(listID)
+	require.NoError(t, err)
+	require.Equal(t, stale.Version+1, current.Version)
+	require.NoError(t, s.storeCSL(current))
+}
+
+func TestCredentialStatusList_GetStatusListVCAt(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+	s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
+		vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	require.NoError(t, err)
+
+	const listID = "localhost:8080/status/1"
+
+	status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+	require.NoError(t, err)
+
+	cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+		verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	cred.Status = status
+
+	index, err := strconv.Atoi(status.CustomFields[RevocationListIndex].(string))
+	require.NoError(t, err)
+
+	revokedAt := func(at time.Time) bool {
+		statusList, errGet := s.GetStatusListVCAt(listID, at)
+		require.NoError(t, errGet)
+		require.False(t, statusList.Updated.After(at))
+
+		listVC, errParse := verifiable.ParseCredential(statusList.VC, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+
+		credSubject, ok := listVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
+
+		bitString, errDecode := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, errDecode)
+
+		bitSet, errGet := bitString.Get(index)
+		require.NoError(t, errGet)
+
+		return bitSet
+	}
+
+	updated := func() time.Time {
+		statusList, errGet := s.GetStatusListVC(listID)
+		require.NoError(t, errGet)
+
+		return *statusList.Updated
+	}
+
+	created := updated()
+
+	require.NoError(t, s.UpdateVC(cred, getTestProfile(), true))
+
+	revoked := updated()
+
+	require.NoError(t, s.UpdateVC(cred, getTestProfile(), false))
+
+	unrevoked := updated()
+
+	require.False(t, revokedAt(created))
+	require.True(t, revokedAt(revoked))
+	require.False(t, revokedAt(unrevoked))
+	require.False(t, revokedAt(unrevoked.Add(time.Hour)))
+
+	t.Run("test time before list was created", func(t *testing.T) {
+		_, err = s.GetStatusListVCAt(listID, created.Add(-time.Hour))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test versions are kept under their own keys", func(t *testing.T) {
+		versions, errGet := s.getVersions(listID)
+		require.NoError(t, errGet)
+		require.Len(t, versions, 3)
+		require.Equal(t, created, versions[0].updated)
+		require.Equal(t, unrevoked, versions[2].updated)
+	})
+
+	t.Run("test list without history", func(t *testing.T) {
+		iter, errQuery := s.store.Query(versionTagName + ":" + versionTagValue(listID))
+		require.NoError(t, errQuery)
+
+		for {
+			ok, errNext := iter.Next()
+			require.NoError(t, errNext)
+
+			if !ok {
+				break
+			}
+
+			key, errKey := iter.Key()
+			require.NoError(t, errKey)
+			require.NoError(t, s.store.Delete(key))
+		}
+
+		statusList, errGet := s.GetStatusListVCAt(listID, unrevoked)
+		require.NoError(t, errGet)
+		require.Equal(t, unrevoked, *statusList.Updated)
+
+		_, err = s.GetStatusListVCAt(listID, revoked)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
 
-		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+	t.Run("test unknown list", func(t *testing.T) {
+		_, err = s.GetStatusListVCAt("localhost:8080/status/10", time.Now())
 		require.Error(t, err)
-		require.Nil(t, status)
-		require.Contains(t, err.Error(), "failed to store latest list ID in store")
+		require.Contains(t, err.Error(), "failed to get revocationListVC from store")
 	})
 
-	t.Run("test error from store csl list in store", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(&storeProvider{store: &mockStore{
-			getFunc: func(k string) (bytes []byte, err error) {
-				return nil, storage.ErrDataNotFound
-			},
-			putFunc: func(k string, v []byte) error {
-				if k == "localhost:8080/status/1" {
-					return fmt.Errorf("put error")
-				}
-				return nil
-			},
-		}}, 1,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
-		require.NoError(t, err)
+	t.Run("test error querying versions", func(t *testing.T) {
+		store, ok := s.store.(*ariesmockstorage.MockStore)
+		require.True(t, ok)
 
-		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		store.ErrQuery = fmt.Errorf("query error")
+		defer func() { store.ErrQuery = nil }()
+
+		_, err = s.GetStatusListVCAt(listID, time.Now())
 		require.Error(t, err)
-		require.Nil(t, status)
-		require.Contains(t, err.Error(), "failed to store csl in store")
+		require.Contains(t, err.Error(), "failed to query status list versions: query error")
 	})
 
-	t.Run("test error from put latest id to store after store new list", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(&storeProvider{store: &mockStore{
-			getFunc: func(k string) (bytes []byte, err error) {
-				return nil, storage.ErrDataNotFound
-			},
-			putFunc: func(k string, v []byte) error {
-				if k == latestListID && string(v) == "2" {
-					return fmt.Errorf("put error")
-				}
-				return nil
-			},
-		}}, 1,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
-		require.NoError(t, err)
+	t.Run("test version isn't kept when the list fails to be stored", func(t *testing.T) {
+		cslWrapper, errGet := s.getCSLWrapper(listID)
+		require.NoError(t, errGet)
 
-		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
-		require.Error(t, err)
-		require.Nil(t, status)
-		require.Contains(t, err.Error(), "failed to store latest list ID in store")
+		cslWrapper.Version--
+
+		now := time.Now().UTC()
+		cslWrapper.Updated = &now
+
+		require.Equal(t, ErrConcurrentUpdate, s.publishCSL(cslWrapper))
+
+		statusList, errGet := s.GetStatusListVCAt(listID, now)
+		require.NoError(t, errGet)
+		require.Equal(t, unrevoked, *statusList.Updated)
 	})
 }
 
-func TestCredentialStatusList_GetRevocationListVC(t *testing.T) {
-	t.Run("test error getting csl from store", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
-			return nil, fmt.Errorf("get error")
-		}}}, 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{}, &vdrmock.MockVDRegistry{},
-				loader), loader)
+func TestCredentialStatusList_Concurrency(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	newManager := func(t *testing.T, provider storage.Provider, opts ...Opt) *CredentialStatusManager {
+		t.Helper()
+
+		s, err := New(provider, 10, vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader, opts...)
 		require.NoError(t, err)
-		csl, err := s.GetRevocationListVC("1")
-		require.Error(t, err)
-		require.Nil(t, csl)
-		require.Contains(t, err.Error(), "failed to get revocationListVC from store")
+
+		return s
+	}
+
+	newStoreLocker := func(t *testing.T, provider storage.Provider) Locker {
+		t.Helper()
+
+		l, err := lock.NewStoreLocker(provider, lock.WithLockLease(time.Minute), lock.WithLockWait(time.Minute),
+			lock.WithLockSettle(5*time.Millisecond))
+		require.NoError(t, err)
+
+		return l
+	}
+
+	t.Run("in-memory locker", func(t *testing.T) {
+		s := newManager(t, ariesmemstorage.NewProvider())
+
+		testConcurrentUpdates(t, loader, s, s)
+	})
+
+	t.Run("store locker of managers sharing the store", func(t *testing.T) {
+		provider := ariesmemstorage.NewProvider()
+
+		testConcurrentUpdates(t, loader,
+			newManager(t, provider, WithLocker(newStoreLocker(t, provider))),
+			newManager(t, provider, WithLocker(newStoreLocker(t, provider))))
 	})
 }
 
-func TestCredentialStatusList_RevokeVC(t *testing.T) {
-	t.Run("test vc status not exists", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
-		require.NoError(t, err)
+// testConcurrentUpdates issues and revokes credentials in parallel with the given managers in turn.
+func testConcurrentUpdates(t *testing.T, loader ld.DocumentLoader, managers ...*CredentialStatusManager) {
+	t.Helper()
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
-			verifiable.WithJSONLDDocumentLoader(loader))
+	const vcCount = 35
+
+	statuses := make([]*verifiable.TypedID, vcCount)
+	errs := make([]error, vcCount)
+
+	var wg sync.WaitGroup
+
+	// issue in parallel, half of the credentials is revoked while the rest is still being issued
+	for i := 0; i < vcCount; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			s := managers[i%len(managers)]
+
+			statuses[i], errs[i] = s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+			if errs[i] != nil || i%2 == 0 {
+				return
+			}
+
+			cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+				verifiable.WithJSONLDDocumentLoader(loader))
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			cred.Status = statuses[i]
+			errs[i] = s.UpdateVC(cred, getTestProfile(), true)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
 		require.NoError(t, err)
+	}
 
-		cred.ID = credID
-		err = s.UpdateVC(cred, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "vc status not exist")
-	})
+	issued := make(map[string]bool)
 
-	t.Run("test vc status type not supported", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	for i, status := range statuses {
+		require.False(t, issued[status.ID], "status %s handed out twice", status.ID)
+		issued[status.ID] = true
+
+		listVCBytes, err := managers[0].GetRevocationListVC(status.CustomFields[RevocationListCredential].(string))
 		require.NoError(t, err)
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+		listVC, err := verifiable.ParseCredential(listVCBytes, verifiable.WithDisabledProofCheck(),
 			verifiable.WithJSONLDDocumentLoader(loader))
 		require.NoError(t, err)
 
-		cred.ID = credID
-		cred.Status = &verifiable.TypedID{Type: "noMatch"}
-		err = s.UpdateVC(cred, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "vc status noMatch not supported")
-	})
+		credSubject, ok := listVC.Subject.([]verifiable.Subject)
+		require.True(t, ok)
 
-	t.Run("test vc status revocationListIndex not exists", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
 		require.NoError(t, err)
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
-			verifiable.WithJSONLDDocumentLoader(loader))
+		index, err := strconv.Atoi(status.CustomFields[RevocationListIndex].(string))
 		require.NoError(t, err)
 
-		cred.ID = credID
-		cred.Status = &verifiable.TypedID{Type: RevocationList2020Status}
-		err = s.UpdateVC(cred, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "revocationListIndex field not exist in vc status")
-	})
-
-	t.Run("test vc status revocationListCredential not exists", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+		bitSet, err := bitString.Get(index)
 		require.NoError(t, err)
+		require.Equal(t, i%2 == 1, bitSet, "unexpected status of %s", status.ID)
+	}
+}
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
-			verifiable.WithJSONLDDocumentLoader(loader))
+func TestAllocateIndexes(t *testing.T) {
+	const length = 1000
+
+	w := &cslWrapper{Capacity: length, Length: length, VC: &verifiable.Credential{ID: "localhost:8080/status/1"}}
+
+	allocated := make(map[int]bool)
+
+	// the indexes are drawn at random at first, then picked among the free ones once the list is nearly full
+	for w.Size < w.Capacity {
+		indexes, err := w.allocateIndexes(100)
 		require.NoError(t, err)
+		require.Len(t, indexes, 100)
 
-		cred.ID = credID
-		cred.Status = &verifiable.TypedID{
-			Type: RevocationList2020Status, CustomFields: map[string]interface{}{RevocationListIndex: "1"},
+		for _, index := range indexes {
+			require.True(t, index >= 0 && index < length)
+			require.False(t, allocated[index], "index %d handed out twice", index)
+
+			allocated[index] = true
 		}
-		err = s.UpdateVC(cred, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "revocationListCredential field not exist in vc status")
-	})
 
-	t.Run("test vc status revocationListCredential wrong value type", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
-		require.NoError(t, err)
+		w.Size += len(indexes)
+	}
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
-			verifiable.WithJSONLDDocumentLoader(loader))
-		require.NoError(t, err)
+	_, err := w.allocateIndexes(1)
+	require.EqualError(t, err, "status list localhost:8080/status/1 is full")
 
-		cred.ID = credID
-		cred.Status = &verifiable.TypedID{Type: RevocationList2020Status, CustomFields: map[string]interface{}{
-			RevocationListIndex: "1", RevocationListCredential: 1,
-		}}
-		err = s.UpdateVC(cred, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to cast status revocationListCredential")
-	})
+	used, err := w.usedIndexes()
+	require.NoError(t, err)
 
-	t.Run("test success", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	free, err := freeIndexes(used, length)
+	require.NoError(t, err)
+	require.Empty(t, free)
+}
+
+func TestSetStatus(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	newManager := func(t *testing.T) *CredentialStatusManager {
+		t.Helper()
+
+		s, err := New(ariesmemstorage.NewProvider(), 2, vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
+			&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
 		require.NoError(t, err)
 
-		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status")
+		return s
+	}
+
+	t.Run("StatusList2021 credential gets suspension entry", func(t *testing.T) {
+		s := newManager(t)
+
+		status, err := s.CreateStatusID(getTestProfile(), "localhost:8080/status", WithStatusType(StatusList2021Entry))
 		require.NoError(t, err)
 
-		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred),
+		cred, err := verifiable.ParseCredential([]byte(universityDegreeCred), verifiable.WithDisabledProofCheck(),
 			verifiable.WithJSONLDDocumentLoader(loader))
 		require.NoError(t, err)
 
-		cred.ID = credID
-		cred.Status = status
-		require.NoError(t, s.UpdateVC(cred, getTestProfile(), true))
+		SetStatus(cred, status)
+		require.Equal(t, status, cred.Status)
+		require.Contains(t, cred.Context, StatusList2021Context)
 
-		revocationListVCBytes, err := s.GetRevocationListVC(status.CustomFields["revocationListCredential"].(string))
-		require.NoError(t, err)
-		revocationListIndex, err := strconv.Atoi(status.CustomFields[RevocationListIndex].(string))
+		credBytes, err := cred.MarshalJSON()
 		require.NoError(t, err)
 
-		revocationListVC, err := verifiable.ParseCredential(revocationListVCBytes, verifiable.WithDisabledProofCheck(),
-			verifiable.WithJSONLDDocumentLoader(loader))
+		cred, err = verifiable.ParseCredential(credBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader), verifiable.WithStrictValidation())
 		require.NoError(t, err)
-		credSubject, ok := revocationListVC.Subject.([]verifiable.Subject)
-		require.True(t, ok)
-		require.NotEmpty(t, credSubject[0].CustomFields["encodedList"].(string))
-		bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+
+		suspensionStatus, err := GetSuspensionStatus(cred)
 		require.NoError(t, err)
-		bitSet, err := bitString.Get(revocationListIndex)
+		require.Equal(t, StatusList2021Entry, suspensionStatus.Type)
+		require.Equal(t, StatusPurposeSuspension, suspensionStatus.CustomFields[StatusPurpose])
+		require.Equal(t, status.CustomFields[StatusListIndex], suspensionStatus.CustomFields[StatusListIndex])
+		require.Equal(t, SuspensionListID(status.CustomFields[StatusListCredential].(string)),
+			suspensionStatus.CustomFields[StatusListCredential])
+
+		// the suspension list is published along with the revocation list
+		_, err = s.GetRevocationListVC(suspensionStatus.CustomFields[StatusListCredential].(string))
 		require.NoError(t, err)
-		require.True(t, bitSet)
 	})
 
-	t.Run("test error get csl from store", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(&storeProvider{store: &mockStore{getFunc: func(k string) (bytes []byte, err error) {
-			return nil, fmt.Errorf("get error")
-		}}}, 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
+	t.Run("RevocationList2020 credential has no suspension entry", func(t *testing.T) {
+		status, err := newManager(t).CreateStatusID(getTestProfile(), "localhost:8080/status")
 		require.NoError(t, err)
 
-		err = s.UpdateVC(&verifiable.Credential{
-			ID: credID,
-			Status: &verifiable.TypedID{
-				ID: "test", Type: RevocationList2020Status,
-				CustomFields: map[string]interface{}{
-					RevocationListCredential: "test",
-					RevocationListIndex:      "1",
-				},
-			},
-		}, getTestProfile(), true)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to get csl from store")
-	})
+		cred := &verifiable.Credential{}
 
-	t.Run("test error from sign status credential", func(t *testing.T) {
-		loader := testutil.DocumentLoader(t)
-		s, err := New(ariesmockstorage.NewMockStoreProvider(), 2,
-			vccrypto.New(&mockkms.KeyManager{}, &cryptomock.Crypto{SignErr: fmt.Errorf("failed to sign")},
-				&vdrmock.MockVDRegistry{ResolveValue: createDIDDoc("did:test:abc")}, loader), loader)
-		require.NoError(t, err)
+		SetStatus(cred, status)
+		require.Equal(t, []string{Context}, cred.Context)
 
-		_, err = s.CreateStatusID(getTestProfile(), "localhost:8080/status")
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to sign vc")
+		suspensionStatus, err := GetSuspensionStatus(cred)
+		require.NoError(t, err)
+		require.Nil(t, suspensionStatus)
 	})
 }
 
 func TestPrepareSigningOpts(t *testing.T) {
 	t.Run("prepare signing opts", func(t *testing.T) {
 		profile := &vcprofile.DataProfile{
+			DID:     "did:creator",
 			Creator: "did:creator#key-1",
 		}
 
@@ -415,6 +1408,17 @@ func TestPrepareSigningOpts(t *testing.T) {
     				}`,
 				count: 3,
 			},
+			{
+				name: "prepare signing opts from proof of a rotated key",
+				proof: `{
+        				"created": "2020-04-17T04:17:48Z",
+        				"proofPurpose": "assertionMethod",
+        				"jws": "CAQJKqd0MELydkNdPh7TIwgKhcMt_ypQd8ejsNbHZCJDRptPkBuqAQ",
+        				"type": "Ed25519Signature2018",
+        				"verificationMethod": "did:creator#key-0"
+    				}`,
+				count: 3,
+			},
 			{
 				name: "prepare signing opts from proof with 3 required properties",
 				proof: `{
@@ -509,6 +1513,19 @@ func getTestProfile() *vcprofile.DataProfile {
 	}
 }
 
+// countingCrypto counts signed credentials.
+type countingCrypto struct {
+	crypto *vccrypto.Crypto
+	calls  int
+}
+
+func (c *countingCrypto) SignCredential(dataProfile *vcprofile.DataProfile, vc *verifiable.Credential,
+	opts ...vccrypto.SigningOpts) (*verifiable.Credential, error) {
+	c.calls++
+
+	return c.crypto.SignCredential(dataProfile, vc, opts...)
+}
+
 // storeProvider mock store provider.
 type storeProvider struct {
 	store *mockStore
@@ -600,7 +1617,7 @@ func (s *mockStore) Query(expression string, _ ...storage.QueryOption) (storage.
 }
 
 func (s *mockStore) Delete(k string) error {
-	panic("implement me")
+	return nil
 }
 
 // nolint: unparam
@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package csl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+const (
+	historyKeyPrefix = "history_"
+
+	// versions are tagged with their list and the time they were published at
+	versionTagName        = "statusListVersion"
+	versionUpdatedTagName = "statusListUpdated"
+)
+
+// statusListVersion is the signed status list vc published at the given time.
+type statusListVersion struct {
+	updated time.Time
+	vc      []byte
+}
+
+// GetStatusListVCAt returns the status list vc as it was published at the given time.
+// Versions are kept since status list history was introduced, earlier times are reported as not found.
+func (c *CredentialStatusManager) GetStatusListVCAt(id string, t time.Time) (*StatusListVC, error) {
+	cslWrapper, err := c.getCSLWrapper(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revocationListVC from store: %w", err)
+	}
+
+	versions, err := c.getVersions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i].updated.After(t)
+	})
+
+	if i > 0 {
+		return &StatusListVC{VC: versions[i-1].vc, Updated: &versions[i-1].updated}, nil
+	}
+
+	// only a list without any kept version that hasn't changed since the given time can still be returned
+	if len(versions) > 0 || cslWrapper.Updated == nil || t.Before(*cslWrapper.Updated) {
+		return nil, fmt.Errorf("status list %s at %s: %w", id, t.Format(time.RFC3339),
+			ariesstorage.ErrDataNotFound)
+	}
+
+	return &StatusListVC{VC: cslWrapper.VCByte, Updated: cslWrapper.Updated}, nil
+}
+
+// publishCSL stores the newly signed status list as a version of the list, then as the current list.
+// The version is deleted if the list fails to be stored.
+func (c *CredentialStatusManager) publishCSL(cslWrapper *cslWrapper) error {
+	id := cslWrapper.VC.ID
+	key := fmt.Sprintf("%s%s_%s", historyKeyPrefix, id, uuid.New().String())
+
+	err := c.store.Put(key, cslWrapper.VCByte,
+		ariesstorage.Tag{Name: versionTagName, Value: versionTagValue(id)},
+		ariesstorage.Tag{Name: versionUpdatedTagName, Value: strconv.FormatInt(cslWrapper.Updated.UnixNano(), 10)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store status list version in store: %w", err)
+	}
+
+	if err := c.storeCSL(cslWrapper); err != nil {
+		if errDelete := c.store.Delete(key); errDelete != nil {
+			logger.Warnf("failed to delete status list version %s: %s", key, errDelete)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// getVersions returns the versions of the list, oldest first.
+func (c *CredentialStatusManager) getVersions(id string) ([]*statusListVersion, error) {
+	iter, err := c.store.Query(versionTagName + ":" + versionTagValue(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status list versions: %w", err)
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Warnf("failed to close status list version iterator: %s", errClose)
+		}
+	}()
+
+	var versions []*statusListVersion
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query status list versions: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		version, err := readVersion(iter)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].updated.Before(versions[j].updated) })
+
+	return versions, nil
+}
+
+func readVersion(iter ariesstorage.Iterator) (*statusListVersion, error) {
+	tags, err := iter.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status list versions: %w", err)
+	}
+
+	version := &statusListVersion{}
+
+	for _, tag := range tags {
+		if tag.Name != versionUpdatedTagName {
+			continue
+		}
+
+		nanos, errParse := strconv.ParseInt(tag.Value, 10, 64)
+		if errParse != nil {
+			return nil, fmt.Errorf("invalid status list version time: %w", errParse)
+		}
+
+		version.updated = time.Unix(0, nanos).UTC()
+	}
+
+	version.vc, err = iter.Value()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status list versions: %w", err)
+	}
+
+	return version, nil
+}
+
+// versionTagValue encodes the list ID, tag values can't contain colons.
+func versionTagValue(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
@@ -10,12 +10,17 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 )
 
 const (
 	bitsPerByte = 8
 	one         = 0x1
+
+	// MaxBitStringLength is the length of the largest bit string DecodeBits accepts, 16 MiB once decompressed
+	MaxBitStringLength = 16 * 1024 * 1024 * bitsPerByte
 )
 
 // BitString struct
@@ -30,7 +35,9 @@ func NewBitString(length int) *BitString {
 	return &BitString{bits: make([]byte, size), numBits: length}
 }
 
-// DecodeBits decode bits
+// DecodeBits decode bits.
+// Encoded bits come from status lists published by others, so the input has to be a single GZIP stream
+// that decompresses to at most MaxBitStringLength bits.
 func DecodeBits(encodedBits string) (*BitString, error) {
 	decodedBits, err := base64.RawURLEncoding.DecodeString(encodedBits)
 	if err != nil {
@@ -44,12 +51,32 @@ func DecodeBits(encodedBits string) (*BitString, error) {
 		return nil, err
 	}
 
+	// data following the first stream is malformed input rather than another stream to decompress
+	r.Multistream(false)
+
 	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(r); err != nil {
+	if _, err := buf.ReadFrom(io.LimitReader(r, MaxBitStringLength/bitsPerByte+1)); err != nil {
 		return nil, err
 	}
 
-	return &BitString{bits: buf.Bytes()}, nil
+	if buf.Len() > MaxBitStringLength/bitsPerByte {
+		return nil, fmt.Errorf("bit string exceeds maximum length of %d bits", MaxBitStringLength)
+	}
+
+	if buf.Len() == 0 {
+		return nil, errors.New("bit string is empty")
+	}
+
+	if b.Len() != 0 {
+		return nil, errors.New("unexpected data after compressed bit string")
+	}
+
+	return &BitString{bits: buf.Bytes(), numBits: buf.Len() * bitsPerByte}, nil
+}
+
+// Len returns number of bits in the bit string
+func (b *BitString) Len() int {
+	return b.numBits
 }
 
 // Set bit
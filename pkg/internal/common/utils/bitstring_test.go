@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package utils
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,6 +34,48 @@ func TestBitString(t *testing.T) {
 		require.Contains(t, err.Error(), "illegal base64 data at input")
 	})
 
+	t.Run("test error decode malformed or oversized bits", func(t *testing.T) {
+		compress := func(data []byte) []byte {
+			var buf bytes.Buffer
+
+			w := gzip.NewWriter(&buf)
+			_, err := w.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			return buf.Bytes()
+		}
+
+		encode := base64.RawURLEncoding.EncodeToString
+
+		_, err := DecodeBits(encode([]byte("not a gzip stream at all")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "gzip: invalid header")
+
+		corrupted := compress([]byte{1, 2, 3})
+		corrupted[len(corrupted)-5]++
+
+		_, err = DecodeBits(encode(corrupted))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "gzip: invalid checksum")
+
+		_, err = DecodeBits(encode(compress(nil)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bit string is empty")
+
+		_, err = DecodeBits(encode(append(compress([]byte{1}), compress([]byte{2})...)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected data after compressed bit string")
+
+		bitString, err := DecodeBits(encode(compress(make([]byte, MaxBitStringLength/bitsPerByte))))
+		require.NoError(t, err)
+		require.Equal(t, MaxBitStringLength, bitString.Len())
+
+		_, err = DecodeBits(encode(compress(make([]byte, MaxBitStringLength/bitsPerByte+1))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bit string exceeds maximum length")
+	})
+
 	t.Run("test success", func(t *testing.T) {
 		bitString := NewBitString(17)
 
@@ -50,6 +95,7 @@ func TestBitString(t *testing.T) {
 
 		bitStr, err := DecodeBits(encodeBits)
 		require.NoError(t, err)
+		require.Equal(t, 24, bitStr.Len())
 
 		bitSet, err = bitStr.Get(1)
 		require.NoError(t, err)
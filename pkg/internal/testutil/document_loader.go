@@ -29,6 +29,8 @@ var (
 	governance []byte
 	//go:embed contexts/lds-jws2020-v1.jsonld
 	jws2020 []byte
+	//go:embed contexts/status-list-2021-v1.jsonld
+	statusList2021 []byte
 )
 
 // DocumentLoader returns a document loader with preloaded test contexts.
@@ -62,6 +64,11 @@ func DocumentLoader(t *testing.T) *jsonld.DocumentLoader {
 				URL:     "https://w3c-ccg.github.io/lds-jws2020/contexts/lds-jws2020-v1.json",
 				Content: jws2020,
 			},
+			jsonld.ContextDocument{
+				URL:         "https://w3id.org/vc/status-list/2021/v1",
+				DocumentURL: "https://w3c-ccg.github.io/vc-status-list-2021/contexts/v1.jsonld",
+				Content:     statusList2021,
+			},
 		),
 	)
 	require.NoError(t, err)
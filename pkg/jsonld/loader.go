@@ -21,6 +21,8 @@ var (
 	jws2020V1Vocab []byte
 	//go:embed contexts/governance.jsonld
 	governanceVocab []byte
+	//go:embed contexts/status-list-2021-v1.jsonld
+	statusList2021Vocab []byte
 )
 
 var embedContexts = []jsonld.ContextDocument{ //nolint:gochecknoglobals
@@ -32,6 +34,11 @@ var embedContexts = []jsonld.ContextDocument{ //nolint:gochecknoglobals
 		URL:     "https://trustbloc.github.io/context/governance/context.jsonld",
 		Content: governanceVocab,
 	},
+	{
+		URL:         "https://w3id.org/vc/status-list/2021/v1",
+		DocumentURL: "https://w3c-ccg.github.io/vc-status-list-2021/contexts/v1.jsonld",
+		Content:     statusList2021Vocab,
+	},
 }
 
 // DocumentLoader returns a JSON-LD document loader with preloaded contexts.
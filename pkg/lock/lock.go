@@ -0,0 +1,337 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package lock serializes read-modify-write of the data kept in the store, like status lists, profiles and
+// challenges, within one process or across the instances sharing the store.
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+)
+
+var logger = log.New("edge-service-lock")
+
+const (
+	lockStore = "lock"
+
+	defaultLockLease  = 30 * time.Second
+	defaultLockWait   = time.Minute
+	lockSettleTime    = 50 * time.Millisecond
+	lockRetryInterval = 20 * time.Millisecond
+
+	// leaseRenewals is the number of times a held lease is renewed within the lease time
+	leaseRenewals = 3
+)
+
+// Locker guards read-modify-write of the data kept in the store.
+// The in-memory locker only serializes updates within one process, vc-rest instances sharing
+// the store need a locker backed by the store, like StoreLocker.
+type Locker interface {
+	// Lock blocks until the lock for the given key is acquired and returns the function releasing it.
+	Lock(key string) (func(), error)
+}
+
+// memLocker is in-memory Locker holding one mutex per key in use.
+type memLocker struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newMemLocker() *memLocker {
+	return &memLocker{locks: make(map[string]*keyLock)}
+}
+
+// NewMemLocker returns new in-memory locker, it only serializes updates within one process.
+func NewMemLocker() Locker {
+	return newMemLocker()
+}
+
+// Lock locks the given key.
+func (l *memLocker) Lock(key string) (func(), error) {
+	l.mu.Lock()
+
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+
+	kl.refs++
+
+	l.mu.Unlock()
+
+	kl.Lock()
+
+	return func() {
+		kl.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		kl.refs--
+
+		if kl.refs == 0 {
+			delete(l.locks, key)
+		}
+	}, nil
+}
+
+// StoreLocker is Locker keeping the locks in the store shared by all the vc-rest instances and by the services
+// of one instance. A lock is a lease which is renewed while the lock is held and expires
+// after the lease time once it isn't, so that locks of a crashed instance are released.
+//
+// The locking is best-effort only: the store has no compare-and-swap, so the lease is written and read back after
+// a settle time and the instance whose lease was overwritten meanwhile keeps waiting. A write of another instance
+// landing after the settle time, or a lease not renewed in time, lets two instances hold the lock at once. Status
+// lists are versioned so that an update based on a list changed meanwhile fails rather than being lost, but the
+// check isn't atomic either.
+type StoreLocker struct {
+	store  ariesstorage.Store
+	local  *memLocker
+	lease  time.Duration
+	wait   time.Duration
+	settle time.Duration
+	retry  time.Duration
+}
+
+// StoreLockerOpt configures the store locker
+type StoreLockerOpt func(l *StoreLocker)
+
+// WithLockLease is an option to pass the time a lock is held for at most, 30 seconds is used if not set
+func WithLockLease(lease time.Duration) StoreLockerOpt {
+	return func(l *StoreLocker) {
+		l.lease = lease
+	}
+}
+
+// WithLockWait is an option to pass the time to wait for a lock before giving up, one minute is used if not set
+func WithLockWait(wait time.Duration) StoreLockerOpt {
+	return func(l *StoreLocker) {
+		l.wait = wait
+	}
+}
+
+// WithLockSettle is an option to pass the time a written lease is left to settle before it's read back, longer
+// times make a race with a slow write of another instance less likely, 50 milliseconds is used if not set
+func WithLockSettle(settle time.Duration) StoreLockerOpt {
+	return func(l *StoreLocker) {
+		l.settle = settle
+	}
+}
+
+// lockLease is the lock record kept in the store.
+type lockLease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewStoreLocker returns new locker keeping the locks in the given store provider.
+func NewStoreLocker(provider ariesstorage.Provider, opts ...StoreLockerOpt) (*StoreLocker, error) {
+	store, err := provider.OpenStore(lockStore)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &StoreLocker{
+		store:  store,
+		local:  newMemLocker(),
+		lease:  defaultLockLease,
+		wait:   defaultLockWait,
+		settle: lockSettleTime,
+		retry:  lockRetryInterval,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l, nil
+}
+
+// Lock locks the given key, the lock is taken within the process first so that only one request
+// of the instance competes for the lease.
+func (l *StoreLocker) Lock(key string) (func(), error) {
+	unlockLocal, err := l.local.Lock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		unlockLocal()
+
+		return nil, err
+	}
+
+	deadline := time.Now().Add(l.wait)
+
+	for {
+		acquired, err := l.tryLock(key, owner)
+		if err != nil {
+			unlockLocal()
+
+			return nil, err
+		}
+
+		if acquired {
+			stopRenewal := l.renew(key, owner)
+
+			return func() {
+				stopRenewal()
+				l.release(key, owner)
+				unlockLocal()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			unlockLocal()
+
+			return nil, fmt.Errorf("timed out waiting for lock %s", key)
+		}
+
+		time.Sleep(l.retry)
+	}
+}
+
+func (l *StoreLocker) tryLock(key, owner string) (bool, error) {
+	current, err := l.getLease(key)
+	if err != nil {
+		return false, err
+	}
+
+	if current != nil && time.Now().Before(current.Expires) {
+		return false, nil
+	}
+
+	if err = l.putLease(key, owner); err != nil {
+		return false, err
+	}
+
+	// another instance may have found the lock free at the same time, the lease written last wins
+	time.Sleep(l.settle)
+
+	current, err = l.getLease(key)
+	if err != nil {
+		return false, err
+	}
+
+	return current != nil && current.Owner == owner, nil
+}
+
+// renew extends the lease while the lock is held, until the returned function is called. Leases taken over by
+// another instance meanwhile aren't extended.
+func (l *StoreLocker) renew(key, owner string) func() {
+	interval := l.lease / leaseRenewals
+	if interval <= 0 {
+		interval = l.lease
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := l.extend(key, owner); err != nil {
+					logger.Warnf("failed to renew lock %s: %s", key, err.Error())
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (l *StoreLocker) extend(key, owner string) error {
+	current, err := l.getLease(key)
+	if err != nil {
+		return err
+	}
+
+	if current == nil || current.Owner != owner {
+		return errors.New("lock was taken over")
+	}
+
+	return l.putLease(key, owner)
+}
+
+func (l *StoreLocker) putLease(key, owner string) error {
+	leaseBytes, err := json.Marshal(&lockLease{Owner: owner, Expires: time.Now().Add(l.lease)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock: %w", err)
+	}
+
+	if err = l.store.Put(key, leaseBytes); err != nil {
+		return fmt.Errorf("failed to store lock: %w", err)
+	}
+
+	return nil
+}
+
+// release deletes the lease, unless it expired and was taken over by another instance meanwhile.
+func (l *StoreLocker) release(key, owner string) {
+	current, err := l.getLease(key)
+	if err != nil || current == nil || current.Owner != owner {
+		return
+	}
+
+	if err := l.store.Delete(key); err != nil {
+		logger.Warnf("failed to release lock %s: %s", key, err.Error())
+	}
+}
+
+func (l *StoreLocker) getLease(key string) (*lockLease, error) {
+	leaseBytes, err := l.store.Get(key)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get lock: %w", err)
+	}
+
+	lease := &lockLease{}
+	if err := json.Unmarshal(leaseBytes, lease); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock: %w", err)
+	}
+
+	return lease, nil
+}
+
+func newLockOwner() (string, error) {
+	b := make([]byte, 16) // nolint: gomnd
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemLocker(t *testing.T) {
+	l := newMemLocker()
+
+	unlock, err := l.Lock("key")
+	require.NoError(t, err)
+
+	locked := make(chan struct{})
+
+	go func() {
+		unlockOther, errLock := l.Lock("key")
+		require.NoError(t, errLock)
+
+		close(locked)
+		unlockOther()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock acquired twice")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	<-locked
+
+	// unused locks are dropped
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		return len(l.locks) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestStoreLocker(t *testing.T) {
+	t.Run("test error from open store", func(t *testing.T) {
+		_, err := NewStoreLocker(&ariesmockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("error open")})
+		require.EqualError(t, err, "error open")
+	})
+
+	t.Run("lock is exclusive across lockers and released", func(t *testing.T) {
+		provider := ariesmemstorage.NewProvider()
+
+		l1, err := NewStoreLocker(provider, WithLockWait(50*time.Millisecond))
+		require.NoError(t, err)
+
+		l2, err := NewStoreLocker(provider, WithLockWait(50*time.Millisecond))
+		require.NoError(t, err)
+
+		unlock, err := l1.Lock("list")
+		require.NoError(t, err)
+
+		_, err = l2.Lock("list")
+		require.EqualError(t, err, "timed out waiting for lock list")
+
+		unlock()
+
+		unlock, err = l2.Lock("list")
+		require.NoError(t, err)
+		unlock()
+	})
+
+	t.Run("expired lock is taken over", func(t *testing.T) {
+		provider := ariesmemstorage.NewProvider()
+
+		// the lease of a crashed instance isn't renewed
+		l1, err := NewStoreLocker(provider, WithLockLease(time.Millisecond))
+		require.NoError(t, err)
+
+		require.NoError(t, l1.putLease("list", "crashed"))
+
+		l2, err := NewStoreLocker(provider)
+		require.NoError(t, err)
+
+		unlock, err := l2.Lock("list")
+		require.NoError(t, err)
+
+		// the lock taken over isn't released or renewed by its former owner
+		l1.release("list", "crashed")
+		require.EqualError(t, l1.extend("list", "crashed"), "lock was taken over")
+
+		lease, err := l2.getLease("list")
+		require.NoError(t, err)
+		require.NotNil(t, lease)
+
+		unlock()
+
+		lease, err = l2.getLease("list")
+		require.NoError(t, err)
+		require.Nil(t, lease)
+	})
+
+	t.Run("lease is renewed while the lock is held", func(t *testing.T) {
+		provider := ariesmemstorage.NewProvider()
+
+		l1, err := NewStoreLocker(provider, WithLockLease(30*time.Millisecond))
+		require.NoError(t, err)
+
+		l1.settle = time.Millisecond
+
+		l2, err := NewStoreLocker(provider, WithLockWait(100*time.Millisecond))
+		require.NoError(t, err)
+
+		unlock, err := l1.Lock("list")
+		require.NoError(t, err)
+
+		_, err = l2.Lock("list")
+		require.EqualError(t, err, "timed out waiting for lock list")
+
+		unlock()
+	})
+
+	t.Run("test error from store", func(t *testing.T) {
+		l, err := NewStoreLocker(&ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+			Store: make(map[string]ariesmockstorage.DBEntry), ErrPut: errors.New("put error"),
+		}})
+		require.NoError(t, err)
+
+		_, err = l.Lock("list")
+		require.EqualError(t, err, "failed to store lock: put error")
+	})
+}
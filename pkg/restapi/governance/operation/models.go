@@ -9,6 +9,7 @@ package operation
 import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -22,9 +23,24 @@ type GovernanceProfileRequest struct {
 	DIDKeyType              string                             `json:"didKeyType"`
 	DIDKeyID                string                             `json:"didKeyID"`
 	UNIRegistrar            model.UNIRegistrar                 `json:"uniRegistrar,omitempty"`
+	VCStatusListSize        int                                `json:"vcStatusListSize,omitempty"`
+	VCStatusListBitLength   int                                `json:"vcStatusListBitLength,omitempty"`
 }
 
 // IssueCredentialRequest request for issuing credential.
 type IssueCredentialRequest struct {
 	DID string `json:"did,omitempty"`
 }
+
+// UpdateGovernanceProfileRequest request for updating the mutable fields of a governance profile.
+type UpdateGovernanceProfileRequest struct {
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+}
+
+// ListGovernanceProfilesResponse page of governance profiles.
+type ListGovernanceProfilesResponse struct {
+	Profiles []*vcprofile.GovernanceProfile `json:"profiles"`
+	Page     int                            `json:"page"`
+	PageSize int                            `json:"pageSize"`
+	Total    int                            `json:"total"`
+}
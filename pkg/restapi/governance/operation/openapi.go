@@ -34,6 +34,43 @@ type governanceProfileReq struct { // nolint: unused,deadcode
 	Params GovernanceProfileRequest
 }
 
+// listGovernanceProfilesReq model
+//
+// swagger:parameters listGovernanceProfilesReq
+type listGovernanceProfilesReq struct { // nolint: unused,deadcode
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// listGovernanceProfilesRes model
+//
+// swagger:response listGovernanceProfilesRes
+type listGovernanceProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	Body ListGovernanceProfilesResponse
+}
+
+// updateGovernanceProfileReq model
+//
+// swagger:parameters updateGovernanceProfileReq
+type updateGovernanceProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateGovernanceProfileRequest
+}
+
 // issueGovernanceCredentialReq model
 //
 // swagger:parameters issueGovernanceCredentialReq
@@ -30,6 +30,7 @@ import (
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
+	"github.com/trustbloc/edge-service/pkg/lock"
 	commondid "github.com/trustbloc/edge-service/pkg/restapi/internal/common/did"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
@@ -43,12 +44,14 @@ const (
 	profileIDPathParam = "profileID"
 
 	// governance endpoints
-	governanceProfileEndpoint = "/governance/profile"
-	issueCredentialHandler    = "/governance/" + "{" + profileIDPathParam + "}" + "/issueCredential"
-	credentialStatus          = "/governance/status"
+	governanceProfileEndpoint       = "/governance/profile"
+	updateGovernanceProfileEndpoint = governanceProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
+	issueCredentialHandler          = "/governance/" + "{" + profileIDPathParam + "}" + "/issueCredential"
+	credentialStatus                = "/governance/status"
 
 	invalidRequestErrMsg = "Invalid request"
 
+	// cslSize is the number of credentials per status list of profiles not setting it
 	cslSize = 50
 )
 
@@ -65,7 +68,8 @@ type commonDID interface {
 }
 
 type vcStatusManager interface {
-	CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error)
+	CreateStatusID(profile *vcprofile.DataProfile, url string,
+		opts ...cslstatus.StatusOpts) (*verifiable.TypedID, error)
 }
 
 // New returns governance operation instance
@@ -88,7 +92,12 @@ func New(config *Config) (*Operation, error) {
 
 	c := crypto.New(config.KeyManager, config.Crypto, config.VDRI, config.DocumentLoader)
 
-	vcStatusManager, err := cslstatus.New(config.StoreProvider, cslSize, c, config.DocumentLoader)
+	var statusOpts []cslstatus.Opt
+	if config.StatusListLocker != nil {
+		statusOpts = append(statusOpts, cslstatus.WithLocker(config.StatusListLocker))
+	}
+
+	vcStatusManager, err := cslstatus.New(config.StoreProvider, cslSize, c, config.DocumentLoader, statusOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to instantiate new csl status: %w", err)
 	}
@@ -98,8 +107,14 @@ func New(config *Config) (*Operation, error) {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	profileLocker := config.ProfileLocker
+	if profileLocker == nil {
+		profileLocker = lock.NewMemLocker()
+	}
+
 	svc := &Operation{
-		profileStore: p,
+		profileStore:  p,
+		profileLocker: profileLocker,
 		commonDID: commondid.New(&commondid.Config{
 			VDRI: config.VDRI, KeyManager: config.KeyManager,
 			Domain: config.Domain, TLSConfig: config.TLSConfig,
@@ -127,6 +142,10 @@ type Config struct {
 	ClaimsFile      string
 	DIDAnchorOrigin string
 	DocumentLoader  ld.DocumentLoader
+	// StatusListLocker guards updates of the status lists shared with other instances, in-memory locker if not set
+	StatusListLocker cslstatus.Locker
+	// ProfileLocker guards updates of the profiles shared with other instances, in-memory locker if not set
+	ProfileLocker lock.Locker
 }
 
 type keyManager interface {
@@ -137,6 +156,7 @@ type keyManager interface {
 type Operation struct {
 	commonDID               commonDID
 	profileStore            *vcprofile.Profile
+	profileLocker           lock.Locker
 	crypto                  *crypto.Crypto
 	vcStatusManager         vcStatusManager
 	claims                  []byte
@@ -149,6 +169,9 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		// governance profile
 		support.NewHTTPHandler(governanceProfileEndpoint, http.MethodPost, o.createGovernanceProfileHandler),
+		support.NewHTTPHandler(governanceProfileEndpoint, http.MethodGet, o.listGovernanceProfilesHandler),
+		support.NewHTTPHandler(updateGovernanceProfileEndpoint, http.MethodPut, o.updateGovernanceProfileHandler),
+		support.NewHTTPHandler(updateGovernanceProfileEndpoint, http.MethodPatch, o.patchGovernanceProfileHandler),
 		support.NewHTTPHandler(issueCredentialHandler, http.MethodPost, o.issueCredentialHandler),
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
@@ -251,7 +274,8 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 
 	// set credential status
 	credential.Status, err = o.vcStatusManager.CreateStatusID(profile.DataProfile,
-		o.hostURL+"/"+profileID+credentialStatus)
+		o.hostURL+"/"+profileID+credentialStatus, cslstatus.WithListSize(profile.VCStatusListSize),
+		cslstatus.WithBitStringLength(profile.VCStatusListBitLength))
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to add credential status:"+
 			" %s", err.Error()))
@@ -287,6 +311,8 @@ func (o *Operation) createGovernanceProfile(pr *GovernanceProfileRequest) (*vcpr
 	created := time.Now().UTC()
 
 	return &vcprofile.GovernanceProfile{
+		VCStatusListSize:      pr.VCStatusListSize,
+		VCStatusListBitLength: pr.VCStatusListBitLength,
 		DataProfile: &vcprofile.DataProfile{
 			Name:                    pr.Name,
 			Created:                 &created,
@@ -303,6 +329,15 @@ func validateGovernanceProfileRequest(pr *GovernanceProfileRequest) error {
 		return fmt.Errorf("missing profile name")
 	}
 
+	if pr.VCStatusListSize < 0 || pr.VCStatusListSize > cslstatus.MaxBitStringLength {
+		return fmt.Errorf("invalid credential status list size : %d", pr.VCStatusListSize)
+	}
+
+	if pr.VCStatusListBitLength < 0 || pr.VCStatusListBitLength > cslstatus.MaxBitStringLength ||
+		(pr.VCStatusListBitLength > 0 && pr.VCStatusListBitLength < pr.VCStatusListSize) {
+		return fmt.Errorf("invalid credential status list bit length : %d", pr.VCStatusListBitLength)
+	}
+
 	return nil
 }
 
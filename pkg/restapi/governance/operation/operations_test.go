@@ -36,6 +36,7 @@ import (
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
@@ -98,6 +99,30 @@ func TestCreateGovernanceProfile(t *testing.T) {
 		require.Contains(t, rr.Body.String(), "missing profile name")
 	})
 
+	t.Run("create profile - invalid status list size", func(t *testing.T) {
+		vReq := &GovernanceProfileRequest{Name: "test1", VCStatusListSize: -1}
+
+		vReqBytes, err := json.Marshal(vReq)
+		require.NoError(t, err)
+
+		rr := serveHTTP(t, handler.Handle(), http.MethodPost, endpoint, vReqBytes)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid credential status list size : -1")
+	})
+
+	t.Run("create profile - invalid status list bit length", func(t *testing.T) {
+		vReq := &GovernanceProfileRequest{Name: "test1", VCStatusListSize: 100, VCStatusListBitLength: 10}
+
+		vReqBytes, err := json.Marshal(vReq)
+		require.NoError(t, err)
+
+		rr := serveHTTP(t, handler.Handle(), http.MethodPost, endpoint, vReqBytes)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid credential status list bit length : 10")
+	})
+
 	t.Run("create profile - profile already exists", func(t *testing.T) {
 		vReq := &GovernanceProfileRequest{
 			Name:          "test1",
@@ -514,7 +539,8 @@ type mockVCStatusManager struct {
 	GetRevocationListVCErr   error
 }
 
-func (m *mockVCStatusManager) CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error) {
+func (m *mockVCStatusManager) CreateStatusID(profile *vcprofile.DataProfile, url string,
+	opts ...cslstatus.StatusOpts) (*verifiable.TypedID, error) {
 	return m.createStatusIDValue, m.createStatusIDErr
 }
 
@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const profileLockKeyPrefix = "governanceprofile_"
+
+// ListGovernanceProfiles swagger:route GET /governance/profile governance listGovernanceProfilesReq
+//
+// Lists the governance profiles sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listGovernanceProfilesRes
+func (o *Operation) listGovernanceProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	page, pageSize, err := commhttp.GetPage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListGovernanceProfiles(page, pageSize)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListGovernanceProfilesResponse{
+		Profiles: profiles,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// UpdateGovernanceProfile swagger:route PUT /governance/profile/{id} governance updateGovernanceProfileReq
+//
+// Replaces the mutable fields of the governance profile, the ones missing are reset.
+//
+// Responses:
+//    default: genericError
+//        200: governanceProfileRes
+func (o *Operation) updateGovernanceProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateGovernanceProfile(rw, req, true)
+}
+
+// PatchGovernanceProfile swagger:route PATCH /governance/profile/{id} governance updateGovernanceProfileReq
+//
+// Updates the given mutable fields of the governance profile, the ones missing are left unchanged.
+//
+// Responses:
+//    default: genericError
+//        200: governanceProfileRes
+func (o *Operation) patchGovernanceProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateGovernanceProfile(rw, req, false)
+}
+
+func (o *Operation) updateGovernanceProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	unlock, err := o.profileLocker.Lock(profileLockKeyPrefix + profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to lock profile %s: %s", profileID, err.Error()))
+
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetGovernanceProfile(profileID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("profile %s not found", profileID))
+
+			return
+		}
+
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	data := UpdateGovernanceProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if replace {
+		profile.SignatureRepresentation = verifiable.SignatureProofValue
+	}
+
+	if data.SignatureRepresentation != nil {
+		profile.SignatureRepresentation = *data.SignatureRepresentation
+	}
+
+	if err = o.profileStore.SaveGovernanceProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+)
+
+func TestListGovernanceProfiles(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		Crypto:        customCrypto,
+		StoreProvider: ariesmemstorage.NewProvider(),
+		KeyManager:    createKMS(t),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"profile2", "profile1"} {
+		require.NoError(t, op.profileStore.SaveGovernanceProfile(&vcprofile.GovernanceProfile{
+			DataProfile: &vcprofile.DataProfile{Name: name, SignatureType: vccrypto.Ed25519Signature2018},
+		}))
+	}
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTP(t, op.listGovernanceProfilesHandler, http.MethodGet, governanceProfileEndpoint, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListGovernanceProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 2, resp.Total)
+		require.Len(t, resp.Profiles, 2)
+		require.Equal(t, "profile1", resp.Profiles[0].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTP(t, op.listGovernanceProfilesHandler, http.MethodGet,
+			governanceProfileEndpoint+"?pageSize=1000", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid pageSize")
+	})
+}
+
+func TestUpdateGovernanceProfile(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		Crypto:        customCrypto,
+		StoreProvider: ariesmemstorage.NewProvider(),
+		KeyManager:    createKMS(t),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	profile := &vcprofile.GovernanceProfile{
+		DataProfile: &vcprofile.DataProfile{
+			Name:                    "test",
+			DID:                     "did:test:abc",
+			SignatureType:           vccrypto.Ed25519Signature2018,
+			SignatureRepresentation: verifiable.SignatureJWS,
+		},
+	}
+	require.NoError(t, op.profileStore.SaveGovernanceProfile(profile))
+
+	update := func(handler http.HandlerFunc, profileID string, reqBytes []byte) *httptest.ResponseRecorder {
+		r, err := http.NewRequest(http.MethodPut, updateGovernanceProfileEndpoint, bytes.NewBuffer(reqBytes))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler(rr, mux.SetURLVars(r, map[string]string{profileIDPathParam: profileID}))
+
+		return rr
+	}
+
+	t.Run("patch profile - success", func(t *testing.T) {
+		rr := update(op.patchGovernanceProfileHandler, profile.Name, []byte("{}"))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		saved, err := op.profileStore.GetGovernanceProfile(profile.Name)
+		require.NoError(t, err)
+		require.Equal(t, verifiable.SignatureJWS, saved.SignatureRepresentation)
+	})
+
+	t.Run("put profile - success", func(t *testing.T) {
+		rr := update(op.updateGovernanceProfileHandler, profile.Name, []byte("{}"))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		updated := &vcprofile.GovernanceProfile{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), updated))
+		require.Equal(t, verifiable.SignatureProofValue, updated.SignatureRepresentation)
+		require.Equal(t, profile.DID, updated.DID)
+	})
+
+	t.Run("update profile - invalid request", func(t *testing.T) {
+		rr := update(op.patchGovernanceProfileHandler, profile.Name, []byte("{"))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		rr = update(op.patchGovernanceProfileHandler, "other", []byte("{}"))
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile other not found")
+	})
+
+	t.Run("update profile - lock error", func(t *testing.T) {
+		locked, err := New(&Config{
+			Crypto:        customCrypto,
+			StoreProvider: ariesmemstorage.NewProvider(),
+			KeyManager:    createKMS(t),
+			VDRI:          &vdrmock.MockVDRegistry{},
+			ProfileLocker: &mockLocker{err: errors.New("lock error")},
+		})
+		require.NoError(t, err)
+
+		rr := update(locked.patchGovernanceProfileHandler, profile.Name, []byte("{}"))
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to lock profile "+profile.Name+": lock error")
+	})
+}
+
+type mockLocker struct {
+	err error
+}
+
+func (m *mockLocker) Lock(string) (func(), error) {
+	return func() {}, m.err
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -28,6 +29,21 @@ type HolderProfileRequest struct {
 	OverwriteHolder         bool                               `json:"overwriteHolder,omitempty"`
 }
 
+// UpdateHolderProfileRequest request for updating the mutable fields of a holder profile. Fields missing are reset
+// when the profile is replaced and left unchanged when it is patched.
+type UpdateHolderProfileRequest struct {
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+	OverwriteHolder         *bool                               `json:"overwriteHolder,omitempty"`
+}
+
+// ListHolderProfilesResponse is a page of the holder profiles.
+type ListHolderProfilesResponse struct {
+	Profiles []*vcprofile.HolderProfile `json:"profiles"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"pageSize"`
+	Total    int                        `json:"total"`
+}
+
 // SignPresentationRequest request for signing a presentation.
 type SignPresentationRequest struct {
 	Presentation json.RawMessage          `json:"presentation,omitempty"`
@@ -58,6 +74,30 @@ type DeriveCredentialResponse struct {
 	VerifiableCredential json.RawMessage `json:"verifiableCredential,omitempty"`
 }
 
+// DiscloseCredentialRequest is request for presenting an SD-JWT credential disclosing chosen claims only.
+type DiscloseCredentialRequest struct {
+	// Credential is the SD-JWT credential with the disclosures of all its disclosable claims
+	Credential string `json:"credential,omitempty"`
+	// Claims are the names of the claims to disclose
+	Claims []string                   `json:"claims,omitempty"`
+	Opts   *DiscloseCredentialOptions `json:"options,omitempty"`
+}
+
+// DiscloseCredentialOptions options for the key binding of the presentation.
+type DiscloseCredentialOptions struct {
+	// Challenge is the nonce of the key binding JWT
+	Challenge string `json:"challenge,omitempty"`
+	// Domain is the audience of the key binding JWT
+	Domain string `json:"domain,omitempty"`
+}
+
+// DiscloseCredentialResponse is model for disclose credential response.
+type DiscloseCredentialResponse struct {
+	// Presentation is the SD-JWT with the chosen disclosures, followed by the key binding JWT if the credential
+	// is bound to the holder
+	Presentation string `json:"presentation"`
+}
+
 // DeriveCredentialOptions options for derive credential.
 type DeriveCredentialOptions struct {
 	// Nonce to prove uniqueness or freshness of the proof.
@@ -56,6 +56,43 @@ type deleteHolderProfileReq struct { // nolint: unused,deadcode
 	ID string `json:"id"`
 }
 
+// listHolderProfilesReq model
+//
+// swagger:parameters listHolderProfilesReq
+type listHolderProfilesReq struct { // nolint: unused,deadcode
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// listHolderProfilesRes model
+//
+// swagger:response listHolderProfilesRes
+type listHolderProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	Body ListHolderProfilesResponse
+}
+
+// updateHolderProfileReq model
+//
+// swagger:parameters updateHolderProfileReq
+type updateHolderProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateHolderProfileRequest
+}
+
 // signPresentationReq model
 //
 // swagger:parameters signPresentationReq
@@ -99,6 +136,32 @@ type deriveCredentialReq struct { // nolint: unused,deadcode
 	Body DeriveCredentialRequest
 }
 
+// discloseCredentialReq model
+//
+// This is used for presenting an SD-JWT credential.
+//
+// swagger:parameters discloseCredentialReq
+type discloseCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Body DiscloseCredentialRequest
+}
+
+// discloseCredentialRes model
+//
+// This is used for returning the SD-JWT presentation.
+//
+// swagger:response discloseCredentialRes
+type discloseCredentialRes struct { // nolint: unused,deadcode
+	// in: body
+	Body DiscloseCredentialResponse
+}
+
 // deriveCredentialRes model
 //
 // This is used for returning the derive credential response.
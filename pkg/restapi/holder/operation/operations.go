@@ -27,7 +27,10 @@ import (
 
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
+	"github.com/trustbloc/edge-service/pkg/lock"
 	commondid "github.com/trustbloc/edge-service/pkg/restapi/internal/common/did"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
@@ -40,8 +43,10 @@ const (
 	holderProfileEndpoint       = "/holder/profile"
 	getHolderProfileEndpoint    = holderProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
 	deleteHolderProfileEndpoint = holderProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
+	updateHolderProfileEndpoint = holderProfileEndpoint + "/" + "{" + profileIDPathParam + "}"
 	signPresentationEndpoint    = "/" + "{" + profileIDPathParam + "}" + "/prove/presentations"
 	deriveCredentialsEndpoint   = "/" + "{" + profileIDPathParam + "}" + "/credentials/derive"
+	discloseCredentialEndpoint  = "/" + "{" + profileIDPathParam + "}" + "/credentials/disclose"
 
 	invalidRequestErrMsg = "Invalid request"
 )
@@ -70,9 +75,15 @@ func New(config *Config) (*Operation, error) {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	profileLocker := config.ProfileLocker
+	if profileLocker == nil {
+		profileLocker = lock.NewMemLocker()
+	}
+
 	svc := &Operation{
-		vdr:          config.VDRI,
-		profileStore: p,
+		vdr:           config.VDRI,
+		profileStore:  p,
+		profileLocker: profileLocker,
 		commonDID: commondid.New(&commondid.Config{
 			VDRI: config.VDRI, KeyManager: config.KeyManager,
 			Domain: config.Domain, TLSConfig: config.TLSConfig,
@@ -96,6 +107,8 @@ type Config struct {
 	Crypto          ariescrypto.Crypto
 	DIDAnchorOrigin string
 	DocumentLoader  ld.DocumentLoader
+	// ProfileLocker guards updates of the profiles shared with other instances, in-memory locker if not set
+	ProfileLocker lock.Locker
 }
 
 type keyManager interface {
@@ -106,6 +119,7 @@ type keyManager interface {
 type Operation struct {
 	commonDID               commonDID
 	profileStore            *vcprofile.Profile
+	profileLocker           lock.Locker
 	crypto                  *crypto.Crypto
 	vdr                     vdrapi.Registry
 	documentLoader          ld.DocumentLoader
@@ -117,10 +131,14 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		// holder profile
 		support.NewHTTPHandler(holderProfileEndpoint, http.MethodPost, o.createHolderProfileHandler),
+		support.NewHTTPHandler(holderProfileEndpoint, http.MethodGet, o.listHolderProfilesHandler),
 		support.NewHTTPHandler(getHolderProfileEndpoint, http.MethodGet, o.getHolderProfileHandler),
 		support.NewHTTPHandler(deleteHolderProfileEndpoint, http.MethodDelete, o.deleteHolderProfileHandler),
+		support.NewHTTPHandler(updateHolderProfileEndpoint, http.MethodPut, o.updateHolderProfileHandler),
+		support.NewHTTPHandler(updateHolderProfileEndpoint, http.MethodPatch, o.patchHolderProfileHandler),
 		support.NewHTTPHandler(signPresentationEndpoint, http.MethodPost, o.signPresentationHandler),
 		support.NewHTTPHandler(deriveCredentialsEndpoint, http.MethodPost, o.deriveCredentialsHandler),
+		support.NewHTTPHandler(discloseCredentialEndpoint, http.MethodPost, o.discloseCredentialHandler),
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
 	}
@@ -343,6 +361,97 @@ func (o *Operation) deriveCredentialsHandler(rw http.ResponseWriter, req *http.R
 	})
 }
 
+// DiscloseCredential swagger:route POST /{id}/credentials/disclose holder discloseCredentialReq
+//
+// Presents an SD-JWT credential disclosing the chosen claims only.
+//
+// Responses:
+//    default: genericError
+//        201: discloseCredentialRes
+func (o *Operation) discloseCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	// get the holder profile
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid holder profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	discloseReq := DiscloseCredentialRequest{}
+
+	err = json.NewDecoder(req.Body).Decode(&discloseReq)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if discloseReq.Opts == nil {
+		discloseReq.Opts = &DiscloseCredentialOptions{}
+	}
+
+	sdJWT, err := sdjwt.Parse(discloseReq.Credential)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse credential: %s", err.Error()))
+
+		return
+	}
+
+	holderKeyID, err := sdJWT.HolderKeyID()
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse credential: %s", err.Error()))
+
+		return
+	}
+
+	presentation, err := sdJWT.Select(discloseReq.Claims)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if holderKeyID != "" {
+		presentation.KeyBinding, err = o.signKeyBinding(profile, presentation, holderKeyID, discloseReq.Opts)
+		if err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, DiscloseCredentialResponse{Presentation: presentation.Serialize()})
+}
+
+// signKeyBinding signs the key binding JWT of the presentation with the holder key the credential is bound to
+func (o *Operation) signKeyBinding(profile *vcprofile.HolderProfile, presentation *sdjwt.SDJWT, holderKeyID string,
+	opts *DiscloseCredentialOptions) (string, error) {
+	holderDID, err := diddoc.GetDIDFromVerificationMethod(holderKeyID)
+	if err != nil {
+		return "", err
+	}
+
+	if holderDID != profile.DID {
+		return "", fmt.Errorf("credential is bound to %s, not to the profile holder", holderDID)
+	}
+
+	keyBinding, err := o.crypto.SignJWT(profile.DataProfile, &sdjwt.KeyBindingClaims{
+		Nonce:    opts.Challenge,
+		Audience: opts.Domain,
+		IssuedAt: time.Now().Unix(),
+		SDHash:   presentation.SDHash(),
+	}, sdjwt.KeyBindingJWTType, crypto.WithVerificationMethod(holderKeyID), crypto.WithPurpose(crypto.Authentication))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign key binding: %w", err)
+	}
+
+	return keyBinding, nil
+}
+
 func nonceFromDeriveRequestOpts(options *DeriveCredentialOptions) ([]byte, error) {
 	const defaultNonceSize = 50
 
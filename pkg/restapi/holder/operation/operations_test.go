@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/bbsblssignature2020"
@@ -43,6 +44,7 @@ import (
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
@@ -822,6 +824,174 @@ func TestSignPresentation(t *testing.T) {
 	})
 }
 
+func TestDiscloseCredential(t *testing.T) {
+	endpoint := "/test/credentials/disclose"
+	keyID := "key-333"
+	holderDID := "did:test:holder"
+	holderKeyID := holderDID + "#" + keyID
+
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, _, err = customKMS.ImportPrivateKey(privKey, kms.ED25519Type, kms.WithKeyID(keyID))
+	require.NoError(t, err)
+
+	signingKey, err := customKMS.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	vdri := &vdrmock.MockVDRegistry{
+		ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+			return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, signingKey)}, nil
+		},
+	}
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		KeyManager:     customKMS,
+		Crypto:         customCrypto,
+		VDRI:           vdri,
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	profile := &vcprofile.HolderProfile{
+		DataProfile: &vcprofile.DataProfile{
+			Name:          "test",
+			DID:           holderDID,
+			SignatureType: vccrypto.Ed25519Signature2018,
+			Creator:       holderKeyID,
+		},
+	}
+
+	err = op.profileStore.SaveHolderProfile(profile)
+	require.NoError(t, err)
+
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
+
+	handler := getHandler(t, op, discloseCredentialEndpoint, http.MethodPost)
+
+	credential, err := verifiable.ParseCredential([]byte(vcForDerive), verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	credential.Proofs = nil
+
+	// the holder key signs as issuer too, the holder doesn't check the issuer signature
+	signSDJWT := func(t *testing.T, holderVerificationMethod string) string {
+		t.Helper()
+
+		sdJWT, errSign := vccrypto.New(customKMS, customCrypto, vdri, loader).SignCredentialSDJWT(
+			profile.DataProfile, credential, []string{"givenName", "familyName"}, holderVerificationMethod)
+		require.NoError(t, errSign)
+
+		return sdJWT
+	}
+
+	disclose := func(t *testing.T, req *DiscloseCredentialRequest) *httptest.ResponseRecorder {
+		t.Helper()
+
+		reqBytes, errMarshal := json.Marshal(req)
+		require.NoError(t, errMarshal)
+
+		return serveHTTPMux(t, handler, endpoint, reqBytes, urlVars)
+	}
+
+	t.Run("disclose credential - success", func(t *testing.T) {
+		rr := disclose(t, &DiscloseCredentialRequest{
+			Credential: signSDJWT(t, holderKeyID),
+			Claims:     []string{"givenName"},
+			Opts:       &DiscloseCredentialOptions{Challenge: challenge, Domain: domain},
+		})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &DiscloseCredentialResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		presentation, err := sdjwt.Parse(resp.Presentation)
+		require.NoError(t, err)
+		require.Len(t, presentation.Disclosures, 1)
+
+		disclosure, err := sdjwt.DecodeDisclosure(presentation.Disclosures[0])
+		require.NoError(t, err)
+		require.Equal(t, "givenName", disclosure.Name)
+
+		jws, err := jose.ParseJWS(presentation.KeyBinding,
+			vccrypto.NewJWTVerifier(vdri, vccrypto.Authentication))
+		require.NoError(t, err)
+
+		typ, _ := jws.ProtectedHeaders.Type()
+		require.Equal(t, sdjwt.KeyBindingJWTType, typ)
+
+		keyBinding := &sdjwt.KeyBindingClaims{}
+		require.NoError(t, json.Unmarshal(jws.Payload, keyBinding))
+		require.Equal(t, challenge, keyBinding.Nonce)
+		require.Equal(t, domain, keyBinding.Audience)
+		require.Equal(t, presentation.SDHash(), keyBinding.SDHash)
+	})
+
+	t.Run("disclose credential - success without holder binding", func(t *testing.T) {
+		rr := disclose(t, &DiscloseCredentialRequest{
+			Credential: signSDJWT(t, ""),
+			Claims:     []string{"givenName", "familyName"},
+		})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &DiscloseCredentialResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		presentation, err := sdjwt.Parse(resp.Presentation)
+		require.NoError(t, err)
+		require.Len(t, presentation.Disclosures, 2)
+		require.Empty(t, presentation.KeyBinding)
+	})
+
+	t.Run("disclose credential - invalid profile", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&DiscloseCredentialRequest{})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, endpoint, reqBytes, map[string]string{profileIDPathParam: "invalid"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid holder profile")
+	})
+
+	t.Run("disclose credential - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, endpoint, []byte("invalid json"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+
+	t.Run("disclose credential - not a sd-jwt", func(t *testing.T) {
+		rr := disclose(t, &DiscloseCredentialRequest{Credential: vc})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to parse credential")
+	})
+
+	t.Run("disclose credential - claim not disclosable", func(t *testing.T) {
+		rr := disclose(t, &DiscloseCredentialRequest{
+			Credential: signSDJWT(t, ""),
+			Claims:     []string{"birthDate"},
+		})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "no disclosure for claim birthDate")
+	})
+
+	t.Run("disclose credential - bound to other holder", func(t *testing.T) {
+		rr := disclose(t, &DiscloseCredentialRequest{
+			Credential: signSDJWT(t, "did:test:other#"+keyID),
+			Claims:     []string{"givenName"},
+		})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential is bound to did:test:other, not to the profile holder")
+	})
+}
+
 type mockCommonDID struct {
 	createDIDValue string
 	createDIDKeyID string
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const profileLockKeyPrefix = "holderprofile_"
+
+// ListHolderProfiles swagger:route GET /holder/profile holder listHolderProfilesReq
+//
+// Lists the holder profiles sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listHolderProfilesRes
+func (o *Operation) listHolderProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	page, pageSize, err := commhttp.GetPage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListHolderProfiles(page, pageSize)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListHolderProfilesResponse{
+		Profiles: profiles,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// UpdateHolderProfile swagger:route PUT /holder/profile/{id} holder updateHolderProfileReq
+//
+// Replaces the mutable fields of the holder profile, the ones missing are reset.
+//
+// Responses:
+//    default: genericError
+//        200: holderProfileRes
+func (o *Operation) updateHolderProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateHolderProfile(rw, req, true)
+}
+
+// PatchHolderProfile swagger:route PATCH /holder/profile/{id} holder updateHolderProfileReq
+//
+// Updates the given mutable fields of the holder profile, the ones missing are left unchanged.
+//
+// Responses:
+//    default: genericError
+//        200: holderProfileRes
+func (o *Operation) patchHolderProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateHolderProfile(rw, req, false)
+}
+
+func (o *Operation) updateHolderProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	unlock, err := o.profileLocker.Lock(profileLockKeyPrefix + profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to lock profile %s: %s", profileID, err.Error()))
+
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetHolderProfile(profileID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("profile %s not found", profileID))
+
+			return
+		}
+
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	data := UpdateHolderProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if replace {
+		profile.SignatureRepresentation = verifiable.SignatureProofValue
+		profile.OverwriteHolder = false
+	}
+
+	if data.SignatureRepresentation != nil {
+		profile.SignatureRepresentation = *data.SignatureRepresentation
+	}
+
+	if data.OverwriteHolder != nil {
+		profile.OverwriteHolder = *data.OverwriteHolder
+	}
+
+	if err = o.profileStore.SaveHolderProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+)
+
+func TestListHolderProfiles(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		Crypto:        customCrypto,
+		StoreProvider: ariesmemstorage.NewProvider(),
+		KeyManager:    createKMS(t),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"profile2", "profile1", "profile3"} {
+		require.NoError(t, op.profileStore.SaveHolderProfile(&vcprofile.HolderProfile{
+			DataProfile: &vcprofile.DataProfile{Name: name, SignatureType: vccrypto.Ed25519Signature2018},
+		}))
+	}
+
+	handler := getHandler(t, op, holderProfileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, holderProfileEndpoint+"?pageSize=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListHolderProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Equal(t, 0, resp.Page)
+		require.Len(t, resp.Profiles, 2)
+		require.Equal(t, "profile1", resp.Profiles[0].Name)
+		require.Equal(t, "profile2", resp.Profiles[1].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, holderProfileEndpoint+"?page=-1", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid page")
+	})
+}
+
+func TestUpdateHolderProfile(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		Crypto:        customCrypto,
+		StoreProvider: ariesmemstorage.NewProvider(),
+		KeyManager:    createKMS(t),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	profile := &vcprofile.HolderProfile{
+		DataProfile: &vcprofile.DataProfile{
+			Name:                    "test",
+			DID:                     "did:test:abc",
+			SignatureType:           vccrypto.Ed25519Signature2018,
+			SignatureRepresentation: verifiable.SignatureJWS,
+		},
+		OverwriteHolder: true,
+	}
+
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
+
+	update := func(t *testing.T, method string, req interface{}) *vcprofile.HolderProfile {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, updateHolderProfileEndpoint, method), updateHolderProfileEndpoint,
+			reqBytes, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		updated := &vcprofile.HolderProfile{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), updated))
+
+		return updated
+	}
+
+	t.Run("patch profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveHolderProfile(profile))
+
+		overwrite := false
+
+		updated := update(t, http.MethodPatch, &UpdateHolderProfileRequest{OverwriteHolder: &overwrite})
+		require.False(t, updated.OverwriteHolder)
+		require.Equal(t, verifiable.SignatureJWS, updated.SignatureRepresentation)
+		require.Equal(t, profile.DID, updated.DID)
+
+		saved, err := op.profileStore.GetHolderProfile(profile.Name)
+		require.NoError(t, err)
+		require.False(t, saved.OverwriteHolder)
+	})
+
+	t.Run("put profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveHolderProfile(profile))
+
+		updated := update(t, http.MethodPut, &UpdateHolderProfileRequest{})
+		require.False(t, updated.OverwriteHolder)
+		require.Equal(t, verifiable.SignatureProofValue, updated.SignatureRepresentation)
+		require.Equal(t, profile.DID, updated.DID)
+	})
+
+	t.Run("update profile - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, updateHolderProfileEndpoint, http.MethodPatch),
+			updateHolderProfileEndpoint, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		rr = serveHTTPMux(t, getHandler(t, op, updateHolderProfileEndpoint, http.MethodPut),
+			updateHolderProfileEndpoint, []byte("{}"), map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile other not found")
+	})
+
+	t.Run("update profile - lock error", func(t *testing.T) {
+		locked, err := New(&Config{
+			Crypto:        customCrypto,
+			StoreProvider: ariesmemstorage.NewProvider(),
+			KeyManager:    createKMS(t),
+			VDRI:          &vdrmock.MockVDRegistry{},
+			ProfileLocker: &mockLocker{err: errors.New("lock error")},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, locked, updateHolderProfileEndpoint, http.MethodPatch), updateHolderProfileEndpoint,
+			[]byte("{}"), urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to lock profile "+profile.Name+": lock error")
+	})
+}
+
+type mockLocker struct {
+	err error
+}
+
+func (m *mockLocker) Lock(string) (func(), error) {
+	return func() {}, m.err
+}
@@ -12,7 +12,9 @@ import (
 	"crypto/elliptic"
 	"crypto/tls"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
@@ -23,14 +25,19 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	didmethodoperation "github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod/operation"
 
 	"github.com/trustbloc/edge-service/pkg/client/uniregistrar"
+	did2 "github.com/trustbloc/edge-service/pkg/did"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
-const splitDidIDLength = 4
+const (
+	splitDidIDLength  = 4
+	bls12381G2Key2020 = "Bls12381G2Key2020"
+)
 
 // nolint: gochecknoglobals
 var signatureKeyTypeMap = map[string]string{
@@ -46,6 +53,7 @@ type CommonDID struct {
 	domain             string
 	createKey          func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error)
 	didAnchorOrigin    string
+	operationKeys      *did2.OperationKeyStore
 }
 
 // Config defines configuration for vcs operations
@@ -55,6 +63,8 @@ type Config struct {
 	Domain          string
 	TLSConfig       *tls.Config
 	DIDAnchorOrigin string
+	// OperationKeys keeps the update keys of the orb DIDs created, their keys can't be rotated without it
+	OperationKeys *did2.OperationKeyStore
 }
 
 type uniRegistrarClient interface {
@@ -73,6 +83,7 @@ func New(config *Config) *CommonDID {
 		vdr:             config.VDRI,
 		createKey:       createKey,
 		didAnchorOrigin: config.DIDAnchorOrigin,
+		operationKeys:   config.OperationKeys,
 	}
 }
 
@@ -237,19 +248,14 @@ func (o *CommonDID) createDID(keyType, signatureType string) (string, string, er
 		return "", "", fmt.Errorf("failed to create did public key: %w", err)
 	}
 
-	_, recoveryPubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
-	if err != nil {
-		return "", "", err
-	}
-
-	_, updatePubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
+	recoveryPubKey, updatePubKey, operationKeys, err := o.createOperationKeys()
 	if err != nil {
 		return "", "", err
 	}
 
 	opts = append(opts,
-		vdrapi.WithOption(orb.RecoveryPublicKeyOpt, ed25519.PublicKey(recoveryPubKey)),
-		vdrapi.WithOption(orb.UpdatePublicKeyOpt, ed25519.PublicKey(updatePubKey)),
+		vdrapi.WithOption(orb.RecoveryPublicKeyOpt, recoveryPubKey),
+		vdrapi.WithOption(orb.UpdatePublicKeyOpt, updatePubKey),
 		vdrapi.WithOption(orb.AnchorOriginOpt, o.didAnchorOrigin))
 
 	docResolution, err := o.vdr.Create(orb.DIDMethod, didDoc, opts...)
@@ -259,9 +265,182 @@ func (o *CommonDID) createDID(keyType, signatureType string) (string, string, er
 
 	docID := docResolution.DIDDocument.ID
 
+	if operationKeys != nil {
+		if err = o.operationKeys.Save(docID, operationKeys); err != nil {
+			return "", "", fmt.Errorf("failed to save did operation keys: %w", err)
+		}
+	}
+
 	return docID, docID + "#" + selectedKeyID, nil
 }
 
+// createOperationKeys creates the recovery and update keys of a new DID. They are generated to be kept in
+// the operation key store when there is one, so that the DID can be updated, otherwise they are created in the KMS.
+func (o *CommonDID) createOperationKeys() (ed25519.PublicKey, ed25519.PublicKey, *did2.OperationKeys, error) {
+	if o.operationKeys != nil {
+		keys, err := did2.GenerateKeys()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return keys.RecoveryKey.Public().(ed25519.PublicKey), keys.UpdateKey.Public().(ed25519.PublicKey), keys, nil
+	}
+
+	_, recoveryPubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	_, updatePubKey, err := o.createKey(kms.ED25519Type, o.keyManager)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return recoveryPubKey, updatePubKey, nil, nil
+}
+
+// RotateKey adds a new key to the DID and returns its verification method ID, the verification methods
+// of the DID are kept so that what was signed with them still verifies. The private key of a DID managed
+// elsewhere is imported, its verification method has to be added to the DID beforehand. Orb DIDs created
+// by the service get a new key of the same type as the creator key through a DID update, this needs their
+// operation keys so orb DIDs created before the service kept them are rotated like the DIDs managed elsewhere.
+func (o *CommonDID) RotateKey(didID, creator, keyID, privateKey string) (string, error) {
+	docResolution, err := o.vdr.Resolve(didID)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve did: %w", err)
+	}
+
+	if privateKey != "" {
+		vm := findVerificationMethod(docResolution.DIDDocument, keyID)
+		if vm == nil {
+			return "", fmt.Errorf("verification method %s not found in did %s", keyID, didID)
+		}
+
+		keyType, err := importKeyType(vm)
+		if err != nil {
+			return "", err
+		}
+
+		if err = o.importKey(keyID, keyType, base58.Decode(privateKey)); err != nil {
+			return "", err
+		}
+
+		return keyID, nil
+	}
+
+	if !strings.HasPrefix(didID, "did:"+orb.DIDMethod+":") || o.operationKeys == nil {
+		return "", fmt.Errorf("key rotation of did %s needs the private key of a verification method added to it",
+			didID)
+	}
+
+	if _, err = o.operationKeys.Get(didID); err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			return "", fmt.Errorf("operation keys of did %s aren't kept by the service, its key rotation needs "+
+				"the private key of a verification method added to it", didID)
+		}
+
+		return "", err
+	}
+
+	return o.addKey(didID, docResolution.DIDDocument, creator)
+}
+
+// importKeyType returns the KMS key type of the private key of the verification method.
+func importKeyType(vm *did.VerificationMethod) (kms.KeyType, error) {
+	if jwk := vm.JSONWebKey(); jwk != nil {
+		switch jwk.Crv {
+		case crypto.Ed25519KeyType:
+			return kms.ED25519Type, nil
+		case elliptic.P256().Params().Name:
+			return kms.ECDSAP256TypeIEEEP1363, nil
+		}
+
+		return "", fmt.Errorf("import of %s keys isn't supported", jwk.Crv)
+	}
+
+	switch vm.Type {
+	case crypto.Ed25519VerificationKey2018:
+		return kms.ED25519Type, nil
+	case bls12381G2Key2020:
+		return kms.BLS12381G2Type, nil
+	}
+
+	return "", fmt.Errorf("import of %s keys isn't supported", vm.Type)
+}
+
+func (o *CommonDID) addKey(didID string, didDoc *did.Doc, creator string) (string, error) {
+	current := findVerificationMethod(didDoc, creator)
+	if current == nil {
+		return "", fmt.Errorf("verification method %s not found in did %s", creator, didID)
+	}
+
+	keyType := kms.ED25519Type
+	if jwk := current.JSONWebKey(); jwk != nil && jwk.Crv == elliptic.P256().Params().Name {
+		keyType = kms.ECDSAP256IEEEP1363
+	}
+
+	keyID, pubKeyBytes, err := o.createKey(keyType, o.keyManager)
+	if err != nil {
+		return "", err
+	}
+
+	var pubKey interface{} = ed25519.PublicKey(pubKeyBytes)
+
+	if keyType == kms.ECDSAP256IEEEP1363 {
+		x, y := elliptic.Unmarshal(elliptic.P256(), pubKeyBytes)
+		pubKey = &ecdsa.PublicKey{X: x, Y: y, Curve: elliptic.P256()}
+	}
+
+	jwk, err := jose.JWKFromKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+
+	vm, err := did.NewVerificationMethodFromJWK(keyID, current.Type, "", jwk)
+	if err != nil {
+		return "", err
+	}
+
+	// orb takes the keys from the verification relationships, the ones left out would be removed
+	updatedDoc := &did.Doc{
+		ID:                   didID,
+		Service:              didDoc.Service,
+		Authentication:       append(didDoc.Authentication, *did.NewReferencedVerification(vm, did.Authentication)),
+		AssertionMethod:      append(didDoc.AssertionMethod, *did.NewReferencedVerification(vm, did.AssertionMethod)),
+		CapabilityDelegation: didDoc.CapabilityDelegation,
+		CapabilityInvocation: didDoc.CapabilityInvocation,
+		KeyAgreement:         didDoc.KeyAgreement,
+	}
+
+	if err = o.vdr.Update(updatedDoc); err != nil {
+		return "", fmt.Errorf("failed to update did: %w", err)
+	}
+
+	if err = o.operationKeys.CommitUpdate(didID); err != nil {
+		return "", err
+	}
+
+	return didID + "#" + keyID, nil
+}
+
+// findVerificationMethod returns the verification method of the DID document with the ID or fragment of the given
+// verification method ID, nil if there is none.
+func findVerificationMethod(didDoc *did.Doc, vmID string) *did.VerificationMethod {
+	fragment := vmID[strings.LastIndex(vmID, "#")+1:]
+
+	for _, verifications := range didDoc.VerificationMethods() {
+		for i := range verifications {
+			vm := &verifications[i].VerificationMethod
+
+			if vm.ID == vmID || vm.ID[strings.LastIndex(vm.ID, "#")+1:] == fragment {
+				return vm
+			}
+		}
+	}
+
+	return nil
+}
+
 // nolint:funlen,gocyclo
 func (o *CommonDID) createPublicKeys(keyType, signatureType string) (*did.Doc,
 	[]*didmethodoperation.PublicKey, string, error) {
@@ -391,6 +570,11 @@ func (o *CommonDID) importKey(keyID string, keyType kms.KeyType, privateKeyBytes
 	switch keyType { //nolint:exhaustive
 	case kms.ED25519Type:
 		privKey = ed25519.PrivateKey(privateKeyBytes)
+	case kms.ECDSAP256TypeIEEEP1363:
+		key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(privateKeyBytes)}
+		key.Curve = elliptic.P256()
+		key.X, key.Y = key.Curve.ScalarBaseMult(privateKeyBytes)
+		privKey = key
 	case kms.BLS12381G2Type:
 		privKey, err = bbs12381g2pub.UnmarshalPrivateKey(privateKeyBytes)
 		if err != nil {
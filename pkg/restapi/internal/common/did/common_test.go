@@ -15,15 +15,19 @@ import (
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 	"github.com/stretchr/testify/require"
 	didmethodoperation "github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod/operation"
 
 	"github.com/trustbloc/edge-service/pkg/client/uniregistrar"
+	did2 "github.com/trustbloc/edge-service/pkg/did"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
@@ -117,6 +121,47 @@ func TestCommonDID_CreateDID(t *testing.T) {
 		require.Equal(t, "did:trustbloc:123", did)
 	})
 
+	t.Run("test success - operation keys saved", func(t *testing.T) {
+		keys, err := did2.NewOperationKeyStore(mem.NewProvider(), &noop.NoLock{}, "")
+		require.NoError(t, err)
+
+		c := New(&Config{OperationKeys: keys, VDRI: &vdr.MockVDRegistry{
+			CreateFunc: func(s string, doc *ariesdid.Doc,
+				option ...vdrapi.DIDMethodOption) (*ariesdid.DocResolution, error) {
+				return &ariesdid.DocResolution{DIDDocument: &ariesdid.Doc{ID: "did:orb:123"}}, nil
+			}}})
+
+		var createdKeys int
+
+		c.createKey = func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error) {
+			createdKeys++
+
+			if keyType == kms.ED25519Type {
+				_, v, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err)
+
+				return key1, v, nil
+			}
+
+			ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+
+			return key1, elliptic.Marshal(ecPrivKey.PublicKey.Curve, ecPrivKey.PublicKey.X, ecPrivKey.PublicKey.Y), nil
+		}
+
+		_, _, err = c.CreateDID(crypto.Ed25519KeyType, crypto.Ed25519Signature2018, "", "",
+			"", crypto.Authentication, model.UNIRegistrar{})
+		require.NoError(t, err)
+
+		// only the keys of the verification methods are created in the KMS
+		require.Equal(t, 3, createdKeys)
+
+		operationKeys, err := keys.Get("did:orb:123")
+		require.NoError(t, err)
+		require.NotNil(t, operationKeys.UpdateKey)
+		require.NotNil(t, operationKeys.RecoveryKey)
+	})
+
 	t.Run("test error - create public keys failed", func(t *testing.T) {
 		c := New(&Config{})
 
@@ -177,6 +222,203 @@ func TestCommonDID_CreateDID(t *testing.T) {
 		require.Empty(t, did)
 	})
 }
+func TestCommonDID_RotateKey(t *testing.T) {
+	const didID = "did:orb:abc"
+
+	createKey := func(keyType kms.KeyType, keyManager keyManager) (string, []byte, error) {
+		_, v, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		return "key2", v, nil
+	}
+
+	resolve := func(didID string, opts ...vdrapi.DIDMethodOption) (*ariesdid.DocResolution, error) {
+		_, pubKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := jose.JWKFromKey(pubKey)
+		require.NoError(t, err)
+
+		vm, err := ariesdid.NewVerificationMethodFromJWK(didID+"#key1", crypto.JSONWebKey2020, "", jwk)
+		require.NoError(t, err)
+
+		return &ariesdid.DocResolution{DIDDocument: &ariesdid.Doc{
+			ID:                 didID,
+			VerificationMethod: []ariesdid.VerificationMethod{*vm},
+			Authentication:     []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.Authentication)},
+			AssertionMethod:    []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.AssertionMethod)},
+		}}, nil
+	}
+
+	t.Run("test success - orb DID update", func(t *testing.T) {
+		keys, err := did2.NewOperationKeyStore(mem.NewProvider(), &noop.NoLock{}, "")
+		require.NoError(t, err)
+
+		operationKeys, err := did2.GenerateKeys()
+		require.NoError(t, err)
+		require.NoError(t, keys.Save(didID, operationKeys))
+
+		var updatedDoc *ariesdid.Doc
+
+		c := New(&Config{OperationKeys: keys, VDRI: &vdr.MockVDRegistry{
+			ResolveFunc: resolve,
+			UpdateFunc: func(didDoc *ariesdid.Doc, opts ...vdrapi.DIDMethodOption) error {
+				updatedDoc = didDoc
+
+				// the orb VDR signs the update with the current key and commits to the next one
+				_, err := keys.GetNextUpdatePublicKey(didDoc.ID)
+
+				return err
+			},
+		}})
+		c.createKey = createKey
+
+		keyID, err := c.RotateKey(didID, didID+"#key1", "", "")
+		require.NoError(t, err)
+		require.Equal(t, didID+"#key2", keyID)
+
+		require.Empty(t, updatedDoc.VerificationMethod)
+		require.Len(t, updatedDoc.Authentication, 2)
+		require.Len(t, updatedDoc.AssertionMethod, 2)
+		require.Equal(t, didID+"#key1", updatedDoc.AssertionMethod[0].VerificationMethod.ID)
+		require.Equal(t, "key2", updatedDoc.AssertionMethod[1].VerificationMethod.ID)
+		require.Equal(t, crypto.JSONWebKey2020, updatedDoc.AssertionMethod[1].VerificationMethod.Type)
+
+		committed, err := keys.Get(didID)
+		require.NoError(t, err)
+		require.NotEqual(t, operationKeys.UpdateKey, committed.UpdateKey)
+	})
+
+	t.Run("test success - imported key", func(t *testing.T) {
+		km := &importKeyManager{}
+		c := New(&Config{KeyManager: km, VDRI: &vdr.MockVDRegistry{ResolveFunc: resolve}})
+
+		keyID, err := c.RotateKey("did:test:abc", "did:test:abc#key0", "did:test:abc#key1",
+			base58.Encode([]byte("key")))
+		require.NoError(t, err)
+		require.Equal(t, "did:test:abc#key1", keyID)
+		require.Equal(t, kms.ED25519Type, km.keyType)
+	})
+
+	t.Run("test success - imported key of the DID key type", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := jose.JWKFromKey(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		vm, err := ariesdid.NewVerificationMethodFromJWK("did:test:abc#key1", crypto.JSONWebKey2020, "", jwk)
+		require.NoError(t, err)
+
+		km := &importKeyManager{}
+		c := New(&Config{KeyManager: km, VDRI: &vdr.MockVDRegistry{ResolveValue: &ariesdid.Doc{
+			ID:              "did:test:abc",
+			AssertionMethod: []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.AssertionMethod)},
+		}}})
+
+		keyID, err := c.RotateKey("did:test:abc", "did:test:abc#key0", "did:test:abc#key1",
+			base58.Encode(privateKey.D.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, "did:test:abc#key1", keyID)
+		require.Equal(t, kms.ECDSAP256TypeIEEEP1363, km.keyType)
+		require.True(t, privateKey.Equal(km.privKey))
+	})
+
+	t.Run("test error - imported key type not supported", func(t *testing.T) {
+		vm := ariesdid.NewVerificationMethodFromBytes("did:test:abc#key1", "X25519KeyAgreementKey2019", "",
+			[]byte("key"))
+
+		c := New(&Config{KeyManager: &mockkms.KeyManager{}, VDRI: &vdr.MockVDRegistry{ResolveValue: &ariesdid.Doc{
+			ID:              "did:test:abc",
+			AssertionMethod: []ariesdid.Verification{*ariesdid.NewReferencedVerification(vm, ariesdid.AssertionMethod)},
+		}}})
+
+		_, err := c.RotateKey("did:test:abc", "did:test:abc#key0", "did:test:abc#key1",
+			base58.Encode([]byte("key")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import of X25519KeyAgreementKey2019 keys isn't supported")
+	})
+
+	t.Run("test error - imported key not in DID", func(t *testing.T) {
+		c := New(&Config{KeyManager: &mockkms.KeyManager{}, VDRI: &vdr.MockVDRegistry{ResolveFunc: resolve}})
+
+		_, err := c.RotateKey("did:test:abc", "did:test:abc#key1", "did:test:abc#key2",
+			base58.Encode([]byte("key")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verification method did:test:abc#key2 not found in did did:test:abc")
+	})
+
+	t.Run("test error - DID not updated by the service", func(t *testing.T) {
+		c := New(&Config{VDRI: &vdr.MockVDRegistry{ResolveFunc: resolve}})
+
+		_, err := c.RotateKey("did:test:abc", "did:test:abc#key1", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key rotation of did did:test:abc needs the private key")
+
+		_, err = c.RotateKey(didID, didID+"#key1", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key rotation of did did:orb:abc needs the private key")
+	})
+
+	t.Run("test error - DID created before its operation keys were kept", func(t *testing.T) {
+		keys, err := did2.NewOperationKeyStore(mem.NewProvider(), &noop.NoLock{}, "")
+		require.NoError(t, err)
+
+		c := New(&Config{OperationKeys: keys, VDRI: &vdr.MockVDRegistry{ResolveFunc: resolve}})
+
+		_, err = c.RotateKey(didID, didID+"#key1", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "operation keys of did did:orb:abc aren't kept by the service")
+	})
+
+	t.Run("test error - resolve DID", func(t *testing.T) {
+		c := New(&Config{VDRI: &vdr.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")}})
+
+		_, err := c.RotateKey(didID, didID+"#key1", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve did: resolve error")
+	})
+
+	t.Run("test error - update DID", func(t *testing.T) {
+		keys, err := did2.NewOperationKeyStore(mem.NewProvider(), &noop.NoLock{}, "")
+		require.NoError(t, err)
+
+		operationKeys, err := did2.GenerateKeys()
+		require.NoError(t, err)
+		require.NoError(t, keys.Save(didID, operationKeys))
+
+		c := New(&Config{OperationKeys: keys, VDRI: &vdr.MockVDRegistry{
+			ResolveFunc: resolve,
+			UpdateFunc: func(didDoc *ariesdid.Doc, opts ...vdrapi.DIDMethodOption) error {
+				return fmt.Errorf("update error")
+			},
+		}})
+		c.createKey = createKey
+
+		_, err = c.RotateKey(didID, didID+"#key1", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to update did: update error")
+
+		_, err = c.RotateKey(didID, didID+"#key0", "", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verification method did:orb:abc#key0 not found in did did:orb:abc")
+	})
+}
+
+// importKeyManager records the private key imported.
+type importKeyManager struct {
+	mockkms.KeyManager
+	privKey interface{}
+	keyType kms.KeyType
+}
+
+func (k *importKeyManager) ImportPrivateKey(privKey interface{}, keyType kms.KeyType,
+	opts ...kms.PrivateKeyOpts) (string, interface{}, error) {
+	k.privKey, k.keyType = privKey, keyType
+
+	return k.KeyManager.ImportPrivateKey(privKey, keyType, opts...)
+}
+
 func TestCommonDID_CreateDIDUniRegistrar(t *testing.T) {
 	t.Run("test success - trustbloc method", func(t *testing.T) {
 		c := New(&Config{})
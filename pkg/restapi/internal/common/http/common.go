@@ -8,12 +8,23 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/trustbloc/edge-core/pkg/log"
 )
 
+const (
+	pageQueryParam     = "page"
+	pageSizeQueryParam = "pageSize"
+
+	// DefaultPageSize is the size of the pages of lists when it isn't requested
+	DefaultPageSize = 20
+	maxPageSize     = 100
+)
+
 var logger = log.New("edge-service-restapi-common-http")
 
 // ErrorResponse to send error message in the response
@@ -41,3 +52,26 @@ func WriteResponse(rw io.Writer, v interface{}) {
 		logger.Errorf("Unable to send error response, %s", err)
 	}
 }
+
+// GetPage returns the page number and page size requested, pages are numbered from 0.
+func GetPage(req *http.Request) (int, int, error) {
+	page, pageSize := 0, DefaultPageSize
+
+	var err error
+
+	if v := req.URL.Query().Get(pageQueryParam); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 0 {
+			return 0, 0, fmt.Errorf("invalid %s : %s", pageQueryParam, v)
+		}
+	}
+
+	if v := req.URL.Query().Get(pageSizeQueryParam); v != "" {
+		pageSize, err = strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return 0, 0, fmt.Errorf("invalid %s : %s, expected 1 to %d", pageSizeQueryParam, v, maxPageSize)
+		}
+	}
+
+	return page, pageSize, nil
+}
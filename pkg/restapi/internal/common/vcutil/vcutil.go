@@ -128,14 +128,25 @@ func UpdateIssuer(credential *verifiable.Credential, profile *vcprofile.IssuerPr
 // UpdateSignatureTypeContext updates context for JSONWebSignature2020
 func UpdateSignatureTypeContext(credential *verifiable.Credential, profile *vcprofile.IssuerProfile) {
 	if profile.SignatureType == crypto.JSONWebSignature2020 {
-		credential.Context = append(credential.Context, jsonWebSignature2020Context)
+		addContext(credential, jsonWebSignature2020Context)
 	}
 
 	if profile.SignatureType == crypto.BbsBlsSignature2020 {
-		credential.Context = append(credential.Context, bbsBlsSignature2020Context)
+		addContext(credential, bbsBlsSignature2020Context)
 	}
 }
 
+// addContext adds the context unless the credential already has it, as refreshed credentials do
+func addContext(credential *verifiable.Credential, context string) {
+	for _, c := range credential.Context {
+		if c == context {
+			return
+		}
+	}
+
+	credential.Context = append(credential.Context, context)
+}
+
 // GetDocIDFromURL Given an EDV document URL, returns just the document ID
 func GetDocIDFromURL(docURL string) string {
 	splitBySlashes := strings.Split(docURL, `/`)
@@ -236,6 +236,10 @@ func TestUpdateSignatureTypeContext(t *testing.T) {
 	profile.SignatureType = crypto.BbsBlsSignature2020
 	UpdateSignatureTypeContext(vc, profile)
 	require.Len(t, vc.Context, 3)
+
+	// the context is added once
+	UpdateSignatureTypeContext(vc, profile)
+	require.Len(t, vc.Context, 3)
 }
 
 func TestGetDocIDFromURL(t *testing.T) {
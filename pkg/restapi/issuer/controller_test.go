@@ -74,5 +74,5 @@ func TestController_GetOperations(t *testing.T) {
 
 	ops := controller.GetOperations()
 
-	require.Equal(t, 11, len(ops))
+	require.Equal(t, 26, len(ops))
 }
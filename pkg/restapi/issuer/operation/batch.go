@@ -0,0 +1,292 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
+)
+
+const (
+	maxBatchSize                = 1000
+	defaultBatchIssuanceWorkers = 8
+)
+
+// BatchIssueCredential swagger:route POST /{id}/credentials/issue/batch issuer batchIssueCredentialReq
+//
+// Issues many credentials with the same options, signing them in parallel. Results are returned in the order of
+// the request, a credential failing to be issued doesn't fail the others.
+//
+// Responses:
+//    default: genericError
+//        200: batchIssueCredentialResp
+func (o *Operation) batchIssueCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := BatchIssueCredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	switch {
+	case len(data.Credentials) == 0:
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credentials")
+
+		return
+	case len(data.Credentials) > maxBatchSize:
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("batch of %d credentials exceeds the maximum of %d", len(data.Credentials), maxBatchSize))
+
+		return
+	}
+
+	if err = validateIssueCredOptions(data.Opts); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	format := getIssuerFormatOpts(profile, data.Opts)
+
+	if err = format.validate(); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	results := make([]IssueCredentialResult, len(data.Credentials))
+	credentials := make([]*verifiable.Credential, len(data.Credentials))
+
+	o.inParallel(len(data.Credentials), func(i int) {
+		credential, errParse := o.parseCredential(data.Credentials[i])
+		if errParse != nil {
+			results[i].Error = fmt.Sprintf("failed to validate credential: %s", errParse.Error())
+
+			return
+		}
+
+		if errRegistered := o.checkNotRegistered(profile, credential); errRegistered != nil {
+			results[i].Error = errRegistered.Error()
+
+			return
+		}
+
+		credentials[i] = credential
+	})
+
+	// the status of a credential is allocated only once it passed validation
+	rejectRepeatedCredentials(profile, credentials, results)
+
+	if err = o.setCredentialsStatus(profile, credentials); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to add credential status: %s", err.Error()))
+
+		return
+	}
+
+	// every credential is signed with the same keys, resolve them only once
+	signer := o.crypto.WithDIDCache()
+	signingOpts := getIssuerSigningOpts(data.Opts)
+	// status of the credentials that fail to be issued, their indexes are handed back once the batch is done
+	notIssued := make([]*verifiable.TypedID, len(credentials))
+
+	o.inParallel(len(credentials), func(i int) {
+		credential := credentials[i]
+		if credential == nil {
+			return
+		}
+
+		vcutil.UpdateIssuer(credential, profile)
+
+		setRegistryCredentialID(profile, credential)
+
+		setExpirationDate(credential, profile.ValidityPeriod)
+
+		o.setRefreshService(profile, credential)
+
+		signedVC, errSign := signCredential(signer, profile, credential, format, signingOpts...)
+		if errSign != nil {
+			notIssued[i] = allocatedStatus(profile, credential)
+			results[i].Error = fmt.Sprintf("failed to sign credential: %s", errSign.Error())
+
+			return
+		}
+
+		if errRegister := o.registerCredential(profile, credential); errRegister != nil {
+			notIssued[i] = allocatedStatus(profile, credential)
+			results[i].Error = errRegister.Error()
+
+			return
+		}
+
+		results[i].Credential = signedVC
+	})
+
+	o.releaseStatus(notIssued...)
+
+	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &BatchIssueCredentialResponse{Results: results})
+}
+
+// parseCredential parses the credential to issue and validates it against its schemas, ignoring the proof
+func (o *Operation) parseCredential(vcBytes []byte) (*verifiable.Credential, error) {
+	credential, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+		verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = o.schemaValidator.Validate(credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// checkNotRegistered fails when the profile already registered a credential with the same ID, it couldn't be
+// registered once signed. The check only saves signing such credentials, one issued concurrently with the same ID
+// still passes it and is rejected when registered, before it's returned.
+func (o *Operation) checkNotRegistered(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) error {
+	if !profile.CredentialRegistry || credential.ID == "" {
+		return nil
+	}
+
+	_, err := o.credentialRegistry.Get(profile.Name, credential.ID)
+
+	switch {
+	case err == nil:
+		return fmt.Errorf("failed to register credential: %w: %s", registry.ErrAlreadyRegistered, credential.ID)
+	case errors.Is(err, registry.ErrNotFound):
+		return nil
+	default:
+		return fmt.Errorf("failed to register credential: %w", err)
+	}
+}
+
+// rejectRepeatedCredentials drops the credentials whose ID an earlier credential of the batch already has when the
+// profile keeps a credential registry, only the first of them could be registered.
+func rejectRepeatedCredentials(profile *vcprofile.IssuerProfile, credentials []*verifiable.Credential,
+	results []IssueCredentialResult) {
+	if !profile.CredentialRegistry {
+		return
+	}
+
+	ids := make(map[string]bool)
+
+	for i, credential := range credentials {
+		if credential == nil || credential.ID == "" {
+			continue
+		}
+
+		if ids[credential.ID] {
+			credentials[i] = nil
+			results[i].Error = fmt.Sprintf("credential %s is repeated in the batch", credential.ID)
+
+			continue
+		}
+
+		ids[credential.ID] = true
+	}
+}
+
+// setCredentialsStatus sets the status of the given credentials, skipping nil ones,
+// their status list indexes are allocated in one pass.
+func (o *Operation) setCredentialsStatus(profile *vcprofile.IssuerProfile,
+	credentials []*verifiable.Credential) error {
+	if profile.DisableVCStatus {
+		return nil
+	}
+
+	var positions []int
+
+	for i, credential := range credentials {
+		if credential != nil {
+			positions = append(positions, i)
+		}
+	}
+
+	if len(positions) == 0 {
+		return nil
+	}
+
+	statusIDs, err := o.vcStatusManager.CreateStatusIDs(profile.DataProfile,
+		o.hostURL+"/"+profile.Name+credentialStatus, len(positions), cslstatus.WithStatusType(profile.VCStatusType),
+		cslstatus.WithListSize(profile.VCStatusListSize),
+		cslstatus.WithBitStringLength(profile.VCStatusListBitLength))
+	if err != nil {
+		return err
+	}
+
+	for i, pos := range positions {
+		cslstatus.SetStatus(credentials[pos], statusIDs[i])
+	}
+
+	return nil
+}
+
+// allocatedStatus returns the status the service allocated to the credential, nil when the profile doesn't.
+func allocatedStatus(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) *verifiable.TypedID {
+	if profile.DisableVCStatus {
+		return nil
+	}
+
+	return credential.Status
+}
+
+// inParallel calls fn for every index up to n, running at most the configured number of batch workers at once
+func (o *Operation) inParallel(n int, fn func(i int)) {
+	workers := o.batchIssuanceWorkers
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,302 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestBatchIssueCredential(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	loader := testutil.DocumentLoader(t)
+
+	var resolutions int32
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				atomic.AddInt32(&resolutions, 1)
+
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader:       loader,
+		HostURL:              "https://issuer.example.com",
+		BatchIssuanceWorkers: 2,
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.OverwriteIssuer = true
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
+	handler := getHandler(t, op, batchIssueCredentialPath, http.MethodPost)
+
+	issue := func(t *testing.T, req *BatchIssueCredentialRequest) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, batchIssueCredentialPath, reqBytes, urlVars)
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("batch issue credentials - success", func(t *testing.T) {
+		const batchSize = 5
+
+		credentials := make([]json.RawMessage, batchSize)
+		for i := range credentials {
+			credentials[i] = json.RawMessage(validVCWithoutStatus)
+		}
+
+		// an invalid credential fails on its own
+		credentials[2] = json.RawMessage(invalidVC)
+
+		atomic.StoreInt32(&resolutions, 0)
+
+		code, body := issue(t, &BatchIssueCredentialRequest{Credentials: credentials})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &BatchIssueCredentialResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Results, batchSize)
+
+		statusIDs := make(map[string]bool)
+
+		for i, result := range resp.Results {
+			if i == 2 {
+				require.Nil(t, result.Credential)
+				require.Contains(t, result.Error, "failed to validate credential")
+
+				continue
+			}
+
+			require.Empty(t, result.Error)
+
+			vcBytes, err := json.Marshal(result.Credential)
+			require.NoError(t, err)
+
+			vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(loader))
+			require.NoError(t, err)
+			require.Len(t, vc.Proofs, 1)
+			require.Equal(t, profile.DID, vc.Issuer.ID)
+			require.Equal(t, cslstatus.RevocationList2020Status, vc.Status.Type)
+			require.False(t, statusIDs[vc.Status.ID], "status %s handed out twice", vc.Status.ID)
+
+			statusIDs[vc.Status.ID] = true
+		}
+
+		// the DID of the profile is resolved once to sign the status list and once for the whole batch
+		require.Equal(t, int32(2), atomic.LoadInt32(&resolutions))
+	})
+
+	t.Run("batch issue credentials - sign error", func(t *testing.T) {
+		code, body := issue(t, &BatchIssueCredentialRequest{
+			Credentials: []json.RawMessage{json.RawMessage(validVCWithoutStatus)},
+			Opts:        &IssueCredentialOptions{VerificationMethod: "did:test:abc#invalid"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &BatchIssueCredentialResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Results, 1)
+		require.Contains(t, resp.Results[0].Error, "failed to sign credential")
+	})
+
+	t.Run("batch issue credentials - status of failed credentials released", func(t *testing.T) {
+		statusManager := op.vcStatusManager
+		mockStatusManager := &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "status#1"}}
+		op.vcStatusManager = mockStatusManager
+
+		defer func() { op.vcStatusManager = statusManager }()
+
+		code, body := issue(t, &BatchIssueCredentialRequest{
+			Credentials: []json.RawMessage{json.RawMessage(validVCWithoutStatus), json.RawMessage(invalidVC)},
+			Opts:        &IssueCredentialOptions{VerificationMethod: "did:test:abc#invalid"},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, []*verifiable.TypedID{mockStatusManager.createStatusIDValue}, mockStatusManager.released)
+	})
+
+	t.Run("batch issue credentials - validated before status allocation", func(t *testing.T) {
+		const schemaURL = "https://example.com/schemas/subject.json"
+
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			Crypto:             customCrypto,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+				},
+			},
+			DocumentLoader: loader,
+			SchemaLoader: schema.NewLocalLoader(map[string][]byte{schemaURL: []byte(`{
+			  "type": "object",
+			  "properties": {"credentialSubject": {"type": "object", "required": ["name"]}}
+			}`)}, nil),
+		})
+		require.NoError(t, err)
+
+		statusManager := &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "status#1"}}
+		ops.vcStatusManager = statusManager
+
+		registryProfile := getTestProfile()
+		registryProfile.Creator = "did:test:abc#" + keyID
+		registryProfile.CredentialRegistry = true
+
+		require.NoError(t, ops.profileStore.SaveProfile(registryProfile))
+
+		withSchema := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(validVCWithoutStatus), &withSchema))
+
+		withSchema["id"] = "http://example.edu/credentials/schema"
+		withSchema["credentialSchema"] = map[string]interface{}{
+			"id": schemaURL, "type": schema.JSONSchemaValidator2018,
+		}
+
+		withSchemaBytes, err := json.Marshal(withSchema)
+		require.NoError(t, err)
+
+		batchHandler := getHandler(t, ops, batchIssueCredentialPath, http.MethodPost)
+
+		issueBatch := func(t *testing.T, credentials ...json.RawMessage) *BatchIssueCredentialResponse {
+			t.Helper()
+
+			reqBytes, err := json.Marshal(&BatchIssueCredentialRequest{Credentials: credentials})
+			require.NoError(t, err)
+
+			rr := serveHTTPMux(t, batchHandler, batchIssueCredentialPath, reqBytes, urlVars)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			resp := &BatchIssueCredentialResponse{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+			return resp
+		}
+
+		resp := issueBatch(t, json.RawMessage(validVCWithoutStatus), json.RawMessage(validVCWithoutStatus),
+			withSchemaBytes)
+		require.Empty(t, resp.Results[0].Error)
+		require.NotNil(t, resp.Results[0].Credential)
+		require.Equal(t, "credential http://example.edu/credentials/1872 is repeated in the batch",
+			resp.Results[1].Error)
+		require.Contains(t, resp.Results[2].Error, "failed to validate credential: credential doesn't conform to "+
+			"schema "+schemaURL)
+		require.Equal(t, 1, statusManager.created)
+
+		resp = issueBatch(t, json.RawMessage(validVCWithoutStatus))
+		require.Contains(t, resp.Results[0].Error, registry.ErrAlreadyRegistered.Error())
+		require.Equal(t, 1, statusManager.created)
+
+		ops.credentialRegistry = &mockCredentialRegistry{err: errors.New("get error")}
+
+		resp = issueBatch(t, json.RawMessage(validVCWithoutStatus))
+		require.Equal(t, "failed to register credential: get error", resp.Results[0].Error)
+		require.Equal(t, 1, statusManager.created)
+	})
+
+	t.Run("batch issue credentials - status error", func(t *testing.T) {
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			Crypto:             customCrypto,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			DocumentLoader:     loader,
+		})
+		require.NoError(t, err)
+
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDErr: errors.New("csl error")}
+
+		require.NoError(t, ops.profileStore.SaveProfile(profile))
+
+		reqBytes, err := json.Marshal(&BatchIssueCredentialRequest{
+			Credentials: []json.RawMessage{json.RawMessage(validVCWithoutStatus)},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, ops, batchIssueCredentialPath, http.MethodPost), batchIssueCredentialPath,
+			reqBytes, urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to add credential status: csl error")
+	})
+
+	t.Run("batch issue credentials - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, batchIssueCredentialPath, nil, map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = serveHTTPMux(t, handler, batchIssueCredentialPath, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		code, body := issue(t, &BatchIssueCredentialRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing credentials")
+
+		code, body = issue(t, &BatchIssueCredentialRequest{
+			Credentials: make([]json.RawMessage, maxBatchSize+1),
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "batch of 1001 credentials exceeds the maximum of 1000")
+
+		code, body = issue(t, &BatchIssueCredentialRequest{
+			Credentials: []json.RawMessage{json.RawMessage(validVC)},
+			Opts:        &IssueCredentialOptions{ProofPurpose: "invalid"},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid proof option : invalid")
+
+		code, body = issue(t, &BatchIssueCredentialRequest{
+			Credentials: []json.RawMessage{json.RawMessage(validVC)},
+			Opts: &IssueCredentialOptions{
+				CredentialFormat:  vcprofile.LDPCredentialFormat,
+				DisclosableClaims: []string{"id"},
+			},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "disclosable claims and holder binding need the")
+	})
+}
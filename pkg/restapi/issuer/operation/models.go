@@ -13,6 +13,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/status/audit"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -31,10 +34,57 @@ type UpdateCredentialStatusRequest struct {
 	CredentialStatus CredentialStatus `json:"credentialStatus"`
 }
 
+// BatchUpdateCredentialStatusRequest request struct for updating status of many vcs at once
+type BatchUpdateCredentialStatusRequest struct {
+	CredentialIDs    []string         `json:"credentialIds"`
+	CredentialStatus CredentialStatus `json:"credentialStatus"`
+}
+
+// BatchUpdateCredentialStatusResponse contains status update result of every vc in the batch
+type BatchUpdateCredentialStatusResponse struct {
+	Results []CredentialStatusResult `json:"results"`
+}
+
+// CredentialStatusResult status update result of a vc
+type CredentialStatusResult struct {
+	CredentialID string `json:"credentialId"`
+	Updated      bool   `json:"updated"`
+	Error        string `json:"error,omitempty"`
+}
+
 // CredentialStatus credential status
 type CredentialStatus struct {
 	Type   string `json:"type"`
 	Status string `json:"status"`
+	// StatusPurpose is the status list to be updated, "revocation" (default) or "suspension".
+	StatusPurpose string `json:"statusPurpose,omitempty"`
+	// ReasonCode is the reason of the status change recorded for audit, "unspecified" if not set.
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Comment is recorded for audit along with the status change.
+	Comment string `json:"comment,omitempty"`
+}
+
+// CredentialStatusHistoryResponse contains status changes of a credential, oldest first
+type CredentialStatusHistoryResponse struct {
+	CredentialID string          `json:"credentialId"`
+	History      []*audit.Record `json:"history"`
+}
+
+// ListCredentialsResponse contains a page of the credentials issued by a profile, latest issued first
+type ListCredentialsResponse struct {
+	Credentials []*registry.Record `json:"credentials"`
+	Page        int                `json:"page"`
+	PageSize    int                `json:"pageSize"`
+	Total       int                `json:"total"`
+}
+
+// RevokeCredentialsRequest request struct for revoking all credentials issued to a subject
+type RevokeCredentialsRequest struct {
+	Subject string `json:"subject"`
+	// ReasonCode is the reason of the revocation recorded for audit, "unspecified" if not set.
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Comment is recorded for audit along with the revocation.
+	Comment string `json:"comment,omitempty"`
 }
 
 // StoreVCRequest stores the credential with profile name
@@ -55,7 +105,64 @@ type ProfileRequest struct {
 	DIDKeyID                string                             `json:"didKeyID"`
 	UNIRegistrar            model.UNIRegistrar                 `json:"uniRegistrar,omitempty"`
 	DisableVCStatus         bool                               `json:"disableVCStatus"`
+	VCStatusType            string                             `json:"vcStatusType,omitempty"`
+	VCStatusListSize        int                                `json:"vcStatusListSize,omitempty"`
+	VCStatusListBitLength   int                                `json:"vcStatusListBitLength,omitempty"`
 	OverwriteIssuer         bool                               `json:"overwriteIssuer,omitempty"`
+	CredentialFormat        string                             `json:"credentialFormat,omitempty"`
+	CredentialRegistry      bool                               `json:"credentialRegistry,omitempty"`
+	// CredentialTemplates are the templates credentials can be composed from
+	CredentialTemplates []*vcprofile.CredentialTemplate `json:"credentialTemplates,omitempty"`
+	// ValidityPeriod in seconds sets the expiration date of credentials issued without one
+	ValidityPeriod int64 `json:"validityPeriod,omitempty"`
+	// RefreshService is embedded in issued credentials, none when empty
+	RefreshService *vcprofile.RefreshService `json:"refreshService,omitempty"`
+}
+
+// UpdateProfileRequest request for updating the mutable fields of an issuer profile. Fields missing are reset
+// when the profile is replaced and left unchanged when it is patched.
+type UpdateProfileRequest struct {
+	URI                     *string                             `json:"uri,omitempty"`
+	SignatureRepresentation *verifiable.SignatureRepresentation `json:"signatureRepresentation,omitempty"`
+	DisableVCStatus         *bool                               `json:"disableVCStatus,omitempty"`
+	OverwriteIssuer         *bool                               `json:"overwriteIssuer,omitempty"`
+	CredentialFormat        *string                             `json:"credentialFormat,omitempty"`
+	CredentialRegistry      *bool                               `json:"credentialRegistry,omitempty"`
+	ValidityPeriod          *int64                              `json:"validityPeriod,omitempty"`
+	RefreshService          *vcprofile.RefreshService           `json:"refreshService,omitempty"`
+}
+
+// ListProfilesResponse is a page of the issuer profiles.
+type ListProfilesResponse struct {
+	Profiles []*vcprofile.IssuerProfile `json:"profiles"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"pageSize"`
+	Total    int                        `json:"total"`
+}
+
+// RotateKeyRequest request for rotating the key of an issuer profile. The key is created when empty, the key
+// of a DID managed outside of the service has to be added to the DID and given with its private key.
+type RotateKeyRequest struct {
+	DIDKeyID      string `json:"didKeyID,omitempty"`
+	DIDPrivateKey string `json:"didPrivateKey,omitempty"`
+}
+
+// RefreshCredentialRequest request for refreshing a credential, the presentation holds the credential
+// and is signed by its subject.
+type RefreshCredentialRequest struct {
+	Presentation json.RawMessage `json:"verifiablePresentation,omitempty"`
+}
+
+// RefreshChallengeRequest request for a challenge to prove in the presentation of a credential refresh.
+type RefreshChallengeRequest struct {
+	Domain string `json:"domain,omitempty"`
+}
+
+// RefreshChallengeResponse the challenge issued, which can be used once until it expires.
+type RefreshChallengeResponse struct {
+	Challenge string    `json:"challenge"`
+	Domain    string    `json:"domain,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 // IssueCredentialRequest request for issuing credential.
@@ -64,6 +171,23 @@ type IssueCredentialRequest struct {
 	Opts       *IssueCredentialOptions `json:"options,omitempty"`
 }
 
+// BatchIssueCredentialRequest request for issuing many credentials with the same options.
+type BatchIssueCredentialRequest struct {
+	Credentials []json.RawMessage       `json:"credentials"`
+	Opts        *IssueCredentialOptions `json:"options,omitempty"`
+}
+
+// BatchIssueCredentialResponse contains issuance result of every credential in the batch, in request order.
+type BatchIssueCredentialResponse struct {
+	Results []IssueCredentialResult `json:"results"`
+}
+
+// IssueCredentialResult issuance result of a credential of the batch, either the issued credential or the error.
+type IssueCredentialResult struct {
+	Credential interface{} `json:"credential,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
 // IssueCredentialOptions options for issuing credential.
 type IssueCredentialOptions struct {
 	// VerificationMethod is the URI of the verificationMethod used for the proof.
@@ -83,6 +207,13 @@ type IssueCredentialOptions struct {
 	Domain string `json:"domain,omitempty"`
 	// The method of credential status to issue the credential including. If omitted credential status will be included.
 	CredentialStatus CredentialStatusOpt `json:"credentialStatus,omitempty"`
+	// CredentialFormat is the format to issue the credential in, "ldp", "jwt" or "sd-jwt".
+	// If omitted profile format will be used.
+	CredentialFormat string `json:"credentialFormat,omitempty"`
+	// DisclosableClaims are the credential subject claims the holder can selectively disclose, for "sd-jwt" only.
+	DisclosableClaims []string `json:"disclosableClaims,omitempty"`
+	// HolderVerificationMethod binds "sd-jwt" presentations to the key of the given holder verification method.
+	HolderVerificationMethod string `json:"holderVerificationMethod,omitempty"`
 }
 
 // CredentialStatusOpt credential status option
@@ -92,6 +223,9 @@ type CredentialStatusOpt struct {
 
 // ComposeCredentialRequest for composing and issuing credential.
 type ComposeCredentialRequest struct {
+	// TemplateID is the profile credential template to compose the credential from, its contexts, types and
+	// evidence replace those of the request and the claims have to match its schema.
+	TemplateID              string          `json:"templateId,omitempty"`
 	Issuer                  string          `json:"issuer,omitempty"`
 	Subject                 string          `json:"subject,omitempty"`
 	Types                   []string        `json:"types,omitempty"`
@@ -104,6 +238,10 @@ type ComposeCredentialRequest struct {
 	ProofFormat             string          `json:"proofFormat,omitempty"`
 	CredentialFormatOptions json.RawMessage `json:"credentialFormatOptions,omitempty"`
 	ProofFormatOptions      json.RawMessage `json:"proofFormatOptions,omitempty"`
+	// DisclosableClaims are the claims the holder can selectively disclose, for the "sd-jwt" format only.
+	DisclosableClaims []string `json:"disclosableClaims,omitempty"`
+	// HolderVerificationMethod binds "sd-jwt" presentations to the key of the given holder verification method.
+	HolderVerificationMethod string `json:"holderVerificationMethod,omitempty"`
 }
 
 // GenerateKeyPairRequest is request for generating key pair
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -56,6 +57,96 @@ type issuerProfileRes struct { // nolint: unused,deadcode
 	model.DataProfile
 }
 
+// listProfilesReq model
+//
+// swagger:parameters listProfilesReq
+type listProfilesReq struct { // nolint: unused,deadcode
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// listProfilesRes model
+//
+// swagger:response listProfilesRes
+type listProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	Body ListProfilesResponse
+}
+
+// updateProfileReq model
+//
+// swagger:parameters updateProfileReq
+type updateProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateProfileRequest
+}
+
+// rotateKeyReq model
+//
+// swagger:parameters rotateKeyReq
+type rotateKeyReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RotateKeyRequest
+}
+
+// addCredentialTemplateReq model
+//
+// swagger:parameters addCredentialTemplateReq
+type addCredentialTemplateReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params vcprofile.CredentialTemplate
+}
+
+// credentialTemplateRes model
+//
+// swagger:response credentialTemplateRes
+type credentialTemplateRes struct { // nolint: unused,deadcode
+	// in: body
+	vcprofile.CredentialTemplate
+}
+
+// deleteCredentialTemplateReq model
+//
+// swagger:parameters deleteCredentialTemplateReq
+type deleteCredentialTemplateReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// template
+	//
+	// in: path
+	// required: true
+	TemplateID string `json:"templateID"`
+}
+
 // issueCredentialReq model
 //
 // swagger:parameters issueCredentialReq
@@ -70,6 +161,64 @@ type issueCredentialReq struct { // nolint: unused,deadcode
 	Params IssueCredentialRequest
 }
 
+// refreshCredentialReq model
+//
+// swagger:parameters refreshCredentialReq
+type refreshCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RefreshCredentialRequest
+}
+
+// refreshChallengeReq model
+//
+// swagger:parameters refreshChallengeReq
+type refreshChallengeReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RefreshChallengeRequest
+}
+
+// refreshChallengeRes model
+//
+// swagger:response refreshChallengeRes
+type refreshChallengeRes struct { // nolint: unused,deadcode
+	// in: body
+	RefreshChallengeResponse
+}
+
+// batchIssueCredentialReq model
+//
+// swagger:parameters batchIssueCredentialReq
+type batchIssueCredentialReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params BatchIssueCredentialRequest
+}
+
+// batchIssueCredentialResp model
+//
+// swagger:response batchIssueCredentialResp
+type batchIssueCredentialResp struct { // nolint: unused,deadcode
+	// in: body
+	Body BatchIssueCredentialResponse
+}
+
 // issueCredentialReq model for OpenAPI annotation
 //
 // swagger:parameters composeCredentialReq
@@ -144,6 +293,127 @@ type updateCredentialStatusReq struct { // nolint: unused,deadcode
 	Params UpdateCredentialStatusRequest
 }
 
+// batchUpdateCredentialStatusReq model
+//
+// swagger:parameters batchUpdateCredentialStatusReq
+type batchUpdateCredentialStatusReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params BatchUpdateCredentialStatusRequest
+}
+
+// batchUpdateCredentialStatusResp model
+//
+// swagger:response batchUpdateCredentialStatusResp
+type batchUpdateCredentialStatusResp struct { // nolint: unused,deadcode
+	// in: body
+	Body BatchUpdateCredentialStatusResponse
+}
+
+// credentialStatusHistoryReq model
+//
+// swagger:parameters credentialStatusHistoryReq
+type credentialStatusHistoryReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// CredentialID
+	//
+	// in: query
+	// required: true
+	CredentialID string `json:"credentialId"`
+}
+
+// credentialStatusHistoryResp model
+//
+// swagger:response credentialStatusHistoryResp
+type credentialStatusHistoryResp struct { // nolint: unused,deadcode
+	// in: body
+	Body CredentialStatusHistoryResponse
+}
+
+// listCredentialsReq model
+//
+// swagger:parameters listCredentialsReq
+type listCredentialsReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// searchCredentialsReq model
+//
+// swagger:parameters searchCredentialsReq
+type searchCredentialsReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// Subject the credentials are issued to
+	//
+	// in: query
+	Subject string `json:"subject"`
+
+	// Type of the credentials
+	//
+	// in: query
+	Type string `json:"type"`
+
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// listCredentialsResp model
+//
+// swagger:response listCredentialsResp
+type listCredentialsResp struct { // nolint: unused,deadcode
+	// in: body
+	Body ListCredentialsResponse
+}
+
+// revokeCredentialsReq model
+//
+// swagger:parameters revokeCredentialsReq
+type revokeCredentialsReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params RevokeCredentialsRequest
+}
+
 // retrieveCredentialStatusReq model
 //
 // swagger:parameters retrieveCredentialStatusReq
@@ -159,6 +429,11 @@ type retrieveCredentialStatusReq struct { // nolint: unused,deadcode
 	// in: path
 	// required: true
 	StatusID string `json:"statusID"`
+
+	// ValidAt returns the status list as it was at the given RFC3339 time
+	//
+	// in: query
+	ValidAt string `json:"validAt"`
 }
 
 // retrieveCredentialStatusResp model
@@ -8,6 +8,7 @@ package operation
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
@@ -39,11 +40,17 @@ import (
 	"github.com/trustbloc/edv/pkg/restapi/models"
 
 	zcapsvc "github.com/trustbloc/edge-service/pkg/auth/zcapld"
+	"github.com/trustbloc/edge-service/pkg/did"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/status/audit"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
 	"github.com/trustbloc/edge-service/pkg/internal/cryptosetup"
+	"github.com/trustbloc/edge-service/pkg/lock"
 	commondid "github.com/trustbloc/edge-service/pkg/restapi/internal/common/did"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 	"github.com/trustbloc/edge-service/pkg/restapi/internal/common/vcutil"
@@ -58,17 +65,37 @@ const (
 	createProfileEndpoint          = "/profile"
 	getProfileEndpoint             = createProfileEndpoint + "/{id}"
 	deleteProfileEndpoint          = createProfileEndpoint + "/{id}"
+	updateProfileEndpoint          = createProfileEndpoint + "/{id}"
+	credentialTemplatesEndpoint    = getProfileEndpoint + "/templates"
+	rotateKeyEndpoint              = getProfileEndpoint + "/rotateKey"
+	credentialTemplateEndpoint     = credentialTemplatesEndpoint + "/{" + templateIDPathParam + "}"
 	storeCredentialEndpoint        = "/store"
 	retrieveCredentialEndpoint     = "/retrieve"
 	credentialStatus               = "/status"
 	credentialStatusEndpoint       = "/" + "{" + profileIDPathParam + "}" + credentialStatus + "/{id}"
+	suspensionStatusEndpoint       = credentialStatusEndpoint + "/" + cslstatus.StatusPurposeSuspension
 	credentialsBasePath            = "/" + "{" + profileIDPathParam + "}" + "/credentials"
 	updateCredentialStatusEndpoint = credentialsBasePath + credentialStatus
+	batchUpdateStatusEndpoint      = updateCredentialStatusEndpoint + "/batch"
+	credentialStatusHistoryPath    = updateCredentialStatusEndpoint + "/history"
 	issueCredentialPath            = credentialsBasePath + "/issue"
+	batchIssueCredentialPath       = issueCredentialPath + "/batch"
+	refreshCredentialPath          = credentialsBasePath + "/refresh"
+	refreshChallengePath           = refreshCredentialPath + "/challenge"
+	searchCredentialsPath          = credentialsBasePath + "/search"
+	revokeCredentialsPath          = credentialsBasePath + "/revoke"
 	composeAndIssueCredentialPath  = credentialsBasePath + "/composeAndIssueCredential"
 	kmsBasePath                    = "/kms"
 	generateKeypairPath            = kmsBasePath + "/generatekeypair"
 
+	validAtQueryParam      = "validAt"
+	credentialIDQueryParam = "credentialId"
+	subjectQueryParam      = "subject"
+	typeQueryParam         = "type"
+
+	// callerIDHeader carries the identity the caller claims, the service doesn't authenticate it
+	callerIDHeader = "X-Caller-ID"
+
 	cslSize = 1000
 
 	invalidRequestErrMsg = "Invalid request"
@@ -89,6 +116,7 @@ var logger = log.New("edge-service-issuer-restapi")
 var (
 	errProfileNotFound  = errors.New("specified profile ID does not exist")
 	errNoDocsMatchQuery = errors.New("no documents match the given query")
+	errInvalidValidAt   = errors.New("invalid validAt, RFC3339 time expected")
 )
 
 var errMultipleInconsistentVCsFoundForOneID = errors.New("multiple VCs with " +
@@ -103,9 +131,30 @@ type Handler interface {
 }
 
 type vcStatusManager interface {
-	CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error)
-	UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool) error
-	GetRevocationListVC(id string) ([]byte, error)
+	CreateStatusID(profile *vcprofile.DataProfile, url string,
+		opts ...cslstatus.StatusOpts) (*verifiable.TypedID, error)
+	CreateStatusIDs(profile *vcprofile.DataProfile, url string, count int,
+		opts ...cslstatus.StatusOpts) ([]*verifiable.TypedID, error)
+	UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool,
+		opts ...cslstatus.StatusOpts) error
+	UpdateVCs(vcs []*verifiable.Credential, profile *vcprofile.DataProfile, status bool,
+		opts ...cslstatus.StatusOpts) []error
+	GetStatusListVC(id string) (*cslstatus.StatusListVC, error)
+	GetStatusListVCAt(id string, t time.Time) (*cslstatus.StatusListVC, error)
+	ReleaseStatusIDs(statusIDs []*verifiable.TypedID) error
+	GetStatus(vcStatus *verifiable.TypedID, purpose string) (bool, error)
+}
+
+type credentialRegistry interface {
+	Add(profileID string, record *registry.Record) error
+	Update(profileID string, record *registry.Record) error
+	Get(profileID, credentialID string) (*registry.Record, error)
+	Find(profileID string, q *registry.Query, page, pageSize int) ([]*registry.Record, int, error)
+}
+
+type statusAuditStore interface {
+	Add(profileID string, records ...*audit.Record) error
+	Get(profileID, credentialID string) ([]*audit.Record, error)
 }
 
 // EDVClient interface to interact with edv client
@@ -128,13 +177,19 @@ type keyManager interface {
 type commonDID interface {
 	CreateDID(keyType, signatureType, did, privateKey, keyID, purpose string,
 		registrar model.UNIRegistrar) (string, string, error)
+	RotateKey(didID, creator, keyID, privateKey string) (string, error)
 }
 
 // New returns CreateCredential instance
 func New(config *Config) (*Operation, error) {
 	c := crypto.New(config.KeyManager, config.Crypto, config.VDRI, config.DocumentLoader)
 
-	vcStatusManager, err := cslstatus.New(config.StoreProvider, cslSize, c, config.DocumentLoader)
+	var statusOpts []cslstatus.Opt
+	if config.StatusListLocker != nil {
+		statusOpts = append(statusOpts, cslstatus.WithLocker(config.StatusListLocker))
+	}
+
+	vcStatusManager, err := cslstatus.New(config.StoreProvider, cslSize, c, config.DocumentLoader, statusOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to instantiate new csl status: %w", err)
 	}
@@ -156,14 +211,50 @@ func New(config *Config) (*Operation, error) {
 		return nil, err
 	}
 
+	auditStore, err := audit.New(config.StoreProvider)
+	if err != nil {
+		return nil, err
+	}
+
 	contextOp, err := jsonldcontextrest.New(&storeProvider{config.StoreProvider})
 	if err != nil {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	schemaLoader := config.SchemaLoader
+	if schemaLoader == nil {
+		schemaLoader = schema.NewHTTPLoader(&http.Client{Transport: &http.Transport{TLSClientConfig: config.TLSConfig}})
+	}
+
+	profileLocker := config.ProfileLocker
+	if profileLocker == nil {
+		profileLocker = lock.NewMemLocker()
+	}
+
+	credentialRegistry, err := registry.New(config.StoreProvider, registry.WithLocker(profileLocker))
+	if err != nil {
+		return nil, err
+	}
+
+	challengeOpts := []verifier.ChallengeStoreOpt{verifier.WithChallengeStoreName(refreshChallengeStoreName)}
+	if config.ChallengeLocker != nil {
+		challengeOpts = append(challengeOpts, verifier.WithChallengeLocker(config.ChallengeLocker))
+	}
+
+	challengeStore, err := verifier.NewChallengeStore(config.StoreProvider, challengeOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeTTL := config.ChallengeTTL
+	if challengeTTL == 0 {
+		challengeTTL = defaultRefreshChallengeTTL
+	}
+
 	svc := &Operation{
 		authService:          zcapsvc.New(config.KeyManager, config.Crypto),
 		profileStore:         p,
+		profileLocker:        profileLocker,
 		edvClient:            config.EDVClient,
 		kms:                  config.KeyManager,
 		vdr:                  config.VDRI,
@@ -171,6 +262,8 @@ func New(config *Config) (*Operation, error) {
 		jweEncrypter:         jweEncrypter,
 		jweDecrypter:         jweDecrypter,
 		vcStatusManager:      vcStatusManager,
+		statusAuditStore:     auditStore,
+		credentialRegistry:   credentialRegistry,
 		domain:               config.Domain,
 		hostURL:              config.HostURL,
 		macKeyHandle:         kh,
@@ -179,11 +272,20 @@ func New(config *Config) (*Operation, error) {
 		commonDID: commondid.New(&commondid.Config{
 			VDRI: config.VDRI, KeyManager: config.KeyManager,
 			Domain: config.Domain, TLSConfig: config.TLSConfig,
-			DIDAnchorOrigin: config.DIDAnchorOrigin,
+			DIDAnchorOrigin: config.DIDAnchorOrigin, OperationKeys: config.DIDOperationKeys,
 		}),
 		retryParameters:         config.RetryParameters,
 		documentLoader:          config.DocumentLoader,
 		addJSONLDContextHandler: contextOp.Add,
+		statusListMaxAge:        config.StatusListMaxAge,
+		schemaValidator:         schema.NewValidator(schemaLoader),
+		batchIssuanceWorkers:    config.BatchIssuanceWorkers,
+		challengeStore:          challengeStore,
+		challengeTTL:            challengeTTL,
+	}
+
+	if svc.batchIssuanceWorkers <= 0 {
+		svc.batchIssuanceWorkers = defaultBatchIssuanceWorkers
 	}
 
 	return svc, nil
@@ -203,11 +305,27 @@ type Config struct {
 	RetryParameters    *retry.Params
 	DIDAnchorOrigin    string
 	DocumentLoader     ld.DocumentLoader
+	StatusListMaxAge   time.Duration
+	// SchemaLoader loads the credential schemas issued credentials are validated against, fetched over HTTP if not set
+	SchemaLoader schema.Loader
+	// BatchIssuanceWorkers is the number of credentials of a batch signed in parallel, 8 if not set
+	BatchIssuanceWorkers int
+	// DIDOperationKeys keeps the update keys of the orb DIDs of new profiles, so that their key can be rotated
+	DIDOperationKeys *did.OperationKeyStore
+	// StatusListLocker guards updates of the status lists shared with other instances, in-memory locker if not set
+	StatusListLocker cslstatus.Locker
+	// ProfileLocker guards updates of the profiles shared with other instances, in-memory locker if not set
+	ProfileLocker lock.Locker
+	// ChallengeTTL is the time the challenges issued for refresh presentations can be used for, 5 minutes if not set
+	ChallengeTTL time.Duration
+	// ChallengeLocker locks a challenge while it is consumed across the instances, in-process lock if not set
+	ChallengeLocker lock.Locker
 }
 
 // Operation defines handlers for Edge service
 type Operation struct {
 	profileStore            *vcprofile.Profile
+	profileLocker           lock.Locker
 	edvClient               EDVClient
 	kms                     keyManager
 	vdr                     vdrapi.Registry
@@ -215,6 +333,8 @@ type Operation struct {
 	jweEncrypter            jose.Encrypter
 	jweDecrypter            jose.Decrypter
 	vcStatusManager         vcStatusManager
+	statusAuditStore        statusAuditStore
+	credentialRegistry      credentialRegistry
 	domain                  string
 	hostURL                 string
 	macKeyHandle            *keyset.Handle
@@ -225,6 +345,11 @@ type Operation struct {
 	authService             authService
 	documentLoader          ld.DocumentLoader
 	addJSONLDContextHandler http.HandlerFunc
+	statusListMaxAge        time.Duration
+	schemaValidator         *schema.Validator
+	batchIssuanceWorkers    int
+	challengeStore          *verifier.ChallengeStore
+	challengeTTL            time.Duration
 }
 
 // GetRESTHandlers get all controller API handler available for this service
@@ -232,8 +357,14 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		// issuer profile
 		support.NewHTTPHandler(createProfileEndpoint, http.MethodPost, o.createIssuerProfileHandler),
+		support.NewHTTPHandler(createProfileEndpoint, http.MethodGet, o.listIssuerProfilesHandler),
 		support.NewHTTPHandler(getProfileEndpoint, http.MethodGet, o.getIssuerProfileHandler),
+		support.NewHTTPHandler(updateProfileEndpoint, http.MethodPut, o.updateIssuerProfileHandler),
+		support.NewHTTPHandler(updateProfileEndpoint, http.MethodPatch, o.patchIssuerProfileHandler),
 		support.NewHTTPHandler(deleteProfileEndpoint, http.MethodDelete, o.deleteIssuerProfileHandler),
+		support.NewHTTPHandler(rotateKeyEndpoint, http.MethodPost, o.rotateKeyHandler),
+		support.NewHTTPHandler(credentialTemplatesEndpoint, http.MethodPost, o.addCredentialTemplateHandler),
+		support.NewHTTPHandler(credentialTemplateEndpoint, http.MethodDelete, o.deleteCredentialTemplateHandler),
 
 		// verifiable credential store
 		support.NewHTTPHandler(storeCredentialEndpoint, http.MethodPost, o.storeCredentialHandler),
@@ -241,11 +372,22 @@ func (o *Operation) GetRESTHandlers() []Handler {
 
 		// verifiable credential status
 		support.NewHTTPHandler(updateCredentialStatusEndpoint, http.MethodPost, o.updateCredentialStatusHandler),
+		support.NewHTTPHandler(batchUpdateStatusEndpoint, http.MethodPost, o.batchUpdateCredentialStatusHandler),
+		support.NewHTTPHandler(credentialStatusHistoryPath, http.MethodGet, o.credentialStatusHistoryHandler),
 		support.NewHTTPHandler(credentialStatusEndpoint, http.MethodGet, o.retrieveCredentialStatus),
+		support.NewHTTPHandler(suspensionStatusEndpoint, http.MethodGet, o.retrieveCredentialStatus),
+
+		// issued credential registry
+		support.NewHTTPHandler(credentialsBasePath, http.MethodGet, o.listCredentialsHandler),
+		support.NewHTTPHandler(searchCredentialsPath, http.MethodGet, o.searchCredentialsHandler),
+		support.NewHTTPHandler(revokeCredentialsPath, http.MethodPost, o.revokeCredentialsHandler),
 
 		// issuer apis
 		support.NewHTTPHandler(generateKeypairPath, http.MethodGet, o.generateKeypairHandler),
 		support.NewHTTPHandler(issueCredentialPath, http.MethodPost, o.issueCredentialHandler),
+		support.NewHTTPHandler(batchIssueCredentialPath, http.MethodPost, o.batchIssueCredentialHandler),
+		support.NewHTTPHandler(refreshCredentialPath, http.MethodPost, o.refreshCredentialHandler),
+		support.NewHTTPHandler(refreshChallengePath, http.MethodPost, o.issueRefreshChallengeHandler),
 		support.NewHTTPHandler(composeAndIssueCredentialPath, http.MethodPost, o.composeAndIssueCredentialHandler),
 
 		// JSON-LD contexts API
@@ -261,21 +403,110 @@ func (o *Operation) GetRESTHandlers() []Handler {
 //    default: genericError
 //        200: retrieveCredentialStatusResp
 func (o *Operation) retrieveCredentialStatus(rw http.ResponseWriter, req *http.Request) {
-	revocationListVCBytes, err := o.vcStatusManager.GetRevocationListVC(o.hostURL + req.RequestURI)
+	statusList, validAt, err := o.getStatusList(req)
 	if err != nil {
+		if errors.Is(err, errInvalidValidAt) {
+			commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
 			fmt.Sprintf("failed to get credential status list: %s", err.Error()))
 
 		return
 	}
 
+	etag := statusListETag(statusList.VC)
+
+	rw.Header().Set("ETag", etag)
+	rw.Header().Set("Cache-Control", o.statusListCacheControl())
+
+	if statusList.Updated != nil {
+		rw.Header().Set("Last-Modified", statusList.Updated.UTC().Format(http.TimeFormat))
+	}
+
+	if validAt != nil {
+		rw.Header().Set(cslstatus.ValidAtHeader, validAt.UTC().Format(time.RFC3339))
+	}
+
+	if notModified(req, etag, statusList.Updated) {
+		rw.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
 	rw.WriteHeader(http.StatusOK)
 
-	if _, err = rw.Write(revocationListVCBytes); err != nil {
+	if _, err = rw.Write(statusList.VC); err != nil {
 		logger.Errorf("Unable to send response, %s", err)
 	}
 }
 
+// getStatusList returns the current status list, or the one published at the time given in validAt query parameter
+// along with that time.
+func (o *Operation) getStatusList(req *http.Request) (*cslstatus.StatusListVC, *time.Time, error) {
+	id := o.hostURL + strings.Split(req.RequestURI, "?")[0]
+
+	validAt := req.URL.Query().Get(validAtQueryParam)
+	if validAt == "" {
+		statusList, err := o.vcStatusManager.GetStatusListVC(id)
+
+		return statusList, nil, err
+	}
+
+	t, err := time.Parse(time.RFC3339, validAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", errInvalidValidAt, err.Error())
+	}
+
+	statusList, err := o.vcStatusManager.GetStatusListVCAt(id, t)
+
+	return statusList, &t, err
+}
+
+// statusListCacheControl tells clients how long they may use the status list before checking it again,
+// without max age configured the list has to be revalidated on every use.
+func (o *Operation) statusListCacheControl() string {
+	if o.statusListMaxAge <= 0 {
+		return "no-cache"
+	}
+
+	return fmt.Sprintf("max-age=%d", int64(o.statusListMaxAge/time.Second))
+}
+
+func statusListETag(vc []byte) string {
+	sum := sha256.Sum256(vc)
+
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// notModified checks the conditional headers of the request, If-Modified-Since is ignored if If-None-Match is set.
+func notModified(req *http.Request, etag string, updated *time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ims := req.Header.Get("If-Modified-Since")
+	if ims == "" || updated == nil {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	return !updated.Truncate(time.Second).After(since)
+}
+
 // UpdateCredentialStatus swagger:route POST /{id}/credentials/status issuer updateCredentialStatusReq
 //
 // Updates credential status.
@@ -310,55 +541,463 @@ func (o *Operation) updateCredentialStatusHandler(rw http.ResponseWriter, req *h
 		return
 	}
 
-	if data.CredentialStatus.Type != cslstatus.RevocationList2020Status {
+	if !isSupportedVCStatusType(data.CredentialStatus.Type) {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
 			fmt.Sprintf("credential status %s not supported", data.CredentialStatus.Type))
 
 		return
 	}
 
-	docURLs, err := o.queryVault(profile.EDVVaultID, profile.EDVCapability, profile.EDVController, data.CredentialID)
+	if !isSupportedStatusPurpose(data.CredentialStatus.StatusPurpose) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("credential status purpose %s not supported", data.CredentialStatus.StatusPurpose))
+
+		return
+	}
+
+	if data.CredentialStatus.ReasonCode != "" && !audit.IsSupportedReasonCode(data.CredentialStatus.ReasonCode) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("credential status reason code %s not supported", data.CredentialStatus.ReasonCode))
+
+		return
+	}
+
+	vc, status, err := o.getStoredCredential(profile, data.CredentialID)
 	if err != nil {
-		// The case where no docs match the given query is handled in o.retrieveCredential.
-		// Any other error is unexpected and is handled here.
-		if !errors.Is(err, errNoDocsMatchQuery) {
-			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	statusValue, err := strconv.ParseBool(data.CredentialStatus.Status)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to parse status: %s", err.Error()))
+
+		return
+	}
+
+	if err := o.vcStatusManager.UpdateVC(vc, profile.DataProfile, statusValue,
+		cslstatus.WithStatusPurpose(data.CredentialStatus.StatusPurpose)); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to update vc status: %s", err.Error()))
+		return
+	}
+
+	record := newStatusRecord(req, data.CredentialID, &data.CredentialStatus, statusValue, time.Now().UTC())
+
+	if err := o.statusAuditStore.Add(profile.Name, record); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("vc status updated but failed to record the change: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// BatchUpdateCredentialStatus swagger:route POST /{id}/credentials/status/batch issuer batchUpdateCredentialStatusReq
+//
+// Updates status of many credentials at once, every status list affected is signed only once.
+//
+// Responses:
+//    default: genericError
+//        200: batchUpdateCredentialStatusResp
+func (o *Operation) batchUpdateCredentialStatusHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if profile.DisableVCStatus {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("vc status is disabled for profile %s", profile.Name))
+
+		return
+	}
+
+	data := BatchUpdateCredentialStatusRequest{}
+
+	err = json.NewDecoder(req.Body).Decode(&data)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to decode request received: %s", err.Error()))
+
+		return
+	}
+
+	switch {
+	case len(data.CredentialIDs) == 0:
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credential IDs")
+
+		return
+	case len(data.CredentialIDs) > maxBatchSize:
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("batch of %d credential IDs exceeds the maximum of %d", len(data.CredentialIDs), maxBatchSize))
+
+		return
+	}
+
+	if !isSupportedVCStatusType(data.CredentialStatus.Type) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("credential status %s not supported", data.CredentialStatus.Type))
+
+		return
+	}
+
+	if !isSupportedStatusPurpose(data.CredentialStatus.StatusPurpose) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("credential status purpose %s not supported", data.CredentialStatus.StatusPurpose))
+
+		return
+	}
+
+	if data.CredentialStatus.ReasonCode != "" && !audit.IsSupportedReasonCode(data.CredentialStatus.ReasonCode) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("credential status reason code %s not supported", data.CredentialStatus.ReasonCode))
+
+		return
+	}
+
+	statusValue, err := strconv.ParseBool(data.CredentialStatus.Status)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+			fmt.Sprintf("failed to parse status: %s", err.Error()))
+
+		return
+	}
+
+	results := make([]CredentialStatusResult, len(data.CredentialIDs))
+	stored := make([]*verifiable.Credential, len(data.CredentialIDs))
+
+	o.inParallel(len(data.CredentialIDs), func(i int) {
+		results[i].CredentialID = data.CredentialIDs[i]
+
+		vc, _, errGet := o.getStoredCredential(profile, data.CredentialIDs[i])
+		if errGet != nil {
+			results[i].Error = errGet.Error()
+
 			return
 		}
+
+		stored[i] = vc
+	})
+
+	var (
+		vcs       []*verifiable.Credential
+		positions []int
+	)
+
+	for i, vc := range stored {
+		if vc != nil {
+			vcs = append(vcs, vc)
+			positions = append(positions, i)
+		}
 	}
 
-	vcBytes, status, err := o.retrieveCredential(profileID, profile.EDVVaultID, docURLs,
-		profile.EDVCapability, profile.EDVController)
+	if len(vcs) != 0 {
+		o.updateVCsStatus(req, profile, vcs, positions, &data.CredentialStatus, statusValue, results)
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &BatchUpdateCredentialStatusResponse{Results: results})
+}
+
+// updateVCsStatus updates status of the given vcs and records the changes, outcome for every vc is
+// reported at its position in results.
+func (o *Operation) updateVCsStatus(req *http.Request, profile *vcprofile.IssuerProfile,
+	vcs []*verifiable.Credential, positions []int, status *CredentialStatus, statusValue bool,
+	results []CredentialStatusResult) {
+	errs := o.vcStatusManager.UpdateVCs(vcs, profile.DataProfile, statusValue,
+		cslstatus.WithStatusPurpose(status.StatusPurpose))
+
+	var (
+		records        []*audit.Record
+		updatedResults []int
+	)
+
+	now := time.Now().UTC()
+
+	for i, errUpdate := range errs {
+		if errUpdate != nil {
+			results[positions[i]].Error = fmt.Sprintf("failed to update vc status: %s", errUpdate.Error())
+
+			continue
+		}
+
+		results[positions[i]].Updated = true
+
+		records = append(records, newStatusRecord(req, results[positions[i]].CredentialID, status, statusValue, now))
+		updatedResults = append(updatedResults, positions[i])
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	if err := o.statusAuditStore.Add(profile.Name, records...); err != nil {
+		logger.Errorf("failed to record status change of profile %s: %s", profile.Name, err)
+
+		for _, i := range updatedResults {
+			results[i].Error = fmt.Sprintf("vc status updated but failed to record the change: %s", err.Error())
+		}
+	}
+}
+
+// CredentialStatusHistory swagger:route GET /{id}/credentials/status/history issuer credentialStatusHistoryReq
+//
+// Returns the status changes of a credential, oldest first.
+//
+// Responses:
+//    default: genericError
+//        200: credentialStatusHistoryResp
+func (o *Operation) credentialStatusHistoryHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	credentialID := req.URL.Query().Get(credentialIDQueryParam)
+	if credentialID == "" {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing credential ID")
+
+		return
+	}
+
+	history, err := o.statusAuditStore.Get(profile.Name, credentialID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to get credential status history: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &CredentialStatusHistoryResponse{CredentialID: credentialID, History: history})
+}
+
+// ListCredentials swagger:route GET /{id}/credentials issuer listCredentialsReq
+//
+// Lists the credentials issued by the profile, latest issued first.
+//
+// Responses:
+//    default: genericError
+//        200: listCredentialsResp
+func (o *Operation) listCredentialsHandler(rw http.ResponseWriter, req *http.Request) {
+	o.findCredentials(rw, req, &registry.Query{})
+}
+
+// SearchCredentials swagger:route GET /{id}/credentials/search issuer searchCredentialsReq
+//
+// Searches the credentials issued by the profile by subject and type, latest issued first.
+//
+// Responses:
+//    default: genericError
+//        200: listCredentialsResp
+func (o *Operation) searchCredentialsHandler(rw http.ResponseWriter, req *http.Request) {
+	o.findCredentials(rw, req, &registry.Query{
+		Subject: req.URL.Query().Get(subjectQueryParam),
+		Type:    req.URL.Query().Get(typeQueryParam),
+	})
+}
+
+func (o *Operation) findCredentials(rw http.ResponseWriter, req *http.Request, q *registry.Query) {
+	profile, status, err := o.getRegistryProfile(req)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, status, err.Error())
 
 		return
 	}
 
-	vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
-		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	page, pageSize, err := commhttp.GetPage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	records, total, err := o.credentialRegistry.Find(profile.Name, q, page, pageSize)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to find credentials: %s", err.Error()))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &ListCredentialsResponse{
+		Credentials: records,
+		Page:        page,
+		PageSize:    pageSize,
+		Total:       total,
+	})
+}
+
+// RevokeCredentials swagger:route POST /{id}/credentials/revoke issuer revokeCredentialsReq
+//
+// Revokes all the credentials the profile issued to a subject.
+//
+// Responses:
+//    default: genericError
+//        200: batchUpdateCredentialStatusResp
+func (o *Operation) revokeCredentialsHandler(rw http.ResponseWriter, req *http.Request) {
+	profile, status, err := o.getRegistryProfile(req)
 	if err != nil {
+		commhttp.WriteErrorResponse(rw, status, err.Error())
+
+		return
+	}
+
+	if profile.DisableVCStatus {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to parse credential: %s", err.Error()))
+			fmt.Sprintf("vc status is disabled for profile %s", profile.Name))
 
 		return
 	}
 
-	statusValue, err := strconv.ParseBool(data.CredentialStatus.Status)
+	data := RevokeCredentialsRequest{}
+
+	err = json.NewDecoder(req.Body).Decode(&data)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to parse status: %s", err.Error()))
+			fmt.Sprintf("failed to decode request received: %s", err.Error()))
+
+		return
+	}
+
+	if data.Subject == "" {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "missing subject")
 
 		return
 	}
 
-	if err := o.vcStatusManager.UpdateVC(vc, profile.DataProfile, statusValue); err != nil {
+	if data.ReasonCode != "" && !audit.IsSupportedReasonCode(data.ReasonCode) {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to update vc status: %s", err.Error()))
+			fmt.Sprintf("credential status reason code %s not supported", data.ReasonCode))
+
 		return
 	}
 
+	records, _, err := o.credentialRegistry.Find(profile.Name, &registry.Query{Subject: data.Subject}, 0, 0)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to find credentials: %s", err.Error()))
+
+		return
+	}
+
+	results := make([]CredentialStatusResult, len(records))
+	vcs := make([]*verifiable.Credential, len(records))
+	positions := make([]int, len(records))
+
+	for i, record := range records {
+		results[i].CredentialID = record.ID
+		vcs[i] = record.Credential()
+		positions[i] = i
+	}
+
+	if len(vcs) != 0 {
+		o.updateVCsStatus(req, profile, vcs, positions, &CredentialStatus{
+			Status:        strconv.FormatBool(true),
+			StatusPurpose: cslstatus.StatusPurposeRevocation,
+			ReasonCode:    data.ReasonCode,
+			Comment:       data.Comment,
+		}, true, results)
+	}
+
 	rw.WriteHeader(http.StatusOK)
+	commhttp.WriteResponse(rw, &BatchUpdateCredentialStatusResponse{Results: results})
+}
+
+// getRegistryProfile returns the issuer profile of the request if it keeps a credential registry, along with
+// the http status to respond with otherwise.
+func (o *Operation) getRegistryProfile(req *http.Request) (*vcprofile.IssuerProfile, int, error) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid issuer profile - id=%s: err=%w", profileID, err)
+	}
+
+	if !profile.CredentialRegistry {
+		return nil, http.StatusBadRequest, fmt.Errorf("credential registry is disabled for profile %s", profile.Name)
+	}
+
+	return profile, http.StatusOK, nil
+}
+
+// newStatusRecord returns the audit record of the requested status change.
+func newStatusRecord(req *http.Request, credentialID string, status *CredentialStatus, statusValue bool,
+	timestamp time.Time) *audit.Record {
+	purpose := status.StatusPurpose
+	if purpose == "" {
+		purpose = cslstatus.StatusPurposeRevocation
+	}
+
+	reasonCode := status.ReasonCode
+	if reasonCode == "" {
+		reasonCode = audit.ReasonUnspecified
+	}
+
+	return &audit.Record{
+		CredentialID:  credentialID,
+		StatusPurpose: purpose,
+		Status:        statusValue,
+		ReasonCode:    reasonCode,
+		Comment:       status.Comment,
+		ClaimedCaller: req.Header.Get(callerIDHeader),
+		Timestamp:     timestamp,
+	}
+}
+
+// getStoredCredential returns the credential stored under the given profile, along with the http status
+// to respond with if it can't be fetched.
+func (o *Operation) getStoredCredential(profile *vcprofile.IssuerProfile,
+	credentialID string) (*verifiable.Credential, int, error) {
+	if profile.CredentialRegistry {
+		record, err := o.credentialRegistry.Get(profile.Name, credentialID)
+		if err == nil {
+			return record.Credential(), http.StatusOK, nil
+		}
+
+		// credentials issued before the registry was enabled are looked up in the vault
+		if !errors.Is(err, registry.ErrNotFound) {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	docURLs, err := o.queryVault(profile.EDVVaultID, profile.EDVCapability, profile.EDVController, credentialID)
+	if err != nil {
+		// The case where no docs match the given query is handled in o.retrieveCredential.
+		// Any other error is unexpected and is handled here.
+		if !errors.Is(err, errNoDocsMatchQuery) {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	vcBytes, status, err := o.retrieveCredential(profile.Name, profile.EDVVaultID, docURLs,
+		profile.EDVCapability, profile.EDVController)
+	if err != nil {
+		return nil, status, err
+	}
+
+	vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to parse credential: %w", err)
+	}
+
+	return vc, http.StatusOK, nil
 }
 
 // CreateIssuerProfile swagger:route POST /profile issuer issuerProfileReq
@@ -656,7 +1295,11 @@ func (o *Operation) createIssuerProfile(pr *ProfileRequest) (*vcprofile.IssuerPr
 			SignatureType: pr.SignatureType, SignatureRepresentation: pr.SignatureRepresentation, Creator: publicKeyID,
 		},
 		URI: pr.URI, EDVCapability: capability, EDVVaultID: edvVaultID, DisableVCStatus: pr.DisableVCStatus,
-		OverwriteIssuer: pr.OverwriteIssuer, EDVController: didKey,
+		VCStatusType: pr.VCStatusType, VCStatusListSize: pr.VCStatusListSize,
+		VCStatusListBitLength: pr.VCStatusListBitLength, OverwriteIssuer: pr.OverwriteIssuer, EDVController: didKey,
+		CredentialFormat: pr.CredentialFormat, CredentialRegistry: pr.CredentialRegistry,
+		CredentialTemplates: pr.CredentialTemplates, RefreshService: pr.RefreshService,
+		ValidityPeriod: pr.ValidityPeriod,
 	}, nil
 }
 
@@ -697,6 +1340,35 @@ func validateProfileRequest(pr *ProfileRequest) error {
 		return fmt.Errorf("missing signature type")
 	}
 
+	if pr.VCStatusType != "" && !isSupportedVCStatusType(pr.VCStatusType) {
+		return fmt.Errorf("not supported credential status type : %s", pr.VCStatusType)
+	}
+
+	if pr.VCStatusListSize < 0 || pr.VCStatusListSize > cslstatus.MaxBitStringLength {
+		return fmt.Errorf("invalid credential status list size : %d", pr.VCStatusListSize)
+	}
+
+	if pr.VCStatusListBitLength < 0 || pr.VCStatusListBitLength > cslstatus.MaxBitStringLength ||
+		(pr.VCStatusListBitLength > 0 && pr.VCStatusListBitLength < pr.VCStatusListSize) {
+		return fmt.Errorf("invalid credential status list bit length : %d", pr.VCStatusListBitLength)
+	}
+
+	if !isSupportedCredentialFormat(pr.CredentialFormat) {
+		return fmt.Errorf("not supported credential format : %s", pr.CredentialFormat)
+	}
+
+	if err := validateCredentialTemplates(pr.CredentialTemplates); err != nil {
+		return err
+	}
+
+	if pr.ValidityPeriod < 0 {
+		return fmt.Errorf("invalid validity period : %d", pr.ValidityPeriod)
+	}
+
+	if err := validateRefreshService(pr.RefreshService); err != nil {
+		return err
+	}
+
 	_, err := url.Parse(pr.URI)
 	if err != nil {
 		return fmt.Errorf("invalid uri: %w", err)
@@ -705,6 +1377,19 @@ func validateProfileRequest(pr *ProfileRequest) error {
 	return nil
 }
 
+func isSupportedVCStatusType(statusType string) bool {
+	return statusType == cslstatus.RevocationList2020Status || statusType == cslstatus.StatusList2021Entry
+}
+
+func isSupportedCredentialFormat(format string) bool {
+	return format == "" || format == vcprofile.LDPCredentialFormat || format == vcprofile.JWTCredentialFormat ||
+		format == vcprofile.SDJWTCredentialFormat
+}
+
+func isSupportedStatusPurpose(purpose string) bool {
+	return purpose == "" || purpose == cslstatus.StatusPurposeRevocation || purpose == cslstatus.StatusPurposeSuspension
+}
+
 func validateRequest(profileName, vcID string) error {
 	if profileName == "" {
 		return fmt.Errorf("missing profile name")
@@ -754,44 +1439,70 @@ func (o *Operation) issueCredentialHandler(rw http.ResponseWriter, req *http.Req
 		return
 	}
 
+	format := getIssuerFormatOpts(profile, cred.Opts)
+
+	if err = format.validate(); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
 	// validate the VC (ignore the proof)
-	credential, err := verifiable.ParseCredential(cred.Credential, verifiable.WithDisabledProofCheck(),
-		verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	credential, err := o.parseCredential(cred.Credential)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to validate credential: %s", err.Error()))
 
 		return
 	}
 
+	var status *verifiable.TypedID
+
 	if !profile.DisableVCStatus {
 		// set credential status
-		credential.Status, err = o.vcStatusManager.CreateStatusID(profile.DataProfile,
-			o.hostURL+"/"+profileID+credentialStatus)
-		if err != nil {
+		var errStatus error
+
+		status, errStatus = o.vcStatusManager.CreateStatusID(profile.DataProfile,
+			o.hostURL+"/"+profileID+credentialStatus, cslstatus.WithStatusType(profile.VCStatusType),
+			cslstatus.WithListSize(profile.VCStatusListSize),
+			cslstatus.WithBitStringLength(profile.VCStatusListBitLength))
+		if errStatus != nil {
 			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to add credential status:"+
-				" %s", err.Error()))
+				" %s", errStatus.Error()))
 
 			return
 		}
 
-		credential.Context = append(credential.Context, cslstatus.Context)
+		cslstatus.SetStatus(credential, status)
 	}
 
-	// update context
-	vcutil.UpdateSignatureTypeContext(credential, profile)
-
 	// update credential issuer
 	vcutil.UpdateIssuer(credential, profile)
 
+	setRegistryCredentialID(profile, credential)
+
+	setExpirationDate(credential, profile.ValidityPeriod)
+
+	o.setRefreshService(profile, credential)
+
 	// sign the credential
-	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential, getIssuerSigningOpts(cred.Opts)...)
+	signedVC, err := signCredential(o.crypto, profile, credential, format, getIssuerSigningOpts(cred.Opts)...)
 	if err != nil {
+		o.releaseStatus(status)
+
 		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign credential:"+
 			" %s", err.Error()))
 
 		return
 	}
 
+	if err = o.registerCredential(profile, credential); err != nil {
+		o.releaseStatus(status)
+
+		commhttp.WriteErrorResponse(rw, registrationErrorStatus(err), err.Error())
+
+		return
+	}
+
 	rw.WriteHeader(http.StatusCreated)
 	commhttp.WriteResponse(rw, signedVC)
 }
@@ -824,6 +1535,40 @@ func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req
 		return
 	}
 
+	format := &credentialFormatOpts{
+		format:                   composeCredReq.CredentialFormat,
+		disclosableClaims:        composeCredReq.DisclosableClaims,
+		holderVerificationMethod: composeCredReq.HolderVerificationMethod,
+	}
+
+	if format.format == "" {
+		format.format = profile.CredentialFormat
+	}
+
+	if err = format.validate(); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	var template *vcprofile.CredentialTemplate
+
+	if composeCredReq.TemplateID != "" {
+		template = profile.CredentialTemplate(composeCredReq.TemplateID)
+		if template == nil {
+			commhttp.WriteErrorResponse(rw, http.StatusBadRequest,
+				fmt.Sprintf("credential template %s not found", composeCredReq.TemplateID))
+
+			return
+		}
+
+		if err = validateTemplateClaims(template, composeCredReq.Claims); err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
 	// create the verifiable credential
 	credential, err := buildCredential(&composeCredReq)
 	if err != nil {
@@ -833,29 +1578,54 @@ func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req
 		return
 	}
 
+	if template != nil {
+		if err = applyCredentialTemplate(credential, template); err != nil {
+			commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	if err = o.schemaValidator.Validate(credential); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to validate credential: %s", err.Error()))
+
+		return
+	}
+
+	var status *verifiable.TypedID
+
 	if !profile.DisableVCStatus {
 		// set credential status
-		credential.Status, err = o.vcStatusManager.CreateStatusID(profile.DataProfile,
-			o.hostURL+"/"+id+credentialStatus)
-		if err != nil {
+		var errStatus error
+
+		status, errStatus = o.vcStatusManager.CreateStatusID(profile.DataProfile,
+			o.hostURL+"/"+id+credentialStatus, cslstatus.WithStatusType(profile.VCStatusType),
+			cslstatus.WithListSize(profile.VCStatusListSize),
+			cslstatus.WithBitStringLength(profile.VCStatusListBitLength))
+		if errStatus != nil {
 			commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to add credential status:"+
-				" %s", err.Error()))
+				" %s", errStatus.Error()))
 
 			return
 		}
 
-		credential.Context = append(credential.Context, cslstatus.Context)
+		cslstatus.SetStatus(credential, status)
 	}
 
-	// update context
-	vcutil.UpdateSignatureTypeContext(credential, profile)
-
 	// update credential issuer
 	vcutil.UpdateIssuer(credential, profile)
 
+	setRegistryCredentialID(profile, credential)
+
+	setExpirationDate(credential, profile.ValidityPeriod)
+
+	o.setRefreshService(profile, credential)
+
 	// prepare signing options from request options
 	opts, err := getComposeSigningOpts(&composeCredReq)
 	if err != nil {
+		o.releaseStatus(status)
+
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to prepare signing options:"+
 			" %s", err.Error()))
 
@@ -863,19 +1633,148 @@ func (o *Operation) composeAndIssueCredentialHandler(rw http.ResponseWriter, req
 	}
 
 	// sign the credential
-	signedVC, err := o.crypto.SignCredential(profile.DataProfile, credential, opts...)
+	signedVC, err := signCredential(o.crypto, profile, credential, format, opts...)
 	if err != nil {
+		o.releaseStatus(status)
+
 		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign credential:"+
 			" %s", err.Error()))
 
 		return
 	}
 
+	if err = o.registerCredential(profile, credential); err != nil {
+		o.releaseStatus(status)
+
+		commhttp.WriteErrorResponse(rw, registrationErrorStatus(err), err.Error())
+
+		return
+	}
+
 	// response
 	rw.WriteHeader(http.StatusCreated)
 	commhttp.WriteResponse(rw, signedVC)
 }
 
+// setRegistryCredentialID gives an ID to the credential about to be registered, registered credentials
+// are looked up by their ID
+func setRegistryCredentialID(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) {
+	if profile.CredentialRegistry && credential.ID == "" {
+		credential.ID = uuid.New().URN()
+	}
+}
+
+// registerCredential records the issued credential when the profile keeps a credential registry
+func (o *Operation) registerCredential(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) error {
+	if !profile.CredentialRegistry {
+		return nil
+	}
+
+	if err := o.credentialRegistry.Add(profile.Name, registry.NewRecord(credential)); err != nil {
+		return fmt.Errorf("failed to register credential: %w", err)
+	}
+
+	return nil
+}
+
+// updateRegisteredCredential replaces the record of a credential issued again when the profile keeps a credential
+// registry.
+func (o *Operation) updateRegisteredCredential(profile *vcprofile.IssuerProfile,
+	credential *verifiable.Credential) error {
+	if !profile.CredentialRegistry {
+		return nil
+	}
+
+	if err := o.credentialRegistry.Update(profile.Name, registry.NewRecord(credential)); err != nil {
+		return fmt.Errorf("failed to register credential: %w", err)
+	}
+
+	return nil
+}
+
+// registrationErrorStatus returns the HTTP status of the registration error, a credential whose ID the profile
+// already registered conflicts with the one issued before.
+func registrationErrorStatus(err error) int {
+	if errors.Is(err, registry.ErrAlreadyRegistered) {
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// releaseStatus hands back the status list indexes allocated to credentials that aren't issued after all.
+func (o *Operation) releaseStatus(statusIDs ...*verifiable.TypedID) {
+	var allocated []*verifiable.TypedID
+
+	for _, s := range statusIDs {
+		if s != nil {
+			allocated = append(allocated, s)
+		}
+	}
+
+	if len(allocated) == 0 {
+		return
+	}
+
+	if err := o.vcStatusManager.ReleaseStatusIDs(allocated); err != nil {
+		logger.Warnf("failed to release status of credentials not issued: %s", err)
+	}
+}
+
+// credentialFormatOpts are the format to issue a credential in and its options
+type credentialFormatOpts struct {
+	format                   string
+	disclosableClaims        []string
+	holderVerificationMethod string
+}
+
+func (f *credentialFormatOpts) validate() error {
+	if !isSupportedCredentialFormat(f.format) {
+		return fmt.Errorf("not supported credential format : %s", f.format)
+	}
+
+	if f.format != vcprofile.SDJWTCredentialFormat &&
+		(len(f.disclosableClaims) != 0 || f.holderVerificationMethod != "") {
+		return fmt.Errorf("disclosable claims and holder binding need the %s credential format",
+			vcprofile.SDJWTCredentialFormat)
+	}
+
+	return nil
+}
+
+func getIssuerFormatOpts(profile *vcprofile.IssuerProfile, opts *IssueCredentialOptions) *credentialFormatOpts {
+	format := &credentialFormatOpts{format: profile.CredentialFormat}
+
+	if opts != nil {
+		format.disclosableClaims = opts.DisclosableClaims
+		format.holderVerificationMethod = opts.HolderVerificationMethod
+
+		if opts.CredentialFormat != "" {
+			format.format = opts.CredentialFormat
+		}
+	}
+
+	return format
+}
+
+// signCredential signs the credential in the given format.
+// Credentials in the VC-JWT and SD-JWT formats are returned as their serialized string.
+func signCredential(c *crypto.Crypto, profile *vcprofile.IssuerProfile, credential *verifiable.Credential,
+	format *credentialFormatOpts, opts ...crypto.SigningOpts) (interface{}, error) {
+	switch format.format {
+	case vcprofile.JWTCredentialFormat:
+		return c.SignCredentialJWT(profile.DataProfile, credential, opts...)
+	case vcprofile.SDJWTCredentialFormat:
+		return c.SignCredentialSDJWT(profile.DataProfile, credential, format.disclosableClaims,
+			format.holderVerificationMethod, opts...)
+	default:
+		// update context
+		vcutil.UpdateSignatureTypeContext(credential, profile)
+
+		return c.SignCredential(profile.DataProfile, credential, opts...)
+	}
+}
+
 // nolint: funlen
 func buildCredential(composeCredReq *ComposeCredentialRequest) (*verifiable.Credential, error) {
 	// create the verifiable credential
@@ -1209,7 +2108,7 @@ func validateIssueCredOptions(options *IssueCredentialOptions) error {
 			if len(idSplit) != splitAssertionMethodLength {
 				return fmt.Errorf("invalid assertion method : %s", idSplit)
 			}
-		case options.CredentialStatus.Type != "" && options.CredentialStatus.Type != cslstatus.RevocationList2020Status:
+		case options.CredentialStatus.Type != "" && !isSupportedVCStatusType(options.CredentialStatus.Type):
 			return fmt.Errorf("not supported credential status type : %s", options.CredentialStatus.Type)
 		}
 	}
@@ -8,14 +8,20 @@ package operation
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -47,9 +53,14 @@ import (
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/registry"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/status/audit"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/mock/edv"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 	"github.com/trustbloc/edge-service/pkg/restapi/model"
 )
 
@@ -245,14 +256,13 @@ func TestNew(t *testing.T) {
 	})
 }
 
-func TestUpdateCredentialStatusHandler(t *testing.T) {
+func TestBatchUpdateCredentialStatusHandler(t *testing.T) {
 	const profileID = "example_university"
 
 	client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
 	s := make(map[string]ariesmockstorage.DBEntry)
 	s["profile_issuer_example_university"] = ariesmockstorage.DBEntry{Value: []byte(testIssuerProfile)}
 	s["profile_issuer_vc_without_status"] = ariesmockstorage.DBEntry{Value: []byte(testIssuerProfileWithDisableVCStatus)}
-	s["profile_issuer_empty"] = ariesmockstorage.DBEntry{Value: []byte("{}")}
 
 	customKMS := createKMS(t)
 
@@ -273,490 +283,532 @@ func TestUpdateCredentialStatusHandler(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	updateCredentialStatusHandler := getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost)
+	batchUpdateHandler := getHandler(t, op, batchUpdateStatusEndpoint, http.MethodPost)
 
-	t.Run("update credential status success", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{}
-		op.edvClient = client
+	urlVars := map[string]string{profileIDPathParam: profileID}
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
-			fmt.Sprintf(testStructuredVCDocument, validVC))
+	newRequest := func(t *testing.T, status, purpose string) []byte {
+		t.Helper()
 
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+		reqBytes, errMarshal := json.Marshal(BatchUpdateCredentialStatusRequest{
+			CredentialIDs: []string{"http://example.edu/credentials/1872", "http://example.edu/credentials/1873"},
 			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
-
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
-
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+				Type:          cslstatus.RevocationList2020Status,
+				Status:        status,
+				StatusPurpose: purpose,
+			},
+		})
+		require.NoError(t, errMarshal)
 
-		require.Equal(t, http.StatusOK, rr.Code)
-	})
+		return reqBytes
+	}
 
-	t.Run("test disable vc status", func(t *testing.T) {
+	t.Run("batch update credential status success", func(t *testing.T) {
 		op.vcStatusManager = &mockVCStatusManager{}
 		op.edvClient = client
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op,
-			fmt.Sprintf(testStructuredVCDocument, validVCWithoutStatus),
-			fmt.Sprintf(testStructuredVCDocument, validVCWithoutStatus))
-
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = "vc_without_status"
+		rr := serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", ""), urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "vc status is disabled for profile")
+		resp := BatchUpdateCredentialStatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 2)
+		require.Equal(t, "http://example.edu/credentials/1872", resp.Results[0].CredentialID)
+		require.True(t, resp.Results[0].Updated)
+		require.Empty(t, resp.Results[0].Error)
+		require.Equal(t, "http://example.edu/credentials/1873", resp.Results[1].CredentialID)
+		require.True(t, resp.Results[1].Updated)
+
+		for _, r := range resp.Results {
+			history, errGet := op.statusAuditStore.Get("issuer", r.CredentialID)
+			require.NoError(t, errGet)
+			require.NotEmpty(t, history)
+			require.Equal(t, audit.ReasonUnspecified, history[len(history)-1].ReasonCode)
+		}
 	})
 
-	t.Run("test error decode request", func(t *testing.T) {
+	t.Run("batch update credential status - error recording status change", func(t *testing.T) {
 		op.vcStatusManager = &mockVCStatusManager{}
 		op.edvClient = client
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
+		auditStore := op.statusAuditStore
+		op.statusAuditStore = &mockStatusAuditStore{addErr: fmt.Errorf("add error")}
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, []byte("w"),
-			urlVars)
+		defer func() { op.statusAuditStore = auditStore }()
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to decode request received")
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
+
+		rr := serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", ""), urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		resp := BatchUpdateCredentialStatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 2)
+
+		for _, r := range resp.Results {
+			require.True(t, r.Updated)
+			require.Equal(t, "vc status updated but failed to record the change: add error", r.Error)
+		}
 	})
 
-	t.Run("test error from parse credential", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{}
+	t.Run("batch update credential status - update failures", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{updateVCErr: fmt.Errorf("failed to update")}
 		op.edvClient = client
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, invalidVC),
-			fmt.Sprintf(testStructuredVCDocument, invalidVC))
-
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
+		rr := serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", ""), urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+		resp := BatchUpdateCredentialStatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 2)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to parse credentia")
+		for _, r := range resp.Results {
+			require.False(t, r.Updated)
+			require.Equal(t, "failed to update vc status: failed to update", r.Error)
+		}
 	})
 
-	t.Run("test error from get profile", func(t *testing.T) {
+	t.Run("batch update credential status - credentials not found", func(t *testing.T) {
 		op.vcStatusManager = &mockVCStatusManager{}
-		op.edvClient = client
+		op.edvClient = &edv.Client{ReadDocumentError: fmt.Errorf("failed to read")}
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = "wrongProfile"
+		rr := serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "false", ""), urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, []byte("w"),
-			urlVars)
+		resp := BatchUpdateCredentialStatusResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 2)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+		for _, r := range resp.Results {
+			require.False(t, r.Updated)
+			require.Contains(t, r.Error, "no VC under profile")
+		}
 	})
 
-	t.Run("test error from update vc status", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{updateVCErr: fmt.Errorf("failed to update")}
+	t.Run("batch update credential status - invalid requests", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
 		op.edvClient = client
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
-			fmt.Sprintf(testStructuredVCDocument, validVC))
-
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
-
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
-
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+		rr := serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, []byte("w"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to decode request received")
 
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, []byte("{}"), urlVars)
 		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to update vc status")
-	})
+		require.Contains(t, rr.Body.String(), "missing credential IDs")
 
-	t.Run("test error from update vc status wrong type", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{}
-		op.edvClient = client
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint,
+			[]byte(`{"credentialIds":[`+strings.Repeat(`"a",`, maxBatchSize)+`"a"]}`), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "exceeds the maximum of")
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
-			fmt.Sprintf(testStructuredVCDocument, validVC))
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, []byte(`{"credentialIds":["a"],`+
+			`"credentialStatus":{"type":"wrongType","status":"true"}}`), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential status wrongType not supported")
 
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   "wrongType",
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", "wrongPurpose"),
+			urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential status purpose wrongPurpose not supported")
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "wrong", ""), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to parse status")
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", ""),
+			map[string]string{profileIDPathParam: "vc_without_status"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "vc status is disabled for profile")
 
+		rr = serveHTTPMux(t, batchUpdateHandler, batchUpdateStatusEndpoint, newRequest(t, "true", ""),
+			map[string]string{profileIDPathParam: "wrongProfile"})
 		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "credential status wrongType not supported")
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
 	})
+}
 
-	t.Run("test error get credential", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{}
-		op.edvClient = &edv.Client{ReadDocumentError: fmt.Errorf("failed to read")}
-
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "1",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
+func TestCredentialStatusHistoryHandler(t *testing.T) {
+	const (
+		profileID    = "example_university"
+		credentialID = "http://example.edu/credentials/1872"
+	)
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
+	s := make(map[string]ariesmockstorage.DBEntry)
+	s["profile_issuer_example_university"] = ariesmockstorage.DBEntry{Value: []byte(testIssuerProfile)}
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "no VC under profile")
+	op, err := New(&Config{
+		StoreProvider: &ariesmockstorage.MockStoreProvider{
+			Store: &ariesmockstorage.MockStore{Store: s},
+		},
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		Crypto:             customCrypto,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		HostURL:            "localhost:8080",
+		DocumentLoader:     testutil.DocumentLoader(t),
 	})
+	require.NoError(t, err)
 
-	t.Run("test error parse cred status", func(t *testing.T) {
-		op.vcStatusManager = &mockVCStatusManager{}
-		op.edvClient = client
+	historyHandler := getHandler(t, op, credentialStatusHistoryPath, http.MethodGet)
 
-		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
-			fmt.Sprintf(testStructuredVCDocument, validVC))
+	urlVars := map[string]string{profileIDPathParam: profileID}
 
-		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
-			CredentialStatus: CredentialStatus{
-				Type:   cslstatus.RevocationList2020Status,
-				Status: "wrong",
-			}}
-		ucsReqBytes, err := json.Marshal(ucsReq)
-		require.NoError(t, err)
+	t.Run("test success", func(t *testing.T) {
+		require.NoError(t, op.statusAuditStore.Add("issuer",
+			&audit.Record{CredentialID: credentialID, Status: true, ReasonCode: audit.ReasonSuperseded},
+			&audit.Record{CredentialID: credentialID, Status: false, ReasonCode: audit.ReasonUnspecified}))
 
-		urlVars := make(map[string]string)
-		urlVars[profileIDPathParam] = profileID
+		rr := serveHTTPMux(t, historyHandler, credentialStatusHistoryPath+"?"+credentialIDQueryParam+"="+
+			url.QueryEscape(credentialID), nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
 
-		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+		resp := CredentialStatusHistoryResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Equal(t, credentialID, resp.CredentialID)
+		require.Len(t, resp.History, 2)
+		require.True(t, resp.History[0].Status)
+		require.Equal(t, audit.ReasonSuperseded, resp.History[0].ReasonCode)
+		require.False(t, resp.History[1].Status)
+	})
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to parse status")
+	t.Run("test no history", func(t *testing.T) {
+		rr := serveHTTPMux(t, historyHandler, credentialStatusHistoryPath+"?"+credentialIDQueryParam+"=other",
+			nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), `"history":[]`)
 	})
-}
 
-type mockCommonDID struct {
-	createDIDValue string
-	createDIDKeyID string
-	createDIDErr   error
-}
+	t.Run("test missing credential ID", func(t *testing.T) {
+		rr := serveHTTPMux(t, historyHandler, credentialStatusHistoryPath, nil, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing credential ID")
+	})
 
-func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, keyID, purpose string,
-	registrar model.UNIRegistrar) (string, string, error) {
-	return m.createDIDValue, m.createDIDKeyID, m.createDIDErr
-}
+	t.Run("test invalid profile", func(t *testing.T) {
+		rr := serveHTTPMux(t, historyHandler, credentialStatusHistoryPath+"?"+credentialIDQueryParam+"=other",
+			nil, map[string]string{profileIDPathParam: "wrongProfile"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+	})
 
-type mockAuthService struct {
-	createDIDKeyFunc func() (string, error)
-	signHeaderFunc   func(req *http.Request, capability []byte,
-		verificationMethod string) (*http.Header, error)
-}
+	t.Run("test error getting history", func(t *testing.T) {
+		auditStore := op.statusAuditStore
+		op.statusAuditStore = &mockStatusAuditStore{getErr: fmt.Errorf("get error")}
 
-func (m *mockAuthService) CreateDIDKey() (string, error) {
-	if m.createDIDKeyFunc != nil {
-		return m.createDIDKeyFunc()
-	}
+		defer func() { op.statusAuditStore = auditStore }()
 
-	return "", nil
+		rr := serveHTTPMux(t, historyHandler, credentialStatusHistoryPath+"?"+credentialIDQueryParam+"=other",
+			nil, urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to get credential status history: get error")
+	})
 }
 
-func (m *mockAuthService) SignHeader(req *http.Request, capability []byte,
-	verificationMethod string) (*http.Header, error) {
-	if m.signHeaderFunc != nil {
-		return m.signHeaderFunc(req, capability, verificationMethod)
-	}
-
-	return nil, nil
-}
+func TestCredentialRegistry(t *testing.T) {
+	const subject = "did:example:ebfeb1f712ebc6f1c276e12ec21"
 
-func TestCreateProfileHandler(t *testing.T) {
-	client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
 	customKMS := createKMS(t)
 
 	customCrypto, err := tinkcrypto.New()
 	require.NoError(t, err)
 
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
 	op, err := New(&Config{
 		StoreProvider:      ariesmemstorage.NewProvider(),
 		KMSSecretsProvider: ariesmemstorage.NewProvider(),
-		EDVClient:          client,
 		KeyManager:         customKMS,
-		VDRI:               &vdrmock.MockVDRegistry{},
 		Crypto:             customCrypto,
-		HostURL:            "localhost:8080", Domain: "testnet",
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: testutil.DocumentLoader(t),
 	})
 	require.NoError(t, err)
 
-	op.commonDID = &mockCommonDID{}
-	op.authService = &mockAuthService{}
+	op.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+		ID:   "https://example.com/status/1#94567",
+		Type: cslstatus.RevocationList2020Status,
+		CustomFields: verifiable.CustomFields{
+			cslstatus.RevocationListIndex:      "94567",
+			cslstatus.RevocationListCredential: "https://example.com/status/1",
+		},
+	}}
 
-	createProfileHandler := getHandler(t, op, createProfileEndpoint, http.MethodPost)
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.CredentialRegistry = true
 
-	t.Run("failed to create did key", func(t *testing.T) {
-		op.authService = &mockAuthService{createDIDKeyFunc: func() (string, error) {
-			return "", fmt.Errorf("failed to create did key")
-		}}
+	require.NoError(t, op.profileStore.SaveProfile(profile))
 
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
-			bytes.NewBuffer([]byte(testIssuerProfile)))
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+	noRegistryProfile := getTestProfile()
+	noRegistryProfile.Name = "noRegistry"
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
+	require.NoError(t, op.profileStore.SaveProfile(noRegistryProfile))
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to create did key")
-	})
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
 
-	t.Run("failed to create vault", func(t *testing.T) {
-		op.authService = &mockAuthService{}
-		op.edvClient = edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, fmt.Errorf("failed to create vault"))
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
-			bytes.NewBuffer([]byte(testIssuerProfile)))
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+	issueHandler := getHandler(t, op, issueCredentialPath, http.MethodPost)
+	composeHandler := getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost)
+	listHandler := getHandler(t, op, credentialsBasePath, http.MethodGet)
+	searchHandler := getHandler(t, op, searchCredentialsPath, http.MethodGet)
+	revokeHandler := getHandler(t, op, revokeCredentialsPath, http.MethodPost)
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
+	reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: []byte(validVC)})
+	require.NoError(t, err)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "fail to create vault in EDV")
-	})
+	rr := serveHTTPMux(t, issueHandler, issueCredentialPath, reqBytes, urlVars)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
 
-	t.Run("create profile success", func(t *testing.T) {
-		op.authService = &mockAuthService{}
-		op.edvClient = edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+	issued := time.Now().UTC()
 
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
-			bytes.NewBuffer([]byte(testIssuerProfile)))
+	for _, s := range []string{subject, "did:example:other"} {
+		reqBytes, err = json.Marshal(&ComposeCredentialRequest{
+			Issuer:       "did:example:issuer",
+			Subject:      s,
+			Types:        []string{"VerifiableCredential", "UniversityDegreeCredential"},
+			IssuanceDate: &issued,
+			CredentialFormatOptions: json.RawMessage(
+				`{"@context":["https://www.w3.org/2018/credentials/v1","https://www.w3.org/2018/credentials/examples/v1"]}`),
+		})
 		require.NoError(t, err)
-		rr := httptest.NewRecorder()
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		profile := vcprofile.IssuerProfile{}
+		rr = serveHTTPMux(t, composeHandler, composeAndIssueCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	}
 
-		err = json.Unmarshal(rr.Body.Bytes(), &profile)
+	find := func(t *testing.T, handler Handler, query string, profileID string) (int, *ListCredentialsResponse) {
+		t.Helper()
 
-		require.NoError(t, err)
+		r := serveHTTPMux(t, handler, credentialsBasePath+"?"+query, nil,
+			map[string]string{profileIDPathParam: profileID})
 
-		require.Equal(t, http.StatusCreated, rr.Code)
-		require.NotEmpty(t, profile.Name)
-		require.Contains(t, profile.URI, "https://example.com/credentials")
-	})
+		resp := &ListCredentialsResponse{}
+		if r.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(r.Body.Bytes(), resp))
+		}
 
-	t.Run("create profile - profile already exists", func(t *testing.T) {
-		op.authService = &mockAuthService{}
-		vReqBytes, err := json.Marshal(getTestProfile())
-		require.NoError(t, err)
+		return r.Code, resp
+	}
 
-		rr := serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
-		require.Equal(t, http.StatusCreated, rr.Code)
+	t.Run("list credentials", func(t *testing.T) {
+		code, resp := find(t, listHandler, "", profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 3, resp.Total)
+		require.Equal(t, commhttp.DefaultPageSize, resp.PageSize)
+		require.Len(t, resp.Credentials, 3)
 
-		rr = serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
+		for _, c := range resp.Credentials {
+			require.NotEmpty(t, c.ID)
+			require.Equal(t, "94567", c.Status.CustomFields[cslstatus.RevocationListIndex])
+		}
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "profile test already exists")
+		// latest issued first, the credential issued in 2010 is last
+		require.Equal(t, "http://example.edu/credentials/1872", resp.Credentials[2].ID)
+		require.Equal(t, []string{subject}, resp.Credentials[2].Subjects)
+
+		code, resp = find(t, listHandler, "page=1&pageSize=2", profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 3, resp.Total)
+		require.Equal(t, 1, resp.Page)
+		require.Len(t, resp.Credentials, 1)
+		require.Equal(t, "http://example.edu/credentials/1872", resp.Credentials[0].ID)
 	})
 
-	t.Run("create profile - other error in GetProfile", func(t *testing.T) {
-		s := &ariesmockstorage.MockStore{
-			Store: make(map[string]ariesmockstorage.DBEntry),
-		}
+	t.Run("search credentials", func(t *testing.T) {
+		code, resp := find(t, searchHandler, "subject="+url.QueryEscape(subject), profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 2, resp.Total)
+
+		code, resp = find(t, searchHandler, "subject="+url.QueryEscape(subject)+"&type=UniversityDegreeCredential",
+			profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 1, resp.Total)
+		require.True(t, strings.HasPrefix(resp.Credentials[0].ID, "urn:uuid:"))
+
+		code, resp = find(t, searchHandler, "subject=did:example:unknown", profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 0, resp.Total)
+		require.Empty(t, resp.Credentials)
+	})
 
-		op, err := New(&Config{
-			StoreProvider:      &ariesmockstorage.MockStoreProvider{Store: s},
-			KMSSecretsProvider: ariesmemstorage.NewProvider(),
-			EDVClient:          client,
-			KeyManager:         customKMS,
-			VDRI:               &vdrmock.MockVDRegistry{},
-			Crypto:             customCrypto,
-			HostURL:            "localhost:8080", Domain: "testnet",
-		})
-		require.NoError(t, err)
+	t.Run("find credentials - errors", func(t *testing.T) {
+		code, _ := find(t, listHandler, "", "wrongProfile")
+		require.Equal(t, http.StatusBadRequest, code)
 
-		s.ErrGet = errors.New("get error")
+		r := serveHTTPMux(t, listHandler, credentialsBasePath, nil,
+			map[string]string{profileIDPathParam: noRegistryProfile.Name})
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "credential registry is disabled for profile noRegistry")
 
-		op.commonDID = &mockCommonDID{}
+		r = serveHTTPMux(t, listHandler, credentialsBasePath+"?pageSize=1000", nil, urlVars)
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "invalid pageSize : 1000")
 
-		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+		r = serveHTTPMux(t, listHandler, credentialsBasePath+"?page=-1", nil, urlVars)
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "invalid page : -1")
 
-		vReqBytes, err := json.Marshal(getTestProfile())
-		require.NoError(t, err)
+		credentialRegistry := op.credentialRegistry
+		op.credentialRegistry = &mockCredentialRegistry{err: fmt.Errorf("find error")}
 
-		rr := serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "get error")
-	})
+		defer func() { op.credentialRegistry = credentialRegistry }()
 
-	t.Run("create profile success without creating did", func(t *testing.T) {
-		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+		r = serveHTTPMux(t, listHandler, credentialsBasePath, nil, urlVars)
+		require.Equal(t, http.StatusInternalServerError, r.Code)
+		require.Contains(t, r.Body.String(), "failed to find credentials: find error")
+	})
 
-		op, err := New(&Config{
-			StoreProvider:      ariesmemstorage.NewProvider(),
-			KMSSecretsProvider: ariesmemstorage.NewProvider(),
-			Crypto:             customCrypto,
-			EDVClient:          client, KeyManager: customKMS,
-			VDRI: &vdrmock.MockVDRegistry{ResolveValue: &did.Doc{
-				ID:             "did1",
-				Authentication: []did.Verification{{VerificationMethod: did.VerificationMethod{ID: "did1#key1"}}},
-			}},
-			HostURL: "localhost:8080",
+	t.Run("update status of registered credential", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&UpdateCredentialStatusRequest{
+			CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "true",
+			},
 		})
-
 		require.NoError(t, err)
 
-		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+		r := serveHTTPMux(t, getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost),
+			updateCredentialStatusEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusOK, r.Code, r.Body.String())
+	})
 
-		_, priv, err := ed25519.GenerateKey(rand.Reader)
+	t.Run("revoke credentials by subject", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&RevokeCredentialsRequest{
+			Subject:    subject,
+			ReasonCode: audit.ReasonAffiliationChanged,
+			Comment:    "left the university",
+		})
 		require.NoError(t, err)
 
-		issuerProfileWithDID := strings.Replace(testIssuerProfileWithDID, "\"key\",",
-			"\""+base58.Encode(priv.Seed())+"\",", 1)
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
-			bytes.NewBuffer([]byte(issuerProfileWithDID)))
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+		r := serveHTTPMux(t, revokeHandler, revokeCredentialsPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusOK, r.Code, r.Body.String())
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		profile := vcprofile.IssuerProfile{}
+		resp := &BatchUpdateCredentialStatusResponse{}
+		require.NoError(t, json.Unmarshal(r.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 2)
 
-		err = json.Unmarshal(rr.Body.Bytes(), &profile)
+		for _, result := range resp.Results {
+			require.True(t, result.Updated)
+
+			history, errGet := op.statusAuditStore.Get(profile.Name, result.CredentialID)
+			require.NoError(t, errGet)
+			require.NotEmpty(t, history)
+			require.Equal(t, audit.ReasonAffiliationChanged, history[len(history)-1].ReasonCode)
+			require.Equal(t, cslstatus.StatusPurposeRevocation, history[len(history)-1].StatusPurpose)
+		}
+
+		// no credentials issued to the subject
+		reqBytes, err = json.Marshal(&RevokeCredentialsRequest{Subject: "did:example:unknown"})
 		require.NoError(t, err)
 
-		require.Equal(t, http.StatusCreated, rr.Code)
-		require.NotEmpty(t, profile.Name)
-		require.Contains(t, profile.URI, "https://example.com/credentials")
-		require.Equal(t, "did1#key1", profile.Creator)
+		r = serveHTTPMux(t, revokeHandler, revokeCredentialsPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusOK, r.Code)
+		require.Contains(t, r.Body.String(), `"results":[]`)
 	})
 
-	t.Run("test failed to resolve did", func(t *testing.T) {
-		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+	t.Run("revoke credentials - errors", func(t *testing.T) {
+		r := serveHTTPMux(t, revokeHandler, revokeCredentialsPath, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "failed to decode request received")
 
-		op, err := New(&Config{
-			StoreProvider:      ariesmemstorage.NewProvider(),
-			KMSSecretsProvider: ariesmemstorage.NewProvider(),
-			Crypto:             customCrypto,
-			EDVClient:          client, KeyManager: customKMS,
-			VDRI:    &vdrmock.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")},
-			HostURL: "localhost:8080",
-		})
+		r = serveHTTPMux(t, revokeHandler, revokeCredentialsPath, []byte("{}"), urlVars)
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "missing subject")
 
-		require.NoError(t, err)
+		r = serveHTTPMux(t, revokeHandler, revokeCredentialsPath,
+			[]byte(`{"subject":"did:example:1","reasonCode":"invalid"}`), urlVars)
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "credential status reason code invalid not supported")
 
-		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+		r = serveHTTPMux(t, revokeHandler, revokeCredentialsPath, []byte(`{"subject":"did:example:1"}`),
+			map[string]string{profileIDPathParam: noRegistryProfile.Name})
+		require.Equal(t, http.StatusBadRequest, r.Code)
+		require.Contains(t, r.Body.String(), "credential registry is disabled")
 
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
-			bytes.NewBuffer([]byte(testIssuerProfileWithDID)))
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+		credentialRegistry := op.credentialRegistry
+		op.credentialRegistry = &mockCredentialRegistry{err: fmt.Errorf("find error")}
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "failed to resolve did")
-	})
+		defer func() { op.credentialRegistry = credentialRegistry }()
 
-	t.Run("missing profile name", func(t *testing.T) {
-		prBytes, err := json.Marshal(ProfileRequest{})
-		require.NoError(t, err)
+		r = serveHTTPMux(t, revokeHandler, revokeCredentialsPath, []byte(`{"subject":"did:example:1"}`), urlVars)
+		require.Equal(t, http.StatusInternalServerError, r.Code)
+		require.Contains(t, r.Body.String(), "failed to find credentials: find error")
 
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer(prBytes))
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+		r = serveHTTPMux(t, getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost),
+			updateCredentialStatusEndpoint,
+			[]byte(`{"credentialId":"id","credentialStatus":{"type":"RevocationList2020Status","status":"true"}}`),
+			urlVars)
+		require.Equal(t, http.StatusInternalServerError, r.Code)
+		require.Contains(t, r.Body.String(), "find error")
+	})
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
+	t.Run("issue credential - registration error", func(t *testing.T) {
+		credentialRegistry := op.credentialRegistry
+		op.credentialRegistry = &mockCredentialRegistry{err: fmt.Errorf("add error")}
 
-		errResp := &model.ErrorResponse{}
-		err = json.Unmarshal(rr.Body.Bytes(), &errResp)
-		require.NoError(t, err)
+		defer func() { op.credentialRegistry = credentialRegistry }()
 
-		require.Equal(t, errResp.Message, "missing profile name")
-	})
-	t.Run("create profile error by passing invalid request", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: []byte(validVC)})
 		require.NoError(t, err)
-		rr := httptest.NewRecorder()
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
+		statusManager, ok := op.vcStatusManager.(*mockVCStatusManager)
+		require.True(t, ok)
 
-		errResp := &model.ErrorResponse{}
-		err = json.Unmarshal(rr.Body.Bytes(), &errResp)
-		require.NoError(t, err)
+		statusManager.released = nil
+		statusManager.releaseErr = fmt.Errorf("release error")
 
-		require.Equal(t, invalidRequestErrMsg+": EOF", errResp.Message)
-	})
-	t.Run("create profile error - unable to create vault in EDV", func(t *testing.T) {
-		errTest := errors.New("error in create EDV vault")
-		client := edv.NewMockEDVClient("test", nil,
-			nil, []string{"testID"}, errTest)
+		defer func() { statusManager.releaseErr = nil }()
 
-		op, err := New(&Config{
-			StoreProvider:      ariesmemstorage.NewProvider(),
-			KMSSecretsProvider: ariesmemstorage.NewProvider(),
-			EDVClient:          client,
-			KeyManager:         customKMS,
-			VDRI:               &vdrmock.MockVDRegistry{},
-			Crypto:             customCrypto,
-			HostURL:            "localhost:8080", Domain: "testnet",
-		})
-		require.NoError(t, err)
+		r := serveHTTPMux(t, issueHandler, issueCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusInternalServerError, r.Code)
+		require.Contains(t, r.Body.String(), "failed to register credential: add error")
+		require.Equal(t, []*verifiable.TypedID{statusManager.createStatusIDValue}, statusManager.released)
+	})
 
-		op.commonDID = &mockCommonDID{}
+	t.Run("issue credential - already registered", func(t *testing.T) {
+		statusManager, ok := op.vcStatusManager.(*mockVCStatusManager)
+		require.True(t, ok)
 
-		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+		statusManager.released = nil
 
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: []byte(validVC)})
 		require.NoError(t, err)
-		rr := httptest.NewRecorder()
 
-		createProfileHandler.Handle().ServeHTTP(rr, req)
-		require.Equal(t, http.StatusBadRequest, rr.Code)
-	})
-	t.Run("create profile error unable to write a response while reading the request", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
-		require.NoError(t, err)
-		rw := mockResponseWriter{}
-		createProfileHandler.Handle().ServeHTTP(rw, req)
-		require.Contains(t, mockLoggerProvider.MockLogger.AllLogContents,
-			"Unable to send error message, response writer failed")
+		r := serveHTTPMux(t, issueHandler, issueCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusConflict, r.Code)
+		require.Contains(t, r.Body.String(), registry.ErrAlreadyRegistered.Error())
+		require.Len(t, statusManager.released, 1)
+
+		code, resp := find(t, listHandler, "", profile.Name)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, 3, resp.Total)
 	})
 }
 
-func TestGetProfileHandler(t *testing.T) {
+func TestUpdateCredentialStatusHandler(t *testing.T) {
+	const profileID = "example_university"
+
 	client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+	s := make(map[string]ariesmockstorage.DBEntry)
+	s["profile_issuer_example_university"] = ariesmockstorage.DBEntry{Value: []byte(testIssuerProfile)}
+	s["profile_issuer_vc_without_status"] = ariesmockstorage.DBEntry{Value: []byte(testIssuerProfileWithDisableVCStatus)}
+	s["profile_issuer_empty"] = ariesmockstorage.DBEntry{Value: []byte("{}")}
 
 	customKMS := createKMS(t)
 
@@ -764,62 +816,654 @@ func TestGetProfileHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	op, err := New(&Config{
-		StoreProvider:      ariesmemstorage.NewProvider(),
+		StoreProvider: &ariesmockstorage.MockStoreProvider{
+			Store: &ariesmockstorage.MockStore{Store: s},
+		},
 		KMSSecretsProvider: ariesmemstorage.NewProvider(),
-		Crypto:             customCrypto,
-		EDVClient:          client,
 		KeyManager:         customKMS,
+		Crypto:             customCrypto,
 		VDRI:               &vdrmock.MockVDRegistry{},
 		HostURL:            "localhost:8080",
+		RetryParameters:    &retry.Params{},
+		DocumentLoader:     testutil.DocumentLoader(t),
 	})
-
-	require.NoError(t, err)
-
-	op.commonDID = &mockCommonDID{}
-
-	getProfileHandler := getHandler(t, op, getProfileEndpoint, http.MethodGet)
-
-	notFoundID := "test"
-	req, err := http.NewRequest(http.MethodGet,
-		"/profile/"+notFoundID,
-		bytes.NewBuffer([]byte("")))
 	require.NoError(t, err)
 
-	urlVars := make(map[string]string)
-	urlVars["id"] = notFoundID
+	updateCredentialStatusHandler := getHandler(t, op, updateCredentialStatusEndpoint, http.MethodPost)
 
-	req = mux.SetURLVars(req, urlVars)
+	t.Run("update credential status success", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
 
-	t.Run("get profile success", func(t *testing.T) {
-		profile := createProfileSuccess(t, op)
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
 
-		r, err := http.NewRequest(http.MethodGet,
-			"/profile/"+profile.Name,
-			bytes.NewBuffer([]byte("")))
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:       cslstatus.RevocationList2020Status,
+				Status:     "1",
+				ReasonCode: audit.ReasonKeyCompromise,
+				Comment:    "key lost",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
 		require.NoError(t, err)
 
-		rr := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodPost, updateCredentialStatusEndpoint, bytes.NewBuffer(ucsReqBytes))
+		require.NoError(t, err)
 
-		urlVars := make(map[string]string)
-		urlVars["id"] = profile.Name
-		req = mux.SetURLVars(r, urlVars)
+		r.Header.Set(callerIDHeader, "admin")
 
-		getProfileHandler.Handle().ServeHTTP(rr, req)
+		rr := httptest.NewRecorder()
+		updateCredentialStatusHandler.Handle().ServeHTTP(rr,
+			mux.SetURLVars(r, map[string]string{profileIDPathParam: profileID}))
 
 		require.Equal(t, http.StatusOK, rr.Code)
-		profileResponse := &vcprofile.IssuerProfile{}
-		err = json.Unmarshal(rr.Body.Bytes(), profileResponse)
+
+		history, err := op.statusAuditStore.Get("issuer", "http://example.edu/credentials/1872")
 		require.NoError(t, err)
-		require.Equal(t, profileResponse.Name, profile.Name)
-		require.Equal(t, profileResponse.URI, profile.URI)
+		require.NotEmpty(t, history)
+
+		record := history[len(history)-1]
+		require.Equal(t, cslstatus.StatusPurposeRevocation, record.StatusPurpose)
+		require.True(t, record.Status)
+		require.Equal(t, audit.ReasonKeyCompromise, record.ReasonCode)
+		require.Equal(t, "key lost", record.Comment)
+		require.Equal(t, "admin", record.ClaimedCaller)
+		require.False(t, record.Timestamp.IsZero())
 	})
-	t.Run("get profile error, bad request", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodGet,
-			"/profile/"+notFoundID,
-			bytes.NewBuffer([]byte("")))
+
+	t.Run("update credential status - invalid reason code", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		ucsReqBytes, err := json.Marshal(UpdateCredentialStatusRequest{
+			CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type: cslstatus.RevocationList2020Status, Status: "1", ReasonCode: "wrongReason",
+			},
+		})
 		require.NoError(t, err)
-		rr := httptest.NewRecorder()
-		getProfileHandler.Handle().ServeHTTP(rr, req)
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes,
+			map[string]string{profileIDPathParam: profileID})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential status reason code wrongReason not supported")
+	})
+
+	t.Run("update credential status - error recording status change", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		auditStore := op.statusAuditStore
+		op.statusAuditStore = &mockStatusAuditStore{addErr: fmt.Errorf("add error")}
+
+		defer func() { op.statusAuditStore = auditStore }()
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
+
+		ucsReqBytes, err := json.Marshal(UpdateCredentialStatusRequest{
+			CredentialID:     "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{Type: cslstatus.RevocationList2020Status, Status: "1"},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes,
+			map[string]string{profileIDPathParam: profileID})
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "vc status updated but failed to record the change: add error")
+	})
+
+	t.Run("test disable vc status", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op,
+			fmt.Sprintf(testStructuredVCDocument, validVCWithoutStatus),
+			fmt.Sprintf(testStructuredVCDocument, validVCWithoutStatus))
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "1",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = "vc_without_status"
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "vc status is disabled for profile")
+	})
+
+	t.Run("test error decode request", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, []byte("w"),
+			urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to decode request received")
+	})
+
+	t.Run("test error from parse credential", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, invalidVC),
+			fmt.Sprintf(testStructuredVCDocument, invalidVC))
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "1",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to parse credentia")
+	})
+
+	t.Run("test error from get profile", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = "wrongProfile"
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, []byte("w"),
+			urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+	})
+
+	t.Run("test error from update vc status", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{updateVCErr: fmt.Errorf("failed to update")}
+		op.edvClient = client
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "1",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to update vc status")
+	})
+
+	t.Run("test error from update vc status wrong type", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   "wrongType",
+				Status: "1",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential status wrongType not supported")
+	})
+
+	t.Run("test error from update vc status wrong purpose", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:          cslstatus.StatusList2021Entry,
+				Status:        "1",
+				StatusPurpose: "wrongPurpose",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential status purpose wrongPurpose not supported")
+	})
+
+	t.Run("test error get credential", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = &edv.Client{ReadDocumentError: fmt.Errorf("failed to read")}
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "1",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "no VC under profile")
+	})
+
+	t.Run("test error parse cred status", func(t *testing.T) {
+		op.vcStatusManager = &mockVCStatusManager{}
+		op.edvClient = client
+
+		setMockEDVClientReadDocumentReturnValue(t, client, op, fmt.Sprintf(testStructuredVCDocument, validVC),
+			fmt.Sprintf(testStructuredVCDocument, validVC))
+
+		ucsReq := UpdateCredentialStatusRequest{CredentialID: "http://example.edu/credentials/1872",
+			CredentialStatus: CredentialStatus{
+				Type:   cslstatus.RevocationList2020Status,
+				Status: "wrong",
+			}}
+		ucsReqBytes, err := json.Marshal(ucsReq)
+		require.NoError(t, err)
+
+		urlVars := make(map[string]string)
+		urlVars[profileIDPathParam] = profileID
+
+		rr := serveHTTPMux(t, updateCredentialStatusHandler, updateCredentialStatusEndpoint, ucsReqBytes, urlVars)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to parse status")
+	})
+}
+
+type mockCommonDID struct {
+	createDIDValue string
+	createDIDKeyID string
+	createDIDErr   error
+	rotateKeyValue string
+	rotateKeyErr   error
+}
+
+func (m *mockCommonDID) CreateDID(keyType, signatureType, didID, privateKey, keyID, purpose string,
+	registrar model.UNIRegistrar) (string, string, error) {
+	return m.createDIDValue, m.createDIDKeyID, m.createDIDErr
+}
+
+func (m *mockCommonDID) RotateKey(didID, creator, keyID, privateKey string) (string, error) {
+	return m.rotateKeyValue, m.rotateKeyErr
+}
+
+type mockAuthService struct {
+	createDIDKeyFunc func() (string, error)
+	signHeaderFunc   func(req *http.Request, capability []byte,
+		verificationMethod string) (*http.Header, error)
+}
+
+func (m *mockAuthService) CreateDIDKey() (string, error) {
+	if m.createDIDKeyFunc != nil {
+		return m.createDIDKeyFunc()
+	}
+
+	return "", nil
+}
+
+func (m *mockAuthService) SignHeader(req *http.Request, capability []byte,
+	verificationMethod string) (*http.Header, error) {
+	if m.signHeaderFunc != nil {
+		return m.signHeaderFunc(req, capability, verificationMethod)
+	}
+
+	return nil, nil
+}
+
+func TestCreateProfileHandler(t *testing.T) {
+	client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		EDVClient:          client,
+		KeyManager:         customKMS,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		Crypto:             customCrypto,
+		HostURL:            "localhost:8080", Domain: "testnet",
+	})
+	require.NoError(t, err)
+
+	op.commonDID = &mockCommonDID{}
+	op.authService = &mockAuthService{}
+
+	createProfileHandler := getHandler(t, op, createProfileEndpoint, http.MethodPost)
+
+	t.Run("failed to create did key", func(t *testing.T) {
+		op.authService = &mockAuthService{createDIDKeyFunc: func() (string, error) {
+			return "", fmt.Errorf("failed to create did key")
+		}}
+
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
+			bytes.NewBuffer([]byte(testIssuerProfile)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to create did key")
+	})
+
+	t.Run("failed to create vault", func(t *testing.T) {
+		op.authService = &mockAuthService{}
+		op.edvClient = edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, fmt.Errorf("failed to create vault"))
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
+			bytes.NewBuffer([]byte(testIssuerProfile)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "fail to create vault in EDV")
+	})
+
+	t.Run("create profile success", func(t *testing.T) {
+		op.authService = &mockAuthService{}
+		op.edvClient = edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
+			bytes.NewBuffer([]byte(testIssuerProfile)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		profile := vcprofile.IssuerProfile{}
+
+		err = json.Unmarshal(rr.Body.Bytes(), &profile)
+
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NotEmpty(t, profile.Name)
+		require.Contains(t, profile.URI, "https://example.com/credentials")
+	})
+
+	t.Run("create profile - profile already exists", func(t *testing.T) {
+		op.authService = &mockAuthService{}
+		vReqBytes, err := json.Marshal(getTestProfile())
+		require.NoError(t, err)
+
+		rr := serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		rr = serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile test already exists")
+	})
+
+	t.Run("create profile - other error in GetProfile", func(t *testing.T) {
+		s := &ariesmockstorage.MockStore{
+			Store: make(map[string]ariesmockstorage.DBEntry),
+		}
+
+		op, err := New(&Config{
+			StoreProvider:      &ariesmockstorage.MockStoreProvider{Store: s},
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			EDVClient:          client,
+			KeyManager:         customKMS,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			Crypto:             customCrypto,
+			HostURL:            "localhost:8080", Domain: "testnet",
+		})
+		require.NoError(t, err)
+
+		s.ErrGet = errors.New("get error")
+
+		op.commonDID = &mockCommonDID{}
+
+		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+
+		vReqBytes, err := json.Marshal(getTestProfile())
+		require.NoError(t, err)
+
+		rr := serveHTTP(t, createProfileHandler.Handle(), http.MethodPost, createProfileEndpoint, vReqBytes)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "get error")
+	})
+
+	t.Run("create profile success without creating did", func(t *testing.T) {
+		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			Crypto:             customCrypto,
+			EDVClient:          client, KeyManager: customKMS,
+			VDRI: &vdrmock.MockVDRegistry{ResolveValue: &did.Doc{
+				ID:             "did1",
+				Authentication: []did.Verification{{VerificationMethod: did.VerificationMethod{ID: "did1#key1"}}},
+			}},
+			HostURL: "localhost:8080",
+		})
+
+		require.NoError(t, err)
+
+		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		issuerProfileWithDID := strings.Replace(testIssuerProfileWithDID, "\"key\",",
+			"\""+base58.Encode(priv.Seed())+"\",", 1)
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
+			bytes.NewBuffer([]byte(issuerProfileWithDID)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		profile := vcprofile.IssuerProfile{}
+
+		err = json.Unmarshal(rr.Body.Bytes(), &profile)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NotEmpty(t, profile.Name)
+		require.Contains(t, profile.URI, "https://example.com/credentials")
+		require.Equal(t, "did1#key1", profile.Creator)
+	})
+
+	t.Run("test failed to resolve did", func(t *testing.T) {
+		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			Crypto:             customCrypto,
+			EDVClient:          client, KeyManager: customKMS,
+			VDRI:    &vdrmock.MockVDRegistry{ResolveErr: fmt.Errorf("resolve error")},
+			HostURL: "localhost:8080",
+		})
+
+		require.NoError(t, err)
+
+		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint,
+			bytes.NewBuffer([]byte(testIssuerProfileWithDID)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to resolve did")
+	})
+
+	t.Run("missing profile name", func(t *testing.T) {
+		prBytes, err := json.Marshal(ProfileRequest{})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer(prBytes))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		errResp := &model.ErrorResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &errResp)
+		require.NoError(t, err)
+
+		require.Equal(t, errResp.Message, "missing profile name")
+	})
+	t.Run("create profile error by passing invalid request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		errResp := &model.ErrorResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &errResp)
+		require.NoError(t, err)
+
+		require.Equal(t, invalidRequestErrMsg+": EOF", errResp.Message)
+	})
+	t.Run("create profile error - unable to create vault in EDV", func(t *testing.T) {
+		errTest := errors.New("error in create EDV vault")
+		client := edv.NewMockEDVClient("test", nil,
+			nil, []string{"testID"}, errTest)
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			EDVClient:          client,
+			KeyManager:         customKMS,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			Crypto:             customCrypto,
+			HostURL:            "localhost:8080", Domain: "testnet",
+		})
+		require.NoError(t, err)
+
+		op.commonDID = &mockCommonDID{}
+
+		createProfileHandler = getHandler(t, op, createProfileEndpoint, http.MethodPost)
+
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		createProfileHandler.Handle().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+	t.Run("create profile error unable to write a response while reading the request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, createProfileEndpoint, bytes.NewBuffer([]byte("")))
+		require.NoError(t, err)
+		rw := mockResponseWriter{}
+		createProfileHandler.Handle().ServeHTTP(rw, req)
+		require.Contains(t, mockLoggerProvider.MockLogger.AllLogContents,
+			"Unable to send error message, response writer failed")
+	})
+}
+
+func TestGetProfileHandler(t *testing.T) {
+	client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		Crypto:             customCrypto,
+		EDVClient:          client,
+		KeyManager:         customKMS,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		HostURL:            "localhost:8080",
+	})
+
+	require.NoError(t, err)
+
+	op.commonDID = &mockCommonDID{}
+
+	getProfileHandler := getHandler(t, op, getProfileEndpoint, http.MethodGet)
+
+	notFoundID := "test"
+	req, err := http.NewRequest(http.MethodGet,
+		"/profile/"+notFoundID,
+		bytes.NewBuffer([]byte("")))
+	require.NoError(t, err)
+
+	urlVars := make(map[string]string)
+	urlVars["id"] = notFoundID
+
+	req = mux.SetURLVars(req, urlVars)
+
+	t.Run("get profile success", func(t *testing.T) {
+		profile := createProfileSuccess(t, op)
+
+		r, err := http.NewRequest(http.MethodGet,
+			"/profile/"+profile.Name,
+			bytes.NewBuffer([]byte("")))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		urlVars := make(map[string]string)
+		urlVars["id"] = profile.Name
+		req = mux.SetURLVars(r, urlVars)
+
+		getProfileHandler.Handle().ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		profileResponse := &vcprofile.IssuerProfile{}
+		err = json.Unmarshal(rr.Body.Bytes(), profileResponse)
+		require.NoError(t, err)
+		require.Equal(t, profileResponse.Name, profile.Name)
+		require.Equal(t, profileResponse.URI, profile.URI)
+	})
+	t.Run("get profile error, bad request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet,
+			"/profile/"+notFoundID,
+			bytes.NewBuffer([]byte("")))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		getProfileHandler.Handle().ServeHTTP(rr, req)
 		require.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 }
@@ -1597,16 +2241,143 @@ func TestVCStatus(t *testing.T) {
 			getRevocationListVCValue: []byte(`{"k1":"v1"}`),
 		}
 
-		vcStatusHandler := getHandler(t, op, credentialStatusEndpoint, http.MethodGet)
+		vcStatusHandler := getHandler(t, op, credentialStatusEndpoint, http.MethodGet)
+
+		req, err := http.NewRequest(http.MethodGet, credentialStatus+"/1", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		vcStatusHandler.Handle().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		require.Equal(t, `{"k1":"v1"}`, rr.Body.String())
+		require.NotEmpty(t, rr.Header().Get("ETag"))
+		require.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+		require.Empty(t, rr.Header().Get("Last-Modified"))
+	})
+
+	t.Run("test conditional requests", func(t *testing.T) {
+		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			EDVClient:          client,
+			Crypto:             customCrypto,
+			KeyManager:         customKMS,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			HostURL:            "localhost:8080",
+			DocumentLoader:     loader,
+			StatusListMaxAge:   time.Minute,
+		})
+		require.NoError(t, err)
+
+		updated := time.Date(2021, 5, 10, 10, 0, 0, 0, time.UTC)
+
+		op.vcStatusManager = &mockVCStatusManager{
+			getRevocationListVCValue: []byte(`{"k1":"v1"}`),
+			statusListUpdated:        &updated,
+		}
+
+		vcStatusHandler := getHandler(t, op, credentialStatusEndpoint, http.MethodGet)
+
+		get := func(header map[string]string) *httptest.ResponseRecorder {
+			req, errReq := http.NewRequest(http.MethodGet, credentialStatus+"/1", nil)
+			require.NoError(t, errReq)
+
+			for k, v := range header {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			vcStatusHandler.Handle().ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := get(nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "max-age=60", rr.Header().Get("Cache-Control"))
+		require.Equal(t, "Mon, 10 May 2021 10:00:00 GMT", rr.Header().Get("Last-Modified"))
+
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rr = get(map[string]string{"If-None-Match": `"other", ` + etag})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Empty(t, rr.Body.String())
+
+		rr = get(map[string]string{"If-None-Match": `"other"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = get(map[string]string{"If-Modified-Since": "Mon, 10 May 2021 10:00:00 GMT"})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+
+		rr = get(map[string]string{"If-Modified-Since": "Mon, 10 May 2021 09:59:59 GMT"})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = get(map[string]string{"If-Modified-Since": "invalid"})
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("test status list at given time", func(t *testing.T) {
+		client := edv.NewMockEDVClient("test", nil, nil, []string{"testID"}, nil)
+
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			EDVClient:          client,
+			Crypto:             customCrypto,
+			KeyManager:         customKMS,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			HostURL:            "localhost:8080",
+			DocumentLoader:     loader,
+		})
+		require.NoError(t, err)
+
+		updated := time.Date(2021, 5, 10, 10, 0, 0, 0, time.UTC)
+
+		op.vcStatusManager = &mockVCStatusManager{
+			getRevocationListVCValue: []byte(`{"k1":"v1"}`),
+			statusListAt: func(id string, at time.Time) (*cslstatus.StatusListVC, error) {
+				require.Equal(t, "localhost:8080/test/status/1", id)
+
+				if at.Before(updated) {
+					return nil, fmt.Errorf("status list %s at %s: data not found", id, at.Format(time.RFC3339))
+				}
+
+				return &cslstatus.StatusListVC{VC: []byte(`{"k1":"v0"}`), Updated: &updated}, nil
+			},
+		}
+
+		vcStatusHandler := getHandler(t, op, credentialStatusEndpoint, http.MethodGet)
+
+		get := func(validAt string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/test"+credentialStatus+"/1?"+validAtQueryParam+"="+validAt, nil)
+
+			rr := httptest.NewRecorder()
+			vcStatusHandler.Handle().ServeHTTP(rr, req)
 
-		req, err := http.NewRequest(http.MethodGet, credentialStatus+"/1", nil)
-		require.NoError(t, err)
-		rr := httptest.NewRecorder()
+			return rr
+		}
 
-		vcStatusHandler.Handle().ServeHTTP(rr, req)
+		rr := get("2021-05-11T00:00:00Z")
 		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `{"k1":"v0"}`, rr.Body.String())
+		require.Equal(t, "Mon, 10 May 2021 10:00:00 GMT", rr.Header().Get("Last-Modified"))
+		require.Equal(t, "2021-05-11T00:00:00Z", rr.Header().Get(cslstatus.ValidAtHeader))
 
-		require.Equal(t, `{"k1":"v1"}`, rr.Body.String())
+		rr = get(url.QueryEscape("2021-05-11T02:00:00+02:00"))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "2021-05-11T00:00:00Z", rr.Header().Get(cslstatus.ValidAtHeader))
+
+		rr = get("2021-05-01T00:00:00Z")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "data not found")
+
+		rr = get("yesterday")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid validAt")
 	})
 }
 
@@ -1637,6 +2408,66 @@ func TestOperation_validateProfileRequest(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "missing signature type")
 	})
+	t.Run("valid StatusList2021 status type", func(t *testing.T) {
+		profile := getProfileRequest()
+		profile.VCStatusType = cslstatus.StatusList2021Entry
+		err := validateProfileRequest(profile)
+		require.NoError(t, err)
+	})
+	t.Run("not supported status type", func(t *testing.T) {
+		profile := getProfileRequest()
+		profile.VCStatusType = "noMatch"
+		err := validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not supported credential status type : noMatch")
+	})
+	t.Run("invalid status list size", func(t *testing.T) {
+		profile := getProfileRequest()
+		profile.VCStatusListSize = -1
+		err := validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential status list size : -1")
+	})
+	t.Run("credential formats", func(t *testing.T) {
+		profile := getProfileRequest()
+		profile.CredentialFormat = vcprofile.JWTCredentialFormat
+		require.NoError(t, validateProfileRequest(profile))
+
+		profile.CredentialFormat = vcprofile.SDJWTCredentialFormat
+		require.NoError(t, validateProfileRequest(profile))
+
+		profile.CredentialFormat = "invalid"
+		err := validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not supported credential format : invalid")
+	})
+	t.Run("invalid status list bit length", func(t *testing.T) {
+		profile := getProfileRequest()
+		profile.VCStatusListSize = 100
+		profile.VCStatusListBitLength = 50
+		err := validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential status list bit length : 50")
+
+		profile.VCStatusListBitLength = -1
+		err = validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential status list bit length : -1")
+
+		profile.VCStatusListBitLength = cslstatus.MaxBitStringLength + 1
+		err = validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential status list bit length")
+
+		profile.VCStatusListBitLength = 1000
+		require.NoError(t, validateProfileRequest(profile))
+
+		profile.VCStatusListSize = cslstatus.MaxBitStringLength + 1
+		profile.VCStatusListBitLength = 0
+		err = validateProfileRequest(profile)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credential status list size")
+	})
 	t.Run("parse uri failed", func(t *testing.T) {
 		profile := getProfileRequest()
 		profile.URI = "//not-valid.&&%^)$"
@@ -1664,51 +2495,303 @@ func TestOperation_GetRESTHandlers(t *testing.T) {
 		DocumentLoader: testutil.DocumentLoader(t),
 	})
 
-	require.NoError(t, err)
+	require.NoError(t, err)
+
+	handlers := op.GetRESTHandlers()
+	require.NotEmpty(t, handlers)
+}
+
+func TestIssueCredential(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	endpoint := "/test/credentials/issue"
+	issuerProfileDIDKey := "did:test:abc#" + keyID
+	profile := getTestProfile()
+	profile.Creator = issuerProfileDIDKey
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	err = op.profileStore.SaveProfile(profile)
+	require.NoError(t, err)
+
+	urlVars := make(map[string]string)
+	urlVars[profileIDPathParam] = profile.Name
+
+	handler := getHandler(t, op, issueCredentialPath, http.MethodPost)
+
+	t.Run("issue credential - success", func(t *testing.T) {
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+				},
+			},
+			Crypto:         customCrypto,
+			DocumentLoader: loader,
+		})
+		require.NoError(t, err)
+
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "id"}}
+
+		profile.SignatureRepresentation = verifiable.SignatureJWS
+		profile.SignatureType = vccrypto.JSONWebSignature2020
+
+		err = ops.profileStore.SaveProfile(profile)
+		require.NoError(t, err)
+
+		issueCredentialHandler := getHandler(t, ops, issueCredentialPath, http.MethodPost)
+
+		const createdTime = "2011-04-16T18:11:09-04:00"
+		ct, err := time.Parse(time.RFC3339, createdTime)
+		require.NoError(t, err)
+
+		req := &IssueCredentialRequest{
+			Credential: []byte(validVC),
+			Opts: &IssueCredentialOptions{
+				AssertionMethod:    "did:local:abc#" + keyID,
+				VerificationMethod: "did:local:abc#" + keyID,
+				Created:            &ct,
+				Challenge:          challenge,
+				Domain:             domain,
+				CredentialStatus: CredentialStatusOpt{
+					Type: cslstatus.RevocationList2020Status,
+				},
+			},
+		}
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		signedVCResp := make(map[string]interface{})
+		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		require.NoError(t, err)
+		require.NotEmpty(t, signedVCResp["proof"])
+
+		proof, ok := signedVCResp["proof"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, cslstatus.Context, signedVCResp["@context"].([]interface{})[1])
+		require.Equal(t, "https://w3c-ccg.github.io/lds-jws2020/contexts/lds-jws2020-v1.json",
+			signedVCResp["@context"].([]interface{})[2])
+		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
+		require.NotEmpty(t, proof["jws"])
+		require.Equal(t, "did:local:abc#"+keyID, proof["verificationMethod"])
+		require.Equal(t, "assertionMethod", proof["proofPurpose"])
+		require.Equal(t, createdTime, proof["created"])
+		require.Equal(t, challenge, proof[challenge])
+		require.Equal(t, domain, proof[domain])
+
+		// default - DID from the issuer profile
+		req.Opts.VerificationMethod = ""
+
+		reqBytes, err = json.Marshal(req)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		signedVCResp = make(map[string]interface{})
+		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		require.NoError(t, err)
+		require.NotEmpty(t, signedVCResp["proof"])
+
+		proof, ok = signedVCResp["proof"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
+		require.NotEmpty(t, proof["jws"])
+		require.Equal(t, "did:local:abc#"+keyID, proof["verificationMethod"])
+		require.Equal(t, "assertionMethod", proof["proofPurpose"])
+
+		// default - DID from the issuer profile
+		req.Opts.AssertionMethod = ""
+		req.Opts.VerificationMethod = ""
+
+		reqBytes, err = json.Marshal(req)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		signedVCResp = make(map[string]interface{})
+		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		require.NoError(t, err)
+		require.NotEmpty(t, signedVCResp["proof"])
+		require.NotEmpty(t, signedVCResp["credentialStatus"])
+
+		proof, ok = signedVCResp["proof"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
+		require.NotEmpty(t, proof["jws"])
+		require.Equal(t, issuerProfileDIDKey, proof["verificationMethod"])
+		require.Equal(t, "assertionMethod", proof["proofPurpose"])
+	})
+
+	t.Run("issue credential - jwt format", func(t *testing.T) {
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+				},
+			},
+			Crypto:         customCrypto,
+			DocumentLoader: loader,
+		})
+		require.NoError(t, err)
+
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+			ID: "https://example.com/status/1#1", Type: cslstatus.RevocationList2020Status,
+		}}
+
+		jwtProfile := getTestProfile()
+		jwtProfile.Creator = issuerProfileDIDKey
+		jwtProfile.CredentialFormat = vcprofile.JWTCredentialFormat
+
+		require.NoError(t, ops.profileStore.SaveProfile(jwtProfile))
+
+		issueCredentialHandler := getHandler(t, ops, issueCredentialPath, http.MethodPost)
+
+		req := &IssueCredentialRequest{Credential: []byte(validVC)}
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var vcJWT string
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vcJWT))
+
+		vc, err := verifiable.ParseCredential([]byte(vcJWT),
+			verifiable.WithPublicKeyFetcher(verifiable.SingleKey(pubKey, kms.ED25519)),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.Empty(t, vc.Proofs)
+		require.Equal(t, "https://example.com/status/1#1", vc.Status.ID)
+
+		// linked data proof requested for the jwt profile
+		req.Opts = &IssueCredentialOptions{CredentialFormat: vcprofile.LDPCredentialFormat}
+
+		reqBytes, err = json.Marshal(req)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		signedVCResp := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &signedVCResp))
+		require.NotEmpty(t, signedVCResp["proof"])
+
+		// unsupported format
+		req.Opts = &IssueCredentialOptions{CredentialFormat: "invalid"}
+
+		reqBytes, err = json.Marshal(req)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "not supported credential format : invalid")
+	})
+
+	t.Run("issue credential - jwt format with ES256", func(t *testing.T) {
+		p256KeyID, p256PubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ECDSAP256TypeIEEEP1363)
+		require.NoError(t, err)
+
+		x, y := elliptic.Unmarshal(elliptic.P256(), p256PubKey)
+		ecPubKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		jwk, err := jose.JWKFromKey(ecPubKey)
+		require.NoError(t, err)
+
+		vm, err := did.NewVerificationMethodFromJWK("did:test:abc#"+p256KeyID, vccrypto.JSONWebKey2020,
+			"did:test:abc", jwk)
+		require.NoError(t, err)
+
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: &did.Doc{
+						ID:                 didID,
+						VerificationMethod: []did.VerificationMethod{*vm},
+						AssertionMethod:    []did.Verification{{VerificationMethod: *vm}},
+					}}, nil
+				},
+			},
+			Crypto:         customCrypto,
+			DocumentLoader: loader,
+		})
+		require.NoError(t, err)
 
-	handlers := op.GetRESTHandlers()
-	require.NotEmpty(t, handlers)
-}
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "id"}}
 
-func TestIssueCredential(t *testing.T) {
-	customKMS := createKMS(t)
+		jwtProfile := getTestProfile()
+		jwtProfile.Creator = vm.ID
+		jwtProfile.SignatureType = vccrypto.JSONWebSignature2020
+		jwtProfile.CredentialFormat = vcprofile.JWTCredentialFormat
 
-	customCrypto, err := tinkcrypto.New()
-	require.NoError(t, err)
+		require.NoError(t, ops.profileStore.SaveProfile(jwtProfile))
 
-	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
-	require.NoError(t, err)
+		req := &IssueCredentialRequest{Credential: []byte(validVC)}
 
-	endpoint := "/test/credentials/issue"
-	issuerProfileDIDKey := "did:test:abc#" + keyID
-	profile := getTestProfile()
-	profile.Creator = issuerProfileDIDKey
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
 
-	loader := testutil.DocumentLoader(t)
+		rr := serveHTTPMux(t, getHandler(t, ops, issueCredentialPath, http.MethodPost), endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code)
 
-	op, err := New(&Config{
-		StoreProvider:      ariesmemstorage.NewProvider(),
-		KMSSecretsProvider: ariesmemstorage.NewProvider(),
-		KeyManager:         customKMS,
-		Crypto:             customCrypto,
-		VDRI: &vdrmock.MockVDRegistry{
-			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
-				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
-			},
-		},
-		DocumentLoader: loader,
-	})
-	require.NoError(t, err)
+		var vcJWT string
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vcJWT))
 
-	err = op.profileStore.SaveProfile(profile)
-	require.NoError(t, err)
+		parts := strings.Split(vcJWT, ".")
+		require.Len(t, parts, 3)
 
-	urlVars := make(map[string]string)
-	urlVars[profileIDPathParam] = profile.Name
+		headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(t, err)
+		require.Contains(t, string(headerBytes), `"alg":"ES256"`)
 
-	handler := getHandler(t, op, issueCredentialPath, http.MethodPost)
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		require.Len(t, sig, 64)
 
-	t.Run("issue credential - success", func(t *testing.T) {
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		require.True(t, ecdsa.Verify(ecPubKey, digest[:],
+			new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+	})
+
+	t.Run("issue credential - sd-jwt format", func(t *testing.T) {
 		ops, err := New(&Config{
 			StoreProvider:      ariesmemstorage.NewProvider(),
 			KMSSecretsProvider: ariesmemstorage.NewProvider(),
@@ -1723,31 +2806,23 @@ func TestIssueCredential(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "id"}}
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+			ID: "https://example.com/status/1#1", Type: cslstatus.RevocationList2020Status,
+		}}
 
-		profile.SignatureRepresentation = verifiable.SignatureJWS
-		profile.SignatureType = vccrypto.JSONWebSignature2020
+		sdJWTProfile := getTestProfile()
+		sdJWTProfile.Creator = issuerProfileDIDKey
 
-		err = ops.profileStore.SaveProfile(profile)
-		require.NoError(t, err)
+		require.NoError(t, ops.profileStore.SaveProfile(sdJWTProfile))
 
 		issueCredentialHandler := getHandler(t, ops, issueCredentialPath, http.MethodPost)
 
-		const createdTime = "2011-04-16T18:11:09-04:00"
-		ct, err := time.Parse(time.RFC3339, createdTime)
-		require.NoError(t, err)
-
 		req := &IssueCredentialRequest{
 			Credential: []byte(validVC),
 			Opts: &IssueCredentialOptions{
-				AssertionMethod:    "did:local:abc#" + keyID,
-				VerificationMethod: "did:local:abc#" + keyID,
-				Created:            &ct,
-				Challenge:          challenge,
-				Domain:             domain,
-				CredentialStatus: CredentialStatusOpt{
-					Type: cslstatus.RevocationList2020Status,
-				},
+				CredentialFormat:         vcprofile.SDJWTCredentialFormat,
+				DisclosableClaims:        []string{"id"},
+				HolderVerificationMethod: "did:example:holder#key-1",
 			},
 		}
 
@@ -1755,72 +2830,114 @@ func TestIssueCredential(t *testing.T) {
 		require.NoError(t, err)
 
 		rr := serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
 
-		require.Equal(t, http.StatusCreated, rr.Code)
+		var vcSDJWT string
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vcSDJWT))
 
-		signedVCResp := make(map[string]interface{})
-		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		sdJWT, err := sdjwt.Parse(vcSDJWT)
 		require.NoError(t, err)
-		require.NotEmpty(t, signedVCResp["proof"])
-
-		proof, ok := signedVCResp["proof"].(map[string]interface{})
-		require.True(t, ok)
-		require.Equal(t, cslstatus.Context, signedVCResp["@context"].([]interface{})[1])
-		require.Equal(t, "https://w3c-ccg.github.io/lds-jws2020/contexts/lds-jws2020-v1.json",
-			signedVCResp["@context"].([]interface{})[2])
-		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
-		require.NotEmpty(t, proof["jws"])
-		require.Equal(t, "did:local:abc#"+keyID, proof["verificationMethod"])
-		require.Equal(t, "assertionMethod", proof["proofPurpose"])
-		require.Equal(t, createdTime, proof["created"])
-		require.Equal(t, challenge, proof[challenge])
-		require.Equal(t, domain, proof[domain])
+		require.Len(t, sdJWT.Disclosures, 1)
+		require.Empty(t, sdJWT.KeyBinding)
 
-		// default - DID from the issuer profile
-		req.Opts.VerificationMethod = ""
+		holderKeyID, err := sdJWT.HolderKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "did:example:holder#key-1", holderKeyID)
 
-		reqBytes, err = json.Marshal(req)
+		claims, err := sdJWT.Claims()
 		require.NoError(t, err)
+		require.Equal(t, sdjwt.SHA256, claims[sdjwt.SDAlgKey])
 
-		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		revealed, err := sdjwt.Reveal(claims, sdJWT.Disclosures)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"},
+			revealed["vc"].(map[string]interface{})["credentialSubject"])
 
-		require.Equal(t, http.StatusCreated, rr.Code)
+		// disclosable claims need the sd-jwt format
+		req.Opts.CredentialFormat = vcprofile.LDPCredentialFormat
 
-		signedVCResp = make(map[string]interface{})
-		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		reqBytes, err = json.Marshal(req)
 		require.NoError(t, err)
-		require.NotEmpty(t, signedVCResp["proof"])
 
-		proof, ok = signedVCResp["proof"].(map[string]interface{})
-		require.True(t, ok)
-		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
-		require.NotEmpty(t, proof["jws"])
-		require.Equal(t, "did:local:abc#"+keyID, proof["verificationMethod"])
-		require.Equal(t, "assertionMethod", proof["proofPurpose"])
+		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "disclosable claims and holder binding need the sd-jwt credential format")
 
-		// default - DID from the issuer profile
-		req.Opts.AssertionMethod = ""
-		req.Opts.VerificationMethod = ""
+		// unknown disclosable claim
+		req.Opts = &IssueCredentialOptions{
+			CredentialFormat:  vcprofile.SDJWTCredentialFormat,
+			DisclosableClaims: []string{"name"},
+		}
 
 		reqBytes, err = json.Marshal(req)
 		require.NoError(t, err)
 
 		rr = serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "claim name not found")
+	})
 
-		require.Equal(t, http.StatusCreated, rr.Code)
+	t.Run("issue credential - credential schema", func(t *testing.T) {
+		const schemaURL = "https://example.com/schemas/issuer.json"
 
-		signedVCResp = make(map[string]interface{})
-		err = json.Unmarshal(rr.Body.Bytes(), &signedVCResp)
+		ops, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+				},
+			},
+			Crypto:         customCrypto,
+			DocumentLoader: loader,
+			SchemaLoader: schema.NewLocalLoader(map[string][]byte{schemaURL: []byte(`{
+			  "type": "object",
+			  "properties": {"issuer": {"type": "object", "required": ["name"]}},
+			  "required": ["issuer"]
+			}`)}, nil),
+		})
 		require.NoError(t, err)
-		require.NotEmpty(t, signedVCResp["proof"])
-		require.NotEmpty(t, signedVCResp["credentialStatus"])
 
-		proof, ok = signedVCResp["proof"].(map[string]interface{})
-		require.True(t, ok)
-		require.Equal(t, vccrypto.JSONWebSignature2020, proof["type"])
-		require.NotEmpty(t, proof["jws"])
-		require.Equal(t, issuerProfileDIDKey, proof["verificationMethod"])
-		require.Equal(t, "assertionMethod", proof["proofPurpose"])
+		ops.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{ID: "id"}}
+
+		schemaProfile := getTestProfile()
+		schemaProfile.Creator = issuerProfileDIDKey
+
+		require.NoError(t, ops.profileStore.SaveProfile(schemaProfile))
+
+		issueCredentialHandler := getHandler(t, ops, issueCredentialPath, http.MethodPost)
+
+		issue := func(vc string) *httptest.ResponseRecorder {
+			vcWithSchema := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(vc), &vcWithSchema))
+
+			vcWithSchema["credentialSchema"] = map[string]interface{}{
+				"id": schemaURL, "type": schema.JSONSchemaValidator2018,
+			}
+
+			vcBytes, err := json.Marshal(vcWithSchema)
+			require.NoError(t, err)
+
+			reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: vcBytes})
+			require.NoError(t, err)
+
+			return serveHTTPMux(t, issueCredentialHandler, endpoint, reqBytes, urlVars)
+		}
+
+		rr := issue(validVC)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		signedVC := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &signedVC))
+		require.Equal(t, schemaURL, signedVC["credentialSchema"].([]interface{})[0].(map[string]interface{})["id"])
+
+		// the issuer of the credential has no name
+		rr = issue(strings.Replace(validVC, `"name": "Example University"`, `"description": "University"`, 1))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to validate credential: credential doesn't conform to "+
+			"schema "+schemaURL)
+		require.Contains(t, rr.Body.String(), "name is required")
 	})
 
 	t.Run("issue credential with opts - success", func(t *testing.T) {
@@ -2401,6 +3518,65 @@ func TestComposeAndIssueCredential(t *testing.T) {
 		require.Equal(t, createdTime, proof["created"])
 	})
 
+	t.Run("compose and issue credential - jwt format", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         customKMS,
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, key1ID, pubKey)}, nil
+				},
+			},
+			Crypto:         customCrypto,
+			DocumentLoader: loader,
+		})
+		require.NoError(t, err)
+
+		op.vcStatusManager = &mockVCStatusManager{createStatusIDValue: &verifiable.TypedID{
+			ID:   uuid.New().URN(),
+			Type: "RevocationList2020Status", CustomFields: verifiable.CustomFields{
+				"revocationListIndex":      "94567",
+				"revocationListCredential": "https://example.com/credentials/status/3",
+			},
+		}}
+
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		restHandler := getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost)
+
+		req := &ComposeCredentialRequest{
+			Issuer:           issuer,
+			Subject:          subject,
+			IssuanceDate:     &issueDate,
+			CredentialFormat: vcprofile.JWTCredentialFormat,
+		}
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, restHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		var vcJWT string
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vcJWT))
+
+		vcResp, err := verifiable.ParseCredential([]byte(vcJWT),
+			verifiable.WithPublicKeyFetcher(verifiable.SingleKey(pubKey, kms.ED25519)),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.Equal(t, issuer, vcResp.Issuer.ID)
+
+		req.CredentialFormat = "invalid"
+
+		reqBytes, err = json.Marshal(req)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, restHandler, endpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "not supported credential format : invalid")
+	})
+
 	t.Run("compose and issue credential - invalid profile", func(t *testing.T) {
 		ops, err := New(&Config{
 			Crypto:             customCrypto,
@@ -2988,18 +4164,110 @@ type mockVCStatusManager struct {
 	updateVCErr              error
 	getRevocationListVCValue []byte
 	GetRevocationListVCErr   error
+	statusListUpdated        *time.Time
+	statusListAt             func(id string, t time.Time) (*cslstatus.StatusListVC, error)
+	releaseErr               error
+	released                 []*verifiable.TypedID
+	created                  int
+	getStatusErr             error
 }
 
-func (m *mockVCStatusManager) CreateStatusID(profile *vcprofile.DataProfile, url string) (*verifiable.TypedID, error) {
+func (m *mockVCStatusManager) CreateStatusID(profile *vcprofile.DataProfile, url string,
+	opts ...cslstatus.StatusOpts) (*verifiable.TypedID, error) {
 	return m.createStatusIDValue, m.createStatusIDErr
 }
 
-func (m *mockVCStatusManager) UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool) error {
+func (m *mockVCStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile, url string, count int,
+	opts ...cslstatus.StatusOpts) ([]*verifiable.TypedID, error) {
+	if m.createStatusIDErr != nil {
+		return nil, m.createStatusIDErr
+	}
+
+	m.created += count
+
+	statusIDs := make([]*verifiable.TypedID, count)
+
+	for i := range statusIDs {
+		statusIDs[i] = m.createStatusIDValue
+	}
+
+	return statusIDs, nil
+}
+
+func (m *mockVCStatusManager) UpdateVC(v *verifiable.Credential, profile *vcprofile.DataProfile, status bool,
+	opts ...cslstatus.StatusOpts) error {
 	return m.updateVCErr
 }
 
-func (m *mockVCStatusManager) GetRevocationListVC(id string) ([]byte, error) {
-	return m.getRevocationListVCValue, m.GetRevocationListVCErr
+func (m *mockVCStatusManager) UpdateVCs(vcs []*verifiable.Credential, profile *vcprofile.DataProfile, status bool,
+	opts ...cslstatus.StatusOpts) []error {
+	errs := make([]error, len(vcs))
+
+	for i := range errs {
+		errs[i] = m.updateVCErr
+	}
+
+	return errs
+}
+
+func (m *mockVCStatusManager) GetStatusListVC(id string) (*cslstatus.StatusListVC, error) {
+	if m.GetRevocationListVCErr != nil {
+		return nil, m.GetRevocationListVCErr
+	}
+
+	return &cslstatus.StatusListVC{VC: m.getRevocationListVCValue, Updated: m.statusListUpdated}, nil
+}
+
+func (m *mockVCStatusManager) GetStatusListVCAt(id string, t time.Time) (*cslstatus.StatusListVC, error) {
+	if m.statusListAt != nil {
+		return m.statusListAt(id, t)
+	}
+
+	return m.GetStatusListVC(id)
+}
+
+func (m *mockVCStatusManager) ReleaseStatusIDs(statusIDs []*verifiable.TypedID) error {
+	m.released = append(m.released, statusIDs...)
+
+	return m.releaseErr
+}
+
+func (m *mockVCStatusManager) GetStatus(vcStatus *verifiable.TypedID, purpose string) (bool, error) {
+	return false, m.getStatusErr
+}
+
+type mockStatusAuditStore struct {
+	addErr error
+	getErr error
+}
+
+func (m *mockStatusAuditStore) Add(profileID string, records ...*audit.Record) error {
+	return m.addErr
+}
+
+func (m *mockStatusAuditStore) Get(profileID, credentialID string) ([]*audit.Record, error) {
+	return nil, m.getErr
+}
+
+type mockCredentialRegistry struct {
+	err error
+}
+
+func (m *mockCredentialRegistry) Add(profileID string, record *registry.Record) error {
+	return m.err
+}
+
+func (m *mockCredentialRegistry) Update(profileID string, record *registry.Record) error {
+	return m.err
+}
+
+func (m *mockCredentialRegistry) Get(profileID, credentialID string) (*registry.Record, error) {
+	return nil, m.err
+}
+
+func (m *mockCredentialRegistry) Find(profileID string, q *registry.Query, page,
+	pageSize int) ([]*registry.Record, int, error) {
+	return nil, 0, m.err
 }
 
 type mockCredentialStatusManager struct {
@@ -3007,7 +4275,7 @@ type mockCredentialStatusManager struct {
 }
 
 func (m *mockCredentialStatusManager) CreateStatusID(profile *vcprofile.DataProfile,
-	url string) (*verifiable.TypedID, error) {
+	url string, opts ...cslstatus.StatusOpts) (*verifiable.TypedID, error) {
 	if m.CreateErr != nil {
 		return nil, m.CreateErr
 	}
@@ -3015,13 +4283,39 @@ func (m *mockCredentialStatusManager) CreateStatusID(profile *vcprofile.DataProf
 	return nil, nil
 }
 
+func (m *mockCredentialStatusManager) CreateStatusIDs(profile *vcprofile.DataProfile,
+	url string, count int, opts ...cslstatus.StatusOpts) ([]*verifiable.TypedID, error) {
+	if m.CreateErr != nil {
+		return nil, m.CreateErr
+	}
+
+	return make([]*verifiable.TypedID, count), nil
+}
+
 func (m *mockCredentialStatusManager) UpdateVC(v *verifiable.Credential,
-	profile *vcprofile.DataProfile, status bool) error {
+	profile *vcprofile.DataProfile, status bool, opts ...cslstatus.StatusOpts) error {
 	return nil
 }
 
-func (m *mockCredentialStatusManager) GetRevocationListVC(id string) ([]byte, error) {
-	return nil, nil
+func (m *mockCredentialStatusManager) UpdateVCs(vcs []*verifiable.Credential,
+	profile *vcprofile.DataProfile, status bool, opts ...cslstatus.StatusOpts) []error {
+	return make([]error, len(vcs))
+}
+
+func (m *mockCredentialStatusManager) GetStatusListVC(id string) (*cslstatus.StatusListVC, error) {
+	return &cslstatus.StatusListVC{}, nil
+}
+
+func (m *mockCredentialStatusManager) GetStatusListVCAt(id string, t time.Time) (*cslstatus.StatusListVC, error) {
+	return &cslstatus.StatusListVC{}, nil
+}
+
+func (m *mockCredentialStatusManager) ReleaseStatusIDs(statusIDs []*verifiable.TypedID) error {
+	return nil
+}
+
+func (m *mockCredentialStatusManager) GetStatus(vcStatus *verifiable.TypedID, purpose string) (bool, error) {
+	return false, nil
 }
 
 func createKMS(t *testing.T) *localkms.LocalKMS {
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const profileLockKeyPrefix = "issuerprofile_"
+
+// ListIssuerProfiles swagger:route GET /profile issuer listProfilesReq
+//
+// Lists the issuer profiles sorted by name.
+//
+// Responses:
+//    default: genericError
+//        200: listProfilesRes
+func (o *Operation) listIssuerProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	page, pageSize, err := commhttp.GetPage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListProfiles(page, pageSize)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListProfilesResponse{
+		Profiles: profiles,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// UpdateIssuerProfile swagger:route PUT /profile/{id} issuer updateProfileReq
+//
+// Replaces the mutable fields of the issuer profile, the ones missing are reset.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) updateIssuerProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateIssuerProfile(rw, req, true)
+}
+
+// PatchIssuerProfile swagger:route PATCH /profile/{id} issuer updateProfileReq
+//
+// Updates the given mutable fields of the issuer profile, the ones missing are left unchanged.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) patchIssuerProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateIssuerProfile(rw, req, false)
+}
+
+func (o *Operation) updateIssuerProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)["id"]
+
+	unlock, ok := o.lockProfile(rw, profileID)
+	if !ok {
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("profile %s not found", profileID))
+
+			return
+		}
+
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	data := UpdateProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	data.apply(profile, replace)
+
+	if err = validateProfileUpdate(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
+
+// lockProfile locks the issuer profile for the read-modify-write of an update, so that concurrent updates of
+// the profile aren't lost. The error response is written if the lock can't be acquired.
+func (o *Operation) lockProfile(rw http.ResponseWriter, profileID string) (func(), bool) {
+	unlock, err := o.profileLocker.Lock(profileLockKeyPrefix + profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to lock profile %s: %s", profileID, err.Error()))
+
+		return nil, false
+	}
+
+	return unlock, true
+}
+
+// apply sets the fields of the request on the profile, resetting the ones missing when replacing them
+func (r *UpdateProfileRequest) apply(profile *vcprofile.IssuerProfile, replace bool) {
+	if replace {
+		profile.URI = ""
+		profile.SignatureRepresentation = verifiable.SignatureProofValue
+		profile.DisableVCStatus = false
+		profile.OverwriteIssuer = false
+		profile.CredentialFormat = ""
+		profile.CredentialRegistry = false
+		profile.ValidityPeriod = 0
+		profile.RefreshService = nil
+	}
+
+	if r.URI != nil {
+		profile.URI = *r.URI
+	}
+
+	if r.SignatureRepresentation != nil {
+		profile.SignatureRepresentation = *r.SignatureRepresentation
+	}
+
+	if r.DisableVCStatus != nil {
+		profile.DisableVCStatus = *r.DisableVCStatus
+	}
+
+	if r.OverwriteIssuer != nil {
+		profile.OverwriteIssuer = *r.OverwriteIssuer
+	}
+
+	if r.CredentialFormat != nil {
+		profile.CredentialFormat = *r.CredentialFormat
+	}
+
+	if r.CredentialRegistry != nil {
+		profile.CredentialRegistry = *r.CredentialRegistry
+	}
+
+	if r.ValidityPeriod != nil {
+		profile.ValidityPeriod = *r.ValidityPeriod
+	}
+
+	if r.RefreshService != nil {
+		profile.RefreshService = r.RefreshService
+	}
+}
+
+func validateProfileUpdate(profile *vcprofile.IssuerProfile) error {
+	if profile.URI == "" {
+		return fmt.Errorf("missing URI information")
+	}
+
+	if _, err := url.Parse(profile.URI); err != nil {
+		return fmt.Errorf("invalid uri: %w", err)
+	}
+
+	if !isSupportedCredentialFormat(profile.CredentialFormat) {
+		return fmt.Errorf("not supported credential format : %s", profile.CredentialFormat)
+	}
+
+	if profile.ValidityPeriod < 0 {
+		return fmt.Errorf("invalid validity period : %d", profile.ValidityPeriod)
+	}
+
+	return validateRefreshService(profile.RefreshService)
+}
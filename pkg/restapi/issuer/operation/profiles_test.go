@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestListIssuerProfiles(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		Crypto:             customCrypto,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		DocumentLoader:     testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"profile3", "profile1", "profile2"} {
+		profile := getTestProfile()
+		profile.Name = name
+
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+	}
+
+	handler := getHandler(t, op, createProfileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, createProfileEndpoint+"?page=1&pageSize=2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Equal(t, 1, resp.Page)
+		require.Equal(t, 2, resp.PageSize)
+		require.Len(t, resp.Profiles, 1)
+		require.Equal(t, "profile3", resp.Profiles[0].Name)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, createProfileEndpoint+"?pageSize=0", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid pageSize : 0")
+	})
+}
+
+func TestUpdateIssuerProfile(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		Crypto:             customCrypto,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		DocumentLoader:     testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.OverwriteIssuer = true
+	profile.CredentialRegistry = true
+
+	urlVars := map[string]string{"id": profile.Name}
+
+	update := func(t *testing.T, method string, req interface{}) (int, *vcprofile.IssuerProfile, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, method), updateProfileEndpoint, reqBytes,
+			urlVars)
+
+		updated := &vcprofile.IssuerProfile{}
+
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), updated))
+		}
+
+		return rr.Code, updated, rr.Body.String()
+	}
+
+	t.Run("patch profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		disable := true
+
+		code, updated, body := update(t, http.MethodPatch, &UpdateProfileRequest{DisableVCStatus: &disable})
+		require.Equal(t, http.StatusOK, code, body)
+		require.True(t, updated.DisableVCStatus)
+		require.True(t, updated.OverwriteIssuer)
+		require.Equal(t, profile.URI, updated.URI)
+		require.Equal(t, profile.DID, updated.DID)
+		require.Equal(t, profile.Creator, updated.Creator)
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.True(t, saved.DisableVCStatus)
+	})
+
+	t.Run("put profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		uri := "https://example.com/other"
+		format := vcprofile.JWTCredentialFormat
+		representation := verifiable.SignatureJWS
+
+		code, updated, body := update(t, http.MethodPut, &UpdateProfileRequest{
+			URI: &uri, CredentialFormat: &format, SignatureRepresentation: &representation,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, uri, updated.URI)
+		require.Equal(t, format, updated.CredentialFormat)
+		require.Equal(t, verifiable.SignatureJWS, updated.SignatureRepresentation)
+		require.False(t, updated.OverwriteIssuer)
+		require.False(t, updated.CredentialRegistry)
+		require.Equal(t, profile.DID, updated.DID)
+	})
+
+	t.Run("update profile - invalid fields", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		code, _, body := update(t, http.MethodPut, &UpdateProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing URI information")
+
+		format := "other"
+
+		code, _, body = update(t, http.MethodPatch, &UpdateProfileRequest{CredentialFormat: &format})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "not supported credential format : other")
+
+		code, _, body = update(t, http.MethodPatch, &UpdateProfileRequest{
+			RefreshService: &vcprofile.RefreshService{URL: "refresh"},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid refresh service url")
+
+		validityPeriod := int64(-1)
+
+		code, _, body = update(t, http.MethodPatch, &UpdateProfileRequest{ValidityPeriod: &validityPeriod})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid validity period : -1")
+
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, http.MethodPatch), updateProfileEndpoint,
+			[]byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+
+	t.Run("update profile - not found", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, http.MethodPatch), updateProfileEndpoint,
+			[]byte("{}"), map[string]string{"id": "other"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile other not found")
+	})
+}
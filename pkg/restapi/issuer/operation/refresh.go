@@ -0,0 +1,415 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const (
+	// defaultRefreshServiceType is the refresh service type the credentials context defines
+	defaultRefreshServiceType = "ManualRefreshService2018"
+
+	// refreshChallengeStoreName keeps the challenges of refresh presentations apart from the ones of the verifier
+	refreshChallengeStoreName  = "issuerchallenge"
+	defaultRefreshChallengeTTL = 5 * time.Minute
+)
+
+// IssueRefreshChallenge swagger:route POST /{id}/credentials/refresh/challenge issuer refreshChallengeReq
+//
+// Issues a single-use challenge for the presentation of a credential refresh with the profile.
+//
+// Responses:
+//    default: genericError
+//        201: refreshChallengeRes
+func (o *Operation) issueRefreshChallengeHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := RefreshChallengeRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	challenge, err := o.challengeStore.Issue(profile.Name, data.Domain, o.challengeTTL)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, &RefreshChallengeResponse{
+		Challenge: challenge.Value,
+		Domain:    challenge.Domain,
+		ExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// RefreshCredential swagger:route POST /{id}/credentials/refresh issuer refreshCredentialReq
+//
+// Refreshes a credential issued by the profile before it expires. The presentation holds the credential signed by
+// the profile for assertion and is signed by the credential subject, its proof is bound to a challenge issued by the profile for the refresh.
+// Revoked and suspended credentials aren't refreshed. The credential is signed again with new dates for the same
+// validity period, it keeps its status entry.
+//
+// Responses:
+//    default: genericError
+//        201: verifiableCredentialRes
+func (o *Operation) refreshCredentialHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := RefreshCredentialRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	credential, err := o.getRefreshedCredential(profile, data.Presentation)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to refresh credential: %s",
+			err.Error()))
+
+		return
+	}
+
+	validity := credential.Expired.Sub(credential.Issued.Time)
+	issued := time.Now().UTC()
+
+	credential.Issued = util.NewTime(issued)
+	credential.Expired = util.NewTime(issued.Add(validity))
+	credential.Proofs = nil
+
+	o.setRefreshService(profile, credential)
+
+	signedVC, err := signCredential(o.crypto, profile, credential, getIssuerFormatOpts(profile, nil))
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to sign credential:"+
+			" %s", err.Error()))
+
+		return
+	}
+
+	if err = o.updateRegisteredCredential(profile, credential); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, signedVC)
+}
+
+// getRefreshedCredential returns the credential of the presentation once checked that the profile issued it
+// to the holder signing the presentation, that it didn't expire yet and isn't revoked or suspended. The challenge
+// the presentation proof is bound to is consumed once the checks pass, so that the presentation is used once.
+func (o *Operation) getRefreshedCredential(profile *vcprofile.IssuerProfile,
+	vpBytes []byte) (*verifiable.Credential, error) {
+	vp, err := verifiable.ParsePresentation(vpBytes,
+		verifiable.WithPresPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+		verifiable.WithPresJSONLDDocumentLoader(o.documentLoader),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid presentation: %w", err)
+	}
+
+	if len(vp.Proofs) == 0 {
+		return nil, errors.New("presentation isn't signed by the holder")
+	}
+
+	verificationMethod, ok := vp.Proofs[0]["verificationMethod"].(string)
+	if !ok {
+		return nil, errors.New("presentation proof has no verification method")
+	}
+
+	proofChallenge, _ := vp.Proofs[0]["challenge"].(string) // nolint: errcheck
+	proofDomain, _ := vp.Proofs[0]["domain"].(string)       // nolint: errcheck
+
+	if proofChallenge == "" {
+		return nil, errors.New("presentation proof has no challenge")
+	}
+
+	if err = o.challengeStore.Check(proofChallenge, profile.Name, proofDomain); err != nil {
+		return nil, err
+	}
+
+	holder, err := diddoc.GetDIDFromVerificationMethod(verificationMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if vp.Holder != "" && vp.Holder != holder {
+		return nil, errors.New("presentation holder didn't sign it")
+	}
+
+	if len(vp.Credentials()) != 1 {
+		return nil, errors.New("presentation has to hold the credential to refresh only")
+	}
+
+	// the parsed presentation holds VC-JWTs decoded, whether they are signed or not
+	vcBytes, err := presentedCredential(vpBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwt.IsJWTUnsecured(string(vcBytes)) {
+		return nil, errors.New("credential isn't signed by the issuer")
+	}
+
+	credential, err := o.parseAndVerifyVC(vcBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential: %w", err)
+	}
+
+	if credential.Issuer.ID != profile.DID {
+		return nil, fmt.Errorf("credential wasn't issued by profile %s", profile.Name)
+	}
+
+	if err = o.checkIssuerProofs(profile, credential, vcBytes); err != nil {
+		return nil, err
+	}
+
+	if err = o.checkRegistered(profile, credential.ID); err != nil {
+		return nil, err
+	}
+
+	if subject, err := verifiable.SubjectID(credential.Subject); err != nil || subject != holder {
+		return nil, fmt.Errorf("credential wasn't issued to holder %s", holder)
+	}
+
+	if credential.Issued == nil || credential.Expired == nil {
+		return nil, errors.New("credential without issuance and expiration dates doesn't need refreshing")
+	}
+
+	if credential.Expired.Before(time.Now()) {
+		return nil, fmt.Errorf("credential expired on %s", credential.Expired.Format(time.RFC3339))
+	}
+
+	if err = o.checkCredentialStatus(credential); err != nil {
+		return nil, err
+	}
+
+	if err = o.challengeStore.Consume(proofChallenge, profile.Name, proofDomain); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// presentedCredential returns the only credential of the presentation as it was presented, the serialized VC-JWT
+// for credentials in that format.
+func presentedCredential(vpBytes []byte) ([]byte, error) {
+	raw := struct {
+		Credential json.RawMessage `json:"verifiableCredential"`
+	}{}
+
+	if err := json.Unmarshal(vpBytes, &raw); err != nil {
+		return nil, fmt.Errorf("invalid presentation: %w", err)
+	}
+
+	credentials := []json.RawMessage{raw.Credential}
+	if err := json.Unmarshal(raw.Credential, &credentials); err == nil && len(credentials) != 1 {
+		return nil, errors.New("presentation has to hold the credential to refresh only")
+	}
+
+	var jwtVC string
+	if err := json.Unmarshal(credentials[0], &jwtVC); err == nil {
+		return []byte(jwtVC), nil
+	}
+
+	return credentials[0], nil
+}
+
+// checkIssuerProofs checks the credential is signed by the profile with its assertion methods only, the signatures
+// are verified when the credential is parsed, with any key they name. VC-JWTs are signed with the key the header
+// names, the other credentials with the keys of their proofs.
+func (o *Operation) checkIssuerProofs(profile *vcprofile.IssuerProfile, credential *verifiable.Credential,
+	vcBytes []byte) error {
+	var methods []string
+
+	if jwt.IsJWS(string(vcBytes)) {
+		keyID, err := jwsKeyID(string(vcBytes))
+		if err != nil {
+			return err
+		}
+
+		// key IDs are relative to the issuer DID unless they name the DID
+		if strings.HasPrefix(keyID, "#") {
+			keyID = profile.DID + keyID
+		}
+
+		methods = append(methods, keyID)
+	}
+
+	for _, proof := range credential.Proofs {
+		method, ok := proof["verificationMethod"].(string)
+		if !ok {
+			return errors.New("credential proof has no verification method")
+		}
+
+		if purpose, _ := proof["proofPurpose"].(string); purpose != assertionMethod { // nolint: errcheck
+			return fmt.Errorf("credential proof purpose %s isn't %s", purpose, assertionMethod)
+		}
+
+		methods = append(methods, method)
+	}
+
+	if len(methods) == 0 {
+		return errors.New("credential isn't signed by the issuer")
+	}
+
+	docResolution, err := o.vdr.Resolve(profile.DID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve profile DID: %w", err)
+	}
+
+	for _, method := range methods {
+		if didID, errDID := diddoc.GetDIDFromVerificationMethod(method); errDID != nil || didID != profile.DID {
+			return fmt.Errorf("credential isn't signed by profile %s", profile.Name)
+		}
+
+		if err = crypto.ValidateProofPurpose(assertionMethod, method, docResolution.DIDDocument); err != nil {
+			return fmt.Errorf("invalid credential proof: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// jwsKeyID returns the key ID in the header of the JWS.
+func jwsKeyID(jws string) (string, error) {
+	headerBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(jws, ".")[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid credential JWS header: %w", err)
+	}
+
+	header := jose.Headers{}
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return "", fmt.Errorf("invalid credential JWS header: %w", err)
+	}
+
+	keyID, ok := header.KeyID()
+	if !ok {
+		return "", errors.New("credential JWS header has no key ID")
+	}
+
+	return keyID, nil
+}
+
+// checkRegistered checks the profile registered the credential, for profiles keeping a credential registry.
+func (o *Operation) checkRegistered(profile *vcprofile.IssuerProfile, credentialID string) error {
+	if !profile.CredentialRegistry {
+		return nil
+	}
+
+	if _, err := o.credentialRegistry.Get(profile.Name, credentialID); err != nil {
+		return fmt.Errorf("credential isn't registered by profile %s: %w", profile.Name, err)
+	}
+
+	return nil
+}
+
+// checkCredentialStatus checks the credential isn't revoked, nor suspended for status types supporting it.
+func (o *Operation) checkCredentialStatus(credential *verifiable.Credential) error {
+	if credential.Status == nil {
+		return nil
+	}
+
+	revoked, err := o.vcStatusManager.GetStatus(credential.Status, cslstatus.StatusPurposeRevocation)
+	if err != nil {
+		return fmt.Errorf("failed to get credential status: %w", err)
+	}
+
+	if revoked {
+		return errors.New("credential is revoked")
+	}
+
+	if credential.Status.Type != cslstatus.StatusList2021Entry {
+		return nil
+	}
+
+	suspended, err := o.vcStatusManager.GetStatus(credential.Status, cslstatus.StatusPurposeSuspension)
+	if err != nil {
+		return fmt.Errorf("failed to get credential status: %w", err)
+	}
+
+	if suspended {
+		return errors.New("credential is suspended")
+	}
+
+	return nil
+}
+
+// setRefreshService embeds the refresh service of the profile in the credential
+func (o *Operation) setRefreshService(profile *vcprofile.IssuerProfile, credential *verifiable.Credential) {
+	if profile.RefreshService == nil {
+		return
+	}
+
+	service := verifiable.TypedID{ID: profile.RefreshService.URL, Type: profile.RefreshService.Type}
+
+	if service.ID == "" {
+		service.ID = o.hostURL + "/" + profile.Name + "/credentials/refresh"
+	}
+
+	if service.Type == "" {
+		service.Type = defaultRefreshServiceType
+	}
+
+	credential.RefreshService = []verifiable.TypedID{service}
+}
+
+func validateRefreshService(service *vcprofile.RefreshService) error {
+	if service == nil || service.URL == "" {
+		return nil
+	}
+
+	if _, err := url.ParseRequestURI(service.URL); err != nil {
+		return fmt.Errorf("invalid refresh service url: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,440 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestRefreshCredential(t *testing.T) {
+	const holderDID = "did:example:holder"
+
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	issuerKeyID, issuerPubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	holderKeyID, holderPubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	vdri := &vdrmock.MockVDRegistry{
+		ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+			if didID == holderDID {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, holderKeyID, holderPubKey)}, nil
+			}
+
+			return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, issuerKeyID, issuerPubKey)}, nil
+		},
+	}
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI:               vdri,
+		DocumentLoader:     testutil.DocumentLoader(t),
+		HostURL:            "https://issuer.example.com",
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.Creator = profile.DID + "#" + issuerKeyID
+	profile.DisableVCStatus = true
+	profile.RefreshService = &vcprofile.RefreshService{}
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	statusProfile := getTestProfile()
+	statusProfile.Name = "status"
+	statusProfile.Creator = profile.Creator
+	statusProfile.VCStatusType = cslstatus.StatusList2021Entry
+
+	require.NoError(t, op.profileStore.SaveProfile(statusProfile))
+
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
+	statusURLVars := map[string]string{profileIDPathParam: statusProfile.Name}
+	signer := vccrypto.New(customKMS, customCrypto, vdri, testutil.DocumentLoader(t))
+	holder := &vcprofile.HolderProfile{DataProfile: &vcprofile.DataProfile{
+		DID:           holderDID,
+		Creator:       holderDID + "#" + holderKeyID,
+		SignatureType: vccrypto.Ed25519Signature2018,
+	}}
+
+	issueWith := func(t *testing.T, vars map[string]string, subject string, issued,
+		expired time.Time) *verifiable.Credential {
+		t.Helper()
+
+		credential := &verifiable.Credential{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			ID:      "http://example.edu/credentials/1872",
+			Types:   []string{"VerifiableCredential"},
+			Subject: verifiable.Subject{ID: subject},
+			Issuer:  verifiable.Issuer{ID: profile.DID},
+			Issued:  util.NewTime(issued),
+			Expired: util.NewTime(expired),
+		}
+
+		vcBytes, err := json.Marshal(credential)
+		require.NoError(t, err)
+
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: vcBytes})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, issueCredentialPath, http.MethodPost), issueCredentialPath, reqBytes,
+			vars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		signedVC, err := verifiable.ParseCredential(rr.Body.Bytes(), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(testutil.DocumentLoader(t)))
+		require.NoError(t, err)
+
+		return signedVC
+	}
+
+	issue := func(t *testing.T, subject string, issued, expired time.Time) *verifiable.Credential {
+		t.Helper()
+
+		return issueWith(t, urlVars, subject, issued, expired)
+	}
+
+	challengeHandler := getHandler(t, op, refreshChallengePath, http.MethodPost)
+
+	getChallenge := func(t *testing.T, vars map[string]string, domain string) string {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&RefreshChallengeRequest{Domain: domain})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, challengeHandler, refreshChallengePath, reqBytes, vars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		res := RefreshChallengeResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		require.Equal(t, domain, res.Domain)
+
+		return res.Challenge
+	}
+
+	presentWith := func(t *testing.T, opts []vccrypto.SigningOpts, credentials ...interface{}) []byte {
+		t.Helper()
+
+		var (
+			vcs    []*verifiable.Credential
+			jwtVCs []string
+		)
+
+		for _, c := range credentials {
+			if jwtVC, ok := c.(string); ok {
+				jwtVCs = append(jwtVCs, jwtVC)
+			} else {
+				vcs = append(vcs, c.(*verifiable.Credential))
+			}
+		}
+
+		vp, err := verifiable.NewPresentation(verifiable.WithCredentials(vcs...),
+			verifiable.WithJWTCredentials(jwtVCs...))
+		require.NoError(t, err)
+
+		vp.Holder = holderDID
+
+		vp, err = signer.SignPresentation(holder, vp, opts...)
+		require.NoError(t, err)
+
+		vpBytes, err := json.Marshal(vp)
+		require.NoError(t, err)
+
+		reqBytes, err := json.Marshal(&RefreshCredentialRequest{Presentation: vpBytes})
+		require.NoError(t, err)
+
+		return reqBytes
+	}
+
+	present := func(t *testing.T, credentials ...interface{}) []byte {
+		t.Helper()
+
+		return presentWith(t, []vccrypto.SigningOpts{vccrypto.WithChallenge(getChallenge(t, urlVars, ""))},
+			credentials...)
+	}
+
+	handler := getHandler(t, op, refreshCredentialPath, http.MethodPost)
+
+	t.Run("refresh credential - success", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		credential := issue(t, holderDID, issued, issued.Add(2*time.Hour))
+
+		require.Len(t, credential.RefreshService, 1)
+		require.Equal(t, "https://issuer.example.com/test/credentials/refresh", credential.RefreshService[0].ID)
+		require.Equal(t, defaultRefreshServiceType, credential.RefreshService[0].Type)
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, present(t, credential), urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		refreshed, err := op.parseAndVerifyVC(rr.Body.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, credential.ID, refreshed.ID)
+		require.True(t, refreshed.Issued.After(issued))
+		require.Equal(t, 2*time.Hour, refreshed.Expired.Sub(refreshed.Issued.Time))
+		require.Len(t, refreshed.Proofs, 1)
+		require.Equal(t, credential.RefreshService, refreshed.RefreshService)
+	})
+
+	t.Run("refresh credential - invalid credential", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath,
+			present(t, issue(t, holderDID, issued, issued.Add(time.Minute))), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential expired on")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath,
+			present(t, issue(t, "did:example:other", issued, issued.Add(2*time.Hour))), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential wasn't issued to holder did:example:holder")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, present(t), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation has to hold the credential to refresh only")
+	})
+
+	t.Run("refresh credential - not signed by the issuer", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+
+		credential := &verifiable.Credential{
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			ID:      "http://example.edu/credentials/forged",
+			Types:   []string{"VerifiableCredential"},
+			Subject: verifiable.Subject{ID: holderDID},
+			Issuer:  verifiable.Issuer{ID: profile.DID},
+			Issued:  util.NewTime(issued),
+			Expired: util.NewTime(issued.Add(2 * time.Hour)),
+		}
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, present(t, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential isn't signed by the issuer")
+
+		claims, err := credential.JWTClaims(false)
+		require.NoError(t, err)
+
+		unsecuredJWT, err := claims.MarshalUnsecuredJWT()
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, present(t, unsecuredJWT), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential isn't signed by the issuer")
+
+		// signed by the holder rather than the issuer
+		holderSigned, err := signer.SignCredential(holder.DataProfile, credential)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, present(t, holderSigned), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential isn't signed by profile test")
+
+		// signed by the issuer, but not for assertion
+		credential.Proofs = nil
+
+		authSigned, err := signer.SignCredential(profile.DataProfile, credential,
+			vccrypto.WithPurpose(vccrypto.Authentication))
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, present(t, authSigned), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential proof purpose authentication isn't assertionMethod")
+	})
+
+	t.Run("refresh credential - VC-JWT", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		credential := issue(t, holderDID, issued, issued.Add(2*time.Hour))
+		credential.Proofs = nil
+
+		claims, err := credential.JWTClaims(false)
+		require.NoError(t, err)
+
+		kh, err := customKMS.Get(issuerKeyID)
+		require.NoError(t, err)
+
+		jws, err := claims.MarshalJWS(verifiable.EdDSA, suite.NewCryptoSigner(customCrypto, kh), "#"+issuerKeyID)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, present(t, jws), urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		// signed with a key of another DID
+		kh, err = customKMS.Get(holderKeyID)
+		require.NoError(t, err)
+
+		jws, err = claims.MarshalJWS(verifiable.EdDSA, suite.NewCryptoSigner(customCrypto, kh),
+			holderDID+"#"+holderKeyID)
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, present(t, jws), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "is not found for DID did:test:abc")
+	})
+
+	t.Run("refresh credential - not registered", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+		credential := issue(t, holderDID, issued, issued.Add(2*time.Hour))
+
+		profile.CredentialRegistry = true
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		defer func() {
+			profile.CredentialRegistry = false
+			require.NoError(t, op.profileStore.SaveProfile(profile))
+		}()
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, present(t, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential isn't registered by profile test")
+	})
+
+	t.Run("refresh credential - presentation replayed", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+		reqBytes := present(t, issue(t, holderDID, issued, issued.Add(2*time.Hour)))
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge not issued or already used")
+	})
+
+	t.Run("refresh credential - presentation not bound to a challenge", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+		credential := issue(t, holderDID, issued, issued.Add(2*time.Hour))
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, presentWith(t, nil, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation proof has no challenge")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentWith(t,
+			[]vccrypto.SigningOpts{vccrypto.WithChallenge("unknown")}, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge not issued or already used")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentWith(t, []vccrypto.SigningOpts{
+			vccrypto.WithChallenge(getChallenge(t, urlVars, "example.com")), vccrypto.WithDomain("other.com"),
+		}, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge was issued for domain example.com")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentWith(t, []vccrypto.SigningOpts{
+			vccrypto.WithChallenge(getChallenge(t, statusURLVars, "")),
+		}, credential), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "challenge was issued for profile status")
+
+		// the challenge of a presentation bound to the domain is accepted
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentWith(t, []vccrypto.SigningOpts{
+			vccrypto.WithChallenge(getChallenge(t, urlVars, "example.com")), vccrypto.WithDomain("example.com"),
+		}, credential), urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	})
+
+	t.Run("refresh credential - revoked or suspended", func(t *testing.T) {
+		issued := time.Now().Add(-time.Hour)
+
+		presentStatus := func(t *testing.T, credential *verifiable.Credential) []byte {
+			t.Helper()
+
+			return presentWith(t, []vccrypto.SigningOpts{
+				vccrypto.WithChallenge(getChallenge(t, statusURLVars, "")),
+			}, credential)
+		}
+
+		credential := issueWith(t, statusURLVars, holderDID, issued, issued.Add(2*time.Hour))
+		require.NotNil(t, credential.Status)
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, presentStatus(t, credential), statusURLVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		require.NoError(t, op.vcStatusManager.UpdateVC(credential, statusProfile.DataProfile, true,
+			cslstatus.WithStatusPurpose(cslstatus.StatusPurposeSuspension)))
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentStatus(t, credential), statusURLVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential is suspended")
+
+		require.NoError(t, op.vcStatusManager.UpdateVC(credential, statusProfile.DataProfile, true))
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentStatus(t, credential), statusURLVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential is revoked")
+
+		statusManager := op.vcStatusManager
+		op.vcStatusManager = &mockVCStatusManager{getStatusErr: errors.New("status error")}
+
+		defer func() { op.vcStatusManager = statusManager }()
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, presentStatus(t, credential), statusURLVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to get credential status: status error")
+	})
+
+	t.Run("refresh challenge - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, challengeHandler, refreshChallengePath, nil,
+			map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = serveHTTPMux(t, challengeHandler, refreshChallengePath, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+
+	t.Run("refresh credential - unsigned presentation", func(t *testing.T) {
+		vpBytes, err := json.Marshal(map[string]interface{}{
+			"@context": []string{"https://www.w3.org/2018/credentials/v1"},
+			"type":     "VerifiablePresentation",
+		})
+		require.NoError(t, err)
+
+		reqBytes, err := json.Marshal(&RefreshCredentialRequest{Presentation: vpBytes})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "presentation isn't signed by the holder")
+	})
+
+	t.Run("refresh credential - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, refreshCredentialPath, nil, map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = serveHTTPMux(t, handler, refreshCredentialPath, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+// RotateKey swagger:route POST /profile/{id}/rotateKey issuer rotateKeyReq
+//
+// Rotates the signing key of the issuer profile. The new key is added to the profile DID, the previous keys stay
+// in it so that issued credentials still verify. Credentials and status lists are signed with the new key from then on.
+//
+// Responses:
+//    default: genericError
+//        200: issuerProfileRes
+func (o *Operation) rotateKeyHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	unlock, ok := o.lockProfile(rw, profileID)
+	if !ok {
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := RotateKeyRequest{}
+
+	// the body is optional, a new key is created when there is none
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if (data.DIDKeyID == "") != (data.DIDPrivateKey == "") {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, "didKeyID and didPrivateKey go together")
+
+		return
+	}
+
+	creator, err := o.commonDID.RotateKey(profile.DID, profile.Creator, data.DIDKeyID, data.DIDPrivateKey)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to rotate key: %s", err.Error()))
+
+		return
+	}
+
+	logger.Infof("issuer profile %s rotated its key from %s to %s", profile.Name, profile.Creator, creator)
+
+	profile.Creator = creator
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
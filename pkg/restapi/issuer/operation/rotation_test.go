@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestRotateKey(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	oldKeyID, oldPubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	newKeyID, newPubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				didDoc := createDIDDocWithKeyID(didID, oldKeyID, oldPubKey)
+				newKey := createDIDDocWithKeyID(didID, newKeyID, newPubKey).VerificationMethod[0]
+
+				didDoc.VerificationMethod = append(didDoc.VerificationMethod, newKey)
+				didDoc.AssertionMethod = append(didDoc.AssertionMethod, did.Verification{VerificationMethod: newKey})
+
+				return &did.DocResolution{DIDDocument: didDoc}, nil
+			},
+		},
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.Creator = profile.DID + "#" + oldKeyID
+	profile.DisableVCStatus = true
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	urlVars := map[string]string{"id": profile.Name}
+	handler := getHandler(t, op, rotateKeyEndpoint, http.MethodPost)
+
+	t.Run("rotate key - success", func(t *testing.T) {
+		op.commonDID = &mockCommonDID{rotateKeyValue: profile.DID + "#" + newKeyID}
+
+		rr := serveHTTPMux(t, handler, rotateKeyEndpoint, nil, urlVars)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rotated := &vcprofile.IssuerProfile{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), rotated))
+		require.Equal(t, profile.DID+"#"+newKeyID, rotated.Creator)
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.Equal(t, rotated.Creator, saved.Creator)
+
+		// credentials are signed with the new key from then on
+		reqBytes, err := json.Marshal(&IssueCredentialRequest{Credential: []byte(validVCWithoutStatus)})
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, getHandler(t, op, issueCredentialPath, http.MethodPost), issueCredentialPath, reqBytes,
+			map[string]string{profileIDPathParam: profile.Name})
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		signedVC := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &signedVC))
+		require.Equal(t, rotated.Creator, signedVC["proof"].(map[string]interface{})["verificationMethod"])
+	})
+
+	t.Run("rotate key - error", func(t *testing.T) {
+		op.commonDID = &mockCommonDID{rotateKeyErr: errors.New("update error")}
+
+		reqBytes, err := json.Marshal(&RotateKeyRequest{DIDKeyID: "did:test:abc#key2", DIDPrivateKey: "key"})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, rotateKeyEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to rotate key: update error")
+	})
+
+	t.Run("rotate key - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, rotateKeyEndpoint, nil, map[string]string{"id": "other"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = serveHTTPMux(t, handler, rotateKeyEndpoint, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		reqBytes, err := json.Marshal(&RotateKeyRequest{DIDKeyID: "did:test:abc#key2"})
+		require.NoError(t, err)
+
+		rr = serveHTTPMux(t, handler, rotateKeyEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "didKeyID and didPrivateKey go together")
+	})
+}
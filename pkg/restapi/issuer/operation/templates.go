@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/xeipuuv/gojsonschema"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const templateIDPathParam = "templateID"
+
+// AddCredentialTemplate swagger:route POST /profile/{id}/templates issuer addCredentialTemplateReq
+//
+// Adds a credential template to the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        201: credentialTemplateRes
+func (o *Operation) addCredentialTemplateHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+
+	unlock, ok := o.lockProfile(rw, profileID)
+	if !ok {
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	template := &vcprofile.CredentialTemplate{}
+
+	if err = json.NewDecoder(req.Body).Decode(template); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if err = validateCredentialTemplates(append(profile.CredentialTemplates, template)); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profile.CredentialTemplates = append(profile.CredentialTemplates, template)
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, template)
+}
+
+// DeleteCredentialTemplate swagger:route DELETE /profile/{id}/templates/{templateID} issuer deleteCredentialTemplateReq
+//
+// Deletes a credential template of the issuer profile.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) deleteCredentialTemplateHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)["id"]
+	templateID := mux.Vars(req)[templateIDPathParam]
+
+	unlock, ok := o.lockProfile(rw, profileID)
+	if !ok {
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid issuer profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	if profile.CredentialTemplate(templateID) == nil {
+		commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("credential template %s not found", templateID))
+
+		return
+	}
+
+	templates := make([]*vcprofile.CredentialTemplate, 0, len(profile.CredentialTemplates)-1)
+
+	for _, t := range profile.CredentialTemplates {
+		if t.ID != templateID {
+			templates = append(templates, t)
+		}
+	}
+
+	profile.CredentialTemplates = templates
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+func validateCredentialTemplates(templates []*vcprofile.CredentialTemplate) error {
+	ids := make(map[string]bool)
+
+	for _, t := range templates {
+		if err := validateCredentialTemplate(t); err != nil {
+			return err
+		}
+
+		if ids[t.ID] {
+			return fmt.Errorf("credential template %s already exists", t.ID)
+		}
+
+		ids[t.ID] = true
+	}
+
+	return nil
+}
+
+func validateCredentialTemplate(t *vcprofile.CredentialTemplate) error {
+	if t == nil || t.ID == "" {
+		return errors.New("missing credential template ID")
+	}
+
+	if t.ValidityPeriod < 0 {
+		return fmt.Errorf("invalid credential template %s validity period : %d", t.ID, t.ValidityPeriod)
+	}
+
+	if len(t.Schema) != 0 {
+		if _, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(t.Schema)); err != nil {
+			return fmt.Errorf("invalid credential template %s schema: %w", t.ID, err)
+		}
+	}
+
+	if len(t.Evidence) != 0 {
+		if _, err := templateEvidence(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateEvidence returns the evidence of the template, an object or an array of objects.
+func templateEvidence(t *vcprofile.CredentialTemplate) (verifiable.Evidence, error) {
+	var evidence interface{}
+
+	if err := json.Unmarshal(t.Evidence, &evidence); err != nil {
+		return nil, fmt.Errorf("invalid credential template %s evidence: %w", t.ID, err)
+	}
+
+	switch e := evidence.(type) {
+	case map[string]interface{}:
+		return e, nil
+	case []interface{}:
+		for _, item := range e {
+			if _, ok := item.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("invalid credential template %s evidence: items must be objects", t.ID)
+			}
+		}
+
+		return e, nil
+	default:
+		return nil, fmt.Errorf("invalid credential template %s evidence: must be an object or an array", t.ID)
+	}
+}
+
+// validateTemplateClaims checks the claims of a credential composed from the template against its schema
+func validateTemplateClaims(t *vcprofile.CredentialTemplate, claims json.RawMessage) error {
+	if len(t.Schema) == 0 {
+		return nil
+	}
+
+	if len(claims) == 0 {
+		claims = json.RawMessage("{}")
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(t.Schema), gojsonschema.NewBytesLoader(claims))
+	if err != nil {
+		return fmt.Errorf("failed to validate claims against credential template %s: %w", t.ID, err)
+	}
+
+	if !result.Valid() {
+		errs := make([]string, len(result.Errors()))
+		for i, e := range result.Errors() {
+			errs[i] = e.String()
+		}
+
+		return fmt.Errorf("claims don't match the schema of credential template %s: %s", t.ID,
+			strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// applyCredentialTemplate sets the contexts, types and evidence the template fixes on the credential,
+// along with the expiration date if the credential has none.
+func applyCredentialTemplate(credential *verifiable.Credential, t *vcprofile.CredentialTemplate) error {
+	if len(t.Contexts) != 0 {
+		credential.Context = t.Contexts
+	}
+
+	if len(t.Types) != 0 {
+		credential.Types = t.Types
+	}
+
+	if len(t.Evidence) != 0 {
+		evidence, err := templateEvidence(t)
+		if err != nil {
+			return err
+		}
+
+		credential.Evidence = evidence
+	}
+
+	setExpirationDate(credential, t.ValidityPeriod)
+
+	return nil
+}
+
+// setExpirationDate sets the expiration date of a credential without one to the end of the validity period
+// in seconds from its issuance, nothing is set when the period is 0
+func setExpirationDate(credential *verifiable.Credential, validityPeriod int64) {
+	if credential.Expired != nil || validityPeriod <= 0 {
+		return
+	}
+
+	issued := time.Now().UTC()
+	if credential.Issued != nil {
+		issued = credential.Issued.Time
+	}
+
+	credential.Expired = util.NewTime(issued.Add(time.Duration(validityPeriod) * time.Second))
+}
@@ -0,0 +1,375 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vcprofile "github.com/trustbloc/edge-service/pkg/doc/vc/profile"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const degreeSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "name": {"type": "string"},
+    "degree": {"type": "string", "enum": ["BachelorDegree", "MasterDegree"]}
+  },
+  "required": ["name", "degree"]
+}`
+
+func getDegreeTemplate() *vcprofile.CredentialTemplate {
+	return &vcprofile.CredentialTemplate{
+		ID: "degree",
+		Contexts: []string{
+			"https://www.w3.org/2018/credentials/v1",
+			"https://www.w3.org/2018/credentials/examples/v1",
+		},
+		Types:          []string{"VerifiableCredential", "UniversityDegreeCredential"},
+		Schema:         json.RawMessage(degreeSchema),
+		ValidityPeriod: int64((24 * time.Hour).Seconds()),
+		Evidence:       json.RawMessage(`{"id":"https://example.edu/evidence/1","type":"IssuerPolicy"}`),
+	}
+}
+
+func TestCredentialTemplateHandlers(t *testing.T) {
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         createKMS(t),
+		Crypto:             customCrypto,
+		VDRI:               &vdrmock.MockVDRegistry{},
+		DocumentLoader:     testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	urlVars := map[string]string{"id": profile.Name}
+
+	addHandler := getHandler(t, op, credentialTemplatesEndpoint, http.MethodPost)
+	deleteHandler := getHandler(t, op, credentialTemplateEndpoint, http.MethodDelete)
+
+	t.Run("add and delete template", func(t *testing.T) {
+		reqBytes, err := json.Marshal(getDegreeTemplate())
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.NotNil(t, saved.CredentialTemplate("degree"))
+		require.Equal(t, getDegreeTemplate().Types, saved.CredentialTemplate("degree").Types)
+
+		rr = serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential template degree already exists")
+
+		rr = serveHTTPMux(t, deleteHandler, credentialTemplateEndpoint, nil,
+			map[string]string{"id": profile.Name, templateIDPathParam: "degree"})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		saved, err = op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+		require.Nil(t, saved.CredentialTemplate("degree"))
+
+		rr = serveHTTPMux(t, deleteHandler, credentialTemplateEndpoint, nil,
+			map[string]string{"id": profile.Name, templateIDPathParam: "degree"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "credential template degree not found")
+	})
+
+	t.Run("add template - errors", func(t *testing.T) {
+		rr := serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, []byte("{}"),
+			map[string]string{"id": "wrongProfile"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+
+		rr = serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+
+		rr = serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, []byte(`{"schema":{"type":"invalid"}}`),
+			urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "missing credential template ID")
+	})
+
+	t.Run("concurrent adds keep all templates", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		codes := make([]int, 10)
+
+		for i := range codes {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				template := getDegreeTemplate()
+				template.ID = fmt.Sprintf("concurrent%d", i)
+
+				reqBytes, err := json.Marshal(template)
+				if err != nil {
+					return
+				}
+
+				codes[i] = serveHTTPMux(t, addHandler, credentialTemplatesEndpoint, reqBytes, urlVars).Code
+			}(i)
+		}
+
+		wg.Wait()
+
+		saved, err := op.profileStore.GetProfile(profile.Name)
+		require.NoError(t, err)
+
+		for i, code := range codes {
+			require.Equal(t, http.StatusCreated, code)
+			require.NotNil(t, saved.CredentialTemplate(fmt.Sprintf("concurrent%d", i)))
+		}
+	})
+
+	t.Run("lock error", func(t *testing.T) {
+		locked, err := New(&Config{
+			StoreProvider:      ariesmemstorage.NewProvider(),
+			KMSSecretsProvider: ariesmemstorage.NewProvider(),
+			KeyManager:         createKMS(t),
+			Crypto:             customCrypto,
+			VDRI:               &vdrmock.MockVDRegistry{},
+			DocumentLoader:     testutil.DocumentLoader(t),
+			ProfileLocker:      &mockLocker{err: errors.New("lock error")},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, locked, credentialTemplatesEndpoint, http.MethodPost),
+			credentialTemplatesEndpoint, []byte("{}"), urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to lock profile test: lock error")
+	})
+
+	t.Run("delete template - invalid profile", func(t *testing.T) {
+		rr := serveHTTPMux(t, deleteHandler, credentialTemplateEndpoint, nil,
+			map[string]string{"id": "wrongProfile", templateIDPathParam: "degree"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid issuer profile")
+	})
+}
+
+func TestValidateCredentialTemplate(t *testing.T) {
+	require.NoError(t, validateCredentialTemplate(getDegreeTemplate()))
+	require.NoError(t, validateCredentialTemplate(&vcprofile.CredentialTemplate{ID: "empty"}))
+
+	template := getDegreeTemplate()
+	template.ValidityPeriod = -1
+	err := validateCredentialTemplate(template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree validity period : -1")
+
+	template = getDegreeTemplate()
+	template.Schema = json.RawMessage(`{"type":"invalid"}`)
+	err = validateCredentialTemplate(template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree schema")
+
+	template = getDegreeTemplate()
+	template.Evidence = json.RawMessage(`[{"id":"https://example.edu/evidence/1"},{"type":"DocumentVerification"}]`)
+	require.NoError(t, validateCredentialTemplate(template))
+
+	template = getDegreeTemplate()
+	template.Evidence = json.RawMessage(`["evidence"]`)
+	err = validateCredentialTemplate(template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree evidence: items must be objects")
+
+	template = getDegreeTemplate()
+	template.Evidence = json.RawMessage(`"evidence"`)
+	err = validateCredentialTemplate(template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree evidence: must be an object or an array")
+
+	template = getDegreeTemplate()
+	template.Evidence = json.RawMessage(`{`)
+	err = validateCredentialTemplate(template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree evidence")
+
+	profile := getProfileRequest()
+	profile.CredentialTemplates = []*vcprofile.CredentialTemplate{getDegreeTemplate(), getDegreeTemplate()}
+	err = validateProfileRequest(profile)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "credential template degree already exists")
+}
+
+func TestApplyCredentialTemplate(t *testing.T) {
+	template := getDegreeTemplate()
+	template.Evidence = json.RawMessage(`[{"id":"https://example.edu/evidence/1"},{"type":"DocumentVerification"}]`)
+
+	credential := &verifiable.Credential{}
+	require.NoError(t, applyCredentialTemplate(credential, template))
+	require.Len(t, credential.Evidence, 2)
+	require.Equal(t, template.Types, credential.Types)
+	require.NotNil(t, credential.Expired)
+
+	template.Evidence = json.RawMessage(`1`)
+	err := applyCredentialTemplate(&verifiable.Credential{}, template)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid credential template degree evidence")
+}
+
+func TestComposeAndIssueCredential_Template(t *testing.T) {
+	customKMS := createKMS(t)
+
+	customCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, pubKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	loader := testutil.DocumentLoader(t)
+
+	op, err := New(&Config{
+		StoreProvider:      ariesmemstorage.NewProvider(),
+		KMSSecretsProvider: ariesmemstorage.NewProvider(),
+		KeyManager:         customKMS,
+		Crypto:             customCrypto,
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: createDIDDocWithKeyID(didID, keyID, pubKey)}, nil
+			},
+		},
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	profile := getTestProfile()
+	profile.Creator = "did:test:abc#" + keyID
+	profile.DisableVCStatus = true
+	profile.CredentialTemplates = []*vcprofile.CredentialTemplate{getDegreeTemplate()}
+	profile.ValidityPeriod = int64(time.Hour.Seconds())
+
+	require.NoError(t, op.profileStore.SaveProfile(profile))
+
+	urlVars := map[string]string{profileIDPathParam: profile.Name}
+	handler := getHandler(t, op, composeAndIssueCredentialPath, http.MethodPost)
+
+	compose := func(t *testing.T, req *ComposeCredentialRequest) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, composeAndIssueCredentialPath, reqBytes, urlVars)
+
+		return rr.Code, rr.Body.String()
+	}
+
+	issued := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("compose from template - success", func(t *testing.T) {
+		code, body := compose(t, &ComposeCredentialRequest{
+			TemplateID:   "degree",
+			Issuer:       "did:example:issuer",
+			Subject:      "did:example:subject",
+			Types:        []string{"VerifiableCredential", "OtherCredential"},
+			IssuanceDate: &issued,
+			Claims:       json.RawMessage(`{"name":"Jayden Doe","degree":"MasterDegree"}`),
+		})
+		require.Equal(t, http.StatusCreated, code, body)
+
+		vc, err := verifiable.ParseCredential([]byte(body), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.Equal(t, getDegreeTemplate().Types, vc.Types)
+		require.Equal(t, getDegreeTemplate().Contexts, vc.Context[:2])
+		require.Equal(t, "https://example.edu/evidence/1", vc.Evidence.(map[string]interface{})["id"])
+		require.NotNil(t, vc.Expired)
+		require.Equal(t, issued.Add(24*time.Hour), vc.Expired.Time)
+
+		// the expiration date of the request wins over the template validity period
+		expired := issued.Add(time.Hour)
+
+		code, body = compose(t, &ComposeCredentialRequest{
+			TemplateID:     "degree",
+			Issuer:         "did:example:issuer",
+			Subject:        "did:example:subject",
+			IssuanceDate:   &issued,
+			ExpirationDate: &expired,
+			Claims:         json.RawMessage(`{"name":"Jayden Doe","degree":"BachelorDegree"}`),
+		})
+		require.Equal(t, http.StatusCreated, code, body)
+
+		vc, err = verifiable.ParseCredential([]byte(body), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.Equal(t, expired, vc.Expired.Time)
+	})
+
+	t.Run("compose without template - profile validity period", func(t *testing.T) {
+		code, body := compose(t, &ComposeCredentialRequest{
+			Issuer:       "did:example:issuer",
+			Subject:      "did:example:subject",
+			IssuanceDate: &issued,
+		})
+		require.Equal(t, http.StatusCreated, code, body)
+
+		vc, err := verifiable.ParseCredential([]byte(body), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, err)
+		require.NotNil(t, vc.Expired)
+		require.Equal(t, issued.Add(time.Hour), vc.Expired.Time)
+	})
+
+	t.Run("compose from template - claims don't match the schema", func(t *testing.T) {
+		code, body := compose(t, &ComposeCredentialRequest{
+			TemplateID: "degree",
+			Subject:    "did:example:subject",
+			Claims:     json.RawMessage(`{"name":"Jayden Doe","degree":"PhD"}`),
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "claims don't match the schema of credential template degree")
+		require.Contains(t, body, "degree must be one of the following")
+
+		code, body = compose(t, &ComposeCredentialRequest{TemplateID: "degree", Subject: "did:example:subject"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "name is required")
+	})
+
+	t.Run("compose from template - template not found", func(t *testing.T) {
+		code, body := compose(t, &ComposeCredentialRequest{TemplateID: "other", Subject: "did:example:subject"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "credential template other not found")
+	})
+}
+
+type mockLocker struct {
+	err error
+}
+
+func (m *mockLocker) Lock(string) (func(), error) {
+	return func() {}, m.err
+}
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+// IssueChallenge swagger:route POST /{id}/verifier/challenges verifier challengeReq
+//
+// Issues a single-use challenge for a presentation verified with the profile.
+//
+// Responses:
+//    default: genericError
+//        201: challengeRes
+func (o *Operation) issueChallengeHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := ChallengeRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	challenge, err := o.challengeStore.Issue(profile.ID, data.Domain, o.challengeTTL)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+	commhttp.WriteResponse(rw, &ChallengeResponse{
+		Challenge: challenge.Value,
+		Domain:    challenge.Domain,
+		ExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// consumeChallenge consumes the challenge the presentation proof is bound to, once the proof is verified with it so
+// that only the holder can use up the challenge. The challenge and domain of the proof are then the ones expected by
+// the proof check, unless the request sets others.
+func (o *Operation) consumeChallenge(profileID string, verificationReq *VerifyPresentationRequest) error {
+	proofChallenge, proofDomain, err := o.getPresentationBinding(verificationReq.Presentation)
+	if err != nil {
+		return err
+	}
+
+	if proofChallenge == "" {
+		return errors.New("presentation proof has no challenge")
+	}
+
+	if err = o.challengeStore.Check(proofChallenge, profileID, proofDomain); err != nil {
+		return err
+	}
+
+	if err = o.validatePresentationProof(verificationReq.Presentation, &VerifyPresentationOptions{
+		Challenge: proofChallenge, Domain: proofDomain,
+	}); err != nil {
+		return err
+	}
+
+	if err = o.challengeStore.Consume(proofChallenge, profileID, proofDomain); err != nil {
+		return err
+	}
+
+	if verificationReq.Opts == nil {
+		verificationReq.Opts = &VerifyPresentationOptions{}
+	}
+
+	if verificationReq.Opts.Challenge == "" {
+		verificationReq.Opts.Challenge = proofChallenge
+		verificationReq.Opts.Domain = proofDomain
+	}
+
+	return nil
+}
+
+// getPresentationBinding returns the challenge and domain of the presentation proof, which are the nonce and
+// audience of a VP-JWT.
+func (o *Operation) getPresentationBinding(vpBytes []byte) (string, string, error) {
+	if s, ok := getJWT(vpBytes); ok {
+		token, err := o.verifyJWT(s, crypto.Authentication)
+		if err != nil {
+			return "", "", err
+		}
+
+		var audience string
+		if len(token.claims.Audience) != 0 {
+			audience = token.claims.Audience[0]
+		}
+
+		return token.claims.Nonce, audience, nil
+	}
+
+	vp, err := o.parsePresentation(vpBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse presentation: %w", err)
+	}
+
+	if len(vp.Proofs) == 0 {
+		return "", "", errors.New("presentation has no proof")
+	}
+
+	proofChallenge, _ := vp.Proofs[0][challenge].(string) // nolint: errcheck
+	proofDomain, _ := vp.Proofs[0][domain].(string)       // nolint: errcheck
+
+	return proofChallenge, proofDomain, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	ariesmockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestIssueChallenge(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: mem.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+		ChallengeTTL:  time.Hour,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{ID: testProfileID, Name: "test"}))
+
+	handler := getHandler(t, op, challengeEndpoint, http.MethodPost)
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+
+	t.Run("issue challenge - success", func(t *testing.T) {
+		reqBytes, err := json.Marshal(&ChallengeRequest{Domain: domain})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, challengeEndpoint, reqBytes, urlVars)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		resp := &ChallengeResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.NotEmpty(t, resp.Challenge)
+		require.Equal(t, domain, resp.Domain)
+		require.True(t, resp.ExpiresAt.After(time.Now().Add(time.Hour-time.Minute)))
+
+		require.NoError(t, op.challengeStore.Consume(resp.Challenge, testProfileID, domain))
+	})
+
+	t.Run("issue challenge - invalid profile", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, challengeEndpoint, []byte("{}"), map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid verifier profile")
+	})
+
+	t.Run("issue challenge - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, challengeEndpoint, []byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+
+	t.Run("issue challenge - store error", func(t *testing.T) {
+		op, err := New(&Config{
+			StoreProvider: &ariesmockstorage.MockStoreProvider{Store: &ariesmockstorage.MockStore{
+				Store: make(map[string]ariesmockstorage.DBEntry),
+			}},
+			VDRI: &vdrmock.MockVDRegistry{},
+		})
+		require.NoError(t, err)
+		require.Equal(t, defaultChallengeTTL, op.challengeTTL)
+
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{ID: testProfileID, Name: "test"}))
+
+		op.challengeStore, err = verifier.NewChallengeStore(&ariesmockstorage.MockStoreProvider{
+			Store: &ariesmockstorage.MockStore{
+				Store:  make(map[string]ariesmockstorage.DBEntry),
+				ErrPut: errors.New("put error"),
+			},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, challengeEndpoint, http.MethodPost), challengeEndpoint, []byte("{}"),
+			urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to save challenge: put error")
+	})
+}
+
+func TestVerifyPresentationIssuedChallenge(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  mem.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: testProfileID, Name: "test", PresentationChecks: []string{proofCheck}, RequireIssuedChallenge: true,
+	}))
+
+	handler := getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost)
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+
+	verify := func(t *testing.T, vp []byte) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{Presentation: vp})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, presentationsVerificationEndpoint, reqBytes, urlVars)
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("issued challenge - used once", func(t *testing.T) {
+		issued, err := op.challengeStore.Issue(testProfileID, domain, time.Minute)
+		require.NoError(t, err)
+
+		vp := getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+			domain, issued.Value)
+
+		code, body := verify(t, vp)
+		require.Equal(t, http.StatusOK, code, body)
+
+		// the presentation is replayed
+		code, body = verify(t, vp)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, challengeCheck, resp.Checks[0].Check)
+		require.Equal(t, verifier.ErrChallengeNotFound.Error(), resp.Checks[0].Error)
+	})
+
+	t.Run("issued challenge - not used up by presentation with invalid proof", func(t *testing.T) {
+		issued, err := op.challengeStore.Issue(testProfileID, domain, time.Minute)
+		require.NoError(t, err)
+
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		code, body := verify(t, getSignedVP(t, otherKey, prCardVC, didID, verificationMethod, didID,
+			verificationMethod, domain, issued.Value))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "verifiable presentation proof validation error")
+
+		// the holder can still use the challenge
+		code, body = verify(t, getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID,
+			verificationMethod, domain, issued.Value))
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("issued challenge - other domain", func(t *testing.T) {
+		issued, err := op.challengeStore.Issue(testProfileID, "other.com", time.Minute)
+		require.NoError(t, err)
+
+		code, body := verify(t, getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID,
+			verificationMethod, domain, issued.Value))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "challenge was issued for domain other.com")
+	})
+
+	t.Run("issued challenge - not issued", func(t *testing.T) {
+		code, body := verify(t, getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID,
+			verificationMethod, domain, challenge))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, verifier.ErrChallengeNotFound.Error())
+	})
+
+	t.Run("issued challenge - no challenge", func(t *testing.T) {
+		code, body := verify(t, getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID,
+			verificationMethod, domain, ""))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "presentation proof has no challenge")
+	})
+
+	t.Run("issued challenge - no proof", func(t *testing.T) {
+		vp, err := verifiable.NewPresentation()
+		require.NoError(t, err)
+
+		vpBytes, err := vp.MarshalJSON()
+		require.NoError(t, err)
+
+		code, body := verify(t, vpBytes)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "presentation has no proof")
+	})
+
+	t.Run("issued challenge - invalid presentation", func(t *testing.T) {
+		code, body := verify(t, []byte(`{}`))
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "failed to parse presentation")
+	})
+}
@@ -0,0 +1,217 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
+)
+
+// jwtClaims are the claims of a VC-JWT or VP-JWT checked by the verifier.
+type jwtClaims struct {
+	jwt.Claims
+
+	Nonce string `json:"nonce,omitempty"`
+	// VP is the presentation of a VP-JWT, with its credentials as they were issued
+	VP *struct {
+		Credential interface{} `json:"verifiableCredential,omitempty"`
+	} `json:"vp,omitempty"`
+}
+
+// verifiedJWT is a JWT which signature is verified, with the key it is signed with.
+type verifiedJWT struct {
+	keyID  string
+	alg    string
+	claims *jwtClaims
+}
+
+// jwtCredential is a verified VC-JWT with the credential of its vc claim.
+type jwtCredential struct {
+	token *verifiedJWT
+	vc    *verifiable.Credential
+}
+
+// getJWT returns the compact JWT the credential or presentation of a request is serialized as, if any.
+func getJWT(raw json.RawMessage) (string, bool) {
+	var s string
+
+	if err := json.Unmarshal(raw, &s); err != nil || !isJWT(s) {
+		return "", false
+	}
+
+	return s, true
+}
+
+func isJWT(s string) bool {
+	return jwt.IsJWS(s) && !sdjwt.IsSDJWT(s)
+}
+
+// verifyJWT verifies the signature of the JWT with the key of its kid, authorized for the proof purpose.
+func (o *Operation) verifyJWT(s, purpose string) (*verifiedJWT, error) {
+	jws, err := jose.ParseJWS(s, crypto.NewJWTVerifier(o.vdr, purpose))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify jwt: %w", err)
+	}
+
+	claims := &jwtClaims{}
+	if err = json.Unmarshal(jws.Payload, claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal jwt claims: %w", err)
+	}
+
+	keyID, _ := jws.ProtectedHeaders.KeyID()   // nolint: errcheck
+	alg, _ := jws.ProtectedHeaders.Algorithm() // nolint: errcheck
+
+	return &verifiedJWT{keyID: keyID, alg: alg, claims: claims}, nil
+}
+
+// parseAndVerifyJWTVC verifies the issuer signature of the VC-JWT, returning the credential of its vc claim.
+func (o *Operation) parseAndVerifyJWTVC(s string) (*jwtCredential, error) {
+	token, err := o.verifyJWT(s, crypto.AssertionMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	vc, err := verifiable.ParseCredential([]byte(s), verifiable.WithDisabledProofCheck(),
+		verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwtCredential{token: token, vc: vc}, nil
+}
+
+// validateJWTCredentialProof checks the issuer and the registered claims of the VC-JWT, like the proof of
+// a JSON-LD credential. The challenge and domain aren't checked for the credentials of a presentation.
+func (o *Operation) validateJWTCredentialProof(cred *jwtCredential, opts *CredentialsVerificationOptions,
+	vcInVPValidation bool) error {
+	if opts == nil {
+		opts = &CredentialsVerificationOptions{}
+	}
+
+	if err := validateJWTSigner(cred.token, cred.vc.Issuer.ID, "issuer"); err != nil {
+		return err
+	}
+
+	if err := o.validateJWTValidity(cred.token.claims, getValidAt(opts)); err != nil {
+		return err
+	}
+
+	if vcInVPValidation {
+		return nil
+	}
+
+	return validateJWTBinding(cred.token.claims, opts.Challenge, opts.Domain)
+}
+
+// validateJWTPresentationProof verifies the holder signature and the registered claims of the VP-JWT, along with
+// the credentials of the presentation.
+func (o *Operation) validateJWTPresentationProof(s string, opts *VerifyPresentationOptions) error {
+	if opts == nil {
+		opts = &VerifyPresentationOptions{}
+	}
+
+	token, err := o.verifyJWT(s, crypto.Authentication)
+	if err != nil {
+		return fmt.Errorf("verifiable presentation proof validation error : %w", err)
+	}
+
+	vp, err := o.parsePresentation([]byte(s))
+	if err != nil {
+		return fmt.Errorf("verifiable presentation proof validation error : %w", err)
+	}
+
+	// the parsed presentation has the credentials decoded from their JWT, without the signature to verify
+	for _, cred := range token.claims.presentationCredentials() {
+		vcBytes, errMarshal := credentialBytes(cred)
+		if errMarshal != nil {
+			return errMarshal
+		}
+
+		if err = o.validateCredentialProof(vcBytes, nil, true); err != nil {
+			return fmt.Errorf("verifiable presentation proof validation error : %w", err)
+		}
+	}
+
+	if vp.Holder != "" {
+		if err = validateJWTSigner(token, vp.Holder, "holder"); err != nil {
+			return err
+		}
+	}
+
+	if err = o.validateJWTValidity(token.claims, nil); err != nil {
+		return err
+	}
+
+	return validateJWTBinding(token.claims, opts.Challenge, opts.Domain)
+}
+
+// validateJWTSigner checks that the key the JWT is signed with is controlled by the issuer or holder.
+func validateJWTSigner(token *verifiedJWT, signerDID, role string) error {
+	keyDID, err := diddoc.GetDIDFromVerificationMethod(token.keyID)
+	if err != nil {
+		return err
+	}
+
+	if signerDID != keyDID {
+		return fmt.Errorf("controller of verification method doesn't match the %s", role)
+	}
+
+	return nil
+}
+
+// validateJWTValidity checks the exp and nbf claims at the given time, now by default, with the clock skew.
+func (o *Operation) validateJWTValidity(claims *jwtClaims, validAt *time.Time) error {
+	now := time.Now()
+	if validAt != nil {
+		now = *validAt
+	}
+
+	if claims.Expiry != nil && claims.Expiry.Time().Before(now.Add(-o.clockSkew)) {
+		return fmt.Errorf("jwt expired at %s", claims.Expiry.Time().UTC().Format(time.RFC3339))
+	}
+
+	if claims.NotBefore != nil && claims.NotBefore.Time().After(now.Add(o.clockSkew)) {
+		return fmt.Errorf("jwt not valid before %s", claims.NotBefore.Time().UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// validateJWTBinding checks the nonce and aud claims against the expected challenge and domain.
+func validateJWTBinding(claims *jwtClaims, expectedChallenge, expectedDomain string) error {
+	if claims.Nonce != expectedChallenge {
+		return fmt.Errorf("invalid %s in the jwt : expected=%s actual=%s", challenge, expectedChallenge, claims.Nonce)
+	}
+
+	if expectedDomain == "" && len(claims.Audience) == 0 {
+		return nil
+	}
+
+	if !claims.Audience.Contains(expectedDomain) {
+		return fmt.Errorf("invalid %s in the jwt : expected=%s actual=%v", domain, expectedDomain, claims.Audience)
+	}
+
+	return nil
+}
+
+// presentationCredentials returns the credentials of the vp claim.
+func (c *jwtClaims) presentationCredentials() []interface{} {
+	if c.VP == nil {
+		return nil
+	}
+
+	return credentialList(c.VP.Credential)
+}
@@ -0,0 +1,332 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestVerifyJWT(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	issuerDID := "did:test:issuer"
+	holderDID := "did:test:holder"
+
+	issuerPubKey, issuerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	holderPubKey, holderPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didDocs := map[string]*did.Doc{
+		issuerDID: createDIDDoc(issuerDID, issuerPubKey),
+		holderDID: createDIDDoc(holderDID, holderPubKey),
+	}
+
+	op, err := New(&Config{
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, _ ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				didDoc, ok := didDocs[didID]
+				if !ok {
+					return nil, fmt.Errorf("did %s not found", didID)
+				}
+
+				return &did.DocResolution{DIDDocument: didDoc}, nil
+			},
+		},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: testProfileID, Name: "test",
+		CredentialChecks:   []string{proofCheck, validityCheck, issuerTrustCheck},
+		PresentationChecks: []string{proofCheck, issuerTrustCheck},
+		IssuerTrust:        &verifier.IssuerTrustPolicy{DIDMethods: []string{"test"}},
+	}))
+
+	vc, err := verifiable.ParseCredential([]byte(prCardVC), verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	vc.Issuer.ID = issuerDID
+	vc.Status = nil
+
+	issuerKeyID := issuerDID + "#key-1"
+	holderKeyID := holderDID + "#key-1"
+
+	verify := func(t *testing.T, endpoint string, req interface{}) (int, string) {
+		t.Helper()
+
+		reqBytes, errMarshal := json.Marshal(req)
+		require.NoError(t, errMarshal)
+
+		rr := serveHTTPMux(t, getHandler(t, op, endpoint, http.MethodPost), endpoint, reqBytes,
+			map[string]string{profileIDPathParam: testProfileID})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	verifyVC := func(t *testing.T, vcJWT string, opts *CredentialsVerificationOptions) (int, string) {
+		t.Helper()
+
+		return verify(t, credentialsVerificationEndpoint, &CredentialsVerificationRequest{
+			Credential: jwtMessage(t, vcJWT),
+			Opts:       opts,
+		})
+	}
+
+	verifyVP := func(t *testing.T, vpJWT string, opts *VerifyPresentationOptions) (int, string) {
+		t.Helper()
+
+		return verify(t, presentationsVerificationEndpoint, &VerifyPresentationRequest{
+			Presentation: jwtMessage(t, vpJWT),
+			Opts:         opts,
+		})
+	}
+
+	vcJWT := createVCJWT(t, vc, issuerPrivKey, issuerKeyID, nil)
+
+	t.Run("vc-jwt verification - success", func(t *testing.T) {
+		code, body := verifyVC(t, vcJWT, nil)
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, []string{proofCheck, validityCheck, issuerTrustCheck}, resp.Checks)
+	})
+
+	t.Run("vc-jwt verification - report", func(t *testing.T) {
+		code, body := verifyVC(t, vcJWT, &CredentialsVerificationOptions{Checks: []string{proofCheck}, Report: true})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, jwtVCFormat, resp.Report.Credentials[0].Format)
+		require.Equal(t, "EdDSA", resp.Report.Credentials[0].ProofType)
+		require.Equal(t, issuerKeyID, resp.Report.Credentials[0].VerificationMethod)
+		require.Equal(t, issuerDID, resp.Report.Credentials[0].Issuer)
+	})
+
+	t.Run("vc-jwt verification - challenge and domain", func(t *testing.T) {
+		bound := createVCJWT(t, vc, issuerPrivKey, issuerKeyID, map[string]interface{}{
+			"nonce": challenge, "aud": []string{domain},
+		})
+
+		opts := &CredentialsVerificationOptions{Checks: []string{proofCheck}, Challenge: challenge, Domain: domain}
+
+		code, body := verifyVC(t, bound, opts)
+		require.Equal(t, http.StatusOK, code, body)
+
+		opts.Challenge = "other-challenge"
+
+		code, body = verifyVC(t, bound, opts)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid challenge in the jwt : expected=other-challenge actual="+challenge)
+
+		opts.Challenge = challenge
+		opts.Domain = "other-domain"
+
+		code, body = verifyVC(t, bound, opts)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid domain in the jwt : expected=other-domain")
+
+		code, body = verifyVC(t, vcJWT, &CredentialsVerificationOptions{Checks: []string{proofCheck}, Domain: domain})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid domain in the jwt")
+	})
+
+	t.Run("vc-jwt verification - expired", func(t *testing.T) {
+		expired := createVCJWT(t, vc, issuerPrivKey, issuerKeyID, map[string]interface{}{
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})
+
+		code, body := verifyVC(t, expired, &CredentialsVerificationOptions{Checks: []string{proofCheck}})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt expired at")
+
+		// valid at a time before it expired
+		validAt := time.Now().Add(-2 * time.Hour)
+
+		code, body = verifyVC(t, expired, &CredentialsVerificationOptions{
+			Checks: []string{proofCheck}, ValidAt: &validAt,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("vc-jwt verification - not valid yet", func(t *testing.T) {
+		notYetValid := createVCJWT(t, vc, issuerPrivKey, issuerKeyID, map[string]interface{}{
+			"nbf": time.Now().Add(time.Hour).Unix(),
+		})
+
+		code, body := verifyVC(t, notYetValid, &CredentialsVerificationOptions{Checks: []string{proofCheck}})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt not valid before")
+	})
+
+	t.Run("vc-jwt verification - invalid signature", func(t *testing.T) {
+		code, body := verifyVC(t, createVCJWT(t, vc, holderPrivKey, issuerKeyID, nil), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt signature doesn't match")
+	})
+
+	t.Run("vc-jwt verification - issuer doesn't match the signing key", func(t *testing.T) {
+		code, body := verifyVC(t, createVCJWT(t, vc, holderPrivKey, holderKeyID, nil), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "controller of verification method doesn't match the issuer")
+	})
+
+	t.Run("vp-jwt verification - success", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, holderPrivKey, holderKeyID, challenge, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: domain})
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("vp-jwt verification - report", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, holderPrivKey, holderKeyID, challenge, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: domain, Report: true})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &VerifyPresentationSuccessResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Report.Credentials, 1)
+		require.Equal(t, jwtVCFormat, resp.Report.Credentials[0].Format)
+		require.Equal(t, issuerKeyID, resp.Report.Credentials[0].VerificationMethod)
+		require.Len(t, resp.Report.Credentials[0].Checks, 2)
+		require.True(t, resp.Report.Credentials[0].Checks[0].Passed)
+	})
+
+	t.Run("vp-jwt verification - invalid challenge and domain", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, holderPrivKey, holderKeyID, challenge, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: "other-challenge", Domain: domain})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid challenge in the jwt")
+
+		code, body = verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: "other-domain"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid domain in the jwt")
+	})
+
+	t.Run("vp-jwt verification - holder doesn't match the signing key", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, issuerPrivKey, issuerKeyID, challenge, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: domain})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "controller of verification method doesn't match the holder")
+	})
+
+	t.Run("vp-jwt verification - invalid credential", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, holderPrivKey, holderKeyID, challenge, domain,
+			createVCJWT(t, vc, holderPrivKey, issuerKeyID, nil))
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: domain})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt signature doesn't match")
+	})
+
+	t.Run("vp-jwt verification - invalid signature", func(t *testing.T) {
+		vpJWT := createVPJWT(t, holderDID, issuerPrivKey, holderKeyID, challenge, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, &VerifyPresentationOptions{Challenge: challenge, Domain: domain})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt signature doesn't match")
+	})
+
+	t.Run("vp-jwt verification - issued challenge", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+			ID: testProfileID, Name: "test", PresentationChecks: []string{proofCheck}, RequireIssuedChallenge: true,
+		}))
+
+		issued, err := op.challengeStore.Issue(testProfileID, domain, time.Minute)
+		require.NoError(t, err)
+
+		vpJWT := createVPJWT(t, holderDID, holderPrivKey, holderKeyID, issued.Value, domain, vcJWT)
+
+		code, body := verifyVP(t, vpJWT, nil)
+		require.Equal(t, http.StatusOK, code, body)
+
+		// the presentation is replayed
+		code, body = verifyVP(t, vpJWT, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, verifier.ErrChallengeNotFound.Error())
+	})
+}
+
+// createVCJWT signs the vc as VC-JWT with the given ed25519 key, with additional claims.
+func createVCJWT(t *testing.T, vc *verifiable.Credential, privKey ed25519.PrivateKey, keyID string,
+	extraClaims map[string]interface{}) string {
+	t.Helper()
+
+	vcClaims, err := vc.JWTClaims(false)
+	require.NoError(t, err)
+
+	return signTestJWT(t, privKey, keyID, jwt.TypeJWT, withClaims(t, vcClaims, extraClaims))
+}
+
+// createVPJWT signs a presentation of the given VC-JWTs as VP-JWT of the holder.
+func createVPJWT(t *testing.T, holderDID string, privKey ed25519.PrivateKey, keyID, nonce, audience string,
+	vcJWTs ...string) string {
+	t.Helper()
+
+	vp, err := verifiable.NewPresentation(verifiable.WithJWTCredentials(vcJWTs...))
+	require.NoError(t, err)
+
+	vp.Holder = holderDID
+
+	vpClaims, err := vp.JWTClaims([]string{audience}, false)
+	require.NoError(t, err)
+
+	return signTestJWT(t, privKey, keyID, jwt.TypeJWT, withClaims(t, vpClaims, map[string]interface{}{
+		"nonce": nonce,
+	}))
+}
+
+func withClaims(t *testing.T, claims interface{}, extraClaims map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	claimsBytes, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	merged := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(claimsBytes, &merged))
+
+	for k, v := range extraClaims {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+func jwtMessage(t *testing.T, s string) json.RawMessage {
+	t.Helper()
+
+	raw, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	return raw
+}
@@ -6,7 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 
 package operation
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+)
 
 // CredentialsVerificationRequest request for verifying credential.
 type CredentialsVerificationRequest struct {
@@ -19,16 +25,22 @@ type CredentialsVerificationOptions struct {
 	Domain    string   `json:"domain,omitempty"`
 	Challenge string   `json:"challenge,omitempty"`
 	Checks    []string `json:"checks,omitempty"`
+	// ValidAt checks the credential status and validity period as they were at the given time
+	ValidAt *time.Time `json:"validAt,omitempty"`
+	// Report returns a detailed report of the verification along with the check results
+	Report bool `json:"report,omitempty"`
 }
 
 // CredentialsVerificationSuccessResponse resp when credential verification is success.
 type CredentialsVerificationSuccessResponse struct {
-	Checks []string `json:"checks,omitempty"`
+	Checks []string            `json:"checks,omitempty"`
+	Report *VerificationReport `json:"report,omitempty"`
 }
 
 // CredentialsVerificationFailResponse resp when credential verification is failed.
 type CredentialsVerificationFailResponse struct {
 	Checks []CredentialsVerificationCheckResult `json:"checks,omitempty"`
+	Report *VerificationReport                  `json:"report,omitempty"`
 }
 
 // CredentialsVerificationCheckResult resp containing failure check details.
@@ -49,16 +61,24 @@ type VerifyPresentationOptions struct {
 	Domain    string   `json:"domain,omitempty"`
 	Challenge string   `json:"challenge,omitempty"`
 	Checks    []string `json:"checks,omitempty"`
+	// Report returns a detailed report of the verification along with the check results
+	Report bool `json:"report,omitempty"`
 }
 
 // VerifyPresentationSuccessResponse resp when presentation verification is success.
 type VerifyPresentationSuccessResponse struct {
 	Checks []string `json:"checks,omitempty"`
+	// PresentationSubmission tells which credentials matched the input descriptors of the presentation definition
+	PresentationSubmission *presexch.Evaluation `json:"presentationSubmission,omitempty"`
+	Report                 *VerificationReport  `json:"report,omitempty"`
 }
 
 // VerifyPresentationFailureResponse resp when presentation verification is failed.
 type VerifyPresentationFailureResponse struct {
 	Checks []VerifyPresentationCheckResult `json:"checks,omitempty"`
+	// PresentationSubmission tells which input descriptors of the presentation definition matched, and why not
+	PresentationSubmission *presexch.Evaluation `json:"presentationSubmission,omitempty"`
+	Report                 *VerificationReport  `json:"report,omitempty"`
 }
 
 // VerifyPresentationCheckResult resp containing failure check details.
@@ -68,8 +88,112 @@ type VerifyPresentationCheckResult struct {
 	VerificationMethod string `json:"verificationMethod,omitempty"`
 }
 
+// VerificationReport detailed report of a verification, for the presentation and each of its credentials.
+type VerificationReport struct {
+	VerifiedAt time.Time     `json:"verifiedAt"`
+	Duration   time.Duration `json:"durationNs"`
+	// Checks of the presentation, the checks of a credential are in its report
+	Checks      []*CheckReport      `json:"checks,omitempty"`
+	Credentials []*CredentialReport `json:"credentials,omitempty"`
+	// ErrorCode and Error are set when the credentials of the presentation couldn't be reported
+	ErrorCode string `json:"errorCode,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckReport result of a verification check, with the code of the error it failed with.
+type CheckReport struct {
+	Check     string        `json:"check"`
+	Passed    bool          `json:"passed"`
+	ErrorCode string        `json:"errorCode,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"durationNs"`
+}
+
+// CredentialReport details of a verified credential and the results of its checks.
+type CredentialReport struct {
+	ID     string   `json:"id,omitempty"`
+	Types  []string `json:"types,omitempty"`
+	Issuer string   `json:"issuer,omitempty"`
+	// Format is ldp_vc, jwt_vc or vc+sd-jwt
+	Format string `json:"format,omitempty"`
+	// ProofType is the type of the linked data proof, or the JWS algorithm of the JWT formats
+	ProofType          string             `json:"proofType,omitempty"`
+	VerificationMethod string             `json:"verificationMethod,omitempty"`
+	DIDDocument        *DIDDocumentReport `json:"didDocument,omitempty"`
+	Status             *StatusReport      `json:"status,omitempty"`
+	Checks             []*CheckReport     `json:"checks,omitempty"`
+	// ErrorCode and Error are set when the credential of a presentation couldn't be parsed
+	ErrorCode string `json:"errorCode,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DIDDocumentReport version of the DID document the proof was verified against.
+type DIDDocumentReport struct {
+	ID          string     `json:"id,omitempty"`
+	CanonicalID string     `json:"canonicalId,omitempty"`
+	Updated     *time.Time `json:"updated,omitempty"`
+	// UpdateCommitment changes with each update of sidetree DIDs
+	UpdateCommitment string `json:"updateCommitment,omitempty"`
+	Deactivated      bool   `json:"deactivated,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// StatusReport status list entry of a credential.
+type StatusReport struct {
+	Type    string `json:"type"`
+	ListURL string `json:"listURL,omitempty"`
+	Index   *int   `json:"index,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
 // VerifyCredentialResponse describes verify credential response
 type VerifyCredentialResponse struct {
 	Verified bool   `json:"verified"`
 	Message  string `json:"message"`
 }
+
+// UpdateProfileRequest request for updating the mutable fields of a verifier profile.
+type UpdateProfileRequest struct {
+	Name               *string   `json:"name,omitempty"`
+	CredentialChecks   *[]string `json:"credentialChecks,omitempty"`
+	PresentationChecks *[]string `json:"presentationChecks,omitempty"`
+	// PresentationDefinitions replace all the presentation definitions of the profile
+	PresentationDefinitions *[]*presexch.PresentationDefinition `json:"presentationDefinitions,omitempty"`
+	RequireIssuedChallenge  *bool                               `json:"requireIssuedChallenge,omitempty"`
+	IssuerTrust             *verifier.IssuerTrustPolicy         `json:"issuerTrust,omitempty"`
+}
+
+// PresentationRequest request for a presentation definition of the profile, the only one if the id isn't set.
+type PresentationRequest struct {
+	DefinitionID string `json:"definitionID,omitempty"`
+	// Domain the presentation proof has to be bound to along with the challenge
+	Domain string `json:"domain,omitempty"`
+}
+
+// PresentationRequestResponse the presentation definition to submit, with the challenge the presentation has to prove.
+type PresentationRequestResponse struct {
+	Challenge              string                           `json:"challenge"`
+	Domain                 string                           `json:"domain,omitempty"`
+	ExpiresAt              time.Time                        `json:"expiresAt"`
+	PresentationDefinition *presexch.PresentationDefinition `json:"presentationDefinition"`
+}
+
+// ChallengeRequest request for a challenge to prove in a presentation verified with the profile.
+type ChallengeRequest struct {
+	Domain string `json:"domain,omitempty"`
+}
+
+// ChallengeResponse the challenge issued, which can be used once until it expires.
+type ChallengeResponse struct {
+	Challenge string    `json:"challenge"`
+	Domain    string    `json:"domain,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ListProfilesResponse page of verifier profiles.
+type ListProfilesResponse struct {
+	Profiles []*verifier.ProfileData `json:"profiles"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"pageSize"`
+	Total    int                     `json:"total"`
+}
@@ -38,6 +38,43 @@ type getProfileReq struct { // nolint: unused,deadcode
 	ID string `json:"id"`
 }
 
+// listProfilesReq model
+//
+// swagger:parameters listProfilesReq
+type listProfilesReq struct { // nolint: unused,deadcode
+	// Page number, from 0
+	//
+	// in: query
+	Page int `json:"page"`
+
+	// PageSize, 20 if not set and 100 at most
+	//
+	// in: query
+	PageSize int `json:"pageSize"`
+}
+
+// listProfilesRes model
+//
+// swagger:response listProfilesRes
+type listProfilesRes struct { // nolint: unused,deadcode
+	// in: body
+	Body ListProfilesResponse
+}
+
+// updateProfileReq model
+//
+// swagger:parameters updateProfileReq
+type updateProfileReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params UpdateProfileRequest
+}
+
 // deleteProfileReq model
 //
 // swagger:parameters deleteProfileReq
@@ -106,7 +143,51 @@ type verifyPresentationSuccessResp struct { // nolint: unused,deadcode
 // swagger:response verifyPresentationFailureResp
 type verifyPresentationFailureResp struct { // nolint: unused,deadcode
 	// in: body
-	Checks []*VerifyPresentationCheckResult `json:"checks,omitempty"`
+	VerifyPresentationFailureResponse
+}
+
+// presentationRequestReq model
+//
+// swagger:parameters presentationRequestReq
+type presentationRequestReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params PresentationRequest
+}
+
+// presentationRequestRes model
+//
+// swagger:response presentationRequestRes
+type presentationRequestRes struct { // nolint: unused,deadcode
+	// in: body
+	PresentationRequestResponse
+}
+
+// challengeReq model
+//
+// swagger:parameters challengeReq
+type challengeReq struct { // nolint: unused,deadcode
+	// profile
+	//
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	Params ChallengeRequest
+}
+
+// challengeRes model
+//
+// swagger:response challengeRes
+type challengeRes struct { // nolint: unused,deadcode
+	// in: body
+	ChallengeResponse
 }
 
 // emptyRes model
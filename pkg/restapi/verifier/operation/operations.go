@@ -11,9 +11,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	jsonldcontextrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/jsonld/context"
@@ -26,10 +29,12 @@ import (
 
 	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
 	"github.com/trustbloc/edge-service/pkg/internal/common/support"
 	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
+	"github.com/trustbloc/edge-service/pkg/lock"
 	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
 )
 
@@ -40,17 +45,32 @@ const (
 	verifierBasePath                  = "/verifier"
 	profileEndpoint                   = verifierBasePath + "/profile"
 	getProfileEndpoint                = profileEndpoint + "/" + "{" + profileIDPathParam + "}"
+	updateProfileEndpoint             = profileEndpoint + "/" + "{" + profileIDPathParam + "}"
 	deleteProfileEndpoint             = profileEndpoint + "/" + "{" + profileIDPathParam + "}"
 	credentialsVerificationEndpoint   = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/credentials/verify"
 	presentationsVerificationEndpoint = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/presentations/verify"
+	presentationRequestEndpoint       = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/presentations/request"
+	challengeEndpoint                 = "/" + "{" + profileIDPathParam + "}" + verifierBasePath + "/challenges"
 
 	invalidRequestErrMsg = "Invalid request"
 
-	successMsg = "success"
+	successMsg   = "success"
+	revokedMsg   = "Revoked"
+	suspendedMsg = "Suspended"
 
 	// credential verification checks
-	proofCheck  = "proof"
-	statusCheck = "credentialStatus"
+	proofCheck    = "proof"
+	statusCheck   = "credentialStatus"
+	schemaCheck   = "schema"
+	validityCheck = "validity"
+	// issuerTrustCheck is also a presentation check, of the issuers of all the credentials
+	issuerTrustCheck = "issuerTrust"
+
+	// presentation verification checks
+	presentationDefinitionCheck = "presentationDefinition"
+	challengeCheck              = "challenge"
+
+	defaultChallengeTTL = 5 * time.Minute
 
 	// proof data keys
 	challenge          = "challenge"
@@ -59,6 +79,12 @@ const (
 	verificationMethod = "verificationMethod"
 
 	cslRequestTokenName = "csl"
+
+	validAtQueryParam = "validAt"
+
+	// maxStatusListVCSize bounds the status list vcs read, the largest bit string DecodeBits accepts fits in it
+	// unless barely compressible
+	maxStatusListVCSize = 32 * 1024 * 1024
 )
 
 var logger = log.New("edge-service-verifier-restapi")
@@ -81,18 +107,51 @@ func New(config *Config) (*Operation, error) {
 		return nil, err
 	}
 
+	var challengeOpts []verifier.ChallengeStoreOpt
+	if config.ChallengeLocker != nil {
+		challengeOpts = append(challengeOpts, verifier.WithChallengeLocker(config.ChallengeLocker))
+	}
+
+	challengeStore, err := verifier.NewChallengeStore(config.StoreProvider, challengeOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeTTL := config.ChallengeTTL
+	if challengeTTL == 0 {
+		challengeTTL = defaultChallengeTTL
+	}
+
 	contextOp, err := jsonldcontextrest.New(&storeProvider{config.StoreProvider})
 	if err != nil {
 		return nil, fmt.Errorf("create jsonld context operation: %w", err)
 	}
 
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: config.TLSConfig}}
+
+	schemaLoader := config.SchemaLoader
+	if schemaLoader == nil {
+		schemaLoader = schema.NewHTTPLoader(httpClient)
+	}
+
+	profileLocker := config.ProfileLocker
+	if profileLocker == nil {
+		profileLocker = lock.NewMemLocker()
+	}
+
 	svc := &Operation{
 		profileStore:            p,
+		profileLocker:           profileLocker,
 		vdr:                     config.VDRI,
-		httpClient:              &http.Client{Transport: &http.Transport{TLSClientConfig: config.TLSConfig}},
+		httpClient:              httpClient,
 		requestTokens:           config.RequestTokens,
 		documentLoader:          config.DocumentLoader,
 		addJSONLDContextHandler: contextOp.Add,
+		statusListCache:         newStatusListCache(config.StatusCacheTTL, config.StatusCacheSize),
+		schemaValidator:         schema.NewValidator(schemaLoader),
+		clockSkew:               config.ClockSkew,
+		challengeStore:          challengeStore,
+		challengeTTL:            challengeTTL,
 	}
 
 	return svc, nil
@@ -105,16 +164,35 @@ type Config struct {
 	TLSConfig      *tls.Config
 	RequestTokens  map[string]string
 	DocumentLoader ld.DocumentLoader
+	StatusCacheTTL time.Duration
+	// StatusCacheSize is the maximum number of status lists cached, 1000 if not set
+	StatusCacheSize int
+	// ClockSkew is the difference tolerated between the clocks of issuers and the verifier by the validity check
+	ClockSkew time.Duration
+	// SchemaLoader loads the credential schemas of the schema check, fetched over HTTP if not set
+	SchemaLoader schema.Loader
+	// ChallengeTTL is the time the challenges issued by the verifier can be used for, 5 minutes if not set
+	ChallengeTTL time.Duration
+	// ChallengeLocker locks a challenge while it is consumed across the instances, in-process lock if not set
+	ChallengeLocker verifier.Locker
+	// ProfileLocker guards updates of the profiles shared with other instances, in-memory locker if not set
+	ProfileLocker lock.Locker
 }
 
 // Operation defines handlers for Edge service
 type Operation struct {
 	profileStore            *verifier.Profile
+	profileLocker           lock.Locker
 	vdr                     vdrapi.Registry
 	httpClient              httpClient
 	requestTokens           map[string]string
 	documentLoader          ld.DocumentLoader
 	addJSONLDContextHandler http.HandlerFunc
+	statusListCache         *statusListCache
+	schemaValidator         *schema.Validator
+	clockSkew               time.Duration
+	challengeStore          *verifier.ChallengeStore
+	challengeTTL            time.Duration
 }
 
 // GetRESTHandlers get all controller API handler available for this service
@@ -122,12 +200,17 @@ func (o *Operation) GetRESTHandlers() []Handler {
 	return []Handler{
 		// profile
 		support.NewHTTPHandler(profileEndpoint, http.MethodPost, o.createProfileHandler),
+		support.NewHTTPHandler(profileEndpoint, http.MethodGet, o.listProfilesHandler),
 		support.NewHTTPHandler(getProfileEndpoint, http.MethodGet, o.getProfileHandler),
+		support.NewHTTPHandler(updateProfileEndpoint, http.MethodPut, o.updateProfileHandler),
+		support.NewHTTPHandler(updateProfileEndpoint, http.MethodPatch, o.patchProfileHandler),
 		support.NewHTTPHandler(deleteProfileEndpoint, http.MethodDelete, o.deleteProfileHandler),
 
 		// verification
 		support.NewHTTPHandler(credentialsVerificationEndpoint, http.MethodPost, o.verifyCredentialHandler),
 		support.NewHTTPHandler(presentationsVerificationEndpoint, http.MethodPost, o.verifyPresentationHandler),
+		support.NewHTTPHandler(presentationRequestEndpoint, http.MethodPost, o.presentationRequestHandler),
+		support.NewHTTPHandler(challengeEndpoint, http.MethodPost, o.issueChallengeHandler),
 
 		// JSON-LD context API
 		support.NewHTTPHandler(jsonldcontextrest.AddContextPath, http.MethodPost, o.addJSONLDContextHandler),
@@ -250,61 +333,57 @@ func (o *Operation) verifyCredentialHandler(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	vc, err := o.parseAndVerifyVC(verificationReq.Credential)
+	op, resolutions := o.withResolutionRecorder()
+
+	cred, err := op.parseRequestCredential(verificationReq.Credential)
 	if err != nil {
 		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
 
 		return
 	}
 
+	start := time.Now()
 	checks := getCredentialChecks(profile, verificationReq.Opts)
 
 	var result []CredentialsVerificationCheckResult
 
+	var checkReports []*CheckReport
+
 	for _, val := range checks {
-		switch val {
-		case proofCheck:
-			err := o.validateCredentialProof(verificationReq.Credential, verificationReq.Opts, false)
-			if err != nil {
-				result = append(result, CredentialsVerificationCheckResult{
-					Check: val,
-					Error: err.Error(),
-				})
-			}
-		case statusCheck:
-			failureMessage := ""
+		checkStart := time.Now()
 
-			ver, err := o.checkVCStatus(vc.Status, vc.Issuer.ID)
+		err := op.checkCredential(val, cred, profile, verificationReq.Opts, false)
 
-			if err != nil {
-				failureMessage = fmt.Sprintf("failed to fetch the status : %s", err.Error())
-			} else if !ver.Verified {
-				failureMessage = ver.Message
-			}
+		checkReports = append(checkReports, newCheckReport(val, err, checkStart))
 
-			if failureMessage != "" {
-				result = append(result, CredentialsVerificationCheckResult{
-					Check: val,
-					Error: failureMessage,
-				})
-			}
-		default:
+		if err != nil {
 			result = append(result, CredentialsVerificationCheckResult{
 				Check: val,
-				Error: "check not supported",
+				Error: err.Error(),
 			})
 		}
 	}
 
+	var report *VerificationReport
+
+	if verificationReq.Opts != nil && verificationReq.Opts.Report {
+		credReport := newCredentialReport(cred, resolutions)
+		credReport.Checks = checkReports
+
+		report = newVerificationReport(start, nil, []*CredentialReport{credReport})
+	}
+
 	if len(result) == 0 {
 		rw.WriteHeader(http.StatusOK)
 		commhttp.WriteResponse(rw, &CredentialsVerificationSuccessResponse{
 			Checks: checks,
+			Report: report,
 		})
 	} else {
 		rw.WriteHeader(http.StatusBadRequest)
 		commhttp.WriteResponse(rw, &CredentialsVerificationFailResponse{
 			Checks: result,
+			Report: report,
 		})
 	}
 }
@@ -339,50 +418,203 @@ func (o *Operation) verifyPresentationHandler(rw http.ResponseWriter, req *http.
 		return
 	}
 
-	checks := getPresentationChecks(profile, verificationReq.Opts)
+	start := time.Now()
+
+	op, resolutions := o.withResolutionRecorder()
 
 	var result []VerifyPresentationCheckResult
 
+	var checkReports []*CheckReport
+
+	if profile.RequireIssuedChallenge {
+		checkStart := time.Now()
+
+		err = o.consumeChallenge(profile.ID, &verificationReq)
+
+		checkReports = append(checkReports, newCheckReport(challengeCheck, err, checkStart))
+
+		if err != nil {
+			result = append(result, VerifyPresentationCheckResult{
+				Check: challengeCheck,
+				Error: err.Error(),
+			})
+		}
+	}
+
+	checks := getPresentationChecks(profile, verificationReq.Opts)
+
 	for _, val := range checks {
-		switch val {
-		case proofCheck:
-			err := o.validatePresentationProof(verificationReq.Presentation, verificationReq.Opts)
-			if err != nil {
-				result = append(result, VerifyPresentationCheckResult{
-					Check: val,
-					Error: err.Error(),
-				})
-			}
-		case statusCheck:
-			_, err := o.parseAndVerifyVP(verificationReq.Presentation, false, false, true)
-			if err != nil {
-				result = append(result, VerifyPresentationCheckResult{
-					Check: val,
-					Error: err.Error(),
-				})
-			}
-		default:
+		checkStart := time.Now()
+
+		err := op.checkPresentation(val, profile, verificationReq.Presentation, verificationReq.Opts)
+
+		checkReports = append(checkReports, newCheckReport(val, err, checkStart))
+
+		if err != nil {
 			result = append(result, VerifyPresentationCheckResult{
 				Check: val,
-				Error: "check not supported",
+				Error: err.Error(),
 			})
 		}
 	}
 
+	checkStart := time.Now()
+
+	evaluation, err := o.evaluatePresentationSubmission(profile, verificationReq.Presentation)
+	if evaluation != nil || err != nil {
+		checkReports = append(checkReports, newCheckReport(presentationDefinitionCheck, err, checkStart))
+	}
+
+	if err != nil {
+		result = append(result, VerifyPresentationCheckResult{
+			Check: presentationDefinitionCheck,
+			Error: err.Error(),
+		})
+	}
+
+	var report *VerificationReport
+
+	if verificationReq.Opts != nil && verificationReq.Opts.Report {
+		credentialReports, errReport := op.presentationCredentialReports(verificationReq.Presentation, profile, checks,
+			resolutions)
+
+		report = newVerificationReport(start, checkReports, credentialReports)
+
+		if errReport != nil {
+			report.ErrorCode = errCodePresentationInvalid
+			report.Error = errReport.Error()
+		}
+	}
+
 	if len(result) == 0 {
 		rw.WriteHeader(http.StatusOK)
 		commhttp.WriteResponse(rw, &VerifyPresentationSuccessResponse{
-			Checks: checks,
+			Checks:                 checks,
+			PresentationSubmission: evaluation,
+			Report:                 report,
 		})
 	} else {
 		rw.WriteHeader(http.StatusBadRequest)
 		commhttp.WriteResponse(rw, &VerifyPresentationFailureResponse{
-			Checks: result,
+			Checks:                 result,
+			PresentationSubmission: evaluation,
+			Report:                 report,
 		})
 	}
 }
 
+// requestCredential is the credential of a verification request, in any of the supported formats.
+type requestCredential struct {
+	raw   json.RawMessage
+	vc    *verifiable.Credential
+	sdJWT *sdJWTCredential
+	jwt   *jwtCredential
+}
+
+// parseRequestCredential parses the credential, verifying the signature of the JWT formats.
+func (o *Operation) parseRequestCredential(raw json.RawMessage) (*requestCredential, error) {
+	cred := &requestCredential{raw: raw}
+
+	var err error
+
+	if s, ok := getSDJWT(raw); ok {
+		cred.sdJWT, err = o.parseAndVerifySDJWT(s)
+		if err == nil {
+			cred.vc = cred.sdJWT.vc
+		}
+	} else if s, ok := getJWT(raw); ok {
+		cred.jwt, err = o.parseAndVerifyJWTVC(s)
+		if err == nil {
+			cred.vc = cred.jwt.vc
+		}
+	} else {
+		cred.vc, err = o.parseAndVerifyVC(raw)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// checkCredential runs the verification check on the credential, which can be one of a presentation.
+func (o *Operation) checkCredential(check string, cred *requestCredential, profile *verifier.ProfileData,
+	opts *CredentialsVerificationOptions, vcInVPValidation bool) error {
+	vc := cred.vc
+
+	switch check {
+	case proofCheck:
+		switch {
+		case cred.sdJWT != nil:
+			return o.validateSDJWTProof(cred.sdJWT, opts)
+		case cred.jwt != nil:
+			return o.validateJWTCredentialProof(cred.jwt, opts, vcInVPValidation)
+		default:
+			return o.validateCredentialProof(cred.raw, opts, vcInVPValidation)
+		}
+	case statusCheck:
+		return statusCheckError(o.checkVCStatus(vc, getValidAt(opts)))
+	case schemaCheck:
+		return o.schemaValidator.Validate(vc)
+	case validityCheck:
+		return o.validateValidityPeriod(vc, getValidAt(opts))
+	case issuerTrustCheck:
+		return o.validateIssuerTrust(profile.IssuerTrust, vc)
+	default:
+		return errCheckNotSupported
+	}
+}
+
+// checkPresentation runs the verification check on the presentation.
+func (o *Operation) checkPresentation(check string, profile *verifier.ProfileData, vpBytes []byte,
+	opts *VerifyPresentationOptions) error {
+	switch check {
+	case proofCheck:
+		return o.validatePresentationProof(vpBytes, opts)
+	case statusCheck:
+		_, err := o.parseAndVerifyVP(vpBytes, false, false, true)
+
+		return err
+	case issuerTrustCheck:
+		return o.validatePresentationIssuerTrust(profile.IssuerTrust, vpBytes)
+	default:
+		return errCheckNotSupported
+	}
+}
+
+// statusCheckError returns the error of the status check, if the status couldn't be fetched or isn't verified.
+func statusCheckError(ver *VerifyCredentialResponse, err error) error {
+	if err != nil {
+		return &checkError{
+			code: errCodeStatusUnavailable,
+			err:  fmt.Errorf("failed to fetch the status : %s", err.Error()),
+		}
+	}
+
+	if ver.Verified {
+		return nil
+	}
+
+	code := errCodeStatusRevoked
+	if ver.Message == suspendedMsg {
+		code = errCodeStatusSuspended
+	}
+
+	return &checkError{code: code, err: errors.New(ver.Message)}
+}
+
 func (o *Operation) validateCredentialProof(vcByte []byte, opts *CredentialsVerificationOptions, vcInVPValidation bool) error { // nolint: lll,gocyclo
+	// credentials in the JWT format are the JWT string
+	if isJWT(string(vcByte)) {
+		cred, err := o.parseAndVerifyJWTVC(string(vcByte))
+		if err != nil {
+			return fmt.Errorf("verifiable credential proof validation error : %w", err)
+		}
+
+		return o.validateJWTCredentialProof(cred, opts, vcInVPValidation)
+	}
+
 	vc, err := o.parseAndVerifyVCStrictMode(vcByte)
 	if err != nil {
 		return fmt.Errorf("verifiable credential proof validation error : %w", err)
@@ -438,6 +670,10 @@ func (o *Operation) validateCredentialProof(vcByte []byte, opts *CredentialsVeri
 }
 
 func (o *Operation) validatePresentationProof(vpByte []byte, opts *VerifyPresentationOptions) error { // nolint: gocyclo
+	if s, ok := getJWT(vpByte); ok {
+		return o.validateJWTPresentationProof(s, opts)
+	}
+
 	vp, err := o.parseAndVerifyVP(vpByte, true, true, false)
 	if err != nil {
 		return fmt.Errorf("verifiable presentation proof validation error : %w", err)
@@ -495,77 +731,263 @@ func (o *Operation) validateVCStatus(vcStatus *verifiable.TypedID) error {
 		return fmt.Errorf("vc status not exist")
 	}
 
-	if vcStatus.Type != csl.RevocationList2020Status {
+	switch vcStatus.Type {
+	case csl.RevocationList2020Status:
+		if vcStatus.CustomFields[csl.RevocationListIndex] == nil {
+			return fmt.Errorf("revocationListIndex field not exist in vc status")
+		}
+
+		if vcStatus.CustomFields[csl.RevocationListCredential] == nil {
+			return fmt.Errorf("revocationListCredential field not exist in vc status")
+		}
+	case csl.StatusList2021Entry:
+		if vcStatus.CustomFields[csl.StatusListIndex] == nil {
+			return fmt.Errorf("statusListIndex field not exist in vc status")
+		}
+
+		if vcStatus.CustomFields[csl.StatusListCredential] == nil {
+			return fmt.Errorf("statusListCredential field not exist in vc status")
+		}
+
+		if vcStatus.CustomFields[csl.StatusPurpose] == nil {
+			return fmt.Errorf("statusPurpose field not exist in vc status")
+		}
+	default:
 		return fmt.Errorf("vc status %s not supported", vcStatus.Type)
 	}
 
-	if vcStatus.CustomFields[csl.RevocationListIndex] == nil {
-		return fmt.Errorf("revocationListIndex field not exist in vc status")
+	return nil
+}
+
+// checkVCStatus checks the status of the credential, as of the given time if set. Only the status entries
+// the credential carries are checked, the suspension entry is there for credentials suspended by the issuer.
+func (o *Operation) checkVCStatus(vc *verifiable.Credential, validAt *time.Time) (*VerifyCredentialResponse, error) {
+	suspensionStatus, err := csl.GetSuspensionStatus(vc)
+	if err != nil {
+		return nil, err
 	}
 
-	if vcStatus.CustomFields[csl.RevocationListCredential] == nil {
-		return fmt.Errorf("revocationListCredential field not exist in vc status")
+	vcStatuses := []*verifiable.TypedID{vc.Status}
+	if suspensionStatus != nil {
+		vcStatuses = append(vcStatuses, suspensionStatus)
 	}
 
-	return nil
-}
+	for _, vcStatus := range vcStatuses {
+		bitSet, purpose, err := o.checkStatusEntry(vcStatus, vc.Issuer.ID, validAt)
+		if err != nil {
+			return nil, err
+		}
 
-//nolint: gocyclo
-func (o *Operation) checkVCStatus(vcStatus *verifiable.TypedID, issuer string) (*VerifyCredentialResponse, error) {
-	vcResp := &VerifyCredentialResponse{
-		Verified: false, Message: "Revoked",
+		if !bitSet {
+			continue
+		}
+
+		vcResp := &VerifyCredentialResponse{Verified: false, Message: revokedMsg}
+		if purpose == csl.StatusPurposeSuspension {
+			vcResp.Message = suspendedMsg
+		}
+
+		return vcResp, nil
 	}
 
+	return &VerifyCredentialResponse{Verified: true, Message: successMsg}, nil
+}
+
+// checkStatusEntry returns whether the bit of the status entry is set in its status list, along with
+// the purpose of the entry.
+func (o *Operation) checkStatusEntry(vcStatus *verifiable.TypedID, issuer string,
+	validAt *time.Time) (bool, interface{}, error) {
 	// validate vc status
 	if err := o.validateVCStatus(vcStatus); err != nil {
-		return nil, err
+		return false, nil, err
+	}
+
+	listCredentialKey, listIndexKey := csl.RevocationListCredential, csl.RevocationListIndex
+	if vcStatus.Type == csl.StatusList2021Entry {
+		listCredentialKey, listIndexKey = csl.StatusListCredential, csl.StatusListIndex
 	}
 
-	revocationListIndex, err := strconv.Atoi(vcStatus.CustomFields[csl.RevocationListIndex].(string))
+	revocationListIndex, err := strconv.Atoi(fmt.Sprint(vcStatus.CustomFields[listIndexKey]))
 	if err != nil {
-		return nil, err
+		return false, nil, err
+	}
+
+	listCredential, ok := vcStatus.CustomFields[listCredentialKey].(string)
+	if !ok {
+		return false, nil, fmt.Errorf("failed to cast status %s", listCredentialKey)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, vcStatus.CustomFields[csl.RevocationListCredential].(string), nil)
+	var purpose interface{}
+	if vcStatus.Type == csl.StatusList2021Entry {
+		purpose = vcStatus.CustomFields[csl.StatusPurpose]
+	}
+
+	bitSet, err := o.getStatusListBit(listCredential, issuer, purpose, revocationListIndex, validAt)
 	if err != nil {
-		return nil, err
+		return false, nil, err
 	}
 
-	resp, err := o.sendHTTPRequest(req, http.StatusOK, o.requestTokens[cslRequestTokenName])
+	return bitSet, purpose, nil
+}
+
+// getStatusListBit fetches the status list credential and returns the bit at the given index.
+// Status list is fetched as it was at the given time if set.
+func (o *Operation) getStatusListBit(listCredential, issuer string, purpose interface{}, index int,
+	validAt *time.Time) (bool, error) {
+	listURL, err := statusListURL(listCredential, validAt)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	revocationListVC, err := o.parseAndVerifyVC(resp)
+	revocationListVC, err := o.getStatusListVC(listURL, validAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse and verify status vc: %w", err)
+		return false, err
 	}
 
 	if revocationListVC.Issuer.ID != issuer {
-		return nil, fmt.Errorf("issuer of the credential do not match vc revocation list issuer")
+		return false, fmt.Errorf("issuer of the credential do not match vc revocation list issuer")
 	}
 
 	credSubject, ok := revocationListVC.Subject.([]verifiable.Subject)
+	if !ok || len(credSubject) == 0 {
+		return false, fmt.Errorf("status list vc has no credential subject")
+	}
+
+	if purpose != nil && credSubject[0].CustomFields[csl.StatusPurpose] != purpose {
+		return false, fmt.Errorf("status purpose of the credential do not match status list purpose")
+	}
+
+	encodedList, ok := credSubject[0].CustomFields["encodedList"].(string)
 	if !ok {
-		return nil, fmt.Errorf("")
+		return false, fmt.Errorf("status list vc has no encoded list")
 	}
 
-	bitString, err := utils.DecodeBits(credSubject[0].CustomFields["encodedList"].(string))
+	bitString, err := utils.DecodeBits(encodedList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode bits: %w", err)
+		return false, fmt.Errorf("failed to decode bits: %w", err)
+	}
+
+	return bitString.Get(index)
+}
+
+// statusListURL returns the URL the status list as it was at the given time is served at, by the issuers that
+// support it, the response confirms the time in the csl.ValidAtHeader.
+func statusListURL(listCredential string, validAt *time.Time) (string, error) {
+	if validAt == nil {
+		return listCredential, nil
 	}
 
-	bitSet, err := bitString.Get(revocationListIndex)
+	u, err := url.Parse(listCredential)
+	if err != nil {
+		return "", fmt.Errorf("invalid status list url: %w", err)
+	}
+
+	q := u.Query()
+	q.Set(validAtQueryParam, validAt.UTC().Format(time.RFC3339))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// getStatusListVC returns the verified status list vc, using the cached one if it is still fresh
+// or the issuer confirms it hasn't changed. The list fetched as it was at the given time has to be confirmed
+// as such, status lists that don't support it return their current status.
+func (o *Operation) getStatusListVC(listCredential string, validAt *time.Time) (*verifiable.Credential, error) {
+	cached := o.statusListCache.get(listCredential)
+	if cached != nil && time.Now().Before(cached.expires) {
+		return cached.vc, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, listCredential, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	if token := o.requestTokens[cslRequestTokenName]; token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	resp, err := o.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	if !bitSet {
-		vcResp.Verified = true
-		vcResp.Message = successMsg
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body")
+		}
+	}()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxStatusListVCSize+1))
+	if err != nil {
+		logger.Warnf("failed to read response body for status %d: %s", resp.StatusCode, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		o.statusListCache.put(listCredential, cached.vc, mergeHeaders(resp.Header, cached))
+
+		return cached.vc, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to read response body for status %d: %s", resp.StatusCode, string(body))
+	case len(body) > maxStatusListVCSize:
+		return nil, fmt.Errorf("status list vc exceeds %d bytes", maxStatusListVCSize)
 	}
 
-	return vcResp, nil
+	if err = confirmValidAt(resp.Header, validAt); err != nil {
+		return nil, err
+	}
+
+	vc, err := o.parseAndVerifyVC(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse and verify status vc: %w", err)
+	}
+
+	o.statusListCache.put(listCredential, vc, resp.Header)
+
+	return vc, nil
+}
+
+// confirmValidAt fails unless the status list response confirms it is the list as it was at the given time.
+func confirmValidAt(header http.Header, validAt *time.Time) error {
+	if validAt == nil {
+		return nil
+	}
+
+	confirmed, err := time.Parse(time.RFC3339, header.Get(csl.ValidAtHeader))
+	if err != nil || !confirmed.Equal(validAt.UTC().Truncate(time.Second)) {
+		return fmt.Errorf("status list doesn't support status as of %s", validAt.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// mergeHeaders fills the validators missing in the not modified response from the cached entry.
+func mergeHeaders(header http.Header, cached *statusListEntry) http.Header {
+	h := header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	if h.Get("ETag") == "" {
+		h.Set("ETag", cached.etag)
+	}
+
+	if h.Get("Last-Modified") == "" {
+		h.Set("Last-Modified", cached.lastModified)
+	}
+
+	return h
 }
 
 func (o *Operation) parseAndVerifyVCStrictMode(vcBytes []byte) (*verifiable.Credential, error) {
@@ -575,6 +997,7 @@ func (o *Operation) parseAndVerifyVCStrictMode(vcBytes []byte) (*verifiable.Cred
 			verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher(),
 		),
 		verifiable.WithStrictValidation(),
+		verifiable.WithNoCustomSchemaCheck(),
 		verifiable.WithJSONLDDocumentLoader(o.documentLoader),
 	)
 	if err != nil {
@@ -603,8 +1026,7 @@ func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof, validateCre
 			return nil, err
 		}
 	} else {
-		vp, err = verifiable.ParsePresentation(vpBytes, verifiable.WithPresDisabledProofCheck(),
-			verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+		vp, err = o.parsePresentation(vpBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -614,7 +1036,7 @@ func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof, validateCre
 
 	// verify if the credentials in vp are valid
 	for _, cred := range vp.Credentials() {
-		vcBytes, err := json.Marshal(cred)
+		vcBytes, err := credentialBytes(cred)
 		if err != nil {
 			return nil, err
 		}
@@ -628,24 +1050,14 @@ func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof, validateCre
 		}
 
 		if validateCredentialStatus {
-			failureMessage := ""
-
 			vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
-				verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+				verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
 			if err != nil {
 				return nil, err
 			}
 
-			ver, err := o.checkVCStatus(vc.Status, vc.Issuer.ID)
-
-			if err != nil {
-				failureMessage = fmt.Sprintf("failed to fetch the status : %s", err.Error())
-			} else if !ver.Verified {
-				failureMessage = ver.Message
-			}
-
-			if failureMessage != "" {
-				return nil, fmt.Errorf(failureMessage)
+			if err = statusCheckError(o.checkVCStatus(vc, nil)); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -653,12 +1065,44 @@ func (o *Operation) parseAndVerifyVP(vpBytes []byte, validateVPPoof, validateCre
 	return vp, nil
 }
 
+// parsePresentation parses the presentation without checking its proof, from a VP-JWT too.
+func (o *Operation) parsePresentation(vpBytes []byte) (*verifiable.Presentation, error) {
+	if s, ok := getJWT(vpBytes); ok {
+		vpBytes = []byte(s)
+	}
+
+	// the public key fetcher is required to decode a VP-JWT, even if its signature isn't verified
+	return verifiable.ParsePresentation(vpBytes, verifiable.WithPresDisabledProofCheck(),
+		verifiable.WithPresPublicKeyFetcher(verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher()),
+		verifiable.WithPresJSONLDDocumentLoader(o.documentLoader))
+}
+
+// credentialBytes returns the credential of a presentation to parse, which is the JWT string for a VC-JWT.
+func credentialBytes(cred interface{}) ([]byte, error) {
+	// credentials in the JWT format are decoded when the presentation is parsed
+	if decoded, ok := cred.([]byte); ok {
+		return decoded, nil
+	}
+
+	vcBytes, err := json.Marshal(cred)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, ok := getJWT(vcBytes); ok {
+		return []byte(s), nil
+	}
+
+	return vcBytes, nil
+}
+
 func (o *Operation) parseAndVerifyVC(vcBytes []byte) (*verifiable.Credential, error) {
 	vc, err := verifiable.ParseCredential(
 		vcBytes,
 		verifiable.WithPublicKeyFetcher(
 			verifiable.NewVDRKeyResolver(o.vdr).PublicKeyFetcher(),
 		),
+		verifiable.WithNoCustomSchemaCheck(),
 		verifiable.WithJSONLDDocumentLoader(o.documentLoader),
 	)
 	if err != nil {
@@ -668,33 +1112,12 @@ func (o *Operation) parseAndVerifyVC(vcBytes []byte) (*verifiable.Credential, er
 	return vc, nil
 }
 
-func (o *Operation) sendHTTPRequest(req *http.Request, status int, token string) ([]byte, error) {
-	if token != "" {
-		req.Header.Add("Authorization", "Bearer "+token)
-	}
-
-	resp, err := o.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		err = resp.Body.Close()
-		if err != nil {
-			logger.Warnf("failed to close response body")
-		}
-	}()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		logger.Warnf("failed to read response body for status %d: %s", resp.StatusCode, err)
-	}
-
-	if resp.StatusCode != status {
-		return nil, fmt.Errorf("failed to read response body for status %d: %s", resp.StatusCode, string(body))
+func getValidAt(opts *CredentialsVerificationOptions) *time.Time {
+	if opts == nil {
+		return nil
 	}
 
-	return body, nil
+	return opts.ValidAt
 }
 
 func getCredentialChecks(profile *verifier.ProfileData, opts *CredentialsVerificationOptions) []string {
@@ -785,7 +1208,7 @@ func validateProfileRequest(pr *verifier.ProfileData) error {
 	case len(pr.CredentialChecks) != 0:
 		for _, val := range pr.CredentialChecks {
 			switch val {
-			case proofCheck, statusCheck:
+			case proofCheck, statusCheck, schemaCheck, validityCheck, issuerTrustCheck:
 			default:
 				return fmt.Errorf("invalid credential check option - %s", val)
 			}
@@ -793,14 +1216,18 @@ func validateProfileRequest(pr *verifier.ProfileData) error {
 	case len(pr.PresentationChecks) != 0:
 		for _, val := range pr.PresentationChecks {
 			switch val {
-			case proofCheck:
+			case proofCheck, issuerTrustCheck:
 			default:
 				return fmt.Errorf("invalid presentation check option - %s", val)
 			}
 		}
 	}
 
-	return nil
+	if err := validateIssuerTrustPolicy(pr.IssuerTrust); err != nil {
+		return err
+	}
+
+	return validatePresentationDefinitions(pr.PresentationDefinitions)
 }
 
 type storeProvider struct {
@@ -36,6 +36,7 @@ import (
 
 	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
 	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/schema"
 	cslstatus "github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
 	"github.com/trustbloc/edge-service/pkg/internal/common/utils"
 	"github.com/trustbloc/edge-service/pkg/internal/testutil"
@@ -490,6 +491,224 @@ func TestVerifyCredential(t *testing.T) {
 		require.Contains(t, rr.Body.String(), "issuer of the credential do not match vc revocation list issuer")
 	})
 
+	t.Run("credential verification - StatusList2021", func(t *testing.T) {
+		pubKey, privKey, errGenerateKey := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, errGenerateKey)
+
+		didDoc := createDIDDoc(didID, pubKey)
+		verificationMethod := didDoc.VerificationMethod[0].ID
+
+		ops, errNew := New(&Config{
+			VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+			StoreProvider:  ariesmemstorage.NewProvider(),
+			DocumentLoader: loader,
+		})
+		require.NoError(t, errNew)
+
+		err = ops.profileStore.SaveProfile(vReq)
+		require.NoError(t, err)
+
+		bitString := utils.NewBitString(3)
+		require.NoError(t, bitString.Set(1, true))
+
+		encodeBits, errNew := bitString.EncodeBits()
+		require.NoError(t, errNew)
+
+		suspensionBitString := utils.NewBitString(3)
+		require.NoError(t, suspensionBitString.Set(2, true))
+
+		suspensionEncodeBits, errNew := suspensionBitString.EncodeBits()
+		require.NoError(t, errNew)
+
+		handler := getHandler(t, ops, credentialsVerificationEndpoint, http.MethodPost)
+
+		verify := func(statusIndex, listPurpose string, suspension bool) *httptest.ResponseRecorder {
+			ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+				list := fmt.Sprintf(statusList2021VC, didDoc.ID, listPurpose, encodeBits)
+				if req.URL.String() == cslstatus.SuspensionListID("http://example.com/status/100") {
+					// lists of other issuers have no suspension list next to them
+					if !suspension {
+						return &http.Response{
+							StatusCode: http.StatusNotFound,
+							Body:       ioutil.NopCloser(strings.NewReader("not found")),
+						}, nil
+					}
+
+					list = fmt.Sprintf(statusList2021VC, didDoc.ID, cslstatus.StatusPurposeSuspension, suspensionEncodeBits)
+				}
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(list)),
+				}, nil
+			}}
+
+			slVC := *vc
+			slVC.Issuer.ID = didDoc.ID
+			slVC.Context = append([]string{}, vc.Context...)
+			slVC.CustomFields = nil
+
+			status := &verifiable.TypedID{
+				ID:   "http://example.com/status/100#" + statusIndex,
+				Type: cslstatus.StatusList2021Entry,
+				CustomFields: map[string]interface{}{
+					cslstatus.StatusPurpose:        cslstatus.StatusPurposeRevocation,
+					cslstatus.StatusListIndex:      statusIndex,
+					cslstatus.StatusListCredential: "http://example.com/status/100",
+				},
+			}
+
+			if suspension {
+				cslstatus.SetStatus(&slVC, status)
+			} else {
+				slVC.Status = status
+				slVC.Context = append(slVC.Context, cslstatus.StatusList2021Context)
+			}
+
+			vcBytes, errMarshal := slVC.MarshalJSON()
+			require.NoError(t, errMarshal)
+
+			vReqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+				Credential: getSignedVC(t, privKey, string(vcBytes), didID, verificationMethod, domain, challenge),
+				Opts: &CredentialsVerificationOptions{
+					Checks:    []string{proofCheck, statusCheck},
+					Challenge: challenge,
+					Domain:    domain,
+				},
+			})
+			require.NoError(t, errMarshal)
+
+			return serveHTTPMux(t, handler, endpoint, vReqBytes, urlVars)
+		}
+
+		rr := verify("0", cslstatus.StatusPurposeRevocation, true)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = verify("1", cslstatus.StatusPurposeRevocation, true)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "Revoked")
+
+		rr = verify("2", cslstatus.StatusPurposeRevocation, true)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "Suspended")
+
+		// the suspension list isn't fetched for credentials without suspension entry
+		rr = verify("2", cslstatus.StatusPurposeRevocation, false)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = verify("1", cslstatus.StatusPurposeRevocation, false)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "Revoked")
+
+		rr = verify("0", cslstatus.StatusPurposeSuspension, false)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "status purpose of the credential do not match status list purpose")
+	})
+
+	t.Run("credential verification - status at given time", func(t *testing.T) {
+		pubKey, privKey, errGenerateKey := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, errGenerateKey)
+
+		didDoc := createDIDDoc(didID, pubKey)
+		verificationMethod := didDoc.VerificationMethod[0].ID
+
+		ops, errNew := New(&Config{
+			VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+			StoreProvider:  ariesmemstorage.NewProvider(),
+			DocumentLoader: loader,
+		})
+		require.NoError(t, errNew)
+
+		err = ops.profileStore.SaveProfile(vReq)
+		require.NoError(t, err)
+
+		revokedBits := utils.NewBitString(3)
+		require.NoError(t, revokedBits.Set(1, true))
+
+		revokedEncodeBits, errNew := revokedBits.EncodeBits()
+		require.NoError(t, errNew)
+
+		emptyEncodeBits, errNew := utils.NewBitString(3).EncodeBits()
+		require.NoError(t, errNew)
+
+		validAt := time.Date(2021, 5, 10, 10, 0, 0, 0, time.UTC)
+
+		// status lists of other issuers ignore validAt
+		supportsValidAt := true
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			// credential was revoked after the given time
+			encodeBits := revokedEncodeBits
+			header := http.Header{}
+
+			if at := req.URL.Query().Get(validAtQueryParam); at == "2021-05-10T10:00:00Z" && supportsValidAt {
+				encodeBits = emptyEncodeBits
+
+				header.Set(cslstatus.ValidAtHeader, at)
+			}
+
+			purpose := cslstatus.StatusPurposeRevocation
+			if strings.HasSuffix(req.URL.Path, "/"+cslstatus.StatusPurposeSuspension) {
+				purpose = cslstatus.StatusPurposeSuspension
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body: ioutil.NopCloser(strings.NewReader(
+					fmt.Sprintf(statusList2021VC, didDoc.ID, purpose, encodeBits))),
+			}, nil
+		}}
+
+		slVC := *vc
+		slVC.Issuer.ID = didDoc.ID
+		slVC.Context = append(append([]string{}, vc.Context...), cslstatus.StatusList2021Context)
+		slVC.Status = &verifiable.TypedID{
+			ID:   "http://example.com/status/100#1",
+			Type: cslstatus.StatusList2021Entry,
+			CustomFields: map[string]interface{}{
+				cslstatus.StatusPurpose:        cslstatus.StatusPurposeRevocation,
+				cslstatus.StatusListIndex:      "1",
+				cslstatus.StatusListCredential: "http://example.com/status/100",
+			},
+		}
+
+		vcBytes, errMarshal := slVC.MarshalJSON()
+		require.NoError(t, errMarshal)
+
+		signedVC := getSignedVC(t, privKey, string(vcBytes), didID, verificationMethod, domain, challenge)
+
+		handler := getHandler(t, ops, credentialsVerificationEndpoint, http.MethodPost)
+
+		verify := func(at *time.Time) *httptest.ResponseRecorder {
+			vReqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+				Credential: signedVC,
+				Opts: &CredentialsVerificationOptions{
+					Checks:  []string{statusCheck},
+					ValidAt: at,
+				},
+			})
+			require.NoError(t, errMarshal)
+
+			return serveHTTPMux(t, handler, endpoint, vReqBytes, urlVars)
+		}
+
+		rr := verify(&validAt)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = verify(nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "Revoked")
+
+		// the current status isn't reported as the status at the given time
+		supportsValidAt = false
+		validAt = validAt.Add(time.Hour)
+
+		rr = verify(&validAt)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "status list doesn't support status as of 2021-05-10T11:00:00Z")
+	})
+
 	t.Run("credential verification - invalid profile", func(t *testing.T) {
 		ops, errNew := New(&Config{
 			VDRI:          &vdrmock.MockVDRegistry{},
@@ -1018,6 +1237,92 @@ func TestVerifyCredential(t *testing.T) {
 	})
 }
 
+func TestVerifyCredential_SchemaCheck(t *testing.T) {
+	const schemaURL = "https://example.com/schemas/prc.json"
+
+	loader := testutil.DocumentLoader(t)
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+		SchemaLoader: schema.NewLocalLoader(map[string][]byte{schemaURL: []byte(`{
+		  "type": "object",
+		  "properties": {
+		    "credentialSubject": {
+		      "type": "object",
+		      "properties": {"lprCategory": {"type": "string", "pattern": "^C[0-9]{2}$"}},
+		      "required": ["lprCategory"]
+		    }
+		  }
+		}`)}, nil),
+	})
+	require.NoError(t, err)
+
+	saveTestProfile(t, op)
+
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+	handler := getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost)
+
+	verify := func(t *testing.T, lprCategory, schemaID string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		vc, errParse := verifiable.ParseCredential([]byte(prCardVC), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+
+		vc.Issuer.ID = didDoc.ID
+		vc.Subject.([]verifiable.Subject)[0].CustomFields["lprCategory"] = lprCategory
+		vc.Schemas = []verifiable.TypedID{{ID: schemaID, Type: schema.JSONSchemaValidator2018}}
+
+		vcBytes, errMarshal := vc.MarshalJSON()
+		require.NoError(t, errMarshal)
+
+		reqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+			Credential: getSignedVC(t, privKey, string(vcBytes), didID, didDoc.VerificationMethod[0].ID, "", ""),
+			Opts: &CredentialsVerificationOptions{
+				Checks: []string{schemaCheck},
+			},
+		})
+		require.NoError(t, errMarshal)
+
+		return serveHTTPMux(t, handler, "/"+testProfileID+"/verifier/credentials/verify", reqBytes, urlVars)
+	}
+
+	t.Run("schema check - success", func(t *testing.T) {
+		rr := verify(t, "C09", schemaURL)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		verificationResp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), verificationResp))
+		require.Equal(t, []string{schemaCheck}, verificationResp.Checks)
+	})
+
+	t.Run("schema check - credential doesn't conform to the schema", func(t *testing.T) {
+		rr := verify(t, "invalid", schemaURL)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		verificationResp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), verificationResp))
+		require.Len(t, verificationResp.Checks, 1)
+		require.Equal(t, schemaCheck, verificationResp.Checks[0].Check)
+		require.Contains(t, verificationResp.Checks[0].Error, "credential doesn't conform to schema "+schemaURL)
+		require.Contains(t, verificationResp.Checks[0].Error, "credentialSubject.lprCategory")
+	})
+
+	t.Run("schema check - schema not found", func(t *testing.T) {
+		rr := verify(t, "C09", "https://example.com/schemas/other.json")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "schema https://example.com/schemas/other.json not found")
+	})
+}
+
 func TestVerifyPresentation(t *testing.T) {
 	loader := testutil.DocumentLoader(t)
 
@@ -1469,6 +1774,289 @@ func TestVerifyPresentation(t *testing.T) {
 	})
 }
 
+func TestGetStatusListVC(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	const listURL = "http://example.com/status/1"
+
+	bitString := utils.NewBitString(3)
+
+	encodeBits, err := bitString.EncodeBits()
+	require.NoError(t, err)
+
+	list := fmt.Sprintf(statusList2021VC, "did:example:issuer", cslstatus.StatusPurposeRevocation, encodeBits)
+
+	newOps := func(t *testing.T, ttl time.Duration) *Operation {
+		t.Helper()
+
+		ops, errNew := New(&Config{
+			VDRI:           &vdrmock.MockVDRegistry{},
+			StoreProvider:  ariesmemstorage.NewProvider(),
+			RequestTokens:  map[string]string{cslRequestTokenName: "tk1"},
+			DocumentLoader: loader,
+			StatusCacheTTL: ttl,
+		})
+		require.NoError(t, errNew)
+
+		return ops
+	}
+
+	response := func(code int, header http.Header, body string) *http.Response {
+		return &http.Response{StatusCode: code, Header: header, Body: ioutil.NopCloser(strings.NewReader(body))}
+	}
+
+	t.Run("test fresh list is served from cache", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		var requests int
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+
+			require.Equal(t, "Bearer tk1", req.Header.Get("Authorization"))
+
+			return response(http.StatusOK, http.Header{"Cache-Control": []string{"max-age=30"}}, list), nil
+		}}
+
+		vc, errGet := ops.getStatusListVC(listURL, nil)
+		require.NoError(t, errGet)
+
+		cached, errGet := ops.getStatusListVC(listURL, nil)
+		require.NoError(t, errGet)
+		require.Equal(t, vc, cached)
+		require.Equal(t, 1, requests)
+	})
+
+	t.Run("test stale list is revalidated", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		var requests int
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				require.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", req.Header.Get("If-Modified-Since"))
+
+				return response(http.StatusNotModified, http.Header{"Cache-Control": []string{"no-cache"}}, ""), nil
+			}
+
+			return response(http.StatusOK, http.Header{
+				"Cache-Control": []string{"no-cache"},
+				"Etag":          []string{`"v1"`},
+				"Last-Modified": []string{"Mon, 02 Jan 2006 15:04:05 GMT"},
+			}, list), nil
+		}}
+
+		vc, errGet := ops.getStatusListVC(listURL, nil)
+		require.NoError(t, errGet)
+
+		for i := 0; i < 2; i++ {
+			cached, errGet := ops.getStatusListVC(listURL, nil)
+			require.NoError(t, errGet)
+			require.Equal(t, vc, cached)
+		}
+
+		require.Equal(t, 3, requests)
+	})
+
+	t.Run("test changed list is fetched again", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return response(http.StatusOK, http.Header{
+				"Cache-Control": []string{"no-cache"},
+				"Etag":          []string{`"v1"`},
+			}, list), nil
+		}}
+
+		_, err = ops.getStatusListVC(listURL, nil)
+		require.NoError(t, err)
+
+		changed := strings.ReplaceAll(list, "did:example:issuer", "did:example:other")
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, `"v1"`, req.Header.Get("If-None-Match"))
+
+			return response(http.StatusOK, http.Header{"Etag": []string{`"v2"`}}, changed), nil
+		}}
+
+		vc, errGet := ops.getStatusListVC(listURL, nil)
+		require.NoError(t, errGet)
+		require.Equal(t, "did:example:other", vc.Issuer.ID)
+		require.Equal(t, `"v2"`, ops.statusListCache.get(listURL).etag)
+	})
+
+	t.Run("test no-store list is not cached", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		var requests int
+
+		ops.httpClient = &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+
+			require.Empty(t, req.Header.Get("If-None-Match"))
+
+			return response(http.StatusOK, http.Header{
+				"Cache-Control": []string{"no-store"},
+				"Etag":          []string{`"v1"`},
+			}, list), nil
+		}}
+
+		for i := 0; i < 2; i++ {
+			_, err = ops.getStatusListVC(listURL, nil)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 2, requests)
+		require.Nil(t, ops.statusListCache.get(listURL))
+	})
+
+	t.Run("test error status", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		ops.httpClient = &mockHTTPClient{doValue: response(http.StatusNotModified, nil, "")}
+
+		_, err = ops.getStatusListVC(listURL, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read response body for status 304")
+	})
+
+	t.Run("test list too large", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		ops.httpClient = &mockHTTPClient{doValue: response(http.StatusOK, nil,
+			strings.Repeat(" ", maxStatusListVCSize+1))}
+
+		_, err = ops.getStatusListVC(listURL, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status list vc exceeds")
+	})
+
+	t.Run("test invalid list subject", func(t *testing.T) {
+		ops := newOps(t, time.Minute)
+
+		noEncodedList := strings.Replace(list, `"encodedList": "`+encodeBits+`"`, `"encodedList": 1`, 1)
+		ops.httpClient = &mockHTTPClient{doValue: response(http.StatusOK, nil, noEncodedList)}
+
+		_, err = ops.getStatusListBit(listURL, "did:example:issuer", nil, 0, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status list vc has no encoded list")
+
+		noSubject := list[:strings.Index(list, `"credentialSubject"`)] + `"credentialSubject": []}`
+		ops.httpClient = &mockHTTPClient{doValue: response(http.StatusOK, nil, noSubject)}
+
+		_, err = ops.getStatusListBit(listURL+"?other", "did:example:issuer", nil, 0, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status list vc has no credential subject")
+	})
+}
+
+func TestStatusListURL(t *testing.T) {
+	listURL, err := statusListURL("http://example.com/status/1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/status/1", listURL)
+
+	validAt := time.Date(2021, 5, 10, 12, 0, 0, 0, time.FixedZone("", 2*60*60))
+
+	listURL, err = statusListURL("http://example.com/status/1", &validAt)
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/status/1?validAt=2021-05-10T10%3A00%3A00Z", listURL)
+
+	_, err = statusListURL("http://example.com/%zz", &validAt)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid status list url")
+}
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		header string
+		maxAge time.Duration
+		store  bool
+	}{
+		{header: "", maxAge: -1, store: true},
+		{header: "max-age=60", maxAge: time.Minute, store: true},
+		{header: "public, Max-Age=10", maxAge: 10 * time.Second, store: true},
+		{header: "no-cache", maxAge: 0, store: true},
+		{header: "max-age=60, no-cache", maxAge: 0, store: true},
+		{header: "max-age=abc", maxAge: 0, store: true},
+		{header: "max-age=60, no-store", maxAge: 0, store: false},
+	}
+
+	for _, tc := range tests {
+		maxAge, store := parseCacheControl(tc.header)
+		require.Equal(t, tc.maxAge, maxAge, tc.header)
+		require.Equal(t, tc.store, store, tc.header)
+	}
+}
+
+func TestStatusListCache(t *testing.T) {
+	t.Run("test least recently used list is evicted", func(t *testing.T) {
+		c := newStatusListCache(time.Minute, 2)
+
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{})
+		c.put("http://example.com/status/2", &verifiable.Credential{}, http.Header{})
+		require.NotNil(t, c.get("http://example.com/status/1"))
+
+		c.put("http://example.com/status/3", &verifiable.Credential{}, http.Header{})
+
+		require.NotNil(t, c.get("http://example.com/status/1"))
+		require.Nil(t, c.get("http://example.com/status/2"))
+		require.NotNil(t, c.get("http://example.com/status/3"))
+		require.Len(t, c.entries, 2)
+	})
+
+	t.Run("test expired list is kept for revalidation for the ttl", func(t *testing.T) {
+		c := newStatusListCache(time.Minute, 0)
+		require.Equal(t, defaultStatusListCacheSize, c.maxEntries)
+
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{
+			"Cache-Control": []string{"no-cache"},
+			"Etag":          []string{`"v1"`},
+		})
+
+		entry := c.get("http://example.com/status/1")
+		require.NotNil(t, entry)
+		require.False(t, time.Now().Before(entry.expires))
+
+		entry.dropAt = time.Now().Add(-time.Second)
+
+		require.Nil(t, c.get("http://example.com/status/1"))
+		require.Empty(t, c.entries)
+		require.Zero(t, c.recent.Len())
+	})
+
+	t.Run("test no-store removes the list", func(t *testing.T) {
+		c := newStatusListCache(time.Minute, 0)
+
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{})
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{
+			"Cache-Control": []string{"no-store"},
+		})
+
+		require.Nil(t, c.get("http://example.com/status/1"))
+		require.Zero(t, c.recent.Len())
+	})
+
+	t.Run("test unexpected value is dropped as a miss", func(t *testing.T) {
+		c := newStatusListCache(time.Minute, 1)
+
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{})
+		c.entries["http://example.com/status/1"].Value = "unexpected"
+
+		require.Nil(t, c.get("http://example.com/status/1"))
+		require.Empty(t, c.entries)
+		require.Zero(t, c.recent.Len())
+
+		c.put("http://example.com/status/1", &verifiable.Credential{}, http.Header{})
+		c.entries["http://example.com/status/1"].Value = "unexpected"
+
+		c.put("http://example.com/status/2", &verifiable.Credential{}, http.Header{})
+		require.Len(t, c.entries, 1)
+		require.NotNil(t, c.get("http://example.com/status/2"))
+	})
+}
+
 func TestValidateProof(t *testing.T) {
 	proof := make(map[string]interface{})
 	key := "challenge"
@@ -1596,9 +2184,14 @@ func TestGetDIDDocFromProof(t *testing.T) {
 type mockHTTPClient struct {
 	doValue *http.Response
 	doErr   error
+	doFunc  func(req *http.Request) (*http.Response, error)
 }
 
 func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if m.doFunc != nil {
+		return m.doFunc(req)
+	}
+
 	return m.doValue, m.doErr
 }
 
@@ -1608,7 +2201,7 @@ func getSignedVC(t *testing.T, privKey []byte, vcJSON, didID, verificationMethod
 	loader := testutil.DocumentLoader(t)
 
 	vc, err := verifiable.ParseCredential([]byte(vcJSON), verifiable.WithDisabledProofCheck(),
-		verifiable.WithJSONLDDocumentLoader(loader))
+		verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(loader))
 	require.NoError(t, err)
 
 	vc.Issuer.ID = didID
@@ -1902,6 +2495,23 @@ const (
   		}
 	}`
 
+	statusList2021VC = `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://w3id.org/vc/status-list/2021/v1"
+  ],
+  "id": "https://example.com/credentials/status/3",
+  "type": ["VerifiableCredential", "StatusList2021Credential"],
+  "issuer": "%s",
+  "issuanceDate": "2020-04-05T14:27:40Z",
+  "credentialSubject": {
+    "id": "https://example.com/status/3#list",
+    "type": "StatusList2021",
+    "statusPurpose": "%s",
+    "encodedList": "%s"
+  }
+}`
+
 	vpWithoutProof = `{	
 		"@context": [	
 			"https://www.w3.org/2018/credentials/v1",	
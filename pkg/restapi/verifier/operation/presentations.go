@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+// PresentationRequest swagger:route POST /{id}/verifier/presentations/request verifier presentationRequestReq
+//
+// Returns a presentation definition of the verifier profile along with a fresh challenge.
+//
+// Responses:
+//    default: genericError
+//        200: presentationRequestRes
+func (o *Operation) presentationRequestHandler(rw http.ResponseWriter, req *http.Request) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid verifier profile - id=%s: err=%s",
+			profileID, err.Error()))
+
+		return
+	}
+
+	data := PresentationRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	pd, err := getPresentationDefinition(profile, data.DefinitionID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	challenge, err := o.challengeStore.Issue(profile.ID, data.Domain, o.challengeTTL)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &PresentationRequestResponse{
+		Challenge:              challenge.Value,
+		Domain:                 challenge.Domain,
+		ExpiresAt:              challenge.ExpiresAt,
+		PresentationDefinition: pd,
+	})
+}
+
+func getPresentationDefinition(profile *verifier.ProfileData, id string) (*presexch.PresentationDefinition, error) {
+	if id == "" {
+		if len(profile.PresentationDefinitions) != 1 {
+			return nil, fmt.Errorf("profile %s has %d presentation definitions, one has to be chosen",
+				profile.ID, len(profile.PresentationDefinitions))
+		}
+
+		return profile.PresentationDefinitions[0], nil
+	}
+
+	pd := profile.PresentationDefinition(id)
+	if pd == nil {
+		return nil, fmt.Errorf("profile %s has no presentation definition %s", profile.ID, id)
+	}
+
+	return pd, nil
+}
+
+// evaluatePresentationSubmission matches the presentation submission against the presentation definition it is for.
+// Presentations without submission are only accepted by profiles without presentation definitions.
+func (o *Operation) evaluatePresentationSubmission(profile *verifier.ProfileData,
+	vpBytes []byte) (*presexch.Evaluation, error) {
+	vp, err := o.parsePresentation(vpBytes)
+	if err != nil {
+		// without presentation definitions, invalid presentations are reported by the checks of the profile only
+		if len(profile.PresentationDefinitions) == 0 {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to parse presentation: %w", err)
+	}
+
+	// the presentation is marshaled again to evaluate the same document whether it was a JWT or not
+	doc, err := toMap(vp)
+	if err != nil {
+		return nil, err
+	}
+
+	submission, err := presexch.GetSubmission(doc)
+	if err != nil {
+		if errors.Is(err, presexch.ErrSubmissionNotFound) && len(profile.PresentationDefinitions) == 0 {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	pd := profile.PresentationDefinition(submission.DefinitionID)
+	if pd == nil {
+		return nil, fmt.Errorf("profile %s has no presentation definition %s", profile.ID, submission.DefinitionID)
+	}
+
+	evaluation := pd.Evaluate(doc, submission)
+	if !evaluation.Matched {
+		return evaluation, fmt.Errorf("presentation submission doesn't match presentation definition %s", pd.ID)
+	}
+
+	return evaluation, nil
+}
+
+func validatePresentationDefinitions(definitions []*presexch.PresentationDefinition) error {
+	ids := make(map[string]bool)
+
+	for _, pd := range definitions {
+		if pd == nil {
+			return errors.New("invalid presentation definition - null")
+		}
+
+		if err := pd.Validate(); err != nil {
+			return fmt.Errorf("invalid presentation definition - %w", err)
+		}
+
+		if ids[pd.ID] {
+			return fmt.Errorf("invalid presentation definition - %s is defined twice", pd.ID)
+		}
+
+		ids[pd.ID] = true
+	}
+
+	return nil
+}
+
+func toMap(vp *verifiable.Presentation) (map[string]interface{}, error) {
+	vpBytes, err := vp.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal presentation: %w", err)
+	}
+
+	var doc map[string]interface{}
+
+	if err = json.Unmarshal(vpBytes, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal presentation: %w", err)
+	}
+
+	return doc, nil
+}
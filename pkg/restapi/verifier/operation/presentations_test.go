@@ -0,0 +1,286 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const presentationDefinition = `{
+  "id": "prc-definition",
+  "purpose": "Proof of permanent residency",
+  "input_descriptors": [{
+    "id": "prc",
+    "constraints": {
+      "fields": [{
+        "path": ["$.credentialSubject.type", "$.vc.credentialSubject.type"],
+        "filter": {"type": "array", "contains": {"const": "PermanentResident"}}
+      }]
+    }
+  }]
+}`
+
+func TestPresentationRequest(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: mem.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	pd := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(presentationDefinition), pd))
+
+	other := &presexch.PresentationDefinition{ID: "other", InputDescriptors: pd.InputDescriptors}
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: "single", Name: "single", PresentationDefinitions: []*presexch.PresentationDefinition{pd},
+	}))
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: "multiple", Name: "multiple", PresentationDefinitions: []*presexch.PresentationDefinition{pd, other},
+	}))
+
+	handler := getHandler(t, op, presentationRequestEndpoint, http.MethodPost)
+
+	request := func(t *testing.T, profileID string, req *PresentationRequest) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, "/"+profileID+"/verifier/presentations/request", reqBytes,
+			map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("presentation request - only definition", func(t *testing.T) {
+		code, body := request(t, "single", &PresentationRequest{})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.NotEmpty(t, resp.Challenge)
+		require.Equal(t, pd.ID, resp.PresentationDefinition.ID)
+		require.Equal(t, pd.Purpose, resp.PresentationDefinition.Purpose)
+
+		_, again := request(t, "single", &PresentationRequest{})
+		require.NotContains(t, again, resp.Challenge)
+	})
+
+	t.Run("presentation request - chosen definition", func(t *testing.T) {
+		code, body := request(t, "multiple", &PresentationRequest{DefinitionID: "other", Domain: domain})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &PresentationRequestResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, "other", resp.PresentationDefinition.ID)
+		require.Equal(t, domain, resp.Domain)
+		require.False(t, resp.ExpiresAt.IsZero())
+
+		require.NoError(t, op.challengeStore.Consume(resp.Challenge, "multiple", domain))
+	})
+
+	t.Run("presentation request - definition not chosen", func(t *testing.T) {
+		code, body := request(t, "multiple", &PresentationRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "profile multiple has 2 presentation definitions, one has to be chosen")
+	})
+
+	t.Run("presentation request - unknown definition", func(t *testing.T) {
+		code, body := request(t, "single", &PresentationRequest{DefinitionID: "other"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "profile single has no presentation definition other")
+	})
+
+	t.Run("presentation request - invalid profile", func(t *testing.T) {
+		code, body := request(t, "unknown", &PresentationRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid verifier profile")
+	})
+
+	t.Run("presentation request - invalid request", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, "/single/verifier/presentations/request", []byte("{"),
+			map[string]string{profileIDPathParam: "single"})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+}
+
+func TestVerifyPresentationSubmission(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  mem.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	pd := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(presentationDefinition), pd))
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: "pe", Name: "pe", PresentationDefinitions: []*presexch.PresentationDefinition{pd},
+	}))
+
+	handler := getHandler(t, op, presentationsVerificationEndpoint, http.MethodPost)
+
+	verify := func(t *testing.T, submission interface{}) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(&VerifyPresentationRequest{
+			Presentation: getSignedVPWithSubmission(t, privKey, didID, verificationMethod, submission),
+			Opts:         &VerifyPresentationOptions{Checks: []string{proofCheck}, Challenge: challenge, Domain: domain},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, handler, "/pe/verifier/presentations/verify", reqBytes,
+			map[string]string{profileIDPathParam: "pe"})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("presentation submission - matched", func(t *testing.T) {
+		code, body := verify(t, &presexch.PresentationSubmission{
+			ID:           "submission",
+			DefinitionID: pd.ID,
+			DescriptorMap: []*presexch.InputDescriptorMapping{{
+				ID: "prc", Format: "ldp_vc", Path: "$.verifiableCredential[0]",
+			}},
+		})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &VerifyPresentationSuccessResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, []string{proofCheck}, resp.Checks)
+		require.True(t, resp.PresentationSubmission.Matched)
+		require.Equal(t, pd.ID, resp.PresentationSubmission.DefinitionID)
+		require.Len(t, resp.PresentationSubmission.InputDescriptors, 1)
+		require.True(t, resp.PresentationSubmission.InputDescriptors[0].Matched)
+		require.Equal(t, "$.verifiableCredential[0]", resp.PresentationSubmission.InputDescriptors[0].Path)
+	})
+
+	t.Run("presentation submission - not matched", func(t *testing.T) {
+		code, body := verify(t, &presexch.PresentationSubmission{
+			ID:           "submission",
+			DefinitionID: pd.ID,
+			DescriptorMap: []*presexch.InputDescriptorMapping{{
+				ID: "prc", Format: "ldp_vc", Path: "$.verifiableCredential[1]",
+			}},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Checks, 1)
+		require.Equal(t, presentationDefinitionCheck, resp.Checks[0].Check)
+		require.Equal(t, "presentation submission doesn't match presentation definition prc-definition",
+			resp.Checks[0].Error)
+		require.False(t, resp.PresentationSubmission.Matched)
+		require.False(t, resp.PresentationSubmission.InputDescriptors[0].Matched)
+		require.Contains(t, resp.PresentationSubmission.InputDescriptors[0].Errors[0],
+			"nothing found at $.verifiableCredential[1]")
+	})
+
+	t.Run("presentation submission - missing", func(t *testing.T) {
+		code, body := verify(t, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, presexch.ErrSubmissionNotFound.Error())
+	})
+
+	t.Run("presentation submission - unknown definition", func(t *testing.T) {
+		code, body := verify(t, &presexch.PresentationSubmission{ID: "submission", DefinitionID: "other"})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "profile pe has no presentation definition other")
+	})
+
+	t.Run("presentation submission - invalid", func(t *testing.T) {
+		code, body := verify(t, "submission")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid presentation submission")
+	})
+}
+
+func TestValidatePresentationDefinitions(t *testing.T) {
+	pd := &presexch.PresentationDefinition{}
+	require.NoError(t, json.Unmarshal([]byte(presentationDefinition), pd))
+
+	require.NoError(t, validatePresentationDefinitions([]*presexch.PresentationDefinition{pd}))
+
+	err := validatePresentationDefinitions([]*presexch.PresentationDefinition{pd, pd})
+	require.EqualError(t, err, "invalid presentation definition - prc-definition is defined twice")
+
+	err = validatePresentationDefinitions([]*presexch.PresentationDefinition{nil})
+	require.EqualError(t, err, "invalid presentation definition - null")
+
+	err = validatePresentationDefinitions([]*presexch.PresentationDefinition{{ID: "empty"}})
+	require.EqualError(t, err,
+		"invalid presentation definition - presentation definition empty has no input descriptors")
+
+	err = validateProfileRequest(&verifier.ProfileData{
+		ID: "id", Name: "name", PresentationDefinitions: []*presexch.PresentationDefinition{{}},
+	})
+	require.EqualError(t, err, "invalid presentation definition - missing presentation definition id")
+}
+
+func getSignedVPWithSubmission(t *testing.T, privKey []byte, didID, verificationMethod string,
+	submission interface{}) []byte {
+	t.Helper()
+
+	loader := testutil.DocumentLoader(t)
+
+	vc, err := verifiable.ParseCredential(getSignedVC(t, privKey, prCardVC, didID, verificationMethod, "", ""),
+		verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	vp, err := verifiable.NewPresentation(verifiable.WithCredentials(vc))
+	require.NoError(t, err)
+
+	vp.Holder = didID
+
+	if submission != nil {
+		vp.CustomFields = verifiable.CustomFields{"presentation_submission": submission}
+	}
+
+	err = vp.AddLinkedDataProof(&verifiable.LinkedDataProofContext{
+		SignatureType:           "Ed25519Signature2018",
+		Suite:                   ed25519signature2018.New(suite.WithSigner(getEd25519TestSigner(privKey))),
+		SignatureRepresentation: verifiable.SignatureJWS,
+		VerificationMethod:      verificationMethod,
+		Domain:                  domain,
+		Challenge:               challenge,
+		Purpose:                 vccrypto.Authentication,
+	}, jsonld.WithDocumentLoader(loader))
+	require.NoError(t, err)
+
+	vpBytes, err := vp.MarshalJSON()
+	require.NoError(t, err)
+
+	return vpBytes
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	commhttp "github.com/trustbloc/edge-service/pkg/restapi/internal/common/http"
+)
+
+const profileLockKeyPrefix = "verifierprofile_"
+
+// ListProfiles swagger:route GET /verifier/profile verifier listProfilesReq
+//
+// Lists the verifier profiles sorted by id.
+//
+// Responses:
+//    default: genericError
+//        200: listProfilesRes
+func (o *Operation) listProfilesHandler(rw http.ResponseWriter, req *http.Request) {
+	page, pageSize, err := commhttp.GetPage(req)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	profiles, total, err := o.profileStore.ListProfiles(page, pageSize)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to list profiles: %s", err.Error()))
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, &ListProfilesResponse{
+		Profiles: profiles,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// UpdateProfile swagger:route PUT /verifier/profile/{id} verifier updateProfileReq
+//
+// Replaces the mutable fields of the verifier profile, the ones missing are reset.
+//
+// Responses:
+//    default: genericError
+//        200: profileData
+func (o *Operation) updateProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateProfile(rw, req, true)
+}
+
+// PatchProfile swagger:route PATCH /verifier/profile/{id} verifier updateProfileReq
+//
+// Updates the given mutable fields of the verifier profile, the ones missing are left unchanged.
+//
+// Responses:
+//    default: genericError
+//        200: profileData
+func (o *Operation) patchProfileHandler(rw http.ResponseWriter, req *http.Request) {
+	o.updateProfile(rw, req, false)
+}
+
+func (o *Operation) updateProfile(rw http.ResponseWriter, req *http.Request, replace bool) {
+	profileID := mux.Vars(req)[profileIDPathParam]
+
+	unlock, err := o.profileLocker.Lock(profileLockKeyPrefix + profileID)
+	if err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to lock profile %s: %s", profileID, err.Error()))
+
+		return
+	}
+
+	defer unlock()
+
+	profile, err := o.profileStore.GetProfile(profileID)
+	if err != nil {
+		if errors.Is(err, ariesstorage.ErrDataNotFound) {
+			commhttp.WriteErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("profile %s not found", profileID))
+
+			return
+		}
+
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	data := UpdateProfileRequest{}
+
+	if err = json.NewDecoder(req.Body).Decode(&data); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	data.apply(profile, replace)
+
+	if err = validateProfileRequest(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = o.profileStore.SaveProfile(profile); err != nil {
+		commhttp.WriteErrorResponse(rw, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	commhttp.WriteResponse(rw, profile)
+}
+
+// apply sets the fields of the request on the profile, resetting the ones missing when replacing them
+func (r *UpdateProfileRequest) apply(profile *verifier.ProfileData, replace bool) {
+	if replace {
+		profile.Name = ""
+		profile.CredentialChecks = nil
+		profile.PresentationChecks = nil
+		profile.PresentationDefinitions = nil
+		profile.RequireIssuedChallenge = false
+		profile.IssuerTrust = nil
+	}
+
+	if r.Name != nil {
+		profile.Name = *r.Name
+	}
+
+	if r.CredentialChecks != nil {
+		profile.CredentialChecks = *r.CredentialChecks
+	}
+
+	if r.PresentationChecks != nil {
+		profile.PresentationChecks = *r.PresentationChecks
+	}
+
+	if r.PresentationDefinitions != nil {
+		profile.PresentationDefinitions = *r.PresentationDefinitions
+	}
+
+	if r.RequireIssuedChallenge != nil {
+		profile.RequireIssuedChallenge = *r.RequireIssuedChallenge
+	}
+
+	if r.IssuerTrust != nil {
+		profile.IssuerTrust = r.IssuerTrust
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/presexch"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+)
+
+func TestListProfiles(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	for _, id := range []string{"profile3", "profile1", "profile2"} {
+		require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{ID: id, Name: id}))
+	}
+
+	handler := getHandler(t, op, profileEndpoint, http.MethodGet)
+
+	t.Run("list profiles - success", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, profileEndpoint+"?page=1&pageSize=1", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &ListProfilesResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, 3, resp.Total)
+		require.Len(t, resp.Profiles, 1)
+		require.Equal(t, "profile2", resp.Profiles[0].ID)
+	})
+
+	t.Run("list profiles - invalid page", func(t *testing.T) {
+		rr := serveHTTPMux(t, handler, profileEndpoint+"?page=a", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid page")
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+	op, err := New(&Config{
+		StoreProvider: ariesmemstorage.NewProvider(),
+		VDRI:          &vdrmock.MockVDRegistry{},
+	})
+	require.NoError(t, err)
+
+	profile := &verifier.ProfileData{
+		ID:                 testProfileID,
+		Name:               "test",
+		CredentialChecks:   []string{proofCheck, statusCheck},
+		PresentationChecks: []string{proofCheck},
+	}
+
+	urlVars := map[string]string{profileIDPathParam: profile.ID}
+
+	update := func(t *testing.T, method string, req interface{}) (int, *verifier.ProfileData, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, method), updateProfileEndpoint, reqBytes,
+			urlVars)
+
+		updated := &verifier.ProfileData{}
+
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), updated))
+		}
+
+		return rr.Code, updated, rr.Body.String()
+	}
+
+	t.Run("patch profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		checks := []string{schemaCheck}
+
+		code, updated, body := update(t, http.MethodPatch, &UpdateProfileRequest{CredentialChecks: &checks})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, checks, updated.CredentialChecks)
+		require.Equal(t, profile.Name, updated.Name)
+		require.Equal(t, profile.PresentationChecks, updated.PresentationChecks)
+
+		saved, err := op.profileStore.GetProfile(profile.ID)
+		require.NoError(t, err)
+		require.Equal(t, checks, saved.CredentialChecks)
+
+		definitions := []*presexch.PresentationDefinition{{
+			ID: "pd", InputDescriptors: []*presexch.InputDescriptor{{ID: "prc"}},
+		}}
+
+		code, updated, body = update(t, http.MethodPatch, &UpdateProfileRequest{PresentationDefinitions: &definitions})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, checks, updated.CredentialChecks)
+		require.NotNil(t, updated.PresentationDefinition("pd"))
+	})
+
+	t.Run("put profile - success", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		name := "other"
+
+		code, updated, body := update(t, http.MethodPut, &UpdateProfileRequest{Name: &name})
+		require.Equal(t, http.StatusOK, code, body)
+		require.Equal(t, name, updated.Name)
+		require.Equal(t, profile.ID, updated.ID)
+		require.Empty(t, updated.CredentialChecks)
+		require.Empty(t, updated.PresentationChecks)
+	})
+
+	t.Run("update profile - invalid fields", func(t *testing.T) {
+		require.NoError(t, op.profileStore.SaveProfile(profile))
+
+		code, _, body := update(t, http.MethodPut, &UpdateProfileRequest{})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing profile name")
+
+		checks := []string{"other"}
+
+		code, _, body = update(t, http.MethodPatch, &UpdateProfileRequest{CredentialChecks: &checks})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid credential check option - other")
+
+		definitions := []*presexch.PresentationDefinition{{ID: "pd"}}
+
+		code, _, body = update(t, http.MethodPatch, &UpdateProfileRequest{PresentationDefinitions: &definitions})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid presentation definition - presentation definition pd has no input descriptors")
+
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, http.MethodPatch), updateProfileEndpoint,
+			[]byte("{"), urlVars)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), invalidRequestErrMsg)
+	})
+
+	t.Run("update profile - not found", func(t *testing.T) {
+		rr := serveHTTPMux(t, getHandler(t, op, updateProfileEndpoint, http.MethodPut), updateProfileEndpoint,
+			[]byte("{}"), map[string]string{profileIDPathParam: "other"})
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "profile other not found")
+	})
+
+	t.Run("update profile - lock error", func(t *testing.T) {
+		locked, err := New(&Config{
+			StoreProvider: ariesmemstorage.NewProvider(),
+			VDRI:          &vdrmock.MockVDRegistry{},
+			ProfileLocker: &mockLocker{err: errors.New("lock error")},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, locked, updateProfileEndpoint, http.MethodPatch), updateProfileEndpoint,
+			[]byte("{}"), urlVars)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to lock profile "+profile.ID+": lock error")
+	})
+}
+
+type mockLocker struct {
+	err error
+}
+
+func (m *mockLocker) Lock(string) (func(), error) {
+	return func() {}, m.err
+}
@@ -0,0 +1,358 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
+)
+
+// error codes of the verification report
+const (
+	errCodeProofInvalid                   = "PROOF_INVALID"
+	errCodeStatusInvalid                  = "STATUS_INVALID"
+	errCodeStatusUnavailable              = "STATUS_UNAVAILABLE"
+	errCodeStatusRevoked                  = "STATUS_REVOKED"
+	errCodeStatusSuspended                = "STATUS_SUSPENDED"
+	errCodeSchemaInvalid                  = "SCHEMA_INVALID"
+	errCodeValidityInvalid                = "VALIDITY_INVALID"
+	errCodeIssuerNotTrusted               = "ISSUER_NOT_TRUSTED"
+	errCodeChallengeInvalid               = "CHALLENGE_INVALID"
+	errCodePresentationDefinitionMismatch = "PRESENTATION_DEFINITION_MISMATCH"
+	errCodeCheckNotSupported              = "CHECK_NOT_SUPPORTED"
+	errCodeCredentialInvalid              = "CREDENTIAL_INVALID"
+	errCodePresentationInvalid            = "PRESENTATION_INVALID"
+
+	ldpVCFormat   = "ldp_vc"
+	jwtVCFormat   = "jwt_vc"
+	sdJWTVCFormat = "vc+sd-jwt"
+)
+
+var errCheckNotSupported = errors.New("check not supported")
+
+// checkErrorCodes are the error codes of the failed checks, unless the error tells a more specific one.
+var checkErrorCodes = map[string]string{ // nolint: gochecknoglobals
+	proofCheck:                  errCodeProofInvalid,
+	statusCheck:                 errCodeStatusInvalid,
+	schemaCheck:                 errCodeSchemaInvalid,
+	validityCheck:               errCodeValidityInvalid,
+	issuerTrustCheck:            errCodeIssuerNotTrusted,
+	challengeCheck:              errCodeChallengeInvalid,
+	presentationDefinitionCheck: errCodePresentationDefinitionMismatch,
+}
+
+// checkError is the error of a failed check with the code it is reported with.
+type checkError struct {
+	code string
+	err  error
+}
+
+func (e *checkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *checkError) Unwrap() error {
+	return e.err
+}
+
+func errorCode(check string, err error) string {
+	var checkErr *checkError
+	if errors.As(err, &checkErr) {
+		return checkErr.code
+	}
+
+	if errors.Is(err, errCheckNotSupported) {
+		return errCodeCheckNotSupported
+	}
+
+	return checkErrorCodes[check]
+}
+
+func newCheckReport(check string, err error, start time.Time) *CheckReport {
+	report := &CheckReport{Check: check, Passed: err == nil, Duration: time.Since(start)}
+
+	if err != nil {
+		report.ErrorCode = errorCode(check, err)
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+func newVerificationReport(start time.Time, checks []*CheckReport,
+	credentials []*CredentialReport) *VerificationReport {
+	return &VerificationReport{
+		VerifiedAt:  start.UTC(),
+		Duration:    time.Since(start),
+		Checks:      checks,
+		Credentials: credentials,
+	}
+}
+
+// resolutionRecorder resolves each DID once for a verification request and keeps its resolution, so that every
+// check uses the same DID document and the report tells the version the proof was verified against.
+type resolutionRecorder struct {
+	vdrapi.Registry
+	mutex       sync.Mutex
+	resolutions map[string]*did.DocResolution
+}
+
+func (r *resolutionRecorder) Resolve(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if docResolution, ok := r.resolutions[didID]; ok {
+		return docResolution, nil
+	}
+
+	docResolution, err := r.Registry.Resolve(didID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.resolutions[didID] = docResolution
+
+	return docResolution, nil
+}
+
+func (r *resolutionRecorder) resolution(didID string) (*did.DocResolution, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	docResolution, ok := r.resolutions[didID]
+
+	return docResolution, ok
+}
+
+// withResolutionRecorder returns a copy of the operation for a verification request, recording the DID
+// resolutions of its checks.
+func (o *Operation) withResolutionRecorder() (*Operation, *resolutionRecorder) {
+	recorder := &resolutionRecorder{Registry: o.vdr, resolutions: make(map[string]*did.DocResolution)}
+
+	op := *o
+	op.vdr = recorder
+
+	return &op, recorder
+}
+
+// newCredentialReport reports the format, issuer, proof and status of the credential.
+func newCredentialReport(cred *requestCredential, resolutions *resolutionRecorder) *CredentialReport {
+	report := credentialDetails(cred.vc)
+
+	switch {
+	case cred.sdJWT != nil:
+		report.Format = sdJWTVCFormat
+		report.ProofType = cred.sdJWT.alg
+		report.VerificationMethod = cred.sdJWT.issuerKeyID
+	case cred.jwt != nil:
+		report.Format = jwtVCFormat
+		report.ProofType = cred.jwt.token.alg
+		report.VerificationMethod = cred.jwt.token.keyID
+	default:
+		report.Format = ldpVCFormat
+
+		if len(cred.vc.Proofs) != 0 {
+			report.ProofType, _ = cred.vc.Proofs[0]["type"].(string)                      // nolint: errcheck
+			report.VerificationMethod, _ = cred.vc.Proofs[0][verificationMethod].(string) // nolint: errcheck
+		}
+	}
+
+	if report.VerificationMethod != "" {
+		report.DIDDocument = didDocumentReport(report.VerificationMethod, resolutions)
+	}
+
+	return report
+}
+
+func credentialDetails(vc *verifiable.Credential) *CredentialReport {
+	report := &CredentialReport{ID: vc.ID, Types: vc.Types, Issuer: vc.Issuer.ID}
+
+	if vc.Status != nil {
+		report.Status = statusReport(vc.Status)
+	}
+
+	return report
+}
+
+// didDocumentReport reports the version of the DID document of the verification method the proof was verified
+// against.
+func didDocumentReport(method string, resolutions *resolutionRecorder) *DIDDocumentReport {
+	didID, err := diddoc.GetDIDFromVerificationMethod(method)
+	if err != nil {
+		return &DIDDocumentReport{Error: err.Error()}
+	}
+
+	report := &DIDDocumentReport{ID: didID}
+
+	docResolution, ok := resolutions.resolution(didID)
+	if !ok {
+		report.Error = fmt.Sprintf("%s wasn't resolved to verify the proof", didID)
+
+		return report
+	}
+
+	report.Updated = docResolution.DIDDocument.Updated
+
+	if metadata := docResolution.DocumentMetadata; metadata != nil {
+		report.CanonicalID = metadata.CanonicalID
+		report.Deactivated = metadata.Deactivated
+
+		if metadata.Method != nil {
+			report.UpdateCommitment = metadata.Method.UpdateCommitment
+		}
+	}
+
+	return report
+}
+
+func statusReport(status *verifiable.TypedID) *StatusReport {
+	listCredentialKey, listIndexKey := csl.RevocationListCredential, csl.RevocationListIndex
+	if status.Type == csl.StatusList2021Entry {
+		listCredentialKey, listIndexKey = csl.StatusListCredential, csl.StatusListIndex
+	}
+
+	report := &StatusReport{Type: status.Type}
+
+	report.ListURL, _ = status.CustomFields[listCredentialKey].(string) // nolint: errcheck
+	report.Purpose, _ = status.CustomFields[csl.StatusPurpose].(string) // nolint: errcheck
+
+	if index, err := strconv.Atoi(fmt.Sprint(status.CustomFields[listIndexKey])); err == nil {
+		report.Index = &index
+	}
+
+	return report
+}
+
+// presentationCredentialReports reports each credential of the presentation, with the results of the checks
+// of the presentation that apply to its credentials.
+func (o *Operation) presentationCredentialReports(vpBytes []byte, profile *verifier.ProfileData,
+	checks []string, resolutions *resolutionRecorder) ([]*CredentialReport, error) {
+	credentials, err := getPresentationCredentials(vpBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the credentials of the presentation: %w", err)
+	}
+
+	reports := make([]*CredentialReport, 0, len(credentials))
+
+	for _, raw := range credentials {
+		cred, err := o.parseRequestCredential(raw)
+		if err != nil {
+			reports = append(reports, o.invalidCredentialReport(raw, err))
+
+			continue
+		}
+
+		report := newCredentialReport(cred, resolutions)
+
+		for _, check := range checks {
+			if check != proofCheck && check != statusCheck && check != issuerTrustCheck {
+				continue
+			}
+
+			checkStart := time.Now()
+
+			err := o.checkCredential(check, cred, profile, nil, true)
+
+			report.Checks = append(report.Checks, newCheckReport(check, err, checkStart))
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// invalidCredentialReport reports the credential which couldn't be verified, with what can be parsed from it.
+func (o *Operation) invalidCredentialReport(raw json.RawMessage, err error) *CredentialReport {
+	report := &CredentialReport{}
+
+	vcBytes := []byte(raw)
+	if s, ok := getJWT(raw); ok {
+		vcBytes = []byte(s)
+	}
+
+	vc, errParse := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+		verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if errParse == nil {
+		report = credentialDetails(vc)
+	}
+
+	report.ErrorCode = errCodeCredentialInvalid
+	report.Error = err.Error()
+
+	return report
+}
+
+// getPresentationCredentials returns the credentials of the presentation as they were issued, before parsing
+// decodes the credentials in the JWT format.
+func getPresentationCredentials(vpBytes []byte) ([]json.RawMessage, error) {
+	var creds []interface{}
+
+	if s, ok := getJWT(vpBytes); ok {
+		payload, err := base64.RawURLEncoding.DecodeString(strings.Split(s, ".")[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode jwt claims: %w", err)
+		}
+
+		claims := &jwtClaims{}
+		if err = json.Unmarshal(payload, claims); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal jwt claims: %w", err)
+		}
+
+		creds = claims.presentationCredentials()
+	} else {
+		vp := &struct {
+			Credential interface{} `json:"verifiableCredential,omitempty"`
+		}{}
+
+		if err := json.Unmarshal(vpBytes, vp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal presentation: %w", err)
+		}
+
+		creds = credentialList(vp.Credential)
+	}
+
+	credentials := make([]json.RawMessage, 0, len(creds))
+
+	for _, cred := range creds {
+		raw, err := json.Marshal(cred)
+		if err != nil {
+			return nil, err
+		}
+
+		credentials = append(credentials, raw)
+	}
+
+	return credentials, nil
+}
+
+// credentialList returns the verifiableCredential property of a presentation, which can be a single credential.
+func credentialList(credential interface{}) []interface{} {
+	if credential == nil {
+		return nil
+	}
+
+	if creds, ok := credential.([]interface{}); ok {
+		return creds
+	}
+
+	return []interface{}{credential}
+}
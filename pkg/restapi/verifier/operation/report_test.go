@@ -0,0 +1,309 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/status/csl"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestVerificationReport(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  mem.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: testProfileID, Name: "test",
+		IssuerTrust: &verifier.IssuerTrustPolicy{DIDMethods: []string{"test"}},
+	}))
+
+	verify := func(t *testing.T, endpoint string, req interface{}) (int, []byte) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, endpoint, http.MethodPost), endpoint, reqBytes,
+			map[string]string{profileIDPathParam: testProfileID})
+
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	signedVC := getSignedVC(t, privKey, prCardVC, didID, verificationMethod, "", "")
+
+	t.Run("credential report - success", func(t *testing.T) {
+		code, body := verify(t, credentialsVerificationEndpoint, &CredentialsVerificationRequest{
+			Credential: signedVC,
+			Opts: &CredentialsVerificationOptions{
+				Checks: []string{proofCheck, issuerTrustCheck},
+				Report: true,
+			},
+		})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.NotNil(t, resp.Report)
+		require.False(t, resp.Report.VerifiedAt.IsZero())
+		require.Empty(t, resp.Report.Checks)
+		require.Len(t, resp.Report.Credentials, 1)
+
+		credReport := resp.Report.Credentials[0]
+		require.Equal(t, didID, credReport.Issuer)
+		require.Equal(t, ldpVCFormat, credReport.Format)
+		require.Equal(t, "Ed25519Signature2018", credReport.ProofType)
+		require.Equal(t, verificationMethod, credReport.VerificationMethod)
+		require.Equal(t, didID, credReport.DIDDocument.ID)
+		require.Empty(t, credReport.DIDDocument.Error)
+
+		require.Equal(t, csl.RevocationList2020Status, credReport.Status.Type)
+		require.Equal(t, "https://example.com/credentials/status/3", credReport.Status.ListURL)
+		require.Equal(t, 1, *credReport.Status.Index)
+
+		require.Len(t, credReport.Checks, 2)
+		require.Equal(t, proofCheck, credReport.Checks[0].Check)
+		require.True(t, credReport.Checks[0].Passed)
+		require.Equal(t, issuerTrustCheck, credReport.Checks[1].Check)
+		require.True(t, credReport.Checks[1].Passed)
+	})
+
+	t.Run("credential report - DID document the proof was verified against", func(t *testing.T) {
+		var resolutions int32
+
+		ops, err := New(&Config{
+			VDRI: &vdrmock.MockVDRegistry{
+				ResolveFunc: func(id string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					// every resolution returns a newer version of the document
+					updated := time.Now().Add(time.Duration(atomic.AddInt32(&resolutions, 1)) * time.Hour).UTC()
+
+					doc := createDIDDoc(didID, pubKey)
+					doc.Updated = &updated
+
+					return &did.DocResolution{DIDDocument: doc}, nil
+				},
+			},
+			StoreProvider:  mem.NewProvider(),
+			DocumentLoader: testutil.DocumentLoader(t),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, ops.profileStore.SaveProfile(&verifier.ProfileData{ID: testProfileID, Name: "test"}))
+
+		reqBytes, err := json.Marshal(&CredentialsVerificationRequest{
+			Credential: signedVC,
+			Opts:       &CredentialsVerificationOptions{Checks: []string{proofCheck}, Report: true},
+		})
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, ops, credentialsVerificationEndpoint, http.MethodPost),
+			credentialsVerificationEndpoint, reqBytes, map[string]string{profileIDPathParam: testProfileID})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+		// the DID is resolved once for the request, the report has the version of its only resolution
+		require.Equal(t, int32(1), atomic.LoadInt32(&resolutions))
+		require.NotNil(t, resp.Report.Credentials[0].DIDDocument.Updated)
+		require.Empty(t, resp.Report.Credentials[0].DIDDocument.Error)
+	})
+
+	t.Run("credential report - failed checks", func(t *testing.T) {
+		code, body := verify(t, credentialsVerificationEndpoint, &CredentialsVerificationRequest{
+			Credential: signedVC,
+			Opts: &CredentialsVerificationOptions{
+				Checks:    []string{proofCheck, "other"},
+				Challenge: challenge,
+				Report:    true,
+			},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Checks, 2)
+
+		checks := resp.Report.Credentials[0].Checks
+		require.Len(t, checks, 2)
+		require.False(t, checks[0].Passed)
+		require.Equal(t, errCodeProofInvalid, checks[0].ErrorCode)
+		require.Equal(t, resp.Checks[0].Error, checks[0].Error)
+		require.Equal(t, errCodeCheckNotSupported, checks[1].ErrorCode)
+	})
+
+	t.Run("credential report - not requested", func(t *testing.T) {
+		code, body := verify(t, credentialsVerificationEndpoint, &CredentialsVerificationRequest{
+			Credential: signedVC,
+		})
+		require.Equal(t, http.StatusOK, code, string(body))
+		require.NotContains(t, string(body), "report")
+	})
+
+	t.Run("presentation report - success", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, &VerifyPresentationRequest{
+			Presentation: getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+				domain, challenge),
+			Opts: &VerifyPresentationOptions{
+				Checks:    []string{proofCheck, issuerTrustCheck},
+				Domain:    domain,
+				Challenge: challenge,
+				Report:    true,
+			},
+		})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		resp := &VerifyPresentationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+
+		require.Len(t, resp.Report.Checks, 2)
+		require.True(t, resp.Report.Checks[0].Passed)
+		require.True(t, resp.Report.Checks[1].Passed)
+
+		require.Len(t, resp.Report.Credentials, 1)
+		require.Equal(t, didID, resp.Report.Credentials[0].Issuer)
+		require.Equal(t, verificationMethod, resp.Report.Credentials[0].VerificationMethod)
+		require.Len(t, resp.Report.Credentials[0].Checks, 2)
+		require.True(t, resp.Report.Credentials[0].Checks[0].Passed)
+	})
+
+	t.Run("presentation report - invalid credential", func(t *testing.T) {
+		tampered := strings.Replace(string(signedVC), "JOHN", "JANE", 1)
+		require.NotEqual(t, string(signedVC), tampered)
+
+		vp := `{
+			"@context": ["https://www.w3.org/2018/credentials/v1"],
+			"type": ["VerifiablePresentation"],
+			"verifiableCredential": [` + tampered + `]
+		}`
+
+		code, body := verify(t, presentationsVerificationEndpoint, &VerifyPresentationRequest{
+			Presentation: []byte(vp),
+			Opts: &VerifyPresentationOptions{
+				Checks: []string{issuerTrustCheck},
+				Report: true,
+			},
+		})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		resp := &VerifyPresentationSuccessResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Report.Credentials, 1)
+		require.Equal(t, errCodeCredentialInvalid, resp.Report.Credentials[0].ErrorCode)
+		require.Equal(t, didID, resp.Report.Credentials[0].Issuer)
+	})
+
+	t.Run("presentation report - credentials not reported", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, &VerifyPresentationRequest{
+			Presentation: []byte(`["not a presentation"]`),
+			Opts: &VerifyPresentationOptions{
+				Checks: []string{issuerTrustCheck},
+				Report: true,
+			},
+		})
+		require.Equal(t, http.StatusBadRequest, code, string(body))
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Empty(t, resp.Report.Credentials)
+		require.Equal(t, errCodePresentationInvalid, resp.Report.ErrorCode)
+		require.Contains(t, resp.Report.Error, "failed to get the credentials of the presentation")
+	})
+
+	t.Run("presentation report - failed challenge", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, &VerifyPresentationRequest{
+			Presentation: getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+				domain, challenge),
+			Opts: &VerifyPresentationOptions{
+				Checks: []string{proofCheck},
+				Domain: domain,
+				Report: true,
+			},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal(body, resp))
+		require.Len(t, resp.Report.Checks, 1)
+		require.Equal(t, errCodeProofInvalid, resp.Report.Checks[0].ErrorCode)
+
+		// the credential is verified on its own
+		require.True(t, resp.Report.Credentials[0].Checks[0].Passed)
+	})
+}
+
+func TestReportErrorCodes(t *testing.T) {
+	require.NoError(t, statusCheckError(&VerifyCredentialResponse{Verified: true, Message: successMsg}, nil))
+
+	err := statusCheckError(&VerifyCredentialResponse{Message: revokedMsg}, nil)
+	require.EqualError(t, err, revokedMsg)
+	require.Equal(t, errCodeStatusRevoked, errorCode(statusCheck, err))
+
+	err = statusCheckError(&VerifyCredentialResponse{Message: suspendedMsg}, nil)
+	require.Equal(t, errCodeStatusSuspended, errorCode(statusCheck, err))
+
+	err = statusCheckError(nil, errors.New("not found"))
+	require.EqualError(t, err, "failed to fetch the status : not found")
+	require.Equal(t, errCodeStatusUnavailable, errorCode(statusCheck, err))
+
+	require.Equal(t, errCodeStatusInvalid, errorCode(statusCheck, errors.New("invalid presentation")))
+	require.Equal(t, errCodeIssuerNotTrusted, errorCode(issuerTrustCheck, errors.New("not trusted")))
+	require.Equal(t, errCodeCheckNotSupported, errorCode("other", errCheckNotSupported))
+
+	report := statusReport(&verifiable.TypedID{Type: csl.StatusList2021Entry, CustomFields: verifiable.CustomFields{
+		csl.StatusListCredential: "https://example.com/status/1",
+		csl.StatusListIndex:      "invalid",
+		csl.StatusPurpose:        csl.StatusPurposeRevocation,
+	}})
+	require.Equal(t, "https://example.com/status/1", report.ListURL)
+	require.Equal(t, csl.StatusPurposeRevocation, report.Purpose)
+	require.Nil(t, report.Index)
+
+	o, err := New(&Config{
+		VDRI:          &vdrmock.MockVDRegistry{ResolveErr: errors.New("resolve error")},
+		StoreProvider: mem.NewProvider(),
+	})
+	require.NoError(t, err)
+
+	op, resolutions := o.withResolutionRecorder()
+
+	_, err = op.vdr.Resolve("did:example:issuer")
+	require.EqualError(t, err, "resolve error")
+
+	didReport := didDocumentReport("did:example:issuer#key-1", resolutions)
+	require.Equal(t, "did:example:issuer", didReport.ID)
+	require.Equal(t, "did:example:issuer wasn't resolved to verify the proof", didReport.Error)
+
+	require.NotEmpty(t, didDocumentReport("invalid", resolutions).Error)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/internal/common/diddoc"
+)
+
+// sdJWTCredential is a verified SD-JWT credential with its disclosed claims.
+type sdJWTCredential struct {
+	sdJWT       *sdjwt.SDJWT
+	vc          *verifiable.Credential
+	issuerKeyID string
+	holderKeyID string
+	alg         string
+}
+
+// getSDJWT returns the SD-JWT the credential of a request is serialized as, if any.
+func getSDJWT(credential json.RawMessage) (string, bool) {
+	var s string
+
+	if err := json.Unmarshal(credential, &s); err != nil || !sdjwt.IsSDJWT(s) {
+		return "", false
+	}
+
+	return s, true
+}
+
+// parseAndVerifySDJWT verifies the issuer signature of the SD-JWT and its disclosures, returning the credential
+// with the disclosed claims.
+func (o *Operation) parseAndVerifySDJWT(s string) (*sdJWTCredential, error) {
+	sdJWT, err := sdjwt.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := jose.ParseJWS(sdJWT.JWT, crypto.NewJWTVerifier(o.vdr, crypto.AssertionMethod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify sd-jwt: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	if err = json.Unmarshal(jws.Payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sd-jwt claims: %w", err)
+	}
+
+	holderKeyID, err := sdJWT.HolderKeyID()
+	if err != nil {
+		return nil, err
+	}
+
+	revealed, err := sdjwt.Reveal(claims, sdJWT.Disclosures)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sd-jwt disclosures: %w", err)
+	}
+
+	vcBytes, err := json.Marshal(revealed["vc"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal vc claim: %w", err)
+	}
+
+	vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+		verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+	if err != nil {
+		return nil, err
+	}
+
+	issuerKeyID, _ := jws.ProtectedHeaders.KeyID() // nolint: errcheck
+	alg, _ := jws.ProtectedHeaders.Algorithm()     // nolint: errcheck
+
+	return &sdJWTCredential{sdJWT: sdJWT, vc: vc, issuerKeyID: issuerKeyID, holderKeyID: holderKeyID, alg: alg}, nil
+}
+
+// validateSDJWTProof checks the issuer of the SD-JWT and the key binding to its holder.
+func (o *Operation) validateSDJWTProof(cred *sdJWTCredential, opts *CredentialsVerificationOptions) error {
+	if opts == nil {
+		opts = &CredentialsVerificationOptions{}
+	}
+
+	issuerDID, err := diddoc.GetDIDFromVerificationMethod(cred.issuerKeyID)
+	if err != nil {
+		return err
+	}
+
+	// validate if issuer matches the controller of verification method
+	if cred.vc.Issuer.ID != issuerDID {
+		return fmt.Errorf("controller of verification method doesn't match the issuer")
+	}
+
+	keyBinding := &sdjwt.KeyBindingClaims{}
+
+	switch {
+	case cred.holderKeyID != "":
+		keyBinding, err = o.verifyKeyBinding(cred)
+		if err != nil {
+			return err
+		}
+	case cred.sdJWT.KeyBinding != "":
+		return errors.New("key binding for a credential not bound to a holder")
+	}
+
+	if keyBinding.Nonce != opts.Challenge {
+		return fmt.Errorf("invalid %s in the key binding : expected=%s actual=%s", challenge, opts.Challenge,
+			keyBinding.Nonce)
+	}
+
+	if keyBinding.Audience != opts.Domain {
+		return fmt.Errorf("invalid %s in the key binding : expected=%s actual=%s", domain, opts.Domain,
+			keyBinding.Audience)
+	}
+
+	return nil
+}
+
+func (o *Operation) verifyKeyBinding(cred *sdJWTCredential) (*sdjwt.KeyBindingClaims, error) {
+	if cred.sdJWT.KeyBinding == "" {
+		return nil, errors.New("missing key binding of the holder")
+	}
+
+	jws, err := jose.ParseJWS(cred.sdJWT.KeyBinding, crypto.NewJWTVerifier(o.vdr, crypto.Authentication))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify key binding: %w", err)
+	}
+
+	if typ, _ := jws.ProtectedHeaders.Type(); typ != sdjwt.KeyBindingJWTType { // nolint: errcheck
+		return nil, fmt.Errorf("invalid key binding type %s", typ)
+	}
+
+	if kid, _ := jws.ProtectedHeaders.KeyID(); kid != cred.holderKeyID { // nolint: errcheck
+		return nil, errors.New("key binding not signed by the holder key")
+	}
+
+	claims := &sdjwt.KeyBindingClaims{}
+	if err = json.Unmarshal(jws.Payload, claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key binding claims: %w", err)
+	}
+
+	if claims.SDHash != cred.sdJWT.SDHash() {
+		return nil, errors.New("key binding doesn't match the presented disclosures")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	vccrypto "github.com/trustbloc/edge-service/pkg/doc/vc/crypto"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/doc/vc/sdjwt"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestVerifyCredential_SDJWT(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	issuerDID := "did:test:issuer"
+	holderDID := "did:test:holder"
+
+	issuerPubKey, issuerPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	holderPubKey, holderPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didDocs := map[string]*did.Doc{
+		issuerDID: createDIDDoc(issuerDID, issuerPubKey),
+		holderDID: createDIDDoc(holderDID, holderPubKey),
+	}
+
+	op, err := New(&Config{
+		VDRI: &vdrmock.MockVDRegistry{
+			ResolveFunc: func(didID string, _ ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				didDoc, ok := didDocs[didID]
+				if !ok {
+					return nil, fmt.Errorf("did %s not found", didID)
+				}
+
+				return &did.DocResolution{DIDDocument: didDoc}, nil
+			},
+		},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+	})
+	require.NoError(t, err)
+
+	vReq := &verifier.ProfileData{
+		ID:               "test",
+		Name:             "test verifier",
+		CredentialChecks: []string{proofCheck},
+	}
+
+	err = op.profileStore.SaveProfile(vReq)
+	require.NoError(t, err)
+
+	urlVars := map[string]string{profileIDPathParam: vReq.ID}
+	endpoint := "/test/verifier/credentials/verify"
+	handler := getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost)
+
+	vc, err := verifiable.ParseCredential([]byte(prCardVC), verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(loader))
+	require.NoError(t, err)
+
+	vc.Issuer.ID = issuerDID
+	vc.Status = nil
+
+	issuerKeyID := issuerDID + "#key-1"
+	holderKeyID := holderDID + "#key-1"
+
+	verify := func(t *testing.T, credential string, opts *CredentialsVerificationOptions) (int, string) {
+		t.Helper()
+
+		credentialBytes, errMarshal := json.Marshal(credential)
+		require.NoError(t, errMarshal)
+
+		reqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+			Credential: credentialBytes,
+			Opts:       opts,
+		})
+		require.NoError(t, errMarshal)
+
+		rr := serveHTTPMux(t, handler, endpoint, reqBytes, urlVars)
+
+		return rr.Code, rr.Body.String()
+	}
+
+	t.Run("sd-jwt verification - success", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, holderKeyID, "givenName", "birthDate")
+
+		presentation, errSelect := sdJWT.Select([]string{"givenName"})
+		require.NoError(t, errSelect)
+
+		presentation.KeyBinding = signKeyBinding(t, holderPrivKey, holderKeyID, presentation, challenge, domain)
+
+		code, body := verify(t, presentation.Serialize(), &CredentialsVerificationOptions{
+			Checks:    []string{proofCheck},
+			Challenge: challenge,
+			Domain:    domain,
+		})
+		require.Equal(t, http.StatusOK, code, body)
+
+		resp := &CredentialsVerificationSuccessResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Equal(t, []string{proofCheck}, resp.Checks)
+	})
+
+	t.Run("sd-jwt verification - success without holder binding", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, "", "givenName")
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("sd-jwt verification - invalid signature", func(t *testing.T) {
+		_, otherPrivKey, errGenerate := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, errGenerate)
+
+		sdJWT := createSDJWT(t, vc, otherPrivKey, issuerKeyID, "", "givenName")
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "jwt signature doesn't match")
+	})
+
+	t.Run("sd-jwt verification - tampered disclosure", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, "", "givenName")
+
+		disclosures, errDisclosable := sdjwt.MakeDisclosable(map[string]interface{}{"givenName": "JANE"},
+			[]string{"givenName"})
+		require.NoError(t, errDisclosable)
+
+		sdJWT.Disclosures = disclosures
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "disclosure without matching digest")
+	})
+
+	t.Run("sd-jwt verification - issuer doesn't match the signing key", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, holderPrivKey, holderKeyID, "", "givenName")
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "controller of verification method doesn't match the issuer")
+	})
+
+	t.Run("sd-jwt verification - missing key binding", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, holderKeyID, "givenName")
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "missing key binding of the holder")
+	})
+
+	t.Run("sd-jwt verification - key binding for unbound credential", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, "", "givenName")
+		sdJWT.KeyBinding = signKeyBinding(t, holderPrivKey, holderKeyID, sdJWT, challenge, domain)
+
+		code, body := verify(t, sdJWT.Serialize(), nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "key binding for a credential not bound to a holder")
+	})
+
+	t.Run("sd-jwt verification - key binding not signed by the holder", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, holderKeyID, "givenName")
+		sdJWT.KeyBinding = signKeyBinding(t, issuerPrivKey, issuerKeyID, sdJWT, challenge, domain)
+
+		code, body := verify(t, sdJWT.Serialize(), &CredentialsVerificationOptions{
+			Challenge: challenge,
+			Domain:    domain,
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "key binding not signed by the holder key")
+	})
+
+	t.Run("sd-jwt verification - key binding for other disclosures", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, holderKeyID, "givenName", "birthDate")
+
+		presentation, errSelect := sdJWT.Select([]string{"givenName"})
+		require.NoError(t, errSelect)
+
+		sdJWT.KeyBinding = signKeyBinding(t, holderPrivKey, holderKeyID, presentation, challenge, domain)
+
+		code, body := verify(t, sdJWT.Serialize(), &CredentialsVerificationOptions{
+			Challenge: challenge,
+			Domain:    domain,
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "key binding doesn't match the presented disclosures")
+	})
+
+	t.Run("sd-jwt verification - invalid challenge and domain", func(t *testing.T) {
+		sdJWT := createSDJWT(t, vc, issuerPrivKey, issuerKeyID, holderKeyID, "givenName")
+		sdJWT.KeyBinding = signKeyBinding(t, holderPrivKey, holderKeyID, sdJWT, challenge, domain)
+
+		code, body := verify(t, sdJWT.Serialize(), &CredentialsVerificationOptions{
+			Challenge: "other-challenge",
+			Domain:    domain,
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid challenge in the key binding")
+
+		code, body = verify(t, sdJWT.Serialize(), &CredentialsVerificationOptions{
+			Challenge: challenge,
+			Domain:    "other-domain",
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "invalid domain in the key binding")
+	})
+}
+
+// createSDJWT signs the vc as SD-JWT with the given ed25519 key, like the issuer does through its kms.
+func createSDJWT(t *testing.T, vc *verifiable.Credential, privKey ed25519.PrivateKey, keyID, holderKeyID string,
+	disclosable ...string) *sdjwt.SDJWT {
+	t.Helper()
+
+	vcClaims, err := vc.JWTClaims(false)
+	require.NoError(t, err)
+
+	claimsBytes, err := json.Marshal(vcClaims)
+	require.NoError(t, err)
+
+	claims := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(claimsBytes, &claims))
+
+	subject, ok := claims["vc"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	require.True(t, ok)
+
+	disclosures, err := sdjwt.MakeDisclosable(subject, disclosable)
+	require.NoError(t, err)
+
+	claims[sdjwt.SDAlgKey] = sdjwt.SHA256
+
+	if holderKeyID != "" {
+		claims[sdjwt.CNFKey] = &sdjwt.Confirmation{KeyID: holderKeyID}
+	}
+
+	return &sdjwt.SDJWT{
+		JWT:         signTestJWT(t, privKey, keyID, jwt.TypeJWT, claims),
+		Disclosures: disclosures,
+	}
+}
+
+func signKeyBinding(t *testing.T, privKey ed25519.PrivateKey, keyID string, sdJWT *sdjwt.SDJWT,
+	nonce, audience string) string {
+	t.Helper()
+
+	return signTestJWT(t, privKey, keyID, sdjwt.KeyBindingJWTType, &sdjwt.KeyBindingClaims{
+		Nonce:    nonce,
+		Audience: audience,
+		IssuedAt: time.Now().Unix(),
+		SDHash:   sdJWT.SDHash(),
+	})
+}
+
+func signTestJWT(t *testing.T, privKey ed25519.PrivateKey, keyID, typ string, claims interface{}) string {
+	t.Helper()
+
+	token, err := jwt.NewSigned(claims, jose.Headers{jose.HeaderKeyID: keyID}, &jwsTestSigner{
+		ed25519TestSigner: getEd25519TestSigner(privKey),
+		headers:           jose.Headers{jose.HeaderAlgorithm: vccrypto.EdDSA, jose.HeaderType: typ},
+	})
+	require.NoError(t, err)
+
+	s, err := token.Serialize(false)
+	require.NoError(t, err)
+
+	return s
+}
+
+type jwsTestSigner struct {
+	*ed25519TestSigner
+	headers jose.Headers
+}
+
+func (s *jwsTestSigner) Headers() jose.Headers {
+	return s.headers
+}
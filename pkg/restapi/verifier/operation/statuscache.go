@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+const defaultStatusListCacheSize = 1000
+
+// statusListCache keeps verified status list vcs. An entry is used as is until it is older than the
+// configured TTL or the max age allowed by the issuer, whichever is shorter, after that it is revalidated
+// with a conditional request and verified again only if the list has changed. Expired entries are kept for
+// revalidation for the configured TTL at most, and the least recently used entries are evicted once the cache
+// is full, the URLs come from the credentials verified.
+type statusListCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	recent     *list.List
+}
+
+type statusListEntry struct {
+	url          string
+	vc           *verifiable.Credential
+	etag         string
+	lastModified string
+	expires      time.Time
+	dropAt       time.Time
+}
+
+func newStatusListCache(ttl time.Duration, maxEntries int) *statusListCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultStatusListCacheSize
+	}
+
+	return &statusListCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+func (c *statusListCache) get(url string) *statusListEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[url]
+	if !ok {
+		return nil
+	}
+
+	entry, ok := entryOf(element)
+	if !ok {
+		logger.Warnf("status list cache holds an unexpected value for %s, dropping it", url)
+
+		c.remove(element)
+
+		return nil
+	}
+
+	if time.Now().After(entry.dropAt) {
+		c.remove(element)
+
+		return nil
+	}
+
+	c.recent.MoveToFront(element)
+
+	return entry
+}
+
+// put caches the status list vc according to the caching headers of the response it was fetched with.
+func (c *statusListCache) put(url string, vc *verifiable.Credential, header http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	maxAge, store := parseCacheControl(header.Get("Cache-Control"))
+	if !store {
+		if element, ok := c.entries[url]; ok {
+			c.remove(element)
+		}
+
+		return
+	}
+
+	ttl := c.ttl
+	if maxAge >= 0 && maxAge < ttl {
+		ttl = maxAge
+	}
+
+	expires := time.Now().Add(ttl)
+
+	entry := &statusListEntry{
+		url:          url,
+		vc:           vc,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		expires:      expires,
+		dropAt:       expires.Add(c.ttl),
+	}
+
+	if element, ok := c.entries[url]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[url] = c.recent.PushFront(entry)
+
+	if c.recent.Len() > c.maxEntries {
+		c.remove(c.recent.Back())
+	}
+}
+
+// remove drops the element, an element not holding an entry is looked up by value since its URL is unknown.
+func (c *statusListCache) remove(element *list.Element) {
+	c.recent.Remove(element)
+
+	if entry, ok := entryOf(element); ok {
+		delete(c.entries, entry.url)
+
+		return
+	}
+
+	for url, e := range c.entries {
+		if e == element {
+			delete(c.entries, url)
+		}
+	}
+}
+
+func entryOf(element *list.Element) (*statusListEntry, bool) {
+	entry, ok := element.Value.(*statusListEntry)
+
+	return entry, ok
+}
+
+// parseCacheControl returns the max age the response may be used for, negative if not limited,
+// and whether the response may be cached at all.
+func parseCacheControl(cacheControl string) (time.Duration, bool) {
+	maxAge := time.Duration(-1)
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			maxAge = 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+			if err != nil || seconds < 0 {
+				maxAge = 0
+
+				continue
+			}
+
+			if maxAge < 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return maxAge, true
+}
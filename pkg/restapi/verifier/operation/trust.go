@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+)
+
+const (
+	governanceCredentialType = "GovernanceCredential"
+
+	didPrefix = "did:"
+)
+
+// governanceFramework holds the participants a governance credential defines
+type governanceFramework struct {
+	CredentialSubject struct {
+		Define []struct {
+			Name string `json:"name,omitempty"`
+			ID   string `json:"id"`
+		} `json:"define"`
+	} `json:"credentialSubject"`
+}
+
+// validateIssuerTrust checks that the policy trusts the issuer of the credential, for its types.
+func (o *Operation) validateIssuerTrust(policy *verifier.IssuerTrustPolicy, vc *verifiable.Credential) error {
+	if policy == nil {
+		return errors.New("verifier profile has no issuer trust policy")
+	}
+
+	issuer := vc.Issuer.ID
+
+	for _, method := range policy.DIDMethods {
+		if getDIDMethod(issuer) == method {
+			return nil
+		}
+	}
+
+	var notTrustedForTypes bool
+
+	for _, trusted := range policy.Issuers {
+		if trusted.DID != issuer {
+			continue
+		}
+
+		if len(trusted.CredentialTypes) == 0 || hasAnyType(vc, trusted.CredentialTypes) {
+			return nil
+		}
+
+		notTrustedForTypes = true
+	}
+
+	if len(policy.GovernanceFramework) != 0 {
+		participants, err := o.getGovernanceParticipants(policy.GovernanceFramework)
+		if err != nil {
+			return err
+		}
+
+		if participants[issuer] {
+			return nil
+		}
+	}
+
+	if notTrustedForTypes {
+		return fmt.Errorf("issuer %s isn't trusted for credential types %s", issuer, strings.Join(vc.Types, ", "))
+	}
+
+	return fmt.Errorf("issuer %s isn't trusted", issuer)
+}
+
+// validatePresentationIssuerTrust checks the issuers of the credentials of the presentation.
+func (o *Operation) validatePresentationIssuerTrust(policy *verifier.IssuerTrustPolicy, vpBytes []byte) error {
+	vp, err := o.parseAndVerifyVP(vpBytes, false, false, false)
+	if err != nil {
+		return err
+	}
+
+	for _, cred := range vp.Credentials() {
+		vcBytes, err := credentialBytes(cred)
+		if err != nil {
+			return err
+		}
+
+		vc, err := verifiable.ParseCredential(vcBytes, verifiable.WithDisabledProofCheck(),
+			verifiable.WithNoCustomSchemaCheck(), verifiable.WithJSONLDDocumentLoader(o.documentLoader))
+		if err != nil {
+			return err
+		}
+
+		if err = o.validateIssuerTrust(policy, vc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getGovernanceParticipants verifies the governance credential and returns the DIDs it defines.
+func (o *Operation) getGovernanceParticipants(governanceVC json.RawMessage) (map[string]bool, error) {
+	vc, err := o.parseAndVerifyVC(governanceVC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid governance framework credential: %w", err)
+	}
+
+	// credentials without proof are parsed without error
+	if len(vc.Proofs) == 0 {
+		return nil, errors.New("invalid governance framework credential: no proof")
+	}
+
+	if !hasAnyType(vc, []string{governanceCredentialType}) {
+		return nil, errors.New("governance framework credential isn't a " + governanceCredentialType)
+	}
+
+	framework := &governanceFramework{}
+
+	if err = json.Unmarshal(governanceVC, framework); err != nil {
+		return nil, fmt.Errorf("invalid governance framework credential: %w", err)
+	}
+
+	participants := make(map[string]bool)
+
+	for _, participant := range framework.CredentialSubject.Define {
+		participants[participant.ID] = true
+	}
+
+	return participants, nil
+}
+
+func validateIssuerTrustPolicy(policy *verifier.IssuerTrustPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	for _, trusted := range policy.Issuers {
+		if trusted == nil || !strings.HasPrefix(trusted.DID, didPrefix) {
+			return errors.New("invalid issuer trust policy - trusted issuers need a DID")
+		}
+	}
+
+	for _, method := range policy.DIDMethods {
+		if method == "" || strings.Contains(method, ":") {
+			return fmt.Errorf("invalid issuer trust policy - invalid DID method : %s", method)
+		}
+	}
+
+	if len(policy.GovernanceFramework) != 0 {
+		if err := json.Unmarshal(policy.GovernanceFramework, &governanceFramework{}); err != nil {
+			return fmt.Errorf("invalid issuer trust policy - invalid governance framework credential: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func getDIDMethod(didID string) string {
+	if !strings.HasPrefix(didID, didPrefix) {
+		return ""
+	}
+
+	return strings.SplitN(strings.TrimPrefix(didID, didPrefix), ":", 2)[0] // nolint: gomnd
+}
+
+func hasAnyType(vc *verifiable.Credential, types []string) bool {
+	for _, t := range vc.Types {
+		for _, allowed := range types {
+			if t == allowed {
+				return true
+			}
+		}
+	}
+
+	return false
+}
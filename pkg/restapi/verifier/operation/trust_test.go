@@ -0,0 +1,238 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/doc/vc/profile/verifier"
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+const governanceVC = `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://trustbloc.github.io/context/governance/context.jsonld"
+  ],
+  "type": ["VerifiableCredential", "%s"],
+  "issuer": "did:example:governance",
+  "issuanceDate": "2021-01-01T00:00:00Z",
+  "credentialSubject": {
+    "name": "trustbloc",
+    "define": [{"name": "DID", "id": "%s"}]
+  }
+}`
+
+func TestValidateIssuerTrust(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  mem.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	vc := &verifiable.Credential{
+		Types:  []string{"VerifiableCredential", "PermanentResidentCard"},
+		Issuer: verifiable.Issuer{ID: "did:example:issuer"},
+	}
+
+	t.Run("trusted issuer", func(t *testing.T) {
+		require.NoError(t, op.validateIssuerTrust(&verifier.IssuerTrustPolicy{
+			Issuers: []*verifier.TrustedIssuer{{DID: "did:example:issuer"}},
+		}, vc))
+
+		require.NoError(t, op.validateIssuerTrust(&verifier.IssuerTrustPolicy{
+			Issuers: []*verifier.TrustedIssuer{{
+				DID: "did:example:issuer", CredentialTypes: []string{"PermanentResidentCard"},
+			}},
+		}, vc))
+	})
+
+	t.Run("trusted DID method", func(t *testing.T) {
+		require.NoError(t, op.validateIssuerTrust(&verifier.IssuerTrustPolicy{DIDMethods: []string{"example"}}, vc))
+	})
+
+	t.Run("trusted by governance framework", func(t *testing.T) {
+		governance := getSignedVC(t, privKey, fmt.Sprintf(governanceVC, governanceCredentialType, vc.Issuer.ID),
+			didID, verificationMethod, "", "")
+
+		require.NoError(t, op.validateIssuerTrust(&verifier.IssuerTrustPolicy{GovernanceFramework: governance}, vc))
+
+		err := op.validateIssuerTrust(&verifier.IssuerTrustPolicy{GovernanceFramework: governance},
+			&verifiable.Credential{Issuer: verifiable.Issuer{ID: "did:example:other"}})
+		require.EqualError(t, err, "issuer did:example:other isn't trusted")
+	})
+
+	t.Run("not trusted for credential type", func(t *testing.T) {
+		err := op.validateIssuerTrust(&verifier.IssuerTrustPolicy{
+			Issuers: []*verifier.TrustedIssuer{{
+				DID: "did:example:issuer", CredentialTypes: []string{"UniversityDegree"},
+			}},
+		}, vc)
+		require.EqualError(t, err, "issuer did:example:issuer isn't trusted for credential types "+
+			"VerifiableCredential, PermanentResidentCard")
+	})
+
+	t.Run("not trusted", func(t *testing.T) {
+		err := op.validateIssuerTrust(&verifier.IssuerTrustPolicy{
+			Issuers:    []*verifier.TrustedIssuer{{DID: "did:example:other"}},
+			DIDMethods: []string{"orb"},
+		}, vc)
+		require.EqualError(t, err, "issuer did:example:issuer isn't trusted")
+
+		err = op.validateIssuerTrust(nil, vc)
+		require.EqualError(t, err, "verifier profile has no issuer trust policy")
+	})
+
+	t.Run("invalid governance framework", func(t *testing.T) {
+		err := op.validateIssuerTrust(&verifier.IssuerTrustPolicy{
+			GovernanceFramework: []byte(fmt.Sprintf(governanceVC, governanceCredentialType, vc.Issuer.ID)),
+		}, vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid governance framework credential")
+
+		other := getSignedVC(t, privKey, prCardVC, didID, verificationMethod, "", "")
+
+		err = op.validateIssuerTrust(&verifier.IssuerTrustPolicy{GovernanceFramework: other}, vc)
+		require.EqualError(t, err, "governance framework credential isn't a GovernanceCredential")
+	})
+}
+
+func TestVerifyIssuerTrust(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+	verificationMethod := didDoc.VerificationMethod[0].ID
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  mem.NewProvider(),
+		DocumentLoader: testutil.DocumentLoader(t),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: "trusted", Name: "trusted",
+		IssuerTrust: &verifier.IssuerTrustPolicy{
+			Issuers: []*verifier.TrustedIssuer{{DID: didID, CredentialTypes: []string{"PermanentResidentCard"}}},
+		},
+	}))
+	require.NoError(t, op.profileStore.SaveProfile(&verifier.ProfileData{
+		ID: "untrusted", Name: "untrusted",
+		IssuerTrust: &verifier.IssuerTrustPolicy{DIDMethods: []string{"orb"}},
+	}))
+
+	verify := func(t *testing.T, endpoint, profileID string, req interface{}) (int, string) {
+		t.Helper()
+
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rr := serveHTTPMux(t, getHandler(t, op, endpoint, http.MethodPost), endpoint, reqBytes,
+			map[string]string{profileIDPathParam: profileID})
+
+		return rr.Code, rr.Body.String()
+	}
+
+	vcReq := &CredentialsVerificationRequest{
+		Credential: getSignedVC(t, privKey, prCardVC, didID, verificationMethod, "", ""),
+		Opts:       &CredentialsVerificationOptions{Checks: []string{issuerTrustCheck}},
+	}
+
+	vpReq := &VerifyPresentationRequest{
+		Presentation: getSignedVP(t, privKey, prCardVC, didID, verificationMethod, didID, verificationMethod,
+			domain, challenge),
+		Opts: &VerifyPresentationOptions{Checks: []string{issuerTrustCheck}},
+	}
+
+	t.Run("credential - trusted issuer", func(t *testing.T) {
+		code, body := verify(t, credentialsVerificationEndpoint, "trusted", vcReq)
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("credential - untrusted issuer", func(t *testing.T) {
+		code, body := verify(t, credentialsVerificationEndpoint, "untrusted", vcReq)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Checks, 1)
+		require.Equal(t, issuerTrustCheck, resp.Checks[0].Check)
+		require.Equal(t, "issuer "+didID+" isn't trusted", resp.Checks[0].Error)
+	})
+
+	t.Run("presentation - trusted issuer", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, "trusted", vpReq)
+		require.Equal(t, http.StatusOK, code, body)
+	})
+
+	t.Run("presentation - untrusted issuer", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, "untrusted", vpReq)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		resp := &VerifyPresentationFailureResponse{}
+		require.NoError(t, json.Unmarshal([]byte(body), resp))
+		require.Len(t, resp.Checks, 1)
+		require.Equal(t, issuerTrustCheck, resp.Checks[0].Check)
+		require.Equal(t, "issuer "+didID+" isn't trusted", resp.Checks[0].Error)
+	})
+
+	t.Run("presentation - invalid presentation", func(t *testing.T) {
+		code, body := verify(t, presentationsVerificationEndpoint, "trusted", &VerifyPresentationRequest{
+			Presentation: []byte(`{}`),
+			Opts:         &VerifyPresentationOptions{Checks: []string{issuerTrustCheck}},
+		})
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, issuerTrustCheck)
+	})
+}
+
+func TestValidateIssuerTrustPolicy(t *testing.T) {
+	require.NoError(t, validateIssuerTrustPolicy(nil))
+	require.NoError(t, validateIssuerTrustPolicy(&verifier.IssuerTrustPolicy{
+		Issuers:             []*verifier.TrustedIssuer{{DID: "did:example:issuer"}},
+		DIDMethods:          []string{"orb"},
+		GovernanceFramework: []byte(fmt.Sprintf(governanceVC, governanceCredentialType, "did:example:issuer")),
+	}))
+
+	err := validateIssuerTrustPolicy(&verifier.IssuerTrustPolicy{
+		Issuers: []*verifier.TrustedIssuer{{DID: "issuer"}},
+	})
+	require.EqualError(t, err, "invalid issuer trust policy - trusted issuers need a DID")
+
+	err = validateIssuerTrustPolicy(&verifier.IssuerTrustPolicy{DIDMethods: []string{"did:orb"}})
+	require.EqualError(t, err, "invalid issuer trust policy - invalid DID method : did:orb")
+
+	err = validateIssuerTrustPolicy(&verifier.IssuerTrustPolicy{GovernanceFramework: []byte(`"governance"`)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid issuer trust policy - invalid governance framework credential")
+
+	err = validateProfileRequest(&verifier.ProfileData{
+		ID: "id", Name: "name", CredentialChecks: []string{issuerTrustCheck},
+		IssuerTrust: &verifier.IssuerTrustPolicy{DIDMethods: []string{""}},
+	})
+	require.EqualError(t, err, "invalid issuer trust policy - invalid DID method : ")
+}
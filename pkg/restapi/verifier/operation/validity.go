@@ -0,0 +1,33 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// validateValidityPeriod checks that the credential is valid at the given time, now if not set. The clocks of the
+// issuer and the verifier may differ by the clock skew.
+func (o *Operation) validateValidityPeriod(vc *verifiable.Credential, validAt *time.Time) error {
+	now := time.Now()
+	if validAt != nil {
+		now = *validAt
+	}
+
+	if vc.Issued != nil && vc.Issued.After(now.Add(o.clockSkew)) {
+		return fmt.Errorf("credential isn't valid before %s", vc.Issued.Format(time.RFC3339))
+	}
+
+	if vc.Expired != nil && vc.Expired.Before(now.Add(-o.clockSkew)) {
+		return fmt.Errorf("credential expired on %s", vc.Expired.Format(time.RFC3339))
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrmock "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-service/pkg/internal/testutil"
+)
+
+func TestVerifyCredential_ValidityCheck(t *testing.T) {
+	loader := testutil.DocumentLoader(t)
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	didID := "did:test:EiBNfNRaz1Ll8BjVsbNv-fWc7K_KIoPuW8GFCh1_Tz_Iuw=="
+	didDoc := createDIDDoc(didID, pubKey)
+
+	op, err := New(&Config{
+		VDRI:           &vdrmock.MockVDRegistry{ResolveValue: didDoc},
+		StoreProvider:  ariesmemstorage.NewProvider(),
+		DocumentLoader: loader,
+		ClockSkew:      time.Minute,
+	})
+	require.NoError(t, err)
+
+	saveTestProfile(t, op)
+
+	urlVars := map[string]string{profileIDPathParam: testProfileID}
+	handler := getHandler(t, op, credentialsVerificationEndpoint, http.MethodPost)
+
+	verify := func(t *testing.T, issued time.Time, expired, validAt *time.Time) *CredentialsVerificationFailResponse {
+		t.Helper()
+
+		vc, errParse := verifiable.ParseCredential([]byte(prCardVC), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader))
+		require.NoError(t, errParse)
+
+		vc.Issuer.ID = didDoc.ID
+		vc.Issued = util.NewTime(issued)
+		vc.Expired = nil
+
+		if expired != nil {
+			vc.Expired = util.NewTime(*expired)
+		}
+
+		vcBytes, errMarshal := vc.MarshalJSON()
+		require.NoError(t, errMarshal)
+
+		reqBytes, errMarshal := json.Marshal(&CredentialsVerificationRequest{
+			Credential: getSignedVC(t, privKey, string(vcBytes), didID, didDoc.VerificationMethod[0].ID, "", ""),
+			Opts: &CredentialsVerificationOptions{
+				Checks:  []string{validityCheck},
+				ValidAt: validAt,
+			},
+		})
+		require.NoError(t, errMarshal)
+
+		rr := serveHTTPMux(t, handler, "/"+testProfileID+"/verifier/credentials/verify", reqBytes, urlVars)
+		if rr.Code == http.StatusOK {
+			return nil
+		}
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+		verificationResp := &CredentialsVerificationFailResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), verificationResp))
+		require.Len(t, verificationResp.Checks, 1)
+		require.Equal(t, validityCheck, verificationResp.Checks[0].Check)
+
+		return verificationResp
+	}
+
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	expiring := now.Add(time.Hour)
+
+	t.Run("validity check - success", func(t *testing.T) {
+		require.Nil(t, verify(t, now.Add(-time.Hour), &expiring, nil))
+		require.Nil(t, verify(t, now.Add(-time.Hour), nil, nil))
+	})
+
+	t.Run("validity check - clock skew tolerated", func(t *testing.T) {
+		require.Nil(t, verify(t, now.Add(30*time.Second), &expiring, nil))
+
+		expiredNow := now.Add(-30 * time.Second)
+		require.Nil(t, verify(t, now.Add(-time.Hour), &expiredNow, nil))
+	})
+
+	t.Run("validity check - expired", func(t *testing.T) {
+		resp := verify(t, now.Add(-2*time.Hour), &expired, nil)
+		require.NotNil(t, resp)
+		require.Contains(t, resp.Checks[0].Error, "credential expired on")
+	})
+
+	t.Run("validity check - not yet valid", func(t *testing.T) {
+		resp := verify(t, now.Add(time.Hour), nil, nil)
+		require.NotNil(t, resp)
+		require.Contains(t, resp.Checks[0].Error, "credential isn't valid before")
+	})
+
+	t.Run("validity check - valid at the given time", func(t *testing.T) {
+		validAt := now.Add(-90 * time.Minute)
+		require.Nil(t, verify(t, now.Add(-2*time.Hour), &expired, &validAt))
+	})
+}